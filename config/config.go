@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type SystemConfig struct {
@@ -15,8 +16,24 @@ type SystemConfig struct {
 type OllamaConfig struct {
 	Host         string `toml:"host"`
 	DefaultModel string `toml:"default_model,omitempty"` // Kept for backward compatibility
+	ReasoningConfig
 }
 
+// ReasoningConfig controls how much reasoning models think before answering.
+// It is set per provider: under [ollama] or in a [[providers]] entry.
+type ReasoningConfig struct {
+	ReasoningEffort string `toml:"reasoning_effort,omitempty"` // "off", "low", "medium" or "high" (empty = model default)
+	ThinkingBudget  int    `toml:"thinking_budget,omitempty"`  // Max thinking tokens for Anthropic, Gemini and OpenRouter (0 = from effort)
+}
+
+// Reasoning effort levels accepted in reasoning_effort
+const (
+	ReasoningOff    = "off"
+	ReasoningLow    = "low"
+	ReasoningMedium = "medium"
+	ReasoningHigh   = "high"
+)
+
 // SecurityConfig defines how credentials are stored
 type SecurityConfig struct {
 	CredentialStorage string `toml:"credential_storage"` // "plaintext" or "ssh_key"
@@ -24,14 +41,28 @@ type SecurityConfig struct {
 }
 
 // ProviderConfig defines a cloud AI provider (Anthropic, OpenRouter, etc.)
+// or a custom OpenAI-compatible server (vLLM, llama.cpp, LM Studio, LiteLLM)
 type ProviderConfig struct {
-	ID      string `toml:"id"`       // e.g., "anthropic", "openrouter"
-	Name    string `toml:"name"`     // Display name
-	Enabled bool   `toml:"enabled"`  // Whether this provider is active
-	BaseURL string `toml:"base_url"` // API base URL
+	ID      string            `toml:"id"`                // e.g., "anthropic", "openrouter", "vllm"
+	Name    string            `toml:"name"`              // Display name
+	Type    string            `toml:"type,omitempty"`    // "openai-compatible" for custom providers, empty for built-ins
+	Enabled bool              `toml:"enabled"`           // Whether this provider is active
+	BaseURL string            `toml:"base_url"`          // API base URL
+	Timeout int               `toml:"timeout,omitempty"` // Generation timeout in seconds (0 = timeouts.generation)
+	Headers map[string]string `toml:"headers,omitempty"` // Extra HTTP headers sent with every request
+	ReasoningConfig
 	// API Key is stored separately in CredentialStore, not in config
 }
 
+// ProviderTypeOpenAICompatible marks a [[providers]] entry as a custom
+// server speaking the OpenAI chat completions API
+const ProviderTypeOpenAICompatible = "openai-compatible"
+
+// IsCustom reports whether the entry is a user-defined provider rather than a built-in
+func (p ProviderConfig) IsCustom() bool {
+	return p.Type == ProviderTypeOpenAICompatible
+}
+
 // CompactionConfig defines settings for context window management
 type CompactionConfig struct {
 	AutoCompact          bool    `toml:"auto_compact"`           // Whether to auto-compact when threshold reached
@@ -40,27 +71,98 @@ type CompactionConfig struct {
 	WarnAtPercentage     float64 `toml:"warn_at_percentage"`     // Percentage (0.0-1.0) at which to show warning
 }
 
+// SemanticSearchConfig turns on searching sessions by meaning: messages are
+// embedded with an Ollama embedding model as sessions are saved
+type SemanticSearchConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Model   string `toml:"model,omitempty"` // Ollama embedding model (default: nomic-embed-text)
+}
+
+// DefaultEmbeddingModel is used when [semantic_search] doesn't name a model
+const DefaultEmbeddingModel = "nomic-embed-text"
+
+// EmbeddingModel returns the Ollama model that embeds messages
+func (s SemanticSearchConfig) EmbeddingModel() string {
+	if s.Model == "" {
+		return DefaultEmbeddingModel
+	}
+	return s.Model
+}
+
+// TimeoutConfig defines how long to wait on providers and plugins, in seconds.
+// Zero means the built-in default. Per-provider and per-plugin settings
+// (ProviderConfig.Timeout, PluginConfigEntry.Timeout) take precedence.
+type TimeoutConfig struct {
+	Generation    int `toml:"generation,omitempty"`     // One model response (default: 120)
+	Compaction    int `toml:"compaction,omitempty"`     // Compaction summary generation (default: 30)
+	ToolListing   int `toml:"tool_listing,omitempty"`   // Listing tools and models (default: 10)
+	ToolExecution int `toml:"tool_execution,omitempty"` // One tool call (default: 120)
+}
+
+// Default timeouts used when TimeoutConfig leaves a value unset
+const (
+	DefaultGenerationTimeout    = 120 * time.Second
+	DefaultCompactionTimeout    = 30 * time.Second
+	DefaultToolListingTimeout   = 10 * time.Second
+	DefaultToolExecutionTimeout = 120 * time.Second
+)
+
+// Session stores accepted in session_store. JSON keeps one file per session,
+// which suits syncing the data directory; SQLite keeps them in sessions.db
+// with a full-text index for fast, ranked search.
+const (
+	SessionStoreJSON   = "json"
+	SessionStoreSQLite = "sqlite"
+)
+
+// DefaultToolConcurrency is how many calls to one plugin may run at once
+// when neither tool_concurrency nor the plugin's max_concurrency is set
+const DefaultToolConcurrency = 4
+
+// ModelPrice is what a model costs, in USD per million tokens
+type ModelPrice struct {
+	Input  float64 `toml:"input"`
+	Output float64 `toml:"output"`
+}
+
+// Cost returns the price of one request in USD
+func (p ModelPrice) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.Input + float64(outputTokens)*p.Output) / 1_000_000
+}
+
+// PriceTable maps provider ID to model name to price ([prices.<provider>] in config.toml)
+type PriceTable map[string]map[string]ModelPrice
+
 type UserConfig struct {
-	DefaultProvider        string           `toml:"default_provider,omitempty"`   // Which provider to use for new sessions
-	DefaultModel           string           `toml:"default_model,omitempty"`      // Default model (moved from Ollama)
-	LastUsedProvider       string           `toml:"last_used_provider,omitempty"` // Last provider user switched to
-	Ollama                 OllamaConfig     `toml:"ollama"`
-	DefaultSystemPrompt    string           `toml:"default_system_prompt,omitempty"`
-	PluginsEnabled         bool             `toml:"plugins_enabled"`
-	Security               SecurityConfig   `toml:"security"`
-	Providers              []ProviderConfig `toml:"providers,omitempty"`
-	AllowedTools           []string         `toml:"allowed_tools,omitempty"` // Global whitelist of tools that don't require approval
-	RequireApproval        bool             `toml:"require_approval"`        // Whether to ask for permission before executing tools
-	MaxIterations          int              `toml:"max_iterations"`          // Default: 10
-	EnableMultiStep        bool             `toml:"enable_multi_step"`       // Default: true
-	NotifyOnComplete       bool             `toml:"notify_on_complete"`      // Emit terminal bell when LLM response completes
-	Compaction             CompactionConfig `toml:"compaction,omitempty"`    // Context window management settings
-	ModelContextOverrides  map[string]int   `toml:"model_context_overrides,omitempty"` // Per-model context window overrides
+	DefaultProvider       string               `toml:"default_provider,omitempty"`   // Which provider to use for new sessions
+	DefaultModel          string               `toml:"default_model,omitempty"`      // Default model (moved from Ollama)
+	LastUsedProvider      string               `toml:"last_used_provider,omitempty"` // Last provider user switched to
+	Ollama                OllamaConfig         `toml:"ollama"`
+	DefaultSystemPrompt   string               `toml:"default_system_prompt,omitempty"`
+	PluginsEnabled        bool                 `toml:"plugins_enabled"`
+	Security              SecurityConfig       `toml:"security"`
+	Providers             []ProviderConfig     `toml:"providers,omitempty"`
+	AllowedTools          []string             `toml:"allowed_tools,omitempty"`           // Global whitelist of tools that don't require approval
+	RequireApproval       bool                 `toml:"require_approval"`                  // Whether to ask for permission before executing tools
+	MaxIterations         int                  `toml:"max_iterations"`                    // Default: 10
+	EnableMultiStep       bool                 `toml:"enable_multi_step"`                 // Default: true
+	NotifyOnComplete      bool                 `toml:"notify_on_complete"`                // Emit terminal bell when LLM response completes
+	Compaction            CompactionConfig     `toml:"compaction,omitempty"`              // Context window management settings
+	ModelContextOverrides map[string]int       `toml:"model_context_overrides,omitempty"` // Per-model context window overrides
+	Timeouts              TimeoutConfig        `toml:"timeouts,omitempty"`                // Provider and plugin timeouts
+	ToolConcurrency       int                  `toml:"tool_concurrency,omitempty"`        // Max parallel calls per plugin (default: 4)
+	Prices                PriceTable           `toml:"prices,omitempty"`                  // Per-model prices by provider
+	Generation            GenerationParams     `toml:"generation,omitempty"`              // Default sampling settings for sessions
+	Retry                 RetryConfig          `toml:"retry,omitempty"`                   // Retrying failed requests
+	Fallback              []FallbackTarget     `toml:"fallback,omitempty"`                // Models to try when the session's model fails
+	SessionStore          string               `toml:"session_store,omitempty"`           // "json" (default) or "sqlite"
+	SemanticSearch        SemanticSearchConfig `toml:"semantic_search,omitempty"`         // Searching sessions by meaning
 }
 
 type Config struct {
 	DataDirectory         string
 	OllamaHost            string
+	OllamaReasoning       ReasoningConfig // Thinking settings for the Ollama provider
 	DefaultModel          string
 	DefaultProvider       string // Which provider to use for new sessions
 	LastUsedProvider      string // Last provider user switched to
@@ -69,13 +171,21 @@ type Config struct {
 	Security              SecurityConfig
 	Providers             []ProviderConfig
 	CredentialStore       *CredentialStore
-	AllowedTools          []string // Global whitelist of tools that don't require approval
-	RequireApproval       bool     // Whether to ask for permission before executing tools
-	MaxIterations         int      // Max iterations per user message
-	EnableMultiStep       bool     // Allow LLM to execute multiple steps
-	NotifyOnComplete      bool     // Emit terminal bell when LLM response completes
-	Compaction            CompactionConfig // Context window management settings
-	ModelContextOverrides map[string]int   // Per-model context window overrides
+	AllowedTools          []string             // Global whitelist of tools that don't require approval
+	RequireApproval       bool                 // Whether to ask for permission before executing tools
+	MaxIterations         int                  // Max iterations per user message
+	EnableMultiStep       bool                 // Allow LLM to execute multiple steps
+	NotifyOnComplete      bool                 // Emit terminal bell when LLM response completes
+	Compaction            CompactionConfig     // Context window management settings
+	ModelContextOverrides map[string]int       // Per-model context window overrides
+	Timeouts              TimeoutConfig        // Provider and plugin timeouts
+	ToolConcurrency       int                  // Max parallel calls per plugin
+	Prices                PriceTable           // User price table
+	Generation            GenerationParams     // Default sampling settings for sessions
+	Retry                 RetryConfig          // Retrying failed requests
+	Fallback              []FallbackTarget     // Models to try when the session's model fails
+	SessionStore          string               // Where sessions are kept: SessionStoreJSON or SessionStoreSQLite
+	SemanticSearch        SemanticSearchConfig // Searching sessions by meaning
 	Keybindings           *KeyBindingsConfig
 }
 
@@ -94,6 +204,90 @@ func (c *Config) DataDir() string {
 	return ExpandPath(c.DataDirectory)
 }
 
+// secondsOr converts a timeout in seconds, falling back to def when unset
+func secondsOr(seconds int, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GenerationTimeout returns how long one response from providerID may take
+func (c *Config) GenerationTimeout(providerID string) time.Duration {
+	for _, p := range c.Providers {
+		if p.ID == providerID && p.Timeout > 0 {
+			return time.Duration(p.Timeout) * time.Second
+		}
+	}
+	return secondsOr(c.Timeouts.Generation, DefaultGenerationTimeout)
+}
+
+// CompactionTimeout returns how long generating a compaction summary may take
+func (c *Config) CompactionTimeout() time.Duration {
+	return secondsOr(c.Timeouts.Compaction, DefaultCompactionTimeout)
+}
+
+// ToolListingTimeout returns how long listing tools (or models) may take
+func (c *Config) ToolListingTimeout() time.Duration {
+	return secondsOr(c.Timeouts.ToolListing, DefaultToolListingTimeout)
+}
+
+// ToolExecutionTimeout returns the default time limit for one tool call
+// (plugins can override it in plugins.toml)
+func (c *Config) ToolExecutionTimeout() time.Duration {
+	return secondsOr(c.Timeouts.ToolExecution, DefaultToolExecutionTimeout)
+}
+
+// ToolConcurrencyLimit returns the default number of calls one plugin may
+// run at once (plugins can override it in plugins.toml)
+func (c *Config) ToolConcurrencyLimit() int {
+	if c.ToolConcurrency <= 0 {
+		return DefaultToolConcurrency
+	}
+	return c.ToolConcurrency
+}
+
+// CustomProviders returns the user-defined providers, in config order
+func (c *Config) CustomProviders() []ProviderConfig {
+	var custom []ProviderConfig
+	for _, p := range c.Providers {
+		if p.IsCustom() {
+			custom = append(custom, p)
+		}
+	}
+	return custom
+}
+
+// Reasoning returns the thinking settings for a provider
+func (c *Config) Reasoning(providerID string) ReasoningConfig {
+	if providerID == "ollama" {
+		return c.OllamaReasoning
+	}
+	for _, p := range c.Providers {
+		if p.ID == providerID {
+			return p.ReasoningConfig
+		}
+	}
+	return ReasoningConfig{}
+}
+
+// ProviderDisplayName returns the name shown for a provider: the configured
+// name of a custom provider, or the built-in name
+func (c *Config) ProviderDisplayName(providerID string) string {
+	for _, p := range c.Providers {
+		if p.ID == providerID && p.IsCustom() && p.Name != "" {
+			return p.Name
+		}
+	}
+	return getProviderDisplayName(providerID)
+}
+
+// LookupPrice returns the user's price for a model from the [prices] table
+func (c *Config) LookupPrice(providerID, model string) (ModelPrice, bool) {
+	price, ok := c.Prices[providerID][model]
+	return price, ok
+}
+
 func (c *Config) applyEnvOverrides() {
 	if host := os.Getenv("OTUI_OLLAMA_HOST"); host != "" {
 		c.OllamaHost = host
@@ -219,6 +413,7 @@ func Load() (*Config, error) {
 			return nil, fmt.Errorf("failed to load user config: %w", err)
 		}
 		cfg.OllamaHost = userCfg.Ollama.Host
+		cfg.OllamaReasoning = userCfg.Ollama.ReasoningConfig
 		cfg.DefaultModel = userCfg.DefaultModel         // Read from top-level first
 		cfg.DefaultProvider = userCfg.DefaultProvider   // NEW
 		cfg.LastUsedProvider = userCfg.LastUsedProvider // NEW
@@ -233,6 +428,14 @@ func Load() (*Config, error) {
 		cfg.NotifyOnComplete = userCfg.NotifyOnComplete
 		cfg.Compaction = userCfg.Compaction
 		cfg.ModelContextOverrides = userCfg.ModelContextOverrides
+		cfg.Timeouts = userCfg.Timeouts
+		cfg.ToolConcurrency = userCfg.ToolConcurrency
+		cfg.Prices = userCfg.Prices
+		cfg.Generation = userCfg.Generation
+		cfg.Retry = userCfg.Retry
+		cfg.Fallback = userCfg.Fallback
+		cfg.SessionStore = userCfg.SessionStore
+		cfg.SemanticSearch = userCfg.SemanticSearch
 
 		// Set defaults for multi-step execution (Phase 2)
 		if cfg.MaxIterations == 0 {
@@ -291,6 +494,7 @@ func Load() (*Config, error) {
 			return nil, fmt.Errorf("failed to load user config: %w", err)
 		}
 		cfg.OllamaHost = userCfg.Ollama.Host
+		cfg.OllamaReasoning = userCfg.Ollama.ReasoningConfig
 		cfg.DefaultModel = userCfg.DefaultModel         // Read from top-level first
 		cfg.DefaultProvider = userCfg.DefaultProvider   // NEW
 		cfg.LastUsedProvider = userCfg.LastUsedProvider // NEW
@@ -305,6 +509,14 @@ func Load() (*Config, error) {
 		cfg.NotifyOnComplete = userCfg.NotifyOnComplete
 		cfg.Compaction = userCfg.Compaction
 		cfg.ModelContextOverrides = userCfg.ModelContextOverrides
+		cfg.Timeouts = userCfg.Timeouts
+		cfg.ToolConcurrency = userCfg.ToolConcurrency
+		cfg.Prices = userCfg.Prices
+		cfg.Generation = userCfg.Generation
+		cfg.Retry = userCfg.Retry
+		cfg.Fallback = userCfg.Fallback
+		cfg.SessionStore = userCfg.SessionStore
+		cfg.SemanticSearch = userCfg.SemanticSearch
 
 		// Set defaults for multi-step execution (Phase 2)
 		if cfg.MaxIterations == 0 {
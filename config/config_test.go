@@ -2,8 +2,12 @@ package config
 
 import (
 	"crypto/rand"
+	"math"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
 )
 
 // --- defaults.go ---
@@ -147,6 +151,245 @@ func TestCredentialStore(t *testing.T) {
 	})
 }
 
+func TestTimeouts(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c := &Config{}
+		if got := c.GenerationTimeout("ollama"); got != DefaultGenerationTimeout {
+			t.Errorf("GenerationTimeout = %v, want %v", got, DefaultGenerationTimeout)
+		}
+		if got := c.CompactionTimeout(); got != DefaultCompactionTimeout {
+			t.Errorf("CompactionTimeout = %v, want %v", got, DefaultCompactionTimeout)
+		}
+		if got := c.ToolListingTimeout(); got != DefaultToolListingTimeout {
+			t.Errorf("ToolListingTimeout = %v, want %v", got, DefaultToolListingTimeout)
+		}
+		if got := c.ToolExecutionTimeout(); got != DefaultToolExecutionTimeout {
+			t.Errorf("ToolExecutionTimeout = %v, want %v", got, DefaultToolExecutionTimeout)
+		}
+	})
+
+	t.Run("provider override", func(t *testing.T) {
+		c := &Config{
+			Timeouts: TimeoutConfig{Generation: 60},
+			Providers: []ProviderConfig{
+				{ID: "ollama", Timeout: 600},
+				{ID: "openai"},
+			},
+		}
+		if got := c.GenerationTimeout("ollama"); got != 600*time.Second {
+			t.Errorf("ollama GenerationTimeout = %v, want 10m", got)
+		}
+		if got := c.GenerationTimeout("openai"); got != 60*time.Second {
+			t.Errorf("openai GenerationTimeout = %v, want 1m", got)
+		}
+	})
+}
+
+func TestLookupPrice(t *testing.T) {
+	c := &Config{Prices: PriceTable{
+		"openai": {"gpt-4o-mini": {Input: 0.15, Output: 0.60}},
+	}}
+
+	price, ok := c.LookupPrice("openai", "gpt-4o-mini")
+	if !ok {
+		t.Fatal("expected a price for openai/gpt-4o-mini")
+	}
+	if got, want := price.Cost(1_000_000, 500_000), 0.45; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+
+	if _, ok := c.LookupPrice("anthropic", "gpt-4o-mini"); ok {
+		t.Error("expected no price for an unlisted provider")
+	}
+	if _, ok := (&Config{}).LookupPrice("openai", "gpt-4o-mini"); ok {
+		t.Error("expected no price without a price table")
+	}
+}
+
+func TestCustomProviders(t *testing.T) {
+	c := &Config{Providers: []ProviderConfig{
+		{ID: "openrouter", Name: "OpenRouter", Enabled: true},
+		{ID: "vllm", Name: "Team vLLM", Type: ProviderTypeOpenAICompatible, BaseURL: "http://gpu:8000/v1"},
+		{ID: "llamacpp", Type: ProviderTypeOpenAICompatible, BaseURL: "http://localhost:8080/v1"},
+	}}
+
+	custom := c.CustomProviders()
+	if len(custom) != 2 || custom[0].ID != "vllm" || custom[1].ID != "llamacpp" {
+		t.Fatalf("CustomProviders() = %+v, want vllm and llamacpp", custom)
+	}
+
+	for id, want := range map[string]string{
+		"vllm":       "Team vLLM",
+		"llamacpp":   "llamacpp",
+		"openrouter": "OpenRouter",
+		"anthropic":  "Anthropic",
+	} {
+		if got := c.ProviderDisplayName(id); got != want {
+			t.Errorf("ProviderDisplayName(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestReasoning(t *testing.T) {
+	data := `
+[ollama]
+host = "http://localhost:11434"
+reasoning_effort = "off"
+
+[[providers]]
+id = "anthropic"
+enabled = true
+thinking_budget = 16000
+`
+	var userCfg UserConfig
+	if _, err := toml.Decode(data, &userCfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	c := &Config{OllamaReasoning: userCfg.Ollama.ReasoningConfig, Providers: userCfg.Providers}
+	if got := c.Reasoning("ollama"); got.ReasoningEffort != ReasoningOff {
+		t.Errorf("Reasoning(ollama) = %+v, want effort off", got)
+	}
+	if got := c.Reasoning("anthropic"); got.ThinkingBudget != 16000 {
+		t.Errorf("Reasoning(anthropic) = %+v, want a 16000 token budget", got)
+	}
+	if got := c.Reasoning("openai"); got != (ReasoningConfig{}) {
+		t.Errorf("Reasoning(openai) = %+v, want the model default", got)
+	}
+}
+
+// --- generation.go ---
+
+func TestGenerationParams(t *testing.T) {
+	t.Run("DecodeDefaults", func(t *testing.T) {
+		var userCfg UserConfig
+		if _, err := toml.Decode("[generation]\ntemperature = 0.0\nnum_ctx = 16384\n", &userCfg); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		got := userCfg.Generation
+		if got.Temperature == nil || *got.Temperature != 0 || got.NumCtx != 16384 || got.TopP != nil {
+			t.Errorf("decoded %+v, want temperature 0 (set) and num_ctx 16384", got)
+		}
+	})
+
+	t.Run("ParseAndFormat", func(t *testing.T) {
+		p, err := ParseGenerationParams("temperature=0.7, top_p=0.9 max_tokens=4096 seed=0 num_ctx=32768")
+		if err != nil {
+			t.Fatalf("ParseGenerationParams: %v", err)
+		}
+		want := "temperature=0.7 top_p=0.9 max_tokens=4096 seed=0 num_ctx=32768"
+		if got := p.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+
+		empty, err := ParseGenerationParams("  ")
+		if err != nil || empty.String() != "" {
+			t.Errorf("blank input = %+v, %v; want no parameters", empty, err)
+		}
+	})
+
+	t.Run("ParseErrors", func(t *testing.T) {
+		for _, input := range []string{"temperature", "temperature=hot", "temperature=3", "top_p=1.5", "max_tokens=0", "num_ctx=-1", "seed=1.5", "top_k=40"} {
+			if _, err := ParseGenerationParams(input); err == nil {
+				t.Errorf("ParseGenerationParams(%q) succeeded, want an error", input)
+			}
+		}
+	})
+
+	t.Run("WithDefaults", func(t *testing.T) {
+		low, high := 0.1, 0.9
+		session := GenerationParams{Temperature: &low}
+		defaults := GenerationParams{Temperature: &high, MaxTokens: 2048}
+
+		got := session.WithDefaults(defaults)
+		if *got.Temperature != 0.1 || got.MaxTokens != 2048 || got.TopP != nil {
+			t.Errorf("WithDefaults = %s, want the session temperature and default max_tokens", got)
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var r RetryConfig
+		if got := r.Attempts(); got != DefaultRetryAttempts {
+			t.Errorf("Attempts = %d, want %d", got, DefaultRetryAttempts)
+		}
+		if got := r.Backoff(1); got != DefaultRetryInitialBackoff {
+			t.Errorf("Backoff(1) = %v, want %v", got, DefaultRetryInitialBackoff)
+		}
+	})
+
+	t.Run("backoff doubles up to max", func(t *testing.T) {
+		r := RetryConfig{InitialBackoff: 2, MaxBackoff: 10}
+		want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+		for i, w := range want {
+			if got := r.Backoff(i + 1); got != w {
+				t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+			}
+		}
+	})
+
+	t.Run("fallback list from toml", func(t *testing.T) {
+		var u UserConfig
+		data := `
+[retry]
+max_attempts = 5
+
+[[fallback]]
+provider = "openrouter"
+model = "qwen/qwen3-coder:free"
+
+[[fallback]]
+provider = "ollama"
+model = "llama3.1:8b"
+`
+		if _, err := toml.Decode(data, &u); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if u.Retry.Attempts() != 5 {
+			t.Errorf("Attempts = %d, want 5", u.Retry.Attempts())
+		}
+		if got := FormatFallbacks(u.Fallback); got != "openrouter:qwen/qwen3-coder:free, ollama:llama3.1:8b" {
+			t.Errorf("Fallback = %q", got)
+		}
+	})
+}
+
+func TestParseFallbacks(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		in := "openrouter:qwen/qwen3-coder:free, ollama:llama3.1:8b"
+		targets, err := ParseFallbacks(in)
+		if err != nil {
+			t.Fatalf("ParseFallbacks: %v", err)
+		}
+		want := []FallbackTarget{
+			{Provider: "openrouter", Model: "qwen/qwen3-coder:free"},
+			{Provider: "ollama", Model: "llama3.1:8b"},
+		}
+		if len(targets) != len(want) || targets[0] != want[0] || targets[1] != want[1] {
+			t.Errorf("ParseFallbacks = %+v, want %+v", targets, want)
+		}
+		if got := FormatFallbacks(targets); got != in {
+			t.Errorf("FormatFallbacks = %q, want %q", got, in)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		targets, err := ParseFallbacks(" ,  ")
+		if err != nil || len(targets) != 0 {
+			t.Errorf("ParseFallbacks = %+v, %v; want none", targets, err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, in := range []string{"llama3.1", "ollama:", ":llama3.1"} {
+			if _, err := ParseFallbacks(in); err == nil {
+				t.Errorf("ParseFallbacks(%q) should fail", in)
+			}
+		}
+	})
+}
+
 // --- plugins.go ---
 
 func TestPluginsConfig(t *testing.T) {
@@ -196,6 +439,30 @@ func TestPluginsConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("GetToolTimeout", func(t *testing.T) {
+		pc.Plugins["mcp-slow"] = PluginConfigEntry{Timeout: 300}
+		if got := pc.GetToolTimeout("mcp-slow", time.Minute); got != 300*time.Second {
+			t.Errorf("expected 5m, got %v", got)
+		}
+		if got := pc.GetToolTimeout("nonexistent", time.Minute); got != time.Minute {
+			t.Errorf("expected default 1m, got %v", got)
+		}
+		var nilPC *PluginsConfig
+		if got := nilPC.GetToolTimeout("mcp-slow", time.Minute); got != time.Minute {
+			t.Errorf("expected default 1m for nil config, got %v", got)
+		}
+	})
+
+	t.Run("GetMaxConcurrency", func(t *testing.T) {
+		pc.Plugins["mcp-serial"] = PluginConfigEntry{MaxConcurrency: 1}
+		if got := pc.GetMaxConcurrency("mcp-serial", 4); got != 1 {
+			t.Errorf("expected 1, got %d", got)
+		}
+		if got := pc.GetMaxConcurrency("nonexistent", 4); got != 4 {
+			t.Errorf("expected default 4, got %d", got)
+		}
+	})
+
 	t.Run("DeletePlugin_nil_map", func(t *testing.T) {
 		nilPC := &PluginsConfig{}
 		nilPC.DeletePlugin("anything") // should not panic
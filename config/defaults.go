@@ -65,6 +65,9 @@ last_used_provider = "ollama"
 [ollama]
 # Ollama server URL
 host = "http://localhost:11434"
+# Thinking for reasoning models (qwen3, deepseek-r1, gpt-oss): "off", "low",
+# "medium" or "high"; unset leaves it to the model
+# reasoning_effort = "medium"
 
 # Default system prompt for new sessions (optional)
 default_system_prompt = ""
@@ -83,12 +86,31 @@ require_approval = true
 enable_multi_step = true  # Allow LLM to execute multiple steps in sequence
 max_iterations = 10       # Maximum steps per user message
 
+# Tool calls from one step run in parallel, up to this many per plugin
+# (a plugin can set its own max_concurrency in plugins.toml; 1 = one at a time)
+# tool_concurrency = 4
+
 # Desktop Notification
 # Emit terminal bell when LLM response completes
 # Works in native, Flatpak, and Docker containers
 # Configure your terminal emulator to show visual notifications on bell
 notify_on_complete = false
 
+# Session Storage
+# "json" keeps each session in its own file (good for syncing the data
+# directory); "sqlite" keeps them in sessions.db with a full-text index for
+# fast, ranked search. Switching to "sqlite" imports the JSON files once and
+# leaves them in place; sessions saved afterwards are only in the database.
+# session_store = "json"
+
+# Semantic search across sessions (optional)
+# Messages are embedded with an Ollama embedding model as sessions are saved
+# (run "ollama pull nomic-embed-text" first); press Tab in Search all
+# sessions to search by meaning instead of keywords.
+# [semantic_search]
+# enabled = true
+# model = "nomic-embed-text"
+
 # Context Window Management
 [compaction]
 auto_compact = false            # Automatically compact when context usage exceeds threshold
@@ -96,6 +118,50 @@ auto_compact_threshold = 0.75   # Trigger auto-compact at 75% context usage (0.0
 keep_percentage = 0.50          # Keep last 50% of context when compacting (0.0-1.0)
 warn_at_percentage = 0.85       # Show warning indicator at 85% usage (0.0-1.0)
 
+# Timeouts in seconds (0 or unset = default)
+# A single provider can override the generation timeout with "timeout" in its
+# [[providers]] entry, and a plugin its tool timeout in plugins.toml.
+# [timeouts]
+# generation = 120      # One model response (raise for slow CPU-only Ollama)
+# compaction = 30       # Compaction summary generation
+# tool_listing = 10     # Listing tools and models
+# tool_execution = 120  # One tool call
+
+# Default generation parameters for sessions (unset = provider default)
+# A session can override them in the session editor (Alt+E).
+# num_ctx only applies to Ollama. Unset, Ollama uses its own default; raise it
+# for long chats, or lower it to save memory on small GPUs.
+# [generation]
+# temperature = 0.7     # 0.0-2.0
+# top_p = 0.9           # 0.0-1.0
+# max_tokens = 4096     # Longest response
+# seed = 42             # Repeatable output where the provider supports it
+# num_ctx = 32768       # Ollama context length in tokens
+
+# Retrying requests that fail with a rate limit (429), a server error (5xx)
+# or an unreachable server, with exponential backoff. A Retry-After longer
+# than max_backoff skips straight to the next fallback model.
+# [retry]
+# max_attempts = 3      # Tries per model (1 = no retries)
+# initial_backoff = 1   # Seconds before the first retry, doubling after each
+# max_backoff = 30      # Longest wait between tries in seconds
+
+# Models to try in order when the session's model keeps failing (optional)
+# A session can set its own list in the session editor (Alt+E).
+# [[fallback]]
+# provider = "openrouter"
+# model = "qwen/qwen3-coder:free"
+# [[fallback]]
+# provider = "ollama"
+# model = "llama3.1:latest"
+
+# Model prices in USD per million tokens, used for the usage ledger (optional)
+# OpenRouter prices are fetched automatically; entries here take precedence.
+# [prices.openai]
+# "gpt-4o-mini" = { input = 0.15, output = 0.60 }
+# [prices.anthropic]
+# "claude-sonnet-4-5-20250929" = { input = 3.0, output = 15.0 }
+
 # Per-Model Context Window Overrides (optional)
 # Override context window size for specific models (in tokens)
 # [model_context_overrides]
@@ -112,5 +178,18 @@ credential_storage = "plaintext"
 # name = "OpenRouter"
 # enabled = true
 # base_url = "https://openrouter.ai/api/v1"
+# timeout = 60  # Generation timeout in seconds for this provider (optional)
+# reasoning_effort = "high"  # How hard reasoning models think: "off", "low", "medium", "high" (optional)
+# thinking_budget = 8000     # Or a thinking token budget (Anthropic, Gemini, OpenRouter; optional)
+
+# Custom OpenAI-compatible servers (vLLM, llama.cpp, LM Studio, LiteLLM)
+# Add as many as you like; the API key (if any) is set in Settings > Providers
+# [[providers]]
+# id = "vllm"
+# name = "vLLM"
+# type = "openai-compatible"
+# enabled = true
+# base_url = "http://gpu-box:8000/v1"
+# headers = { "X-Team" = "research" }  # Extra HTTP headers (optional)
 `
 }
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GenerationParams are the sampling settings sent with each request. Unset
+// fields (nil or 0) are left to the provider. The [generation] table in
+// config.toml holds the defaults; a session's own values take precedence.
+type GenerationParams struct {
+	Temperature *float64 `toml:"temperature,omitempty" json:"temperature,omitempty"`
+	TopP        *float64 `toml:"top_p,omitempty" json:"top_p,omitempty"`
+	MaxTokens   int      `toml:"max_tokens,omitempty" json:"max_tokens,omitempty"` // Longest response
+	Seed        *int     `toml:"seed,omitempty" json:"seed,omitempty"`
+	NumCtx      int      `toml:"num_ctx,omitempty" json:"num_ctx,omitempty"` // Ollama context length
+}
+
+// Generation parameter names, as written in config.toml and the session editor
+const (
+	ParamTemperature = "temperature"
+	ParamTopP        = "top_p"
+	ParamMaxTokens   = "max_tokens"
+	ParamSeed        = "seed"
+	ParamNumCtx      = "num_ctx"
+)
+
+// WithDefaults returns p with its unset fields taken from defaults
+func (p GenerationParams) WithDefaults(defaults GenerationParams) GenerationParams {
+	if p.Temperature == nil {
+		p.Temperature = defaults.Temperature
+	}
+	if p.TopP == nil {
+		p.TopP = defaults.TopP
+	}
+	if p.MaxTokens == 0 {
+		p.MaxTokens = defaults.MaxTokens
+	}
+	if p.Seed == nil {
+		p.Seed = defaults.Seed
+	}
+	if p.NumCtx == 0 {
+		p.NumCtx = defaults.NumCtx
+	}
+	return p
+}
+
+// String formats the set parameters as "temperature=0.7 num_ctx=32768",
+// the form ParseGenerationParams reads
+func (p GenerationParams) String() string {
+	var fields []string
+	if p.Temperature != nil {
+		fields = append(fields, ParamTemperature+"="+strconv.FormatFloat(*p.Temperature, 'g', -1, 64))
+	}
+	if p.TopP != nil {
+		fields = append(fields, ParamTopP+"="+strconv.FormatFloat(*p.TopP, 'g', -1, 64))
+	}
+	if p.MaxTokens > 0 {
+		fields = append(fields, fmt.Sprintf("%s=%d", ParamMaxTokens, p.MaxTokens))
+	}
+	if p.Seed != nil {
+		fields = append(fields, fmt.Sprintf("%s=%d", ParamSeed, *p.Seed))
+	}
+	if p.NumCtx > 0 {
+		fields = append(fields, fmt.Sprintf("%s=%d", ParamNumCtx, p.NumCtx))
+	}
+	return strings.Join(fields, " ")
+}
+
+// ParseGenerationParams reads parameters written as space- or comma-separated
+// name=value pairs. Parameters that aren't listed stay unset.
+func ParseGenerationParams(s string) (GenerationParams, error) {
+	var p GenerationParams
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' })
+	for _, field := range fields {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return GenerationParams{}, fmt.Errorf("%q: expected name=value", field)
+		}
+
+		switch name {
+		case ParamTemperature, ParamTopP:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return GenerationParams{}, fmt.Errorf("%s: %q is not a number", name, value)
+			}
+			if name == ParamTemperature {
+				if f < 0 || f > 2 {
+					return GenerationParams{}, fmt.Errorf("temperature must be between 0 and 2")
+				}
+				p.Temperature = &f
+			} else {
+				if f < 0 || f > 1 {
+					return GenerationParams{}, fmt.Errorf("top_p must be between 0 and 1")
+				}
+				p.TopP = &f
+			}
+		case ParamMaxTokens, ParamSeed, ParamNumCtx:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return GenerationParams{}, fmt.Errorf("%s: %q is not a whole number", name, value)
+			}
+			switch {
+			case name == ParamSeed:
+				p.Seed = &n
+			case n <= 0:
+				return GenerationParams{}, fmt.Errorf("%s must be greater than 0", name)
+			case name == ParamMaxTokens:
+				p.MaxTokens = n
+			default:
+				p.NumCtx = n
+			}
+		default:
+			return GenerationParams{}, fmt.Errorf("unknown parameter %q (use temperature, top_p, max_tokens, seed or num_ctx)", name)
+		}
+	}
+	return p, nil
+}
@@ -61,6 +61,13 @@ var actionRegistry = map[string]actionDef{
 	"yank_conversation":          {"primary", "c"},
 	"external_editor":            {"primary", "i"},
 	"compact_session":            {"secondary", "c"}, // Manual compact (Alt+Shift+C)
+	"toggle_tool_blocks":         {"primary", "t"},   // Expand/collapse tool call blocks
+	"toggle_reasoning":           {"secondary", "t"}, // Expand/collapse model reasoning
+	"stop_generation":            {"primary", "x"},   // Stop the running response/tool calls (Esc also works)
+	"attach_file":                {"primary", "o"},   // Attach an image or text file to the next message
+	"clear_attachments":          {"secondary", "o"}, // Remove pending attachments
+	"regenerate":                 {"primary", "r"},   // Regenerate the last reply as a new version
+	"message_actions":            {"primary", "b"},   // Edit & resend, regenerate or switch versions of a message
 
 	// Model selector modal - normal mode (no modifier needed)
 	"model_selector_down":       {"none", "j"},
@@ -5,14 +5,17 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
 type PluginConfigEntry struct {
-	Enabled       bool              `toml:"enabled"`
-	Config        map[string]string `toml:"config,omitempty"`         // Non-sensitive OR all values if plaintext
-	SensitiveKeys []string          `toml:"sensitive_keys,omitempty"` // Keys stored in CredentialStore
+	Enabled        bool              `toml:"enabled"`
+	Config         map[string]string `toml:"config,omitempty"`          // Non-sensitive OR all values if plaintext
+	SensitiveKeys  []string          `toml:"sensitive_keys,omitempty"`  // Keys stored in CredentialStore
+	Timeout        int               `toml:"timeout,omitempty"`         // Tool execution timeout in seconds (0 = timeouts.tool_execution)
+	MaxConcurrency int               `toml:"max_concurrency,omitempty"` // Parallel calls allowed (0 = tool_concurrency)
 }
 
 type PluginsConfig struct {
@@ -117,6 +120,24 @@ func (pc *PluginsConfig) SetPluginConfig(pluginID string, config map[string]stri
 	pc.Plugins[pluginID] = entry
 }
 
+// GetToolTimeout returns the plugin's tool execution timeout, or def if the
+// plugin doesn't set one
+func (pc *PluginsConfig) GetToolTimeout(pluginID string, def time.Duration) time.Duration {
+	if pc == nil {
+		return def
+	}
+	return secondsOr(pc.Plugins[pluginID].Timeout, def)
+}
+
+// GetMaxConcurrency returns how many calls pluginID may run at once,
+// falling back to def when the plugin doesn't set max_concurrency
+func (pc *PluginsConfig) GetMaxConcurrency(pluginID string, def int) int {
+	if pc == nil || pc.Plugins[pluginID].MaxConcurrency <= 0 {
+		return def
+	}
+	return pc.Plugins[pluginID].MaxConcurrency
+}
+
 func (pc *PluginsConfig) DeletePlugin(pluginID string) {
 	if pc.Plugins == nil {
 		return
@@ -162,9 +183,11 @@ func SavePluginConfigSecure(cfg *Config, dataDir string, pluginsConfig *PluginsC
 
 		// Save config entry
 		entry := PluginConfigEntry{
-			Enabled:       pluginsConfig.GetPluginEnabled(pluginID),
-			Config:        plaintextConfig,
-			SensitiveKeys: sensitiveKeys,
+			Enabled:        pluginsConfig.GetPluginEnabled(pluginID),
+			Config:         plaintextConfig,
+			SensitiveKeys:  sensitiveKeys,
+			Timeout:        pluginsConfig.Plugins[pluginID].Timeout,
+			MaxConcurrency: pluginsConfig.Plugins[pluginID].MaxConcurrency,
 		}
 		pluginsConfig.Plugins[pluginID] = entry
 
@@ -174,9 +197,11 @@ func SavePluginConfigSecure(cfg *Config, dataDir string, pluginsConfig *PluginsC
 	case string(SecurityPlainText):
 		// Store everything in plaintext
 		entry := PluginConfigEntry{
-			Enabled:       pluginsConfig.GetPluginEnabled(pluginID),
-			Config:        configValues,
-			SensitiveKeys: []string{}, // Empty - using plaintext
+			Enabled:        pluginsConfig.GetPluginEnabled(pluginID),
+			Config:         configValues,
+			SensitiveKeys:  []string{}, // Empty - using plaintext
+			Timeout:        pluginsConfig.Plugins[pluginID].Timeout,
+			MaxConcurrency: pluginsConfig.Plugins[pluginID].MaxConcurrency,
 		}
 		pluginsConfig.Plugins[pluginID] = entry
 		return nil
@@ -10,6 +10,7 @@ import (
 // Fields:
 //   - Ollama: "host", "apikey", "enabled"
 //   - Cloud providers: "apikey", "enabled"
+//   - Custom providers: "host" (base URL), "apikey", "enabled"
 func UpdateProviderField(dataDir, providerID, fieldName, value string) error {
 	// Load existing config
 	cfg, err := LoadUserConfig(dataDir)
@@ -58,7 +59,7 @@ func UpdateProviderField(dataDir, providerID, fieldName, value string) error {
 			return fmt.Errorf("unknown field for ollama: %s", fieldName)
 		}
 
-	case "openrouter", "anthropic", "openai":
+	case "openrouter", "anthropic", "openai", "gemini":
 		switch fieldName {
 		case "apikey":
 			// Update API key in credentials
@@ -91,7 +92,37 @@ func UpdateProviderField(dataDir, providerID, fieldName, value string) error {
 		}
 
 	default:
-		return fmt.Errorf("unknown provider: %s", providerID)
+		// Custom OpenAI-compatible providers defined in [[providers]]
+		idx := customProviderIndex(cfg, providerID)
+		if idx == -1 {
+			return fmt.Errorf("unknown provider: %s", providerID)
+		}
+
+		switch fieldName {
+		case "host":
+			cfg.Providers[idx].BaseURL = value
+		case "apikey":
+			fullCfg, err := Load()
+			if err != nil {
+				return fmt.Errorf("failed to load full config for credential update: %w", err)
+			}
+
+			if fullCfg.CredentialStore != nil {
+				if err := fullCfg.CredentialStore.Set(providerID, value); err != nil {
+					return fmt.Errorf("failed to set API key: %w", err)
+				}
+
+				if err := fullCfg.CredentialStore.Save(dataDir); err != nil {
+					return fmt.Errorf("failed to persist credentials: %w", err)
+				}
+			}
+			// Don't save UserConfig for API key changes (already saved credentials)
+			return nil
+		case "enabled":
+			cfg.Providers[idx].Enabled = value == "true"
+		default:
+			return fmt.Errorf("unknown field for %s: %s", providerID, fieldName)
+		}
 	}
 
 	// Save updated config
@@ -127,6 +158,16 @@ func updateProviderEnabled(cfg *UserConfig, providerID string, enabled bool) err
 	return nil
 }
 
+// customProviderIndex returns the index of a custom provider in the list, or -1
+func customProviderIndex(cfg *UserConfig, providerID string) int {
+	for i := range cfg.Providers {
+		if cfg.Providers[i].ID == providerID && cfg.Providers[i].IsCustom() {
+			return i
+		}
+	}
+	return -1
+}
+
 // getProviderDisplayName returns the display name for a provider
 func getProviderDisplayName(providerID string) string {
 	switch providerID {
@@ -138,6 +179,8 @@ func getProviderDisplayName(providerID string) string {
 		return "Anthropic"
 	case "openai":
 		return "OpenAI"
+	case "gemini":
+		return "Gemini"
 	default:
 		return providerID
 	}
@@ -152,6 +195,8 @@ func getProviderDefaultBaseURL(providerID string) string {
 		return "https://api.anthropic.com"
 	case "openai":
 		return "https://api.openai.com/v1"
+	case "gemini":
+		return "https://generativelanguage.googleapis.com/v1beta"
 	default:
 		return ""
 	}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RetryConfig controls how requests that fail with a transient error (rate
+// limits, server errors, an unreachable server) are retried before moving on
+// to the next fallback model. Zero means the built-in default.
+type RetryConfig struct {
+	MaxAttempts    int `toml:"max_attempts,omitempty"`    // Tries per model, including the first (default: 3, 1 = no retries)
+	InitialBackoff int `toml:"initial_backoff,omitempty"` // Seconds before the first retry, doubling after each (default: 1)
+	MaxBackoff     int `toml:"max_backoff,omitempty"`     // Longest wait between tries in seconds (default: 30)
+}
+
+// Default retry settings used when RetryConfig leaves a value unset
+const (
+	DefaultRetryAttempts       = 3
+	DefaultRetryInitialBackoff = 1 * time.Second
+	DefaultRetryMaxBackoff     = 30 * time.Second
+)
+
+// Attempts returns how many times one model is tried before giving up on it
+func (r RetryConfig) Attempts() int {
+	if r.MaxAttempts <= 0 {
+		return DefaultRetryAttempts
+	}
+	return r.MaxAttempts
+}
+
+// Backoff returns how long to wait before retry number n (starting at 1)
+func (r RetryConfig) Backoff(n int) time.Duration {
+	wait := secondsOr(r.InitialBackoff, DefaultRetryInitialBackoff)
+	limit := r.MaxWait()
+	for i := 1; i < n && wait < limit; i++ {
+		wait *= 2
+	}
+	return min(wait, limit)
+}
+
+// MaxWait returns the longest wait between tries. A server asking for a
+// longer wait (Retry-After) gets skipped in favour of the next fallback.
+func (r RetryConfig) MaxWait() time.Duration {
+	return secondsOr(r.MaxBackoff, DefaultRetryMaxBackoff)
+}
+
+// FallbackTarget is a model to try when the session's model keeps failing
+type FallbackTarget struct {
+	Provider string `toml:"provider" json:"provider"`
+	Model    string `toml:"model" json:"model"` // InternalName (e.g. "qwen/qwen3-coder:free")
+}
+
+// String formats the target as "provider:model"
+func (t FallbackTarget) String() string {
+	return t.Provider + ":" + t.Model
+}
+
+// FormatFallbacks formats a fallback list as "provider:model, provider:model",
+// the form ParseFallbacks reads
+func FormatFallbacks(targets []FallbackTarget) string {
+	parts := make([]string, len(targets))
+	for i, t := range targets {
+		parts[i] = t.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseFallbacks reads a comma-separated list of provider:model pairs. The
+// provider ends at the first colon, so model tags like "llama3.1:8b" survive.
+func ParseFallbacks(s string) ([]FallbackTarget, error) {
+	var targets []FallbackTarget
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		provider, model, ok := strings.Cut(field, ":")
+		provider, model = strings.TrimSpace(provider), strings.TrimSpace(model)
+		if !ok || provider == "" || model == "" {
+			return nil, fmt.Errorf("%q: expected provider:model", field)
+		}
+		targets = append(targets, FallbackTarget{Provider: provider, Model: model})
+	}
+	return targets, nil
+}
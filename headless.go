@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"otui/config"
+	"otui/mcp"
+	appmodel "otui/model"
+	"otui/provider"
+	"otui/storage"
+)
+
+// Exit codes for headless runs (otui run / otui ask)
+const (
+	exitOK            = 0
+	exitError         = 1 // Setup/config/storage failure, or a request timed out
+	exitUsage         = 2 // Bad flags or missing prompt
+	exitModelError    = 3 // Provider returned an error
+	exitToolDenied    = 4 // A tool was denied by the policy, or requested with no plugins running
+	exitMaxIterations = 5 // Tool loop stopped at max_iterations
+)
+
+// stringListFlag collects a repeatable and/or comma-separated flag value
+type stringListFlag []string
+
+func (s *stringListFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringListFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*s = append(*s, v)
+		}
+	}
+	return nil
+}
+
+// headlessOptions holds parsed command line options for otui run / otui ask
+type headlessOptions struct {
+	command      string // "run" (persists to a session) or "ask" (one-off)
+	provider     string
+	model        string
+	session      string // Session ID or name
+	systemPrompt string
+	approval     appmodel.ApprovalPolicy
+	allowTools   []string
+	plugins      []string
+	jsonOutput   bool
+	prompt       string
+}
+
+// isHeadlessCommand reports whether argv selects a headless subcommand
+func isHeadlessCommand(args []string) bool {
+	return len(args) > 1 && (args[1] == "run" || args[1] == "ask")
+}
+
+// parseHeadlessArgs parses flags for a headless subcommand.
+// The prompt is taken from the remaining arguments; if there are none (or
+// the prompt is "-") it is read from stdin. Pass a nil stdin when stdin is a
+// terminal so a missing prompt is reported instead of blocking.
+func parseHeadlessArgs(command string, args []string, stdin io.Reader, output io.Writer) (headlessOptions, error) {
+	opts := headlessOptions{command: command}
+
+	fs := flag.NewFlagSet("otui "+command, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(output, "Usage: otui %s [flags] [prompt]\n\n", command)
+		switch command {
+		case "run":
+			fmt.Fprintln(output, "Send a prompt and save the conversation to a session (new unless --session is given).")
+		case "ask":
+			fmt.Fprintln(output, "Send a one-off prompt. Nothing is saved; --session only provides history.")
+		}
+		fmt.Fprintf(output, "The prompt is read from stdin when omitted or \"-\".\n\nFlags:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(output, "\nExit codes: %d ok, %d error (including timeouts), %d usage, %d model error, %d tool denied or unavailable, %d max iterations\n",
+			exitOK, exitError, exitUsage, exitModelError, exitToolDenied, exitMaxIterations)
+	}
+
+	var approval string
+	var allowTools, plugins stringListFlag
+	fs.StringVar(&opts.provider, "provider", "", "provider ID to use (e.g. ollama, openrouter, anthropic)")
+	fs.StringVar(&opts.model, "model", "", "model to use (provider-specific name)")
+	fs.StringVar(&opts.session, "session", "", "session ID or name to continue")
+	fs.StringVar(&opts.systemPrompt, "system", "", "system prompt override")
+	fs.StringVar(&approval, "approve", string(appmodel.ApprovalDeny), "tool approval policy when approval is required: deny or all")
+	fs.Var(&allowTools, "allow-tool", "tool allowed for this run (repeatable, e.g. filesystem.read_file)")
+	fs.Var(&plugins, "plugin", "plugin ID to enable for this run (repeatable)")
+	fs.BoolVar(&opts.jsonOutput, "json", false, "print JSON events (one per line) instead of the answer")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+
+	policy, err := appmodel.ParseApprovalPolicy(approval)
+	if err != nil {
+		return opts, err
+	}
+	opts.approval = policy
+	opts.allowTools = allowTools
+	opts.plugins = plugins
+
+	prompt := strings.Join(fs.Args(), " ")
+	if prompt == "" || prompt == "-" {
+		if stdin == nil {
+			return opts, fmt.Errorf("no prompt given (pass it as an argument or pipe it on stdin)")
+		}
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read prompt from stdin: %w", err)
+		}
+		prompt = string(data)
+	}
+
+	opts.prompt = strings.TrimSpace(prompt)
+	if opts.prompt == "" {
+		return opts, fmt.Errorf("prompt is empty")
+	}
+
+	return opts, nil
+}
+
+// exitCodeForError maps a headless run error to a process exit code
+func exitCodeForError(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, appmodel.ErrToolDenied):
+		return exitToolDenied
+	case errors.Is(err, appmodel.ErrMaxIterations):
+		return exitMaxIterations
+	case errors.Is(err, appmodel.ErrModelFailed):
+		return exitModelError
+	}
+	return exitError
+}
+
+// findSession looks up a session by exact ID first, then by name (case-insensitive).
+// Only IDs returned by List are loaded, so user input never reaches a file path.
+func findSession(sessionStorage *storage.SessionStorage, idOrName string) (*storage.Session, error) {
+	sessions, err := sessionStorage.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, meta := range sessions {
+		if meta.ID == idOrName {
+			return sessionStorage.Load(meta.ID)
+		}
+	}
+	for _, meta := range sessions {
+		if strings.EqualFold(meta.Name, idOrName) {
+			return sessionStorage.Load(meta.ID)
+		}
+	}
+	return nil, fmt.Errorf("session %q not found", idOrName)
+}
+
+// runHeadless runs otui run / otui ask without the TUI and returns the exit code
+func runHeadless(command string, args []string) int {
+	var stdin io.Reader
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice == 0 {
+		stdin = os.Stdin
+	}
+
+	opts, err := parseHeadlessArgs(command, args, stdin, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "otui %s: %v\n", command, err)
+		return exitUsage
+	}
+
+	// Headless mode never runs the welcome wizard or prompts for a passphrase
+	settingsPath := config.GetSettingsFilePath()
+	if !config.HasAllEnvVars() && !config.FileExists(settingsPath) {
+		fmt.Fprintln(os.Stderr, "otui is not configured yet. Run otui once to complete the setup wizard.")
+		return exitError
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		if strings.Contains(err.Error(), "passphrase required") {
+			fmt.Fprintln(os.Stderr, "SSH key passphrase required to decrypt credentials; headless mode cannot prompt for it.")
+			return exitError
+		}
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return exitError
+	}
+
+	config.InitDebugLog(cfg.DataDir())
+
+	sessionStorage, err := storage.OpenSessionStorage(cfg.DataDir(), cfg.SessionStore)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize session storage: %v\n", err)
+		return exitError
+	}
+	defer sessionStorage.Close()
+
+	// Resolve session: existing one by ID/name, otherwise a fresh one
+	var session *storage.Session
+	if opts.session != "" {
+		session, err = findSession(sessionStorage, opts.session)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load session: %v\n", err)
+			return exitError
+		}
+		isLocked, lockErr := sessionStorage.CheckSessionLock(session.ID)
+		if lockErr == nil && isLocked {
+			fmt.Fprintf(os.Stderr, "Session %q is open in another OTUI instance.\n", session.Name)
+			return exitError
+		}
+	}
+	if session == nil {
+		session = &storage.Session{
+			Name:         storage.GenerateSessionName(opts.prompt),
+			Model:        cfg.DefaultModel,
+			Provider:     cfg.DefaultProvider,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+			AllowedTools: []string{},
+			SystemPrompt: cfg.DefaultSystemPrompt,
+		}
+	}
+
+	if opts.provider != "" && opts.provider != session.Provider {
+		session.Provider = opts.provider
+		// Models are provider-specific - don't carry the old one over
+		session.Model = ""
+	}
+	if opts.model != "" {
+		session.Model = opts.model
+	}
+	if opts.systemPrompt != "" {
+		session.SystemPrompt = opts.systemPrompt
+	}
+	for _, pluginID := range opts.plugins {
+		if !session.IsPluginEnabled(pluginID) {
+			session.EnabledPlugins = append(session.EnabledPlugins, pluginID)
+		}
+	}
+
+	allProviders := provider.InitializeProviders(cfg)
+	if session.Provider == "" {
+		session.Provider = "ollama"
+	}
+	client := allProviders[session.Provider]
+	if client == nil {
+		fmt.Fprintf(os.Stderr, "Provider %q is not configured or not enabled.\n", session.Provider)
+		return exitError
+	}
+	if session.Model == "" {
+		session.Model = client.GetModel()
+	}
+	if session.Model == "" {
+		fmt.Fprintf(os.Stderr, "No model selected for provider %q; pass --model.\n", session.Provider)
+		return exitError
+	}
+
+	// Plugins (tools) are only started when the session actually uses some
+	var mcpManager *mcp.MCPManager
+	if cfg.PluginsEnabled && len(session.EnabledPlugins) > 0 {
+		mcpManager, err = newHeadlessMCPManager(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize plugins: %v\n", err)
+			return exitError
+		}
+		if err := mcpManager.StartAllEnabledPlugins(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start plugins: %v\n", err)
+			return exitError
+		}
+		for pluginID, startErr := range mcpManager.GetFailedPlugins() {
+			fmt.Fprintf(os.Stderr, "Warning: plugin %s failed to start: %v\n", pluginID, startErr)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = mcpManager.Shutdown(ctx)
+		}()
+	}
+
+	m := appmodel.NewModel(cfg, client, sessionStorage, session, nil, mcpManager, nil, Version, License)
+	m.Providers = allProviders
+
+	if command == "run" {
+		if err := sessionStorage.Save(session); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save session: %v\n", err)
+			return exitError
+		}
+		if err := sessionStorage.LockSession(session.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to lock session: %v\n", err)
+			return exitError
+		}
+		defer func() {
+			_ = sessionStorage.UnlockSession(session.ID)
+		}()
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	headlessOpts := appmodel.HeadlessOptions{
+		Approval:   opts.approval,
+		AllowTools: opts.allowTools,
+	}
+	if opts.jsonOutput {
+		headlessOpts.OnEvent = func(ev appmodel.HeadlessEvent) {
+			_ = encoder.Encode(ev)
+		}
+	}
+
+	answer, runErr := m.RunHeadless(opts.prompt, headlessOpts)
+
+	if command == "run" {
+		if saveCmd := m.SaveCurrentSession(); saveCmd != nil {
+			if saved, ok := saveCmd().(appmodel.SessionSavedMsg); ok && saved.Err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to save session: %v\n", saved.Err)
+			}
+		}
+	}
+
+	if !opts.jsonOutput && answer != "" {
+		fmt.Println(answer)
+	}
+	if runErr != nil && !opts.jsonOutput {
+		fmt.Fprintf(os.Stderr, "otui %s: %v\n", command, runErr)
+	}
+	if command == "run" && !opts.jsonOutput {
+		fmt.Fprintf(os.Stderr, "Session: %s (%s)\n", session.Name, session.ID)
+	}
+
+	return exitCodeForError(runErr)
+}
+
+// newHeadlessMCPManager creates the plugin manager the same way the TUI does,
+// but fails instead of silently disabling plugins
+func newHeadlessMCPManager(cfg *config.Config) (*mcp.MCPManager, error) {
+	registry, err := mcp.NewRegistry(cfg.DataDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugin registry: %w", err)
+	}
+	pluginStorage, err := storage.NewPluginStorage(cfg.DataDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin storage: %w", err)
+	}
+	pluginsConfig, err := config.LoadPluginsConfig(cfg.DataDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugins config: %w", err)
+	}
+	return mcp.NewMCPManager(cfg, pluginStorage, pluginsConfig, registry, cfg.DataDir()), nil
+}
@@ -68,6 +68,11 @@ func main() {
 	// Initialize early debug logging (writes to cache dir)
 	config.InitEarlyDebugLog()
 
+	// Headless subcommands (otui run / otui ask) bypass the TUI entirely
+	if isHeadlessCommand(os.Args) {
+		os.Exit(runHeadless(os.Args[1], os.Args[2:]))
+	}
+
 	// Validate environment variables first
 	if config.HasAnyEnvVar() && !config.HasAllEnvVars() {
 		missingVar := config.GetMissingEnvVar()
@@ -212,11 +217,12 @@ func main() {
 		}
 	}()
 
-	sessionStorage, err := storage.NewSessionStorage(cfg.DataDir())
+	sessionStorage, err := storage.OpenSessionStorage(cfg.DataDir(), cfg.SessionStore)
 	if err != nil {
 		fmt.Printf("Failed to initialize session storage: %v\n", err)
 		os.Exit(1)
 	}
+	defer sessionStorage.Close()
 
 	// Check if another OTUI instance is already running (single-instance enforcement)
 	isLocked, runningPID, err := sessionStorage.CheckOTUIInstanceLock()
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
+
+	appmodel "otui/model"
+	"otui/storage"
 )
 
 func TestBuildCredentialErrorMessage(t *testing.T) {
@@ -44,3 +50,123 @@ func TestBuildCredentialErrorMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestParseHeadlessArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		noStdin    bool
+		wantPrompt string
+		wantErr    bool
+	}{
+		{"prompt_from_args", []string{"hello", "world"}, "", true, "hello world", false},
+		{"prompt_from_stdin", []string{}, "  piped prompt\n", false, "piped prompt", false},
+		{"dash_reads_stdin", []string{"-"}, "from stdin", false, "from stdin", false},
+		{"missing_prompt_on_terminal", []string{}, "", true, "", true},
+		{"empty_stdin", []string{}, "   ", false, "", true},
+		{"bad_policy", []string{"--approve", "sometimes", "hi"}, "", true, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdin io.Reader
+			if !tt.noStdin {
+				stdin = strings.NewReader(tt.stdin)
+			}
+			opts, err := parseHeadlessArgs("ask", tt.args, stdin, io.Discard)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && opts.prompt != tt.wantPrompt {
+				t.Errorf("prompt = %q, want %q", opts.prompt, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestParseHeadlessArgsFlags(t *testing.T) {
+	args := []string{
+		"--provider", "anthropic",
+		"--model", "claude-sonnet",
+		"--session", "Work",
+		"--system", "be brief",
+		"--approve", "all",
+		"--allow-tool", "fs.read_file,fs.list_dir",
+		"--allow-tool", "git.status",
+		"--plugin", "filesystem",
+		"--json",
+		"do it",
+	}
+	opts, err := parseHeadlessArgs("run", args, nil, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.provider != "anthropic" || opts.model != "claude-sonnet" || opts.session != "Work" || opts.systemPrompt != "be brief" {
+		t.Errorf("unexpected string options: %+v", opts)
+	}
+	if opts.approval != appmodel.ApprovalAll {
+		t.Errorf("approval = %q, want %q", opts.approval, appmodel.ApprovalAll)
+	}
+	if len(opts.allowTools) != 3 || opts.allowTools[2] != "git.status" {
+		t.Errorf("allowTools = %v", opts.allowTools)
+	}
+	if len(opts.plugins) != 1 || !opts.jsonOutput || opts.prompt != "do it" {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+}
+
+func TestExitCodeForError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, exitOK},
+		{"model", fmt.Errorf("%w: boom", appmodel.ErrModelFailed), exitModelError},
+		{"denied", fmt.Errorf("%w: fs.write", appmodel.ErrToolDenied), exitToolDenied},
+		{"max_iterations", fmt.Errorf("%w (10)", appmodel.ErrMaxIterations), exitMaxIterations},
+		{"timeout", fmt.Errorf("tool step timed out: %w", context.DeadlineExceeded), exitError},
+		{"other", errors.New("disk full"), exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeForError(tt.err); got != tt.want {
+				t.Errorf("exitCodeForError(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsHeadlessCommand(t *testing.T) {
+	if !isHeadlessCommand([]string{"otui", "run", "hi"}) || !isHeadlessCommand([]string{"otui", "ask"}) {
+		t.Error("expected run/ask to be headless commands")
+	}
+	if isHeadlessCommand([]string{"otui"}) || isHeadlessCommand([]string{"otui", "help"}) {
+		t.Error("expected plain invocation to start the TUI")
+	}
+}
+
+func TestFindSession(t *testing.T) {
+	sessionStorage, err := storage.NewSessionStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+	saved := &storage.Session{Name: "Release Notes"}
+	if err := sessionStorage.Save(saved); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	for _, idOrName := range []string{saved.ID, "release notes"} {
+		session, err := findSession(sessionStorage, idOrName)
+		if err != nil || session.ID != saved.ID {
+			t.Errorf("findSession(%q) = %v, %v; want session %s", idOrName, session, err, saved.ID)
+		}
+	}
+
+	// Input that is neither a known ID nor a name never reaches the file system
+	if _, err := findSession(sessionStorage, "../../"+saved.ID); err == nil {
+		t.Error("expected path-like input to be rejected")
+	}
+}
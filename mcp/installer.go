@@ -3,9 +3,11 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
@@ -19,6 +21,11 @@ type Installer struct {
 	runtimeChecker *RuntimeChecker
 	pluginsDir     string
 	dataDir        string
+	releaseAPI     string // GitHub API base for binary plugin releases
+	npmRegistry    string // Package registries queried for updates
+	pypiAPI        string
+	goProxy        string
+	httpClient     *http.Client // Release lookups, downloads and update checks
 }
 
 type InstallProgress struct {
@@ -34,6 +41,11 @@ func NewInstaller(pluginStorage *storage.PluginStorage, pluginsConfig *config.Pl
 		runtimeChecker: NewRuntimeChecker(),
 		pluginsDir:     filepath.Join(dataDir, "plugins"),
 		dataDir:        dataDir,
+		releaseAPI:     githubAPIURL,
+		npmRegistry:    npmRegistryURL,
+		pypiAPI:        pypiAPIURL,
+		goProxy:        goProxyURL,
+		httpClient:     &http.Client{Timeout: 10 * time.Minute},
 	}
 }
 
@@ -605,7 +617,101 @@ func (i *Installer) installBinaryWithContext(ctx context.Context, plugin *Plugin
 		return fmt.Errorf("installation cancelled")
 	default:
 	}
-	return i.installBinary(plugin, progressCh)
+
+	// Without checksums from the registry a download can't be trusted, so
+	// the binary is left to the user
+	if len(plugin.Checksums) == 0 {
+		return i.installBinary(plugin, progressCh)
+	}
+	return i.installReleaseWithContext(ctx, plugin, progressCh)
+}
+
+// installReleaseWithContext downloads the plugin's GitHub release build for
+// this platform, checks it against the registry's checksum and installs the
+// executable in bin/
+func (i *Installer) installReleaseWithContext(ctx context.Context, plugin *Plugin, progressCh chan<- InstallProgress) error {
+	repoPath, err := githubRepoPath(plugin.Repository)
+	if err != nil {
+		return fmt.Errorf("invalid repository: %w", err)
+	}
+
+	if progressCh != nil {
+		progressCh <- InstallProgress{Stage: "resolving", Percent: 25, Message: "Finding release for " + runtime.GOOS + "/" + runtime.GOARCH + "..."}
+	}
+
+	release, err := i.fetchRelease(ctx, repoPath, plugin.Version)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("installation cancelled")
+		}
+		return err
+	}
+	asset, err := selectAsset(release.Assets, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", plugin.Name, release.TagName, err)
+	}
+	expected, ok := plugin.Checksums[asset.Name]
+	if !ok {
+		return fmt.Errorf("the registry has no checksum for %s", asset.Name)
+	}
+
+	pluginDir := filepath.Join(i.pluginsDir, plugin.ID)
+	if err := os.MkdirAll(pluginDir, 0700); err != nil {
+		return fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+
+	downloadPath := filepath.Join(pluginDir, "download-"+filepath.Base(asset.Name))
+	actual, err := i.downloadAsset(ctx, asset, downloadPath, progressCh)
+	if err != nil {
+		os.RemoveAll(pluginDir)
+		return err
+	}
+
+	if progressCh != nil {
+		progressCh <- InstallProgress{Stage: "verifying", Percent: 85, Message: "Verifying checksum..."}
+	}
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		os.RemoveAll(pluginDir)
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset.Name, expected, actual)
+	}
+
+	if progressCh != nil {
+		progressCh <- InstallProgress{Stage: "extracting", Percent: 90, Message: "Installing binary..."}
+	}
+	if err := extractBinary(downloadPath, asset.Name, binaryName(plugin), filepath.Join(pluginDir, "bin", binaryName(plugin))); err != nil {
+		os.RemoveAll(pluginDir)
+		return err
+	}
+	os.Remove(downloadPath)
+
+	now := time.Now()
+	installed := storage.InstalledPlugin{
+		ID:            plugin.ID,
+		Name:          plugin.Name,
+		Version:       release.TagName,
+		InstallPath:   pluginDir,
+		InstallMethod: "binary",
+		InstalledAt:   now,
+		UpdatedAt:     now,
+	}
+
+	if err := i.storage.Save(installed); err != nil {
+		os.RemoveAll(pluginDir)
+		return fmt.Errorf("failed to save plugin metadata: %w", err)
+	}
+
+	freshConfig, err := config.LoadPluginsConfig(i.dataDir)
+	if err == nil {
+		freshConfig.SetPluginEnabled(plugin.ID, false)
+		_ = config.SavePluginsConfig(i.dataDir, freshConfig)
+		i.pluginsConfig = freshConfig
+	}
+
+	if progressCh != nil {
+		progressCh <- InstallProgress{Stage: "complete", Percent: 100, Message: "Installed " + release.TagName}
+	}
+
+	return nil
 }
 
 func (i *Installer) installManualWithContext(ctx context.Context, plugin *Plugin, progressCh chan<- InstallProgress) error {
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -26,6 +27,12 @@ type MCPManager struct {
 	activePlugins  map[string]bool
 	failedPlugins  map[string]error // Tracks plugins that failed to start (zombies, errors)
 	dataDir        string
+
+	slotsMu sync.Mutex
+	slots   map[string]chan struct{} // Per-plugin semaphores limiting parallel tool calls
+
+	subsMu     sync.Mutex
+	subscribed map[string]bool // Resources subscribed to, by resourceKey
 }
 
 func NewMCPManager(cfg *config.Config, pluginStorage *storage.PluginStorage, pluginsConfig *config.PluginsConfig, registry *Registry, dataDir string) *MCPManager {
@@ -38,6 +45,8 @@ func NewMCPManager(cfg *config.Config, pluginStorage *storage.PluginStorage, plu
 		activePlugins: make(map[string]bool),
 		failedPlugins: make(map[string]error),
 		dataDir:       dataDir,
+		slots:         make(map[string]chan struct{}),
+		subscribed:    make(map[string]bool),
 	}
 }
 
@@ -54,6 +63,7 @@ func (m *MCPManager) SetSession(session *storage.Session) error {
 	// Simply update the current session reference
 	// Plugins remain running; GetTools() filters by session.EnabledPlugins
 	m.currentSession = session
+	m.syncResourceSubscriptionsAsync()
 
 	return nil
 }
@@ -146,7 +156,11 @@ func (m *MCPManager) buildPluginCommand(plugin *Plugin, installed *storage.Insta
 		args := SubstituteArgs(plugin.Args, pluginConfig)
 		return binaryPath, args
 	case "binary":
-		binaryPath := filepath.Join(installed.InstallPath, plugin.Package)
+		// Downloaded releases live in bin/; binaries set up by hand at Package
+		binaryPath := filepath.Join(installed.InstallPath, "bin", binaryName(plugin))
+		if _, err := os.Stat(binaryPath); err != nil {
+			binaryPath = filepath.Join(installed.InstallPath, plugin.Package)
+		}
 		args := SubstituteArgs(plugin.Args, pluginConfig)
 		return binaryPath, args
 	case "manual", "docker":
@@ -263,6 +277,10 @@ func (m *MCPManager) Shutdown(ctx context.Context) error {
 	m.failedPlugins = make(map[string]error)
 	m.currentSession = nil
 
+	m.subsMu.Lock()
+	m.subscribed = make(map[string]bool)
+	m.subsMu.Unlock()
+
 	if config.DebugLog != nil {
 		config.DebugLog.Printf("[MCP] Shutdown: Shutdown process completed")
 	}
@@ -532,6 +550,8 @@ func (m *MCPManager) StartAllEnabledPlugins(ctx context.Context) error {
 		m.mu.Unlock()
 	}
 
+	m.syncResourceSubscriptionsAsync()
+
 	if config.DebugLog != nil {
 		config.DebugLog.Printf("[MCP] StartAllEnabledPlugins: Completed plugin startup process")
 	}
@@ -669,39 +689,77 @@ func (m *MCPManager) StartPlugin(ctx context.Context, pluginID string) error {
 		return fmt.Errorf("plugin not found in registry: %s", pluginID)
 	}
 
+	mcpConfig, err := m.pluginProcessConfig(plugin, installed)
+	if err != nil {
+		return err
+	}
+
+	if config.DebugLog != nil {
+		config.DebugLog.Printf("[MCP] StartPlugin: Plugin config for '%s': Runtime=%s, Config=%v", pluginID, mcpConfig.Runtime, mcpConfig.Config)
+	}
+
+	// Start the plugin
+	if config.DebugLog != nil {
+		config.DebugLog.Printf("[MCP] StartPlugin: Calling m.client.Start for plugin '%s'", pluginID)
+	}
+	if err := m.client.Start(ctx, mcpConfig); err != nil {
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("[MCP] StartPlugin: ERROR starting plugin '%s': %v", pluginID, err)
+			config.DebugLog.Printf("[MCP] StartPlugin: Marking '%s' as failed (zombie/crashed)", pluginID)
+		}
+		// Mark as both active AND failed - so it shows up in shutdown as unresponsive
+		m.activePlugins[pluginID] = true
+		m.failedPlugins[pluginID] = err
+		return fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	// Mark as active
+	m.activePlugins[pluginID] = true
+	m.syncResourceSubscriptionsAsync()
+
+	if config.DebugLog != nil {
+		config.DebugLog.Printf("[MCP] StartPlugin: Successfully started plugin '%s'", pluginID)
+	}
+
+	return nil
+}
+
+// pluginProcessConfig builds the configuration a plugin is started with.
+// The caller holds m.mu.
+func (m *MCPManager) pluginProcessConfig(plugin *Plugin, installed *storage.InstalledPlugin) (PluginConfig, error) {
 	// Build command
-	pluginConfig := m.pluginsConfig.GetPluginConfig(pluginID)
+	pluginConfig := m.pluginsConfig.GetPluginConfig(installed.ID)
 	command, args := m.buildPluginCommand(plugin, installed, pluginConfig)
 
 	// Skip if no command AND not a remote plugin
 	switch {
 	case command == "" && plugin.InstallType != "remote":
-		return fmt.Errorf("failed to build command for plugin: %s", pluginID)
+		return PluginConfig{}, fmt.Errorf("failed to build command for plugin: %s", installed.ID)
 	}
 
 	if config.DebugLog != nil {
-		config.DebugLog.Printf("[MCP] StartPlugin: Built command for '%s': command='%s', args=%v", pluginID, command, args)
+		config.DebugLog.Printf("[MCP] pluginProcessConfig: Built command for '%s': command='%s', args=%v", installed.ID, command, args)
 	}
 
 	// Configure plugin
 	mcpConfig := PluginConfig{
-		ID:         pluginID,
+		ID:         installed.ID,
 		Runtime:    plugin.InstallType,
 		EntryPoint: command,
 		Args:       args,
 		Env:        make(map[string]string),
-		Config:     m.pluginsConfig.GetPluginConfig(pluginID),
+		Config:     m.pluginsConfig.GetPluginConfig(installed.ID),
 		ServerURL:  installed.ServerURL,
 		AuthType:   installed.AuthType,
 		Transport:  installed.Transport,
 	}
 
 	// Check for server_url override in user config
-	userConfig := m.pluginsConfig.GetPluginConfig(pluginID)
+	userConfig := m.pluginsConfig.GetPluginConfig(installed.ID)
 	if serverURLOverride := userConfig["server_url"]; serverURLOverride != "" {
 		mcpConfig.ServerURL = serverURLOverride
 		if config.DebugLog != nil {
-			config.DebugLog.Printf("[MCP] StartPlugin: Overriding server_url for '%s': %s → %s", pluginID, installed.ServerURL, serverURLOverride)
+			config.DebugLog.Printf("[MCP] pluginProcessConfig: Overriding server_url for '%s': %s → %s", installed.ID, installed.ServerURL, serverURLOverride)
 		}
 	}
 
@@ -709,7 +767,7 @@ func (m *MCPManager) StartPlugin(ctx context.Context, pluginID string) error {
 	switch plugin.InstallType {
 	case "remote":
 		// Get config which may contain auth headers
-		for k, v := range m.pluginsConfig.GetPluginConfig(pluginID) {
+		for k, v := range m.pluginsConfig.GetPluginConfig(installed.ID) {
 			mcpConfig.Env[k] = v
 		}
 	}
@@ -719,33 +777,7 @@ func (m *MCPManager) StartPlugin(ctx context.Context, pluginID string) error {
 		mcpConfig.Env = SubstituteSessionVars(mcpConfig.Env, m.currentSession.ID, m.currentSession.Name, m.dataDir)
 	}
 
-	if config.DebugLog != nil {
-		config.DebugLog.Printf("[MCP] StartPlugin: Plugin config for '%s': Runtime=%s, Config=%v", pluginID, mcpConfig.Runtime, mcpConfig.Config)
-	}
-
-	// Start the plugin
-	if config.DebugLog != nil {
-		config.DebugLog.Printf("[MCP] StartPlugin: Calling m.client.Start for plugin '%s'", pluginID)
-	}
-	if err := m.client.Start(ctx, mcpConfig); err != nil {
-		if config.DebugLog != nil {
-			config.DebugLog.Printf("[MCP] StartPlugin: ERROR starting plugin '%s': %v", pluginID, err)
-			config.DebugLog.Printf("[MCP] StartPlugin: Marking '%s' as failed (zombie/crashed)", pluginID)
-		}
-		// Mark as both active AND failed - so it shows up in shutdown as unresponsive
-		m.activePlugins[pluginID] = true
-		m.failedPlugins[pluginID] = err
-		return fmt.Errorf("failed to start plugin: %w", err)
-	}
-
-	// Mark as active
-	m.activePlugins[pluginID] = true
-
-	if config.DebugLog != nil {
-		config.DebugLog.Printf("[MCP] StartPlugin: Successfully started plugin '%s'", pluginID)
-	}
-
-	return nil
+	return mcpConfig, nil
 }
 
 // StopPlugin stops a specific plugin (called when user disables it in Plugins Manager)
@@ -775,6 +807,7 @@ func (m *MCPManager) StopPlugin(ctx context.Context, pluginID string) error {
 	}
 
 	delete(m.activePlugins, pluginID)
+	m.forgetSubscriptions(pluginID)
 
 	if config.DebugLog != nil {
 		config.DebugLog.Printf("[MCP] StopPlugin: Successfully stopped plugin '%s'", pluginID)
@@ -796,7 +829,55 @@ func (m *MCPManager) ExecuteTool(ctx context.Context, toolName string, args map[
 		return nil, fmt.Errorf("plugins are disabled")
 	}
 
-	return m.client.CallTool(ctx, toolName, args)
+	pluginID := m.pluginIDForTool(toolName)
+
+	// Wait for a free slot first so time spent queued doesn't count
+	// against the call's timeout
+	release, err := m.acquireSlot(ctx, pluginID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	timeout := m.pluginsConfig.GetToolTimeout(pluginID, m.config.ToolExecutionTimeout())
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := m.client.CallTool(ctx, toolName, args)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// The plugin keeps running - only this call is abandoned
+		return nil, fmt.Errorf("%s timed out after %s: %w", toolName, timeout, err)
+	}
+	return result, err
+}
+
+// pluginIDForTool returns the ID of the plugin that provides a namespaced tool
+func (m *MCPManager) pluginIDForTool(toolName string) string {
+	shortName, _ := parseToolName(toolName)
+	return m.client.aggregator.findFullPluginID(shortName)
+}
+
+// acquireSlot blocks until pluginID has fewer calls in flight than its
+// max_concurrency (or tool_concurrency), then reserves one. The returned
+// func frees the slot.
+func (m *MCPManager) acquireSlot(ctx context.Context, pluginID string) (func(), error) {
+	limit := m.pluginsConfig.GetMaxConcurrency(pluginID, m.config.ToolConcurrencyLimit())
+
+	m.slotsMu.Lock()
+	slot, ok := m.slots[pluginID]
+	if !ok || cap(slot) != limit {
+		// Calls already running keep releasing into the old semaphore
+		slot = make(chan struct{}, limit)
+		m.slots[pluginID] = slot
+	}
+	m.slotsMu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // GetFailedPlugins returns a copy of the failed plugins map
@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"otui/config"
+)
+
+func TestAcquireSlot(t *testing.T) {
+	pluginsConfig := &config.PluginsConfig{Plugins: map[string]config.PluginConfigEntry{
+		"serial": {MaxConcurrency: 1},
+	}}
+	m := NewMCPManager(&config.Config{ToolConcurrency: 2}, nil, pluginsConfig, nil, t.TempDir())
+
+	t.Run("plugin_limit", func(t *testing.T) {
+		release, err := m.acquireSlot(context.Background(), "serial")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// A second call waits until the first releases its slot
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := m.acquireSlot(ctx, "serial"); err == nil {
+			t.Fatal("expected the second call to wait for a free slot")
+		}
+
+		release()
+		release2, err := m.acquireSlot(context.Background(), "serial")
+		if err != nil {
+			t.Fatalf("slot not freed by release: %v", err)
+		}
+		release2()
+	})
+
+	t.Run("default_limit", func(t *testing.T) {
+		var releases []func()
+		for i := 0; i < 2; i++ {
+			release, err := m.acquireSlot(context.Background(), "other")
+			if err != nil {
+				t.Fatalf("call %d: unexpected error: %v", i+1, err)
+			}
+			releases = append(releases, release)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := m.acquireSlot(ctx, "other"); err == nil {
+			t.Error("expected a third call to exceed tool_concurrency")
+		}
+
+		for _, release := range releases {
+			release()
+		}
+	})
+}
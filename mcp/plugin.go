@@ -29,6 +29,11 @@ type Plugin struct {
 	ConfigSchema []ConfigField `json:"config_schema,omitempty"`
 	Environment  string        `json:"environment,omitempty"`
 	Args         string        `json:"args,omitempty"`
+
+	// Binary plugins: the GitHub release to install (latest if empty) and the
+	// SHA-256 of each release asset, by asset name
+	Version   string            `json:"version,omitempty"`
+	Checksums map[string]string `json:"checksums,omitempty"`
 }
 
 type ConfigField struct {
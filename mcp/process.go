@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mark3labs/mcp-go/client"
@@ -19,7 +20,11 @@ type ProcessManager struct {
 	processes map[string]*PluginProcess
 	dataDir   string               // For FileTokenStore
 	config    *globalconfig.Config // For security settings
+	updates   chan ResourceUpdate  // Subscribed resources that changed
+	requests  chan PluginRequest   // Sampling and elicitation requests
 	mu        sync.RWMutex
+
+	requestsWatched atomic.Bool // Someone reads requests
 }
 
 func NewProcessManager(dataDir string, cfg *globalconfig.Config) *ProcessManager {
@@ -27,6 +32,8 @@ func NewProcessManager(dataDir string, cfg *globalconfig.Config) *ProcessManager
 		processes: make(map[string]*PluginProcess),
 		dataDir:   dataDir,
 		config:    cfg,
+		updates:   make(chan ResourceUpdate, 64),
+		requests:  make(chan PluginRequest),
 	}
 }
 
@@ -68,6 +75,24 @@ func (pm *ProcessManager) StartPlugin(ctx context.Context, config PluginConfig)
 		}
 	}
 
+	return pm.connect(ctx, config, mcpClient, capturedCmd)
+}
+
+// connect initializes a started client and records the plugin as running,
+// with its tools, resources and prompts
+func (pm *ProcessManager) connect(ctx context.Context, config PluginConfig, mcpClient *client.Client, cmd *exec.Cmd) error {
+	// Registered before initializing so no notification is missed
+	mcpClient.OnNotification(func(notification mcptypes.JSONRPCNotification) {
+		pm.handleNotification(config.ID, notification)
+	})
+	pm.registerRequestHandlers(config.ID, mcpClient)
+
+	// The transport is already running; Start routes its notifications and
+	// the plugin's requests to the client
+	if err := mcpClient.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start plugin %s: %w", config.ID, err)
+	}
+
 	// Initialize plugin (same for remote and local)
 	initReq := mcptypes.InitializeRequest{
 		Params: mcptypes.InitializeParams{
@@ -80,7 +105,7 @@ func (pm *ProcessManager) StartPlugin(ctx context.Context, config PluginConfig)
 		},
 	}
 
-	_, err = mcpClient.Initialize(ctx, initReq)
+	initResult, err := mcpClient.Initialize(ctx, initReq)
 	if err != nil {
 		return fmt.Errorf("failed to initialize plugin %s: %w", config.ID, err)
 	}
@@ -90,18 +115,30 @@ func (pm *ProcessManager) StartPlugin(ctx context.Context, config PluginConfig)
 		return fmt.Errorf("failed to list tools for %s: %w", config.ID, err)
 	}
 
-	// Store process
-	pm.mu.Lock()
-	pm.processes[config.ID] = &PluginProcess{
+	proc := &PluginProcess{
 		ID:        config.ID,
 		Name:      config.ID,
-		Process:   capturedCmd, // nil for remote
+		Process:   cmd, // nil for remote
 		Client:    mcpClient,
 		Tools:     toolsResult.Tools,
 		Running:   true,
-		IsRemote:  isRemote,
+		IsRemote:  config.ServerURL != "",
 		ServerURL: config.ServerURL,
 	}
+
+	// Resources are optional - a server that fails to list them still
+	// provides its tools
+	if caps := initResult.Capabilities.Resources; caps != nil {
+		proc.CanSubscribe = caps.Subscribe
+		proc.Resources, proc.ResourceTemplates = listResources(ctx, config.ID, mcpClient)
+	}
+	if initResult.Capabilities.Prompts != nil {
+		proc.Prompts = listPrompts(ctx, config.ID, mcpClient)
+	}
+
+	// Store process
+	pm.mu.Lock()
+	pm.processes[config.ID] = proc
 	pm.mu.Unlock()
 
 	return nil
@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	globalconfig "otui/config"
+)
+
+// listPrompts returns the prompt templates a plugin offers. Errors are logged
+// and leave the list empty.
+func listPrompts(ctx context.Context, pluginID string, mcpClient *client.Client) []mcptypes.Prompt {
+	result, err := mcpClient.ListPrompts(ctx, mcptypes.ListPromptsRequest{})
+	if err != nil {
+		if globalconfig.DebugLog != nil {
+			globalconfig.DebugLog.Printf("[MCP] Failed to list prompts for '%s': %v", pluginID, err)
+		}
+		return nil
+	}
+	return result.Prompts
+}
+
+// GetPrompts returns the prompt templates a running plugin offers
+func (pm *ProcessManager) GetPrompts(pluginID string) ([]mcptypes.Prompt, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	proc, exists := pm.processes[pluginID]
+	if !exists || !proc.Running {
+		return nil, fmt.Errorf("plugin %s not running", pluginID)
+	}
+
+	return proc.Prompts, nil
+}
+
+// RefreshPrompts lists a plugin's prompts again
+func (pm *ProcessManager) RefreshPrompts(ctx context.Context, pluginID string) error {
+	mcpClient, err := pm.GetClient(pluginID)
+	if err != nil {
+		return err
+	}
+
+	prompts := listPrompts(ctx, pluginID, mcpClient)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if proc, exists := pm.processes[pluginID]; exists && proc.Client == mcpClient {
+		proc.Prompts = prompts
+	}
+	return nil
+}
+
+// GetPromptsForPlugins returns the prompts of the given plugins, named
+// "plugin:prompt" the way tools are named "plugin.tool"
+func (ta *ToolAggregator) GetPromptsForPlugins(pluginMap map[string]string) []mcptypes.Prompt {
+	var allPrompts []mcptypes.Prompt
+
+	for pluginID, shortName := range pluginMap {
+		prompts, err := ta.processManager.GetPrompts(pluginID)
+		if err != nil {
+			continue
+		}
+
+		for _, prompt := range prompts {
+			namespacedPrompt := prompt
+			namespacedPrompt.Name = shortName + ":" + prompt.Name
+			allPrompts = append(allPrompts, namespacedPrompt)
+		}
+	}
+
+	sort.Slice(allPrompts, func(i, j int) bool {
+		return allPrompts[i].Name < allPrompts[j].Name
+	})
+	return allPrompts
+}
+
+// GetPrompt renders a namespaced prompt with the given arguments
+func (ta *ToolAggregator) GetPrompt(ctx context.Context, promptName string, args map[string]string) (*mcptypes.GetPromptResult, error) {
+	shortName, actualPromptName := parsePromptName(promptName)
+	fullPluginID := ta.findFullPluginID(shortName)
+
+	client, err := ta.processManager.GetClient(fullPluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	request := mcptypes.GetPromptRequest{}
+	request.Params.Name = actualPromptName
+	request.Params.Arguments = args
+	return client.GetPrompt(ctx, request)
+}
+
+func parsePromptName(namespacedName string) (string, string) {
+	idx := strings.Index(namespacedName, ":")
+	if idx == -1 {
+		return "", namespacedName
+	}
+	return namespacedName[:idx], namespacedName[idx+1:]
+}
+
+// GetPrompts returns the prompts of the plugins enabled in the current
+// session, sorted by their "plugin:prompt" names
+func (m *MCPManager) GetPrompts() []mcptypes.Prompt {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.config.PluginsEnabled || m.currentSession == nil {
+		return nil
+	}
+
+	pluginMap := m.getEnabledPluginsWithNamesLocked()
+	if len(pluginMap) == 0 {
+		return nil
+	}
+
+	return m.client.aggregator.GetPromptsForPlugins(pluginMap)
+}
+
+// GetPrompt renders a "plugin:prompt" prompt of a plugin enabled in the
+// current session
+func (m *MCPManager) GetPrompt(ctx context.Context, promptName string, args map[string]string) (*mcptypes.GetPromptResult, error) {
+	m.mu.RLock()
+	enabled := m.config.PluginsEnabled
+	pluginMap := m.getEnabledPluginsWithNamesLocked()
+	m.mu.RUnlock()
+
+	if !enabled {
+		if globalconfig.DebugLog != nil {
+			globalconfig.DebugLog.Printf("[MCP] SECURITY: GetPrompt(%s) rejected - plugins disabled", promptName)
+		}
+		return nil, fmt.Errorf("plugin system is disabled")
+	}
+
+	// Only plugins enabled in this session may be asked
+	shortName, _ := parsePromptName(promptName)
+	found := false
+	for _, name := range pluginMap {
+		if name == shortName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no plugin named %s is enabled in this session", shortName)
+	}
+
+	return m.client.aggregator.GetPrompt(ctx, promptName, args)
+}
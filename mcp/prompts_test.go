@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestPrompts(t *testing.T) {
+	s := server.NewMCPServer("reviewer", "1.0.0",
+		server.WithToolCapabilities(false), server.WithPromptCapabilities(true))
+	s.AddPrompt(mcptypes.NewPrompt("review", mcptypes.WithArgument("path", mcptypes.RequiredArgument())),
+		func(ctx context.Context, request mcptypes.GetPromptRequest) (*mcptypes.GetPromptResult, error) {
+			return mcptypes.NewGetPromptResult("Review", []mcptypes.PromptMessage{
+				mcptypes.NewPromptMessage(mcptypes.RoleUser, mcptypes.NewTextContent("Review "+request.Params.Arguments["path"])),
+			}), nil
+		})
+
+	mcpClient, err := client.NewInProcessClient(s)
+	if err != nil {
+		t.Fatalf("NewInProcessClient: %v", err)
+	}
+	ctx := context.Background()
+	if err := mcpClient.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer mcpClient.Close()
+
+	pm := NewProcessManager(t.TempDir(), nil)
+	if err := pm.connect(ctx, PluginConfig{ID: "reviewer"}, mcpClient, nil); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	aggregator := NewToolAggregator(pm, &Registry{})
+
+	t.Run("namespaced", func(t *testing.T) {
+		prompts := aggregator.GetPromptsForPlugins(map[string]string{"reviewer": "reviewer", "stopped": "stopped"})
+		if len(prompts) != 1 || prompts[0].Name != "reviewer:review" {
+			t.Fatalf("prompts = %+v, want reviewer:review", prompts)
+		}
+		if len(prompts[0].Arguments) != 1 || !prompts[0].Arguments[0].Required {
+			t.Errorf("arguments = %+v, want a required path", prompts[0].Arguments)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		result, err := aggregator.GetPrompt(ctx, "reviewer:review", map[string]string{"path": "main.go"})
+		if err != nil {
+			t.Fatalf("GetPrompt: %v", err)
+		}
+		if len(result.Messages) != 1 {
+			t.Fatalf("got %d messages, want 1", len(result.Messages))
+		}
+		if text, ok := result.Messages[0].Content.(mcptypes.TextContent); !ok || text.Text != "Review main.go" {
+			t.Errorf("content = %+v, want the rendered prompt", result.Messages[0].Content)
+		}
+	})
+}
+
+func TestParsePromptName(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantPlugin string
+		wantPrompt string
+	}{
+		{"git:commit", "git", "commit"},
+		{"git:commit:amend", "git", "commit:amend"},
+		{"commit", "", "commit"},
+	}
+	for _, tt := range tests {
+		plugin, prompt := parsePromptName(tt.name)
+		if plugin != tt.wantPlugin || prompt != tt.wantPrompt {
+			t.Errorf("parsePromptName(%q) = %q, %q; want %q, %q", tt.name, plugin, prompt, tt.wantPlugin, tt.wantPrompt)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package mcp
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const githubAPIURL = "https://api.github.com"
+
+// maxBinarySize bounds a binary unpacked from a release archive
+const maxBinarySize = 512 << 20
+
+type githubRelease struct {
+	TagName string               `json:"tag_name"`
+	Assets  []githubReleaseAsset `json:"assets"`
+}
+
+type githubReleaseAsset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+	Size        int64  `json:"size"`
+}
+
+// platformNames are the spellings release assets use for each GOOS and GOARCH
+var platformNames = map[string][]string{
+	"linux":   {"linux"},
+	"darwin":  {"darwin", "macos", "apple", "osx"},
+	"windows": {"windows", "win64", "win32", "win"},
+	"freebsd": {"freebsd"},
+	"amd64":   {"amd64", "x64"},
+	"arm64":   {"arm64", "aarch64"},
+	"386":     {"386", "i386", "i686", "x86"},
+	"arm":     {"armv7", "armv6", "arm"},
+}
+
+// binaryName returns the executable a binary plugin installs: the last part
+// of its package, or else of its repository
+func binaryName(plugin *Plugin) string {
+	name := path.Base(strings.TrimSuffix(plugin.Package, "/"))
+	if plugin.Package == "" {
+		name = path.Base(strings.TrimSuffix(plugin.Repository, "/"))
+	}
+	if runtime.GOOS == "windows" && !strings.HasSuffix(name, ".exe") {
+		name += ".exe"
+	}
+	return name
+}
+
+// githubRepoPath returns "owner/repo" from a GitHub repository URL
+func githubRepoPath(repository string) (string, error) {
+	if err := validateGitHubURL(repository); err != nil {
+		return "", err
+	}
+	parts := strings.Split(strings.TrimPrefix(repository, "https://github.com/"), "/")
+	return parts[0] + "/" + strings.TrimSuffix(parts[1], ".git"), nil
+}
+
+// fetchRelease looks up a release of the repository, the latest if tag is empty
+func (i *Installer) fetchRelease(ctx context.Context, repoPath, tag string) (*githubRelease, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/releases/latest", i.releaseAPI, repoPath)
+	if tag != "" {
+		endpoint = fmt.Sprintf("%s/repos/%s/releases/tags/%s", i.releaseAPI, repoPath, url.PathEscape(tag))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no release found for %s", repoPath)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release lookup returned HTTP %d", resp.StatusCode)
+	}
+
+	var release githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+	return &release, nil
+}
+
+// selectAsset picks the release asset built for goos/goarch, preferring an
+// archive to a bare executable. Checksums, signatures and packages like .deb
+// are skipped.
+func selectAsset(assets []githubReleaseAsset, goos, goarch string) (*githubReleaseAsset, error) {
+	var best *githubReleaseAsset
+	for idx := range assets {
+		asset := &assets[idx]
+		name := strings.ToLower(asset.Name)
+		if skipAsset(name) {
+			continue
+		}
+		// x86_64 would otherwise split into x86 and 64
+		normalized := strings.NewReplacer("x86_64", "amd64", "x86-64", "amd64").Replace(name)
+		tokens := strings.FieldsFunc(normalized, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.' || r == ' '
+		})
+		if !hasPlatformToken(tokens, goos) || !hasPlatformToken(tokens, goarch) {
+			continue
+		}
+		if best == nil || (archiveType(name) != "" && archiveType(best.Name) == "") {
+			best = asset
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("the release has no build for %s/%s", goos, goarch)
+	}
+	return best, nil
+}
+
+// skipAsset reports assets that can't be the plugin's binary
+func skipAsset(name string) bool {
+	for _, suffix := range []string{
+		".sha256", ".sha256sum", ".sha512", ".md5", ".sig", ".asc", ".pem", ".sbom", ".json", ".txt",
+		".deb", ".rpm", ".apk", ".msi", ".pkg", ".dmg", ".xz", ".bz2", ".zst", ".7z",
+	} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	// Compressed files other than tarballs
+	return strings.HasSuffix(name, ".gz") && archiveType(name) == ""
+}
+
+func hasPlatformToken(tokens []string, platform string) bool {
+	names, ok := platformNames[platform]
+	if !ok {
+		names = []string{platform}
+	}
+	for _, token := range tokens {
+		for _, name := range names {
+			if token == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// archiveType returns "tar.gz", "zip" or "" for an asset that isn't an archive
+func archiveType(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	}
+	return ""
+}
+
+// downloadAsset saves the asset to dest, reporting progress from 30% to 80%,
+// and returns the SHA-256 of what it downloaded
+func (i *Installer) downloadAsset(ctx context.Context, asset *githubReleaseAsset, dest string, progressCh chan<- InstallProgress) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", asset.DownloadURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download returned HTTP %d", resp.StatusCode)
+	}
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer out.Close()
+
+	total := resp.ContentLength
+	if total <= 0 {
+		total = asset.Size
+	}
+	hash := sha256.New()
+	counter := &progressWriter{total: total, progressCh: progressCh, name: asset.Name}
+	if _, err := io.Copy(io.MultiWriter(out, hash, counter), resp.Body); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("installation cancelled")
+		}
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return "", fmt.Errorf("failed to save download: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// progressWriter reports download progress each time another percent of the
+// asset arrives
+type progressWriter struct {
+	name       string
+	total      int64
+	written    int64
+	reported   int
+	progressCh chan<- InstallProgress
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	if w.progressCh == nil || w.total <= 0 {
+		return len(p), nil
+	}
+	percent := int(min(w.written*100/w.total, 100))
+	if percent > w.reported {
+		w.reported = percent
+		// Updates the installer view hasn't read yet are dropped, so a
+		// cancelled install never blocks on them
+		select {
+		case w.progressCh <- InstallProgress{
+			Stage:   "downloading",
+			Percent: 30 + float64(percent)/2,
+			Message: fmt.Sprintf("Downloading %s (%.1f / %.1f MB)...", w.name, float64(w.written)/(1<<20), float64(w.total)/(1<<20)),
+		}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// extractBinary writes the plugin's executable to dest: the download itself,
+// or the file of that name in an archive. An archive holding a single file
+// may name it differently.
+func extractBinary(downloadPath, assetName, name, dest string) error {
+	switch archiveType(assetName) {
+	case "tar.gz":
+		return extractFromTarGz(downloadPath, name, dest)
+	case "zip":
+		return extractFromZip(downloadPath, name, dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(downloadPath, dest); err != nil {
+		return fmt.Errorf("failed to install binary: %w", err)
+	}
+	return os.Chmod(dest, 0700)
+}
+
+func extractFromTarGz(archivePath, name, dest string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+
+	// Read the archive once, unpacking the first file next to dest in case
+	// it turns out to be the only one and none matches
+	onlyFile := dest + ".only"
+	defer os.Remove(onlyFile)
+	var files []string
+	var onlyErr error
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		files = append(files, header.Name)
+		if path.Base(header.Name) == name {
+			return writeExecutable(dest, tr)
+		}
+		if len(files) == 1 {
+			onlyErr = writeExecutable(onlyFile, tr)
+		}
+	}
+	if len(files) != 1 {
+		return fmt.Errorf("%s not found in the archive (it has %s)", name, strings.Join(files, ", "))
+	}
+	if onlyErr != nil {
+		return onlyErr
+	}
+	if err := os.Rename(onlyFile, dest); err != nil {
+		return fmt.Errorf("failed to install binary: %w", err)
+	}
+	return nil
+}
+
+func extractFromZip(archivePath, name, dest string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	defer zr.Close()
+
+	var files []*zip.File
+	for _, file := range zr.File {
+		if file.FileInfo().Mode().IsRegular() {
+			files = append(files, file)
+		}
+	}
+
+	var match *zip.File
+	for _, file := range files {
+		if path.Base(file.Name) == name {
+			match = file
+			break
+		}
+	}
+	if match == nil && len(files) == 1 {
+		match = files[0]
+	}
+	if match == nil {
+		var names []string
+		for _, file := range files {
+			names = append(names, file.Name)
+		}
+		return fmt.Errorf("%s not found in the archive (it has %s)", name, strings.Join(names, ", "))
+	}
+
+	rc, err := match.Open()
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	defer rc.Close()
+	return writeExecutable(dest, rc)
+}
+
+// writeExecutable writes the binary with the executable bit set
+func writeExecutable(dest string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0700)
+	if err != nil {
+		return fmt.Errorf("failed to write binary: %w", err)
+	}
+	defer out.Close()
+
+	n, err := io.Copy(out, io.LimitReader(r, maxBinarySize+1))
+	if err != nil {
+		return fmt.Errorf("failed to write binary: %w", err)
+	}
+	if n > maxBinarySize {
+		return fmt.Errorf("binary is larger than %d MB", maxBinarySize>>20)
+	}
+	return out.Close()
+}
@@ -0,0 +1,213 @@
+package mcp
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"otui/config"
+	"otui/storage"
+)
+
+// tarGz returns a gzipped tarball holding the given files
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newReleaseServer stands in for GitHub: a latest release of acme/tool v1.2.0
+// with an archive for this platform
+func newReleaseServer(t *testing.T, assetName string, archive []byte) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/repos/acme/tool/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(githubRelease{TagName: "v1.2.0", Assets: []githubReleaseAsset{
+			{Name: "checksums.txt", DownloadURL: server.URL + "/download/checksums.txt"},
+			{Name: assetName, DownloadURL: server.URL + "/download/" + assetName, Size: int64(len(archive))},
+		}})
+	})
+	mux.HandleFunc("/download/"+assetName, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+	return server
+}
+
+func newTestInstaller(t *testing.T, releaseAPI string) *Installer {
+	t.Helper()
+	dataDir := t.TempDir()
+	pluginStorage, err := storage.NewPluginStorage(dataDir)
+	if err != nil {
+		t.Fatalf("NewPluginStorage: %v", err)
+	}
+	installer := NewInstaller(pluginStorage, &config.PluginsConfig{}, dataDir)
+	installer.releaseAPI = releaseAPI
+	return installer
+}
+
+func TestInstallBinaryRelease(t *testing.T) {
+	assetName := fmt.Sprintf("tool_1.2.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	archive := tarGz(t, map[string]string{"tool_1.2.0/README.md": "docs", "tool_1.2.0/" + binaryName(&Plugin{Package: "tool"}): "#!/bin/sh\n"})
+	sum := sha256.Sum256(archive)
+	server := newReleaseServer(t, assetName, archive)
+
+	plugin := func(checksum string) *Plugin {
+		return &Plugin{
+			ID: "tool", Name: "Tool", InstallType: "binary", Package: "tool",
+			Repository: "https://github.com/acme/tool",
+			Checksums:  map[string]string{assetName: checksum},
+		}
+	}
+
+	t.Run("installs", func(t *testing.T) {
+		installer := newTestInstaller(t, server.URL)
+		progressCh := make(chan InstallProgress, 200)
+		if err := installer.Install(plugin(hex.EncodeToString(sum[:])), progressCh); err != nil {
+			t.Fatalf("Install: %v", err)
+		}
+		close(progressCh)
+
+		var stages []string
+		for p := range progressCh {
+			if len(stages) == 0 || stages[len(stages)-1] != p.Stage {
+				stages = append(stages, p.Stage)
+			}
+		}
+		if stages[len(stages)-1] != "complete" {
+			t.Errorf("stages = %v, want to end complete", stages)
+		}
+
+		installed, err := installer.GetInstalled("tool")
+		if err != nil || installed == nil {
+			t.Fatalf("GetInstalled = %v, %v", installed, err)
+		}
+		if installed.Version != "v1.2.0" {
+			t.Errorf("Version = %q, want v1.2.0", installed.Version)
+		}
+		info, err := os.Stat(filepath.Join(installed.InstallPath, "bin", binaryName(&Plugin{Package: "tool"})))
+		if err != nil {
+			t.Fatalf("binary not installed: %v", err)
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0100 == 0 {
+			t.Errorf("binary mode = %v, want executable", info.Mode())
+		}
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		installer := newTestInstaller(t, server.URL)
+		if err := installer.Install(plugin(hex.EncodeToString(make([]byte, 32))), nil); err == nil {
+			t.Fatal("expected a checksum error")
+		}
+		if installer.IsInstalled("tool") {
+			t.Error("plugin recorded as installed after a failed check")
+		}
+		if _, err := os.Stat(filepath.Join(installer.pluginsDir, "tool")); !os.IsNotExist(err) {
+			t.Errorf("plugin directory left behind: %v", err)
+		}
+	})
+
+	t.Run("without checksums", func(t *testing.T) {
+		installer := newTestInstaller(t, server.URL)
+		p := plugin("")
+		p.Checksums = nil
+		if err := installer.Install(p, nil); err != nil {
+			t.Fatalf("Install: %v", err)
+		}
+		installed, _ := installer.GetInstalled("tool")
+		if installed == nil || installed.Version != "binary" {
+			t.Errorf("installed = %+v, want the manual setup record", installed)
+		}
+	})
+}
+
+func TestSelectAsset(t *testing.T) {
+	assets := []githubReleaseAsset{
+		{Name: "tool_checksums.txt"},
+		{Name: "tool_1.0_linux_amd64.deb"},
+		{Name: "tool-linux-x86_64"},
+		{Name: "tool_1.0_Linux_x86_64.tar.gz"},
+		{Name: "tool_1.0_darwin_arm64.zip"},
+		{Name: "tool_1.0_windows_amd64.zip"},
+	}
+	tests := []struct {
+		goos, goarch string
+		want         string
+	}{
+		{"linux", "amd64", "tool_1.0_Linux_x86_64.tar.gz"},
+		{"darwin", "arm64", "tool_1.0_darwin_arm64.zip"},
+		{"windows", "amd64", "tool_1.0_windows_amd64.zip"},
+		{"linux", "arm64", ""},
+	}
+	for _, tt := range tests {
+		asset, err := selectAsset(assets, tt.goos, tt.goarch)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("%s/%s: got %s, want no match", tt.goos, tt.goarch, asset.Name)
+		case tt.want != "" && (err != nil || asset.Name != tt.want):
+			t.Errorf("%s/%s: got %v, %v; want %s", tt.goos, tt.goarch, asset, err, tt.want)
+		}
+	}
+}
+
+func TestExtractFromTarGz(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"named binary", map[string]string{"tool_1.0/README.md": "docs", "tool_1.0/tool": "binary"}, "binary", false},
+		{"only file", map[string]string{"tool-linux-amd64": "renamed"}, "renamed", false},
+		{"no match", map[string]string{"README.md": "docs", "LICENSE": "MIT"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			archivePath := filepath.Join(dir, "tool.tar.gz")
+			if err := os.WriteFile(archivePath, tarGz(t, tt.files), 0600); err != nil {
+				t.Fatal(err)
+			}
+			dest := filepath.Join(dir, "bin", "tool")
+			err := extractFromTarGz(archivePath, "tool", dest)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+			} else if data, _ := os.ReadFile(dest); err != nil || string(data) != tt.want {
+				t.Errorf("got %q, %v; want %q", data, err, tt.want)
+			}
+			if _, err := os.Stat(dest + ".only"); !os.IsNotExist(err) {
+				t.Errorf("unpacked file left behind: %v", err)
+			}
+		})
+	}
+}
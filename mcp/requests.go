@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	globalconfig "otui/config"
+)
+
+// pluginRequestTimeout bounds how long a plugin's sampling or elicitation
+// request waits for the user
+const pluginRequestTimeout = 10 * time.Minute
+
+// PluginRequest is a request a plugin makes of OTUI while it runs: a
+// *SamplingRequest or an *ElicitationRequest. The plugin waits until it is
+// answered.
+type PluginRequest interface {
+	Plugin() string
+}
+
+// SamplingRequest is a plugin asking for an LLM completion
+type SamplingRequest struct {
+	PluginID string
+	Params   mcptypes.CreateMessageParams
+	reply    chan samplingReply
+}
+
+type samplingReply struct {
+	result *mcptypes.CreateMessageResult
+	err    error
+}
+
+func (r *SamplingRequest) Plugin() string { return r.PluginID }
+
+// Respond answers the request with the completion, or the error that
+// prevented it. Only the first answer counts.
+func (r *SamplingRequest) Respond(result *mcptypes.CreateMessageResult, err error) {
+	select {
+	case r.reply <- samplingReply{result, err}:
+	default:
+	}
+}
+
+// ElicitationRequest is a plugin asking the user to fill in a form
+type ElicitationRequest struct {
+	PluginID string
+	Params   mcptypes.ElicitationParams
+	reply    chan *mcptypes.ElicitationResult
+}
+
+func (r *ElicitationRequest) Plugin() string { return r.PluginID }
+
+// Respond answers the request. Content is only sent with
+// ElicitationResponseActionAccept. Only the first answer counts.
+func (r *ElicitationRequest) Respond(action mcptypes.ElicitationResponseAction, content map[string]any) {
+	result := &mcptypes.ElicitationResult{ElicitationResponse: mcptypes.ElicitationResponse{Action: action}}
+	if action == mcptypes.ElicitationResponseActionAccept {
+		result.Content = content
+	}
+	select {
+	case r.reply <- result:
+	default:
+	}
+}
+
+// pluginRequestHandler passes a plugin's sampling and elicitation requests
+// on to whoever watches PluginRequests
+type pluginRequestHandler struct {
+	pluginID string
+	pm       *ProcessManager
+}
+
+// registerRequestHandlers lets a client advertise sampling and elicitation,
+// answered through pm.requests
+func (pm *ProcessManager) registerRequestHandlers(pluginID string, mcpClient *client.Client) {
+	handler := pluginRequestHandler{pluginID: pluginID, pm: pm}
+	client.WithSamplingHandler(handler)(mcpClient)
+	client.WithElicitationHandler(handler)(mcpClient)
+}
+
+// deliver hands a request to the watcher. Without one (headless runs) the
+// request is refused at once rather than left waiting.
+func (h pluginRequestHandler) deliver(ctx context.Context, request PluginRequest) error {
+	if !h.pm.requestsWatched.Load() {
+		return fmt.Errorf("this OTUI session can't answer plugin requests")
+	}
+	select {
+	case h.pm.requests <- request:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h pluginRequestHandler) CreateMessage(ctx context.Context, request mcptypes.CreateMessageRequest) (*mcptypes.CreateMessageResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginRequestTimeout)
+	defer cancel()
+
+	if globalconfig.DebugLog != nil {
+		globalconfig.DebugLog.Printf("[MCP] Plugin '%s' requested sampling (%d messages)", h.pluginID, len(request.Messages))
+	}
+
+	sampling := &SamplingRequest{PluginID: h.pluginID, Params: request.CreateMessageParams, reply: make(chan samplingReply, 1)}
+	if err := h.deliver(ctx, sampling); err != nil {
+		return nil, err
+	}
+	select {
+	case reply := <-sampling.reply:
+		return reply.result, reply.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (h pluginRequestHandler) Elicit(ctx context.Context, request mcptypes.ElicitationRequest) (*mcptypes.ElicitationResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginRequestTimeout)
+	defer cancel()
+
+	if globalconfig.DebugLog != nil {
+		globalconfig.DebugLog.Printf("[MCP] Plugin '%s' requested user input", h.pluginID)
+	}
+
+	elicitation := &ElicitationRequest{PluginID: h.pluginID, Params: request.Params, reply: make(chan *mcptypes.ElicitationResult, 1)}
+	if err := h.deliver(ctx, elicitation); err != nil {
+		return &mcptypes.ElicitationResult{ElicitationResponse: mcptypes.ElicitationResponse{Action: mcptypes.ElicitationResponseActionDecline}}, nil
+	}
+	select {
+	case result := <-elicitation.reply:
+		return result, nil
+	case <-ctx.Done():
+		return &mcptypes.ElicitationResult{ElicitationResponse: mcptypes.ElicitationResponse{Action: mcptypes.ElicitationResponseActionCancel}}, nil
+	}
+}
+
+// PluginRequests delivers the sampling and elicitation requests of running
+// plugins. Until it is first called, plugins' requests are refused.
+func (m *MCPManager) PluginRequests() <-chan PluginRequest {
+	m.client.processManager.requestsWatched.Store(true)
+	return m.client.processManager.requests
+}
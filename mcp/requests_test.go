@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPluginRequestHandler(t *testing.T) {
+	ctx := context.Background()
+	sampling := mcptypes.CreateMessageRequest{CreateMessageParams: mcptypes.CreateMessageParams{
+		Messages: []mcptypes.SamplingMessage{{Role: mcptypes.RoleUser, Content: mcptypes.NewTextContent("Hi")}},
+	}}
+
+	t.Run("unwatched", func(t *testing.T) {
+		pm := NewProcessManager(t.TempDir(), nil)
+		handler := pluginRequestHandler{pluginID: "notes", pm: pm}
+
+		if _, err := handler.CreateMessage(ctx, sampling); err == nil {
+			t.Error("expected sampling to be refused without a watcher")
+		}
+		result, err := handler.Elicit(ctx, mcptypes.ElicitationRequest{})
+		if err != nil || result.Action != mcptypes.ElicitationResponseActionDecline {
+			t.Errorf("Elicit = %+v, %v; want a decline", result, err)
+		}
+	})
+
+	t.Run("watched", func(t *testing.T) {
+		pm := NewProcessManager(t.TempDir(), nil)
+		pm.requestsWatched.Store(true)
+		handler := pluginRequestHandler{pluginID: "notes", pm: pm}
+
+		go func() {
+			for request := range pm.requests {
+				if request.Plugin() != "notes" {
+					t.Errorf("Plugin() = %q, want notes", request.Plugin())
+				}
+				switch r := request.(type) {
+				case *SamplingRequest:
+					r.Respond(&mcptypes.CreateMessageResult{Model: "llama3"}, nil)
+				case *ElicitationRequest:
+					r.Respond(mcptypes.ElicitationResponseActionAccept, map[string]any{"name": "Ada"})
+				}
+			}
+		}()
+		defer close(pm.requests)
+
+		result, err := handler.CreateMessage(ctx, sampling)
+		if err != nil || result.Model != "llama3" {
+			t.Errorf("CreateMessage = %+v, %v; want the watcher's result", result, err)
+		}
+		elicited, err := handler.Elicit(ctx, mcptypes.ElicitationRequest{})
+		if err != nil || elicited.Action != mcptypes.ElicitationResponseActionAccept {
+			t.Fatalf("Elicit = %+v, %v; want an accept", elicited, err)
+		}
+		if content, _ := elicited.Content.(map[string]any); content["name"] != "Ada" {
+			t.Errorf("content = %v, want the form's values", elicited.Content)
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	globalconfig "otui/config"
+)
+
+// subscriptionTimeout bounds the subscribe/unsubscribe calls made when the
+// session or the running plugins change
+const subscriptionTimeout = 10 * time.Second
+
+// ResourceUpdate reports that a subscribed resource changed on its server
+type ResourceUpdate struct {
+	PluginID string
+	URI      string
+}
+
+// listResources returns the resources and templates a plugin offers. Errors
+// are logged and leave the list empty.
+func listResources(ctx context.Context, pluginID string, mcpClient *client.Client) ([]mcptypes.Resource, []mcptypes.ResourceTemplate) {
+	var resources []mcptypes.Resource
+	var templates []mcptypes.ResourceTemplate
+
+	if result, err := mcpClient.ListResources(ctx, mcptypes.ListResourcesRequest{}); err == nil {
+		resources = result.Resources
+	} else if globalconfig.DebugLog != nil {
+		globalconfig.DebugLog.Printf("[MCP] Failed to list resources for '%s': %v", pluginID, err)
+	}
+
+	// Templates are newer; plenty of servers don't implement the method
+	if result, err := mcpClient.ListResourceTemplates(ctx, mcptypes.ListResourceTemplatesRequest{}); err == nil {
+		templates = result.ResourceTemplates
+	} else if globalconfig.DebugLog != nil {
+		globalconfig.DebugLog.Printf("[MCP] Failed to list resource templates for '%s': %v", pluginID, err)
+	}
+
+	return resources, templates
+}
+
+// handleNotification reacts to resource and prompt notifications from a plugin
+func (pm *ProcessManager) handleNotification(pluginID string, notification mcptypes.JSONRPCNotification) {
+	switch notification.Method {
+	case mcptypes.MethodNotificationResourceUpdated:
+		uri, _ := notification.Params.AdditionalFields["uri"].(string)
+		if uri == "" {
+			return
+		}
+		select {
+		case pm.updates <- ResourceUpdate{PluginID: pluginID, URI: uri}:
+		default:
+			// Nobody is keeping up; the next update refreshes it anyway
+			if globalconfig.DebugLog != nil {
+				globalconfig.DebugLog.Printf("[MCP] Dropped update of %s from '%s'", uri, pluginID)
+			}
+		}
+
+	case mcptypes.MethodNotificationResourcesListChanged:
+		// Notifications arrive on the transport's reader, which must not
+		// block on a request of its own
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), subscriptionTimeout)
+			defer cancel()
+			if err := pm.RefreshResources(ctx, pluginID); err != nil && globalconfig.DebugLog != nil {
+				globalconfig.DebugLog.Printf("[MCP] Failed to refresh resources for '%s': %v", pluginID, err)
+			}
+		}()
+
+	case mcptypes.MethodNotificationPromptsListChanged:
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), subscriptionTimeout)
+			defer cancel()
+			if err := pm.RefreshPrompts(ctx, pluginID); err != nil && globalconfig.DebugLog != nil {
+				globalconfig.DebugLog.Printf("[MCP] Failed to refresh prompts for '%s': %v", pluginID, err)
+			}
+		}()
+	}
+}
+
+// GetResources returns the resources and templates a running plugin offers
+func (pm *ProcessManager) GetResources(pluginID string) ([]mcptypes.Resource, []mcptypes.ResourceTemplate, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	proc, exists := pm.processes[pluginID]
+	if !exists || !proc.Running {
+		return nil, nil, fmt.Errorf("plugin %s not running", pluginID)
+	}
+
+	return proc.Resources, proc.ResourceTemplates, nil
+}
+
+// RefreshResources lists a plugin's resources again
+func (pm *ProcessManager) RefreshResources(ctx context.Context, pluginID string) error {
+	mcpClient, err := pm.GetClient(pluginID)
+	if err != nil {
+		return err
+	}
+
+	// Listed without the lock - the server may be slow to answer
+	resources, templates := listResources(ctx, pluginID, mcpClient)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if proc, exists := pm.processes[pluginID]; exists && proc.Client == mcpClient {
+		proc.Resources = resources
+		proc.ResourceTemplates = templates
+	}
+	return nil
+}
+
+// canSubscribe reports whether a running plugin sends resource updates
+func (pm *ProcessManager) canSubscribe(pluginID string) bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	proc, exists := pm.processes[pluginID]
+	return exists && proc.Running && proc.CanSubscribe
+}
+
+// GetResources returns the resources and templates of a running plugin
+func (m *MCPManager) GetResources(pluginID string) ([]mcptypes.Resource, []mcptypes.ResourceTemplate, error) {
+	if !m.IsEnabled() {
+		return nil, nil, fmt.Errorf("plugins are disabled")
+	}
+	return m.client.processManager.GetResources(pluginID)
+}
+
+// ReadResource reads a resource from a running plugin
+func (m *MCPManager) ReadResource(ctx context.Context, pluginID, uri string) (*mcptypes.ReadResourceResult, error) {
+	if !m.IsEnabled() {
+		if globalconfig.DebugLog != nil {
+			globalconfig.DebugLog.Printf("[MCP] SECURITY: ReadResource(%s) rejected - plugins disabled", uri)
+		}
+		return nil, fmt.Errorf("plugins are disabled")
+	}
+
+	mcpClient, err := m.client.processManager.GetClient(pluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	request := mcptypes.ReadResourceRequest{}
+	request.Params.URI = uri
+	return mcpClient.ReadResource(ctx, request)
+}
+
+// ResourceUpdates delivers changes to subscribed resources
+func (m *MCPManager) ResourceUpdates() <-chan ResourceUpdate {
+	return m.client.processManager.updates
+}
+
+// resourceKey identifies a resource across plugins
+func resourceKey(pluginID, uri string) string {
+	return pluginID + "\n" + uri
+}
+
+// SyncResourceSubscriptions subscribes to the resources pinned in the
+// current session, on plugins that support it, and unsubscribes from the
+// rest
+func (m *MCPManager) SyncResourceSubscriptions(ctx context.Context) {
+	type ref struct{ pluginID, uri string }
+	wanted := make(map[string]ref)
+
+	m.mu.RLock()
+	if m.config.PluginsEnabled && m.currentSession != nil {
+		for _, r := range m.currentSession.Resources {
+			if m.activePlugins[r.PluginID] && m.failedPlugins[r.PluginID] == nil {
+				wanted[resourceKey(r.PluginID, r.URI)] = ref{r.PluginID, r.URI}
+			}
+		}
+	}
+	m.mu.RUnlock()
+
+	pm := m.client.processManager
+
+	m.subsMu.Lock()
+	defer m.subsMu.Unlock()
+
+	for key, r := range wanted {
+		if m.subscribed[key] || !pm.canSubscribe(r.pluginID) {
+			continue
+		}
+		mcpClient, err := pm.GetClient(r.pluginID)
+		if err != nil {
+			continue
+		}
+		request := mcptypes.SubscribeRequest{}
+		request.Params.URI = r.uri
+		if err := mcpClient.Subscribe(ctx, request); err != nil {
+			if globalconfig.DebugLog != nil {
+				globalconfig.DebugLog.Printf("[MCP] Failed to subscribe to %s on '%s': %v", r.uri, r.pluginID, err)
+			}
+			continue
+		}
+		m.subscribed[key] = true
+	}
+
+	for key := range m.subscribed {
+		if _, ok := wanted[key]; ok {
+			continue
+		}
+		delete(m.subscribed, key)
+
+		pluginID, uri, _ := strings.Cut(key, "\n")
+		mcpClient, err := pm.GetClient(pluginID)
+		if err != nil {
+			continue // Stopped, which ended the subscription
+		}
+		request := mcptypes.UnsubscribeRequest{}
+		request.Params.URI = uri
+		_ = mcpClient.Unsubscribe(ctx, request)
+	}
+}
+
+// syncResourceSubscriptionsAsync runs SyncResourceSubscriptions in the
+// background, for callers holding m.mu
+func (m *MCPManager) syncResourceSubscriptionsAsync() {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), subscriptionTimeout)
+		defer cancel()
+		m.SyncResourceSubscriptions(ctx)
+	}()
+}
+
+// forgetSubscriptions drops the subscriptions of a plugin that stopped
+func (m *MCPManager) forgetSubscriptions(pluginID string) {
+	m.subsMu.Lock()
+	defer m.subsMu.Unlock()
+
+	for key := range m.subscribed {
+		if id, _, _ := strings.Cut(key, "\n"); id == pluginID {
+			delete(m.subscribed, key)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestConnectListsResources(t *testing.T) {
+	s := server.NewMCPServer("notes", "1.0.0",
+		server.WithToolCapabilities(false), server.WithResourceCapabilities(true, true))
+	s.AddResource(mcptypes.NewResource("note://todo", "Todo"),
+		func(ctx context.Context, request mcptypes.ReadResourceRequest) ([]mcptypes.ResourceContents, error) {
+			return []mcptypes.ResourceContents{mcptypes.TextResourceContents{URI: request.Params.URI, Text: "buy milk"}}, nil
+		})
+	s.AddResourceTemplate(mcptypes.NewResourceTemplate("note://{name}", "Note"),
+		func(ctx context.Context, request mcptypes.ReadResourceRequest) ([]mcptypes.ResourceContents, error) {
+			return nil, nil
+		})
+
+	mcpClient, err := client.NewInProcessClient(s)
+	if err != nil {
+		t.Fatalf("NewInProcessClient: %v", err)
+	}
+	ctx := context.Background()
+	if err := mcpClient.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer mcpClient.Close()
+
+	pm := NewProcessManager(t.TempDir(), nil)
+	if err := pm.connect(ctx, PluginConfig{ID: "notes"}, mcpClient, nil); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	resources, templates, err := pm.GetResources("notes")
+	if err != nil {
+		t.Fatalf("GetResources: %v", err)
+	}
+	if len(resources) != 1 || resources[0].URI != "note://todo" {
+		t.Errorf("resources = %+v, want note://todo", resources)
+	}
+	if len(templates) != 1 || templates[0].URITemplate.Raw() != "note://{name}" {
+		t.Errorf("templates = %+v, want note://{name}", templates)
+	}
+	if !pm.canSubscribe("notes") {
+		t.Error("plugin advertises subscriptions but canSubscribe is false")
+	}
+
+	if _, _, err := pm.GetResources("missing"); err == nil {
+		t.Error("expected an error for a plugin that isn't running")
+	}
+}
+
+func TestHandleNotification(t *testing.T) {
+	pm := NewProcessManager(t.TempDir(), nil)
+
+	updated := func(uri any) mcptypes.JSONRPCNotification {
+		notification := mcptypes.JSONRPCNotification{}
+		notification.Method = mcptypes.MethodNotificationResourceUpdated
+		notification.Params.AdditionalFields = map[string]any{"uri": uri}
+		return notification
+	}
+
+	t.Run("resource_updated", func(t *testing.T) {
+		pm.handleNotification("notes", updated("note://todo"))
+		select {
+		case update := <-pm.updates:
+			if update != (ResourceUpdate{PluginID: "notes", URI: "note://todo"}) {
+				t.Errorf("update = %+v", update)
+			}
+		default:
+			t.Fatal("no update delivered")
+		}
+	})
+
+	t.Run("missing_uri", func(t *testing.T) {
+		pm.handleNotification("notes", updated(nil))
+		select {
+		case update := <-pm.updates:
+			t.Errorf("unexpected update %+v", update)
+		default:
+		}
+	})
+
+	t.Run("full_channel", func(t *testing.T) {
+		// Never blocks the transport, even when nobody reads the updates
+		for i := 0; i < cap(pm.updates)+1; i++ {
+			pm.handleNotification("notes", updated("note://todo"))
+		}
+		if len(pm.updates) != cap(pm.updates) {
+			t.Errorf("channel holds %d updates, want %d", len(pm.updates), cap(pm.updates))
+		}
+	})
+}
@@ -2,6 +2,8 @@ package mcp
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	mcptypes "github.com/mark3labs/mcp-go/mcp"
@@ -241,3 +243,205 @@ func ConvertMCPToolsToAnthropicFormat(mcpTools []mcptypes.Tool) []anthropic.Tool
 
 	return result
 }
+
+// GeminiFunctionDeclaration is one entry of a Gemini tool's functionDeclarations
+type GeminiFunctionDeclaration struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+// maxGeminiRefDepth bounds $ref inlining so recursive schemas terminate
+const maxGeminiRefDepth = 8
+
+// geminiSchemaKeys are the JSON Schema keywords Gemini accepts unchanged
+var geminiSchemaKeys = map[string]bool{
+	"title": true, "description": true, "nullable": true,
+	"minItems": true, "maxItems": true, "minProperties": true, "maxProperties": true,
+	"minLength": true, "maxLength": true, "pattern": true, "minimum": true, "maximum": true,
+}
+
+// geminiFormats are the formats Gemini accepts; others are dropped
+var geminiFormats = map[string]bool{
+	"enum": true, "date-time": true, "int32": true, "int64": true, "float": true, "double": true,
+}
+
+// ConvertMCPToolsToGeminiFormat converts MCP tools to Gemini function declarations.
+//
+// Gemini accepts a subset of OpenAPI 3.0 rather than JSON Schema:
+//   - Types are upper case ("OBJECT", "STRING"); a type list such as
+//     ["string", "null"] becomes one type with nullable set
+//   - $ref is not supported, so references into $defs are inlined
+//   - Enum values must be strings
+//   - Keywords such as additionalProperties, default and $schema are rejected
+//   - An OBJECT must have properties, so tools without arguments omit parameters
+//   - required may only name declared properties
+func ConvertMCPToolsToGeminiFormat(mcpTools []mcptypes.Tool) []GeminiFunctionDeclaration {
+	if len(mcpTools) == 0 {
+		return nil
+	}
+
+	result := make([]GeminiFunctionDeclaration, len(mcpTools))
+
+	for i, tool := range mcpTools {
+		result[i] = GeminiFunctionDeclaration{
+			Name:        tool.Name,
+			Description: tool.Description,
+		}
+		if len(tool.InputSchema.Properties) == 0 {
+			continue
+		}
+
+		schema := map[string]any{
+			"type":       "object",
+			"properties": tool.InputSchema.Properties,
+		}
+		if len(tool.InputSchema.Required) > 0 {
+			schema["required"] = tool.InputSchema.Required
+		}
+		result[i].Parameters = convertSchemaToGemini(schema, tool.InputSchema.Defs, 0)
+	}
+
+	return result
+}
+
+// convertSchemaToGemini rewrites one JSON Schema node in Gemini's dialect
+func convertSchemaToGemini(schema map[string]any, defs map[string]any, depth int) map[string]any {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := ref[strings.LastIndex(ref, "/")+1:]
+		if def, ok := schemaMap(defs[name]); ok && depth < maxGeminiRefDepth {
+			return convertSchemaToGemini(def, defs, depth+1)
+		}
+		// Unknown or too deeply recursive reference - accept any text
+		return map[string]any{"type": "STRING"}
+	}
+
+	result := make(map[string]any)
+	for key, value := range schema {
+		switch {
+		case key == "type":
+			typ, nullable := geminiType(value)
+			if typ != "" {
+				result["type"] = typ
+			}
+			if nullable {
+				result["nullable"] = true
+			}
+		case key == "format":
+			if format, ok := value.(string); ok && geminiFormats[format] {
+				result["format"] = format
+			}
+		case key == "enum":
+			if values, ok := value.([]any); ok {
+				enum := make([]string, 0, len(values))
+				for _, v := range values {
+					if v != nil {
+						enum = append(enum, fmt.Sprint(v))
+					}
+				}
+				result["enum"] = enum
+			}
+		case key == "items":
+			if items, ok := schemaMap(value); ok {
+				result["items"] = convertSchemaToGemini(items, defs, depth)
+			}
+		case key == "properties":
+			props, ok := schemaMap(value)
+			if !ok || len(props) == 0 {
+				continue
+			}
+			converted := make(map[string]any, len(props))
+			for name, prop := range props {
+				if propSchema, ok := schemaMap(prop); ok {
+					converted[name] = convertSchemaToGemini(propSchema, defs, depth)
+				}
+			}
+			result["properties"] = converted
+		case key == "anyOf" || key == "oneOf":
+			if options, ok := value.([]any); ok {
+				var anyOf []any
+				for _, option := range options {
+					if optionSchema, ok := schemaMap(option); ok {
+						anyOf = append(anyOf, convertSchemaToGemini(optionSchema, defs, depth))
+					}
+				}
+				result["anyOf"] = anyOf
+			}
+		case geminiSchemaKeys[key]:
+			result[key] = value
+		}
+	}
+
+	// Enum values are strings, so the type must be too
+	if _, ok := result["enum"]; ok {
+		result["type"] = "STRING"
+	}
+
+	// Drop required names that aren't declared properties
+	if props, ok := result["properties"].(map[string]any); ok {
+		var required []string
+		for _, name := range stringList(schema["required"]) {
+			if _, declared := props[name]; declared {
+				required = append(required, name)
+			}
+		}
+		if len(required) > 0 {
+			result["required"] = required
+		}
+	}
+
+	return result
+}
+
+// geminiType converts a JSON Schema type (a string or a list of strings) to
+// Gemini's upper-case type, reporting whether "null" was allowed
+func geminiType(value any) (string, bool) {
+	var typ string
+	nullable := false
+	for _, t := range stringList(value) {
+		if t == "null" {
+			nullable = true
+		} else if typ == "" {
+			typ = strings.ToUpper(t)
+		}
+	}
+	return typ, nullable
+}
+
+// stringList reads a string or a list of strings from decoded JSON
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		var list []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// schemaMap returns a schema node as a map, converting typed values through JSON
+func schemaMap(value any) (map[string]any, bool) {
+	if m, ok := value.(map[string]any); ok {
+		return m, true
+	}
+	if value == nil {
+		return nil, false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, false
+	}
+	return m, true
+}
@@ -410,3 +410,71 @@ func TestComplexSchemaConversion(t *testing.T) {
 		t.Errorf("recursive type mismatch")
 	}
 }
+
+func TestConvertMCPToolsToGeminiFormat(t *testing.T) {
+	tools := []mcptypes.Tool{
+		{
+			Name:        "edit_file",
+			Description: "Edit a file",
+			InputSchema: mcptypes.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]any{
+					"path": map[string]any{"type": "string", "format": "uri"},
+					"mode": map[string]any{"type": []any{"string", "null"}, "enum": []any{"append", "replace"}, "default": "replace"},
+					"edits": map[string]any{
+						"type":  "array",
+						"items": map[string]any{"$ref": "#/$defs/Edit"},
+					},
+				},
+				Required: []string{"path", "missing"},
+				Defs: map[string]any{
+					"Edit": map[string]any{
+						"type":                 "object",
+						"additionalProperties": false,
+						"properties":           map[string]any{"line": map[string]any{"type": "integer"}},
+					},
+				},
+			},
+		},
+		{Name: "list_plugins", InputSchema: mcptypes.ToolInputSchema{Type: "object"}},
+	}
+
+	result := ConvertMCPToolsToGeminiFormat(tools)
+	if len(result) != 2 {
+		t.Fatalf("expected 2 declarations, got %d", len(result))
+	}
+
+	params := result[0].Parameters
+	if params["type"] != "OBJECT" {
+		t.Errorf("type = %v, want OBJECT", params["type"])
+	}
+	if required, _ := params["required"].([]string); len(required) != 1 || required[0] != "path" {
+		t.Errorf("required = %v, want only declared properties", params["required"])
+	}
+
+	props := params["properties"].(map[string]any)
+	path := props["path"].(map[string]any)
+	if _, ok := path["format"]; ok {
+		t.Error("unsupported format should be dropped")
+	}
+
+	mode := props["mode"].(map[string]any)
+	if mode["type"] != "STRING" || mode["nullable"] != true {
+		t.Errorf("mode = %v, want nullable STRING", mode)
+	}
+	if _, ok := mode["default"]; ok {
+		t.Error("default should be dropped")
+	}
+
+	item := props["edits"].(map[string]any)["items"].(map[string]any)
+	if item["type"] != "OBJECT" || item["properties"] == nil {
+		t.Errorf("$ref not inlined: %v", item)
+	}
+	if _, ok := item["additionalProperties"]; ok {
+		t.Error("additionalProperties should be dropped")
+	}
+
+	if result[1].Parameters != nil {
+		t.Errorf("tool without arguments should have no parameters, got %v", result[1].Parameters)
+	}
+}
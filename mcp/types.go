@@ -18,6 +18,15 @@ type PluginProcess struct {
 	Transport transport.Interface
 	Tools     []mcptypes.Tool
 	Running   bool
+
+	// Resources the plugin offers, if it supports them
+	Resources         []mcptypes.Resource
+	ResourceTemplates []mcptypes.ResourceTemplate
+	CanSubscribe      bool // Server sends updates for subscribed resources
+
+	// Prompt templates the plugin offers, if it supports them
+	Prompts []mcptypes.Prompt
+
 	Error     error
 	IsRemote  bool   // Remote plugins don't have local processes
 	ServerURL string // URL for remote plugins
@@ -0,0 +1,583 @@
+package mcp
+
+import (
+	"context"
+	"debug/buildinfo"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"otui/config"
+	"otui/storage"
+)
+
+const (
+	npmRegistryURL = "https://registry.npmjs.org"
+	pypiAPIURL     = "https://pypi.org"
+	goProxyURL     = "https://proxy.golang.org"
+)
+
+// maxUpdateChecks bounds the registries queried at once
+const maxUpdateChecks = 4
+
+// PluginUpdate is a newer version of an installed plugin
+type PluginUpdate struct {
+	PluginID  string
+	Installed string
+	Latest    string
+}
+
+// CheckUpdates looks for newer versions of the installed plugins. Plugins
+// whose versions can't be told are left out, as are failed lookups.
+func (i *Installer) CheckUpdates(ctx context.Context, plugins []*Plugin) map[string]PluginUpdate {
+	updates := make(map[string]PluginUpdate)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxUpdateChecks)
+
+	for _, plugin := range plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			update, err := i.CheckUpdate(ctx, plugin)
+			if err != nil {
+				if config.DebugLog != nil {
+					config.DebugLog.Printf("[MCP] CheckUpdates: %s: %v", plugin.ID, err)
+				}
+				return
+			}
+			if update != nil {
+				mu.Lock()
+				updates[plugin.ID] = *update
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return updates
+}
+
+// CheckUpdate looks up the newest release of an installed plugin in its
+// package ecosystem. It returns nil if the plugin is up to date or can't be
+// upgraded in place: remote, manual and docker plugins, and packages pinned
+// to a version.
+func (i *Installer) CheckUpdate(ctx context.Context, plugin *Plugin) (*PluginUpdate, error) {
+	installed, err := i.storage.Load(plugin.ID)
+	if err != nil || installed == nil {
+		return nil, fmt.Errorf("plugin not installed")
+	}
+	if !upgradable(plugin) {
+		return nil, nil
+	}
+
+	current := InstalledVersion(plugin, installed)
+	if current == "" {
+		return nil, nil
+	}
+
+	var latest string
+	switch plugin.InstallType {
+	case "npm":
+		name, pinned := npmPackageName(plugin.Package)
+		if pinned {
+			return nil, nil
+		}
+		latest, err = i.latestNpmVersion(ctx, name)
+	case "pip":
+		name, pinned := pipPackageName(plugin.Package)
+		if pinned {
+			return nil, nil
+		}
+		latest, err = i.latestPyPIVersion(ctx, name)
+	case "go":
+		if _, version, ok := strings.Cut(plugin.Package, "@"); ok && version != "latest" {
+			return nil, nil
+		}
+		module, _ := goBuildVersion(goBinaryPath(plugin, installed))
+		latest, err = i.latestGoVersion(ctx, module)
+	case "binary":
+		latest, err = i.latestReleaseVersion(ctx, plugin)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if latest == "" || compareVersions(latest, current) <= 0 {
+		return nil, nil
+	}
+	return &PluginUpdate{PluginID: plugin.ID, Installed: current, Latest: latest}, nil
+}
+
+// upgradable reports plugins the installer fetches itself, so it can fetch
+// a newer version. Binaries set up by hand are left to the user.
+func upgradable(plugin *Plugin) bool {
+	switch plugin.InstallType {
+	case "npm", "pip", "go":
+		return true
+	case "binary":
+		return len(plugin.Checksums) > 0
+	}
+	return false
+}
+
+// InstalledVersion returns the version of the plugin that's installed, read
+// from the package itself where the install record only holds its name. It
+// returns "" if the version can't be told.
+func InstalledVersion(plugin *Plugin, installed *storage.InstalledPlugin) string {
+	switch plugin.InstallType {
+	case "npm":
+		name, _ := npmPackageName(plugin.Package)
+		data, err := os.ReadFile(filepath.Join(installed.InstallPath, "node_modules", name, "package.json"))
+		if err != nil {
+			return ""
+		}
+		var pkg struct {
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(data, &pkg) != nil {
+			return ""
+		}
+		return pkg.Version
+	case "pip":
+		name, _ := pipPackageName(plugin.Package)
+		return pipInstalledVersion(filepath.Join(installed.InstallPath, "venv"), name)
+	case "go":
+		_, version := goBuildVersion(goBinaryPath(plugin, installed))
+		return version
+	case "binary":
+		if installed.Version == "binary" {
+			return ""
+		}
+		return installed.Version
+	}
+	return ""
+}
+
+// npmPackageName splits a version off an npm package spec like
+// "@scope/name@1.2.0", reporting whether it was pinned to one
+func npmPackageName(spec string) (string, bool) {
+	if idx := strings.LastIndex(spec, "@"); idx > 0 {
+		version := spec[idx+1:]
+		return spec[:idx], version != "" && version != "latest"
+	}
+	return spec, false
+}
+
+// pipPackageName strips extras and version specifiers from a requirement
+// like "name[extra]>=1.0", reporting whether it limited the version
+func pipPackageName(spec string) (string, bool) {
+	name := spec
+	if idx := strings.IndexAny(name, "=<>!~; "); idx >= 0 {
+		name = name[:idx]
+	}
+	pinned := len(name) < len(strings.TrimSpace(spec))
+	if idx := strings.Index(name, "["); idx >= 0 {
+		name = name[:idx]
+	}
+	return name, pinned
+}
+
+// pipInstalledVersion finds the package's version in the name of its
+// dist-info directory, where the name is normalized to lower case with
+// underscores
+func pipInstalledVersion(venvPath, name string) string {
+	normalize := strings.NewReplacer("-", "_", ".", "_").Replace
+	matches, _ := filepath.Glob(filepath.Join(venvPath, "lib", "python*", "site-packages", "*.dist-info"))
+	for _, match := range matches {
+		dist, version, ok := strings.Cut(strings.TrimSuffix(filepath.Base(match), ".dist-info"), "-")
+		if ok && strings.EqualFold(normalize(dist), normalize(name)) {
+			return version
+		}
+	}
+	return ""
+}
+
+// goBinaryPath returns the executable go install built for the plugin,
+// named after its package without a major version suffix
+func goBinaryPath(plugin *Plugin, installed *storage.InstalledPlugin) string {
+	pkg, _, _ := strings.Cut(plugin.Package, "@")
+	parts := strings.Split(strings.TrimSuffix(pkg, "/"), "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	binPath := filepath.Join(installed.InstallPath, "bin", name)
+	if _, err := os.Stat(binPath); err == nil {
+		return binPath
+	}
+	return filepath.Join(installed.InstallPath, plugin.Package)
+}
+
+// goBuildVersion reads the module and version a Go binary was built from
+func goBuildVersion(binPath string) (module, version string) {
+	info, err := buildinfo.ReadFile(binPath)
+	if err != nil || info.Main.Version == "(devel)" {
+		return "", ""
+	}
+	return info.Main.Path, info.Main.Version
+}
+
+func (i *Installer) latestNpmVersion(ctx context.Context, name string) (string, error) {
+	var packument struct {
+		DistTags struct {
+			Latest string `json:"latest"`
+		} `json:"dist-tags"`
+	}
+	// Scoped packages keep their @ but escape the slash
+	endpoint := i.npmRegistry + "/" + strings.Replace(name, "/", "%2F", 1)
+	if err := i.getJSON(ctx, endpoint, "application/vnd.npm.install-v1+json", &packument); err != nil {
+		return "", err
+	}
+	return packument.DistTags.Latest, nil
+}
+
+func (i *Installer) latestPyPIVersion(ctx context.Context, name string) (string, error) {
+	var project struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+	}
+	if err := i.getJSON(ctx, i.pypiAPI+"/pypi/"+url.PathEscape(name)+"/json", "", &project); err != nil {
+		return "", err
+	}
+	return project.Info.Version, nil
+}
+
+func (i *Installer) latestGoVersion(ctx context.Context, module string) (string, error) {
+	if module == "" {
+		return "", nil
+	}
+	var latest struct {
+		Version string `json:"Version"`
+	}
+	if err := i.getJSON(ctx, i.goProxy+"/"+escapeModulePath(module)+"/@latest", "", &latest); err != nil {
+		return "", err
+	}
+	return latest.Version, nil
+}
+
+// latestReleaseVersion returns the release a binary plugin would upgrade
+// to: the one the registry pins, or else the latest one the registry has a
+// checksum for on this platform
+func (i *Installer) latestReleaseVersion(ctx context.Context, plugin *Plugin) (string, error) {
+	if plugin.Version != "" {
+		return plugin.Version, nil
+	}
+	repoPath, err := githubRepoPath(plugin.Repository)
+	if err != nil {
+		return "", err
+	}
+	release, err := i.fetchRelease(ctx, repoPath, "")
+	if err != nil {
+		return "", err
+	}
+	asset, err := selectAsset(release.Assets, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", nil
+	}
+	if _, ok := plugin.Checksums[asset.Name]; !ok {
+		return "", nil
+	}
+	return release.TagName, nil
+}
+
+func (i *Installer) getJSON(ctx context.Context, endpoint, accept string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned HTTP %d", req.URL.Host, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// escapeModulePath writes upper-case letters of a module path as "!" and the
+// lower-case letter, as the module proxy protocol requires
+func escapeModulePath(module string) string {
+	var b strings.Builder
+	for _, r := range module {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// compareVersions orders two version strings, returning -1, 0 or 1. A
+// leading "v" is ignored, numeric parts compare as numbers and a release
+// comes after its pre-releases.
+func compareVersions(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+	coreA, preA, _ := strings.Cut(a, "-")
+	coreB, preB, _ := strings.Cut(b, "-")
+
+	if c := compareParts(strings.Split(coreA, "."), strings.Split(coreB, ".")); c != 0 {
+		return c
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return compareParts(strings.Split(preA, "."), strings.Split(preB, "."))
+}
+
+func compareParts(a, b []string) int {
+	for idx := 0; idx < max(len(a), len(b)); idx++ {
+		var pa, pb string
+		if idx < len(a) {
+			pa = a[idx]
+		}
+		if idx < len(b) {
+			pb = b[idx]
+		}
+		na, errA := strconv.Atoi(pa)
+		nb, errB := strconv.Atoi(pb)
+		if pa == "" {
+			na, errA = 0, nil
+		}
+		if pb == "" {
+			nb, errB = 0, nil
+		}
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case pa != pb:
+			return strings.Compare(pa, pb)
+		}
+	}
+	return 0
+}
+
+// PendingUpgrade is a plugin upgrade that can still be undone, restoring the
+// version it replaced
+type PendingUpgrade struct {
+	Version string // The version now installed
+
+	installer *Installer
+	pluginID  string
+	previous  storage.InstalledPlugin
+	entry     *config.PluginConfigEntry // The plugin's settings, if it had any
+	pluginDir string
+	backupDir string
+}
+
+// Upgrade installs the newest version of a plugin in place of the current
+// one, which is kept aside until the upgrade is committed or rolled back.
+// The plugin keeps its settings, credentials and install date.
+func (i *Installer) Upgrade(ctx context.Context, plugin *Plugin) (*PendingUpgrade, error) {
+	previous, err := i.storage.Load(plugin.ID)
+	if err != nil || previous == nil {
+		return nil, fmt.Errorf("plugin not installed")
+	}
+	if !upgradable(plugin) {
+		return nil, fmt.Errorf("%s plugins can't be upgraded in place", plugin.InstallType)
+	}
+
+	// Installing resets the plugin's entry to a disabled one, so the
+	// user's settings are put back afterwards
+	var entry *config.PluginConfigEntry
+	if cfg, err := config.LoadPluginsConfig(i.dataDir); err == nil {
+		if e, ok := cfg.Plugins[plugin.ID]; ok {
+			entry = &e
+		}
+	}
+
+	pluginDir := filepath.Join(i.pluginsDir, plugin.ID)
+	upgrade := &PendingUpgrade{
+		installer: i,
+		pluginID:  plugin.ID,
+		previous:  *previous,
+		entry:     entry,
+		pluginDir: pluginDir,
+		backupDir: pluginDir + ".previous",
+	}
+	os.RemoveAll(upgrade.backupDir)
+	if err := os.Rename(pluginDir, upgrade.backupDir); err != nil {
+		return nil, fmt.Errorf("failed to set aside the installed version: %w", err)
+	}
+
+	if err := i.InstallWithContext(ctx, plugin, nil); err != nil {
+		if rbErr := upgrade.Rollback(); rbErr != nil {
+			return nil, fmt.Errorf("%w (restoring the previous version failed: %v)", err, rbErr)
+		}
+		return nil, err
+	}
+
+	installed, err := i.storage.Load(plugin.ID)
+	if err == nil && installed == nil {
+		err = fmt.Errorf("no install record")
+	}
+	if err != nil {
+		upgrade.Rollback()
+		return nil, fmt.Errorf("failed to load the upgraded plugin: %w", err)
+	}
+	if version := InstalledVersion(plugin, installed); version != "" {
+		installed.Version = version
+	}
+	installed.InstalledAt = previous.InstalledAt
+	installed.UpdatedAt = time.Now()
+	if err := i.storage.Save(*installed); err != nil {
+		upgrade.Rollback()
+		return nil, fmt.Errorf("failed to save plugin metadata: %w", err)
+	}
+	if err := upgrade.restoreConfig(); err != nil {
+		upgrade.Rollback()
+		return nil, err
+	}
+
+	upgrade.Version = installed.Version
+	return upgrade, nil
+}
+
+// Commit removes the version the upgrade replaced
+func (u *PendingUpgrade) Commit() error {
+	return os.RemoveAll(u.backupDir)
+}
+
+// Rollback puts back the version the upgrade replaced, with its install
+// record and settings
+func (u *PendingUpgrade) Rollback() error {
+	if err := os.RemoveAll(u.pluginDir); err != nil {
+		return fmt.Errorf("failed to remove the new version: %w", err)
+	}
+	if err := os.Rename(u.backupDir, u.pluginDir); err != nil {
+		return fmt.Errorf("failed to restore the previous version: %w", err)
+	}
+	if err := u.installer.storage.Save(u.previous); err != nil {
+		return fmt.Errorf("failed to save plugin metadata: %w", err)
+	}
+	return u.restoreConfig()
+}
+
+func (u *PendingUpgrade) restoreConfig() error {
+	i := u.installer
+	freshConfig, err := config.LoadPluginsConfig(i.dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to load plugin settings: %w", err)
+	}
+	if u.entry != nil {
+		if freshConfig.Plugins == nil {
+			freshConfig.Plugins = make(map[string]config.PluginConfigEntry)
+		}
+		freshConfig.Plugins[u.pluginID] = *u.entry
+	} else {
+		freshConfig.DeletePlugin(u.pluginID)
+	}
+	if err := config.SavePluginsConfig(i.dataDir, freshConfig); err != nil {
+		return fmt.Errorf("failed to restore plugin settings: %w", err)
+	}
+	i.pluginsConfig = freshConfig
+	return nil
+}
+
+// upgradeStartTimeout bounds the check that an upgraded plugin starts
+const upgradeStartTimeout = 30 * time.Second
+
+// UpgradePlugin installs the newest version of a plugin in place of the
+// current one and returns the version installed. The new version has to start
+// and list its tools; if it doesn't, the old version is restored. A running
+// plugin is restarted either way.
+func (m *MCPManager) UpgradePlugin(ctx context.Context, installer *Installer, pluginID string) (string, error) {
+	plugin := m.registry.GetByID(pluginID)
+	if plugin == nil {
+		return "", fmt.Errorf("plugin not found in registry: %s", pluginID)
+	}
+
+	m.mu.RLock()
+	wasRunning := m.activePlugins[pluginID] && m.failedPlugins[pluginID] == nil
+	m.mu.RUnlock()
+	if wasRunning {
+		if err := m.StopPlugin(ctx, pluginID); err != nil {
+			return "", err
+		}
+	}
+	restart := func() error {
+		if !wasRunning {
+			return nil
+		}
+		return m.StartPlugin(ctx, pluginID)
+	}
+
+	upgrade, err := installer.Upgrade(ctx, plugin)
+	if err != nil {
+		restart()
+		return "", fmt.Errorf("upgrade failed: %w", err)
+	}
+
+	if err := m.verifyPlugin(ctx, plugin); err != nil {
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("[MCP] UpgradePlugin: %s %s failed to start, rolling back: %v", pluginID, upgrade.Version, err)
+		}
+		if rbErr := upgrade.Rollback(); rbErr != nil {
+			return "", fmt.Errorf("%s failed to start (%v) and the previous version couldn't be restored: %w", upgrade.Version, err, rbErr)
+		}
+		restart()
+		return "", fmt.Errorf("%s failed to start, so the previous version was restored: %w", upgrade.Version, err)
+	}
+
+	if err := upgrade.Commit(); err != nil && config.DebugLog != nil {
+		config.DebugLog.Printf("[MCP] UpgradePlugin: failed to remove the previous version of %s: %v", pluginID, err)
+	}
+	if err := restart(); err != nil {
+		return upgrade.Version, fmt.Errorf("upgraded to %s but failed to restart: %w", upgrade.Version, err)
+	}
+	return upgrade.Version, nil
+}
+
+// verifyPlugin starts the installed plugin apart from the running ones,
+// which requires it to initialize and list its tools, then stops it again
+func (m *MCPManager) verifyPlugin(ctx context.Context, plugin *Plugin) error {
+	installed, err := m.pluginStorage.Load(plugin.ID)
+	if err != nil || installed == nil {
+		return fmt.Errorf("plugin not installed")
+	}
+
+	m.mu.RLock()
+	mcpConfig, err := m.pluginProcessConfig(plugin, installed)
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, upgradeStartTimeout)
+	defer cancel()
+
+	pm := NewProcessManager(m.dataDir, m.config)
+	if err := pm.StartPlugin(ctx, mcpConfig); err != nil {
+		return err
+	}
+	return pm.StopPlugin(ctx, plugin.ID)
+}
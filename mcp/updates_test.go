@@ -0,0 +1,247 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"otui/config"
+	"otui/storage"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.2.0", 0},
+		{"v1.2.0", "1.2.0", 0},
+		{"1.10.0", "1.9.3", 1},
+		{"1.2", "1.2.1", -1},
+		{"2.0.0-beta.2", "2.0.0-beta.10", -1},
+		{"2.0.0-rc.1", "2.0.0", -1},
+		{"v0.3.1+build.5", "v0.3.1", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPackageNames(t *testing.T) {
+	npm := []struct {
+		spec, name string
+		pinned     bool
+	}{
+		{"@acme/tool", "@acme/tool", false},
+		{"@acme/tool@1.2.0", "@acme/tool", true},
+		{"tool@latest", "tool", false},
+	}
+	for _, tt := range npm {
+		if name, pinned := npmPackageName(tt.spec); name != tt.name || pinned != tt.pinned {
+			t.Errorf("npmPackageName(%q) = %q, %v, want %q, %v", tt.spec, name, pinned, tt.name, tt.pinned)
+		}
+	}
+
+	pip := []struct {
+		spec, name string
+		pinned     bool
+	}{
+		{"acme-tool", "acme-tool", false},
+		{"acme-tool[cli]", "acme-tool", false},
+		{"acme-tool[cli]>=1.0", "acme-tool", true},
+		{"acme-tool==0.9", "acme-tool", true},
+	}
+	for _, tt := range pip {
+		if name, pinned := pipPackageName(tt.spec); name != tt.name || pinned != tt.pinned {
+			t.Errorf("pipPackageName(%q) = %q, %v, want %q, %v", tt.spec, name, pinned, tt.name, tt.pinned)
+		}
+	}
+}
+
+// installFake records a plugin as installed, with the given files in its
+// directory
+func installFake(t *testing.T, installer *Installer, plugin *Plugin, version string, files map[string]string) string {
+	t.Helper()
+	pluginDir := filepath.Join(installer.pluginsDir, plugin.ID)
+	for name, content := range files {
+		path := filepath.Join(pluginDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	installedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := installer.storage.Save(storage.InstalledPlugin{
+		ID: plugin.ID, Name: plugin.Name, Version: version, InstallPath: pluginDir,
+		InstallMethod: plugin.InstallType, InstalledAt: installedAt, UpdatedAt: installedAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pluginDir
+}
+
+func TestCheckUpdates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/npm/@acme%2Fsearch":
+			fmt.Fprint(w, `{"name":"@acme/search","dist-tags":{"latest":"1.4.0"}}`)
+		case "/npm/current":
+			fmt.Fprint(w, `{"dist-tags":{"latest":"2.0.0"}}`)
+		case "/pypi/pypi/acme-notes/json":
+			fmt.Fprint(w, `{"info":{"version":"0.10.0"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	installer := newTestInstaller(t, server.URL)
+	installer.npmRegistry = server.URL + "/npm"
+	installer.pypiAPI = server.URL + "/pypi"
+
+	search := &Plugin{ID: "search", InstallType: "npm", Package: "@acme/search"}
+	installFake(t, installer, search, search.Package, map[string]string{
+		"node_modules/@acme/search/package.json": `{"name":"@acme/search","version":"1.2.3"}`,
+	})
+	current := &Plugin{ID: "current", InstallType: "npm", Package: "current"}
+	installFake(t, installer, current, current.Package, map[string]string{
+		"node_modules/current/package.json": `{"version":"2.0.0"}`,
+	})
+	notes := &Plugin{ID: "notes", InstallType: "pip", Package: "acme-notes"}
+	installFake(t, installer, notes, notes.Package, map[string]string{
+		"venv/lib/python3.12/site-packages/acme_notes-0.9.1.dist-info/METADATA": "",
+	})
+	pinned := &Plugin{ID: "pinned", InstallType: "pip", Package: "acme-notes==0.9.1"}
+	installFake(t, installer, pinned, pinned.Package, map[string]string{
+		"venv/lib/python3.12/site-packages/acme_notes-0.9.1.dist-info/METADATA": "",
+	})
+	manual := &Plugin{ID: "manual", InstallType: "manual"}
+	installFake(t, installer, manual, "manual", nil)
+
+	updates := installer.CheckUpdates(context.Background(), []*Plugin{search, current, notes, pinned, manual})
+
+	want := map[string]PluginUpdate{
+		"search": {PluginID: "search", Installed: "1.2.3", Latest: "1.4.0"},
+		"notes":  {PluginID: "notes", Installed: "0.9.1", Latest: "0.10.0"},
+	}
+	if len(updates) != len(want) {
+		t.Errorf("updates = %+v, want %+v", updates, want)
+	}
+	for id, update := range want {
+		if updates[id] != update {
+			t.Errorf("updates[%s] = %+v, want %+v", id, updates[id], update)
+		}
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	exe := binaryName(&Plugin{Package: "tool"})
+	assetName := fmt.Sprintf("tool_1.2.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	archive := tarGz(t, map[string]string{exe: "new"})
+	sum := sha256.Sum256(archive)
+	server := newReleaseServer(t, assetName, archive)
+
+	plugin := &Plugin{
+		ID: "tool", Name: "Tool", InstallType: "binary", Package: "tool",
+		Repository: "https://github.com/acme/tool",
+		Checksums:  map[string]string{assetName: hex.EncodeToString(sum[:])},
+	}
+	entry := config.PluginConfigEntry{Enabled: true, Config: map[string]string{"API_URL": "https://example.com"}, SensitiveKeys: []string{"API_KEY"}}
+
+	setup := func(t *testing.T) (*Installer, string) {
+		installer := newTestInstaller(t, server.URL)
+		pluginDir := installFake(t, installer, plugin, "v1.0.0", map[string]string{"bin/" + exe: "old"})
+		cfg := &config.PluginsConfig{Plugins: map[string]config.PluginConfigEntry{"tool": entry}}
+		if err := config.SavePluginsConfig(installer.dataDir, cfg); err != nil {
+			t.Fatal(err)
+		}
+		return installer, pluginDir
+	}
+	check := func(t *testing.T, installer *Installer, pluginDir, version, binary string) {
+		t.Helper()
+		installed, _ := installer.GetInstalled("tool")
+		if installed == nil || installed.Version != version {
+			t.Fatalf("installed = %+v, want version %s", installed, version)
+		}
+		if !installed.InstalledAt.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+			t.Errorf("InstalledAt = %v, want the original install date", installed.InstalledAt)
+		}
+		if data, _ := os.ReadFile(filepath.Join(pluginDir, "bin", exe)); string(data) != binary {
+			t.Errorf("binary = %q, want %q", data, binary)
+		}
+		cfg, err := config.LoadPluginsConfig(installer.dataDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := cfg.Plugins["tool"]
+		if !got.Enabled || got.Config["API_URL"] != entry.Config["API_URL"] || len(got.SensitiveKeys) != 1 {
+			t.Errorf("config entry = %+v, want %+v", got, entry)
+		}
+	}
+
+	t.Run("finds the release", func(t *testing.T) {
+		installer, _ := setup(t)
+		update, err := installer.CheckUpdate(context.Background(), plugin)
+		if err != nil || update == nil || update.Installed != "v1.0.0" || update.Latest != "v1.2.0" {
+			t.Errorf("CheckUpdate = %+v, %v, want v1.0.0 to v1.2.0", update, err)
+		}
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		installer, pluginDir := setup(t)
+		upgrade, err := installer.Upgrade(context.Background(), plugin)
+		if err != nil {
+			t.Fatalf("Upgrade: %v", err)
+		}
+		if upgrade.Version != "v1.2.0" {
+			t.Errorf("Version = %q, want v1.2.0", upgrade.Version)
+		}
+		check(t, installer, pluginDir, "v1.2.0", "new")
+		installed, _ := installer.GetInstalled("tool")
+		if !installed.UpdatedAt.After(installed.InstalledAt) {
+			t.Errorf("UpdatedAt = %v, want the upgrade time", installed.UpdatedAt)
+		}
+
+		if err := upgrade.Commit(); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+		if _, err := os.Stat(pluginDir + ".previous"); !os.IsNotExist(err) {
+			t.Errorf("previous version left behind: %v", err)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		installer, pluginDir := setup(t)
+		upgrade, err := installer.Upgrade(context.Background(), plugin)
+		if err != nil {
+			t.Fatalf("Upgrade: %v", err)
+		}
+		if err := upgrade.Rollback(); err != nil {
+			t.Fatalf("Rollback: %v", err)
+		}
+		check(t, installer, pluginDir, "v1.0.0", "old")
+	})
+
+	t.Run("failed install", func(t *testing.T) {
+		installer, pluginDir := setup(t)
+		broken := *plugin
+		broken.Checksums = map[string]string{assetName: hex.EncodeToString(make([]byte, 32))}
+		if _, err := installer.Upgrade(context.Background(), &broken); err == nil {
+			t.Fatal("expected a checksum error")
+		}
+		check(t, installer, pluginDir, "v1.0.0", "old")
+	})
+}
@@ -0,0 +1,148 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxAttachmentSize caps the size of one attached file. Attachments are stored
+// inline in the session file, so large files make every save slower.
+const MaxAttachmentSize = 20 << 20 // 20 MB
+
+// ErrVisionUnsupported is returned when images are sent to a model that can't see them
+var ErrVisionUnsupported = errors.New("model does not accept images")
+
+// Attachment is a file sent with a user message: an image the model looks
+// at, or a text file whose contents are added to the prompt
+type Attachment struct {
+	Name     string // File name shown in the chat
+	MIMEType string // "image/png", "image/jpeg", "image/gif", "image/webp" or "text/plain"
+	Data     []byte
+}
+
+// imageTypes are the image formats every vision-capable provider accepts
+var imageTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// IsImage reports whether the attachment is sent as an image part
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.MIMEType, "image/")
+}
+
+// LoadAttachment reads a file to attach to a message. Images are recognized
+// by extension; anything else must be UTF-8 text.
+func LoadAttachment(path string) (Attachment, error) {
+	name := filepath.Base(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if info.IsDir() {
+		return Attachment{}, fmt.Errorf("%s is a directory", name)
+	}
+	if info.Size() > MaxAttachmentSize {
+		return Attachment{}, fmt.Errorf("%s is %d MB; attachments are limited to %d MB",
+			name, info.Size()>>20, MaxAttachmentSize>>20)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	if mimeType, ok := imageTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		return Attachment{Name: name, MIMEType: mimeType, Data: data}, nil
+	}
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) != -1 {
+		return Attachment{}, fmt.Errorf("%s is neither an image (PNG, JPEG, GIF, WebP) nor a text file", name)
+	}
+	return Attachment{Name: name, MIMEType: "text/plain", Data: data}, nil
+}
+
+// Images returns the message's image attachments
+func (msg Message) Images() []Attachment {
+	var images []Attachment
+	for _, att := range msg.Attachments {
+		if att.IsImage() {
+			images = append(images, att)
+		}
+	}
+	return images
+}
+
+// ContentWithFiles returns the message text followed by the contents of its
+// text attachments, which is how providers receive attached text files
+func (msg Message) ContentWithFiles() string {
+	var b strings.Builder
+	b.WriteString(msg.Content)
+	for _, att := range msg.Attachments {
+		if att.IsImage() {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "File: %s\n```\n%s\n```", att.Name, strings.TrimRight(string(att.Data), "\n"))
+	}
+	return b.String()
+}
+
+// CheckAttachments returns an error wrapping ErrVisionUnsupported if the
+// attachments include images and the current model can't see them
+func (m *Model) CheckAttachments(attachments []Attachment) error {
+	for _, att := range attachments {
+		if !att.IsImage() {
+			continue
+		}
+		if m.GetModelMetadata().SupportsVision {
+			return nil
+		}
+		modelName := ""
+		if m.CurrentSession != nil {
+			modelName = m.CurrentSession.Model
+		}
+		return fmt.Errorf("%w: %s can't read %s. Switch to a vision model (such as llava, gpt-4o or Claude) or remove the image",
+			ErrVisionUnsupported, modelName, att.Name)
+	}
+	return nil
+}
+
+// omitImages replaces the image attachments of the messages with a note in
+// their text, for models that can't see them
+func omitImages(messages []Message) []Message {
+	result := make([]Message, len(messages))
+	for i, msg := range messages {
+		result[i] = msg
+		images := msg.Images()
+		if len(images) == 0 {
+			continue
+		}
+
+		var kept []Attachment
+		for _, att := range msg.Attachments {
+			if !att.IsImage() {
+				kept = append(kept, att)
+			}
+		}
+		for _, img := range images {
+			if msg.Content != "" {
+				msg.Content += "\n\n"
+			}
+			msg.Content += fmt.Sprintf("[image omitted: %s]", img.Name)
+		}
+		msg.Attachments = kept
+		result[i] = msg
+	}
+	return result
+}
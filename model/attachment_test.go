@@ -0,0 +1,147 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestLoadAttachment(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("image by extension", func(t *testing.T) {
+		att, err := LoadAttachment(write("photo.JPG", []byte{0xff, 0xd8, 0xff}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if att.Name != "photo.JPG" || att.MIMEType != "image/jpeg" || !att.IsImage() {
+			t.Errorf("got %+v, want a JPEG image", att)
+		}
+	})
+
+	t.Run("text file", func(t *testing.T) {
+		att, err := LoadAttachment(write("main.go", []byte("package main\n")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if att.MIMEType != "text/plain" || string(att.Data) != "package main\n" {
+			t.Errorf("got %+v, want text/plain contents", att)
+		}
+	})
+
+	t.Run("binary file", func(t *testing.T) {
+		if _, err := LoadAttachment(write("a.out", []byte{0x7f, 'E', 'L', 'F', 0})); err == nil {
+			t.Error("expected binary file to be rejected")
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		if _, err := LoadAttachment(dir); err == nil {
+			t.Error("expected directory to be rejected")
+		}
+	})
+}
+
+func TestContentWithFiles(t *testing.T) {
+	msg := Message{
+		Role:    "user",
+		Content: "review this",
+		Attachments: []Attachment{
+			{Name: "shot.png", MIMEType: "image/png", Data: []byte{1}},
+			{Name: "a.txt", MIMEType: "text/plain", Data: []byte("hello\n")},
+		},
+	}
+
+	want := "review this\n\nFile: a.txt\n```\nhello\n```"
+	if got := msg.ContentWithFiles(); got != want {
+		t.Errorf("ContentWithFiles() = %q, want %q", got, want)
+	}
+	if images := msg.Images(); len(images) != 1 || images[0].Name != "shot.png" {
+		t.Errorf("Images() = %+v, want only shot.png", images)
+	}
+}
+
+func TestCheckAttachments(t *testing.T) {
+	image := []Attachment{{Name: "shot.png", MIMEType: "image/png", Data: []byte{1}}}
+	text := []Attachment{{Name: "a.txt", MIMEType: "text/plain", Data: []byte("hi")}}
+
+	blind := newHeadlessTestModel(&stubProvider{})
+	err := blind.CheckAttachments(image)
+	if !errors.Is(err, ErrVisionUnsupported) {
+		t.Fatalf("err = %v, want ErrVisionUnsupported", err)
+	}
+	if !strings.Contains(err.Error(), "shot.png") {
+		t.Errorf("error should name the image: %v", err)
+	}
+	if err := blind.CheckAttachments(text); err != nil {
+		t.Errorf("text attachments need no vision support, got %v", err)
+	}
+
+	seeing := newHeadlessTestModel(&stubProvider{vision: true})
+	if err := seeing.CheckAttachments(image); err != nil {
+		t.Errorf("vision model rejected image: %v", err)
+	}
+}
+
+// capturingProvider records the messages of the last request
+type capturingProvider struct {
+	stubProvider
+	messages []Message
+}
+
+func (p *capturingProvider) ChatWithTools(ctx context.Context, messages []Message, tools []mcptypes.Tool, callback StreamCallback) error {
+	p.messages = messages
+	return p.stubProvider.ChatWithTools(ctx, messages, tools, callback)
+}
+
+func TestOldImagesOnBlindModel(t *testing.T) {
+	// The image was sent while the session used a vision model
+	p := &capturingProvider{stubProvider: stubProvider{response: "sure"}}
+	m := newHeadlessTestModel(p)
+	m.Messages = []Message{
+		{Role: "user", Content: "what's this?", Attachments: []Attachment{
+			{Name: "shot.png", MIMEType: "image/png", Data: []byte{1}},
+			{Name: "notes.txt", MIMEType: "text/plain", Data: []byte("hi")},
+		}},
+		{Role: "assistant", Content: "A screenshot."},
+	}
+
+	if _, err := m.RunHeadless("thanks, and now?", HeadlessOptions{}); err != nil {
+		t.Fatalf("text-only turn failed: %v", err)
+	}
+
+	var first *Message
+	for i := range p.messages {
+		if p.messages[i].Role == "user" {
+			first = &p.messages[i]
+			break
+		}
+	}
+	if first == nil {
+		t.Fatalf("no user message sent: %+v", p.messages)
+	}
+	if len(first.Images()) != 0 {
+		t.Errorf("image sent to a model without vision")
+	}
+	if len(first.Attachments) != 1 || first.Attachments[0].Name != "notes.txt" {
+		t.Errorf("attachments = %+v, want the text file kept", first.Attachments)
+	}
+	if !strings.Contains(first.Content, "[image omitted: shot.png]") {
+		t.Errorf("content = %q, want a note for the omitted image", first.Content)
+	}
+	if len(m.Messages[0].Images()) != 1 {
+		t.Errorf("the session's own message lost its image")
+	}
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"fmt"
+
+	"otui/storage"
+)
+
+// BranchVersion says which of the versions of the conversation from a
+// message on is showing (1-based), and how many there are
+type BranchVersion struct {
+	Current int
+	Total   int
+}
+
+// isSaved reports whether msg is kept with the session. Transient system
+// messages (spinners, errors, notices) are not.
+func (msg Message) isSaved() bool {
+	return msg.Role == "user" || msg.Role == "assistant" || msg.Role == "tool" || (msg.Role == "system" && msg.Persistent)
+}
+
+// syncSessionMessages copies the messages that are kept with the session
+// from m.Messages into the current session
+func (m *Model) syncSessionMessages() {
+	var sessionMessages []storage.Message
+	for _, msg := range m.Messages {
+		if msg.isSaved() {
+			sessionMessages = append(sessionMessages, msg.ToStorage())
+		}
+	}
+	m.CurrentSession.Messages = sessionMessages
+}
+
+// LoadSessionMessages replaces m.Messages with the current session's messages
+func (m *Model) LoadSessionMessages() {
+	m.Messages = []Message{}
+	if m.CurrentSession == nil {
+		return
+	}
+	for _, sMsg := range m.CurrentSession.Messages {
+		// Use cached rendering if available, otherwise use content
+		msg := MessageFromStorage(sMsg)
+		if msg.Rendered == "" {
+			msg.Rendered = sMsg.Content
+		}
+		m.Messages = append(m.Messages, msg)
+	}
+	m.cachedUsagePercentage = -1 // Same length, different messages
+}
+
+// sessionIndex maps an index into m.Messages to the index of the same
+// message in the session (len(m.Messages) maps to the end)
+func (m *Model) sessionIndex(idx int) int {
+	n := 0
+	for _, msg := range m.Messages[:min(idx, len(m.Messages))] {
+		if msg.isSaved() {
+			n++
+		}
+	}
+	return n
+}
+
+// BranchFrom starts a new version of the conversation from m.Messages[idx]
+// on: the messages from there are kept as another version of the session
+// (see storage.Session.Branch) and removed from m.Messages. Editing a prompt
+// branches at the prompt, regenerating a reply right after its prompt.
+func (m *Model) BranchFrom(idx int) error {
+	if m.CurrentSession == nil {
+		return fmt.Errorf("no session loaded")
+	}
+	m.syncSessionMessages()
+	if err := m.CurrentSession.Branch(m.sessionIndex(idx)); err != nil {
+		return err
+	}
+	m.LoadSessionMessages()
+	m.SessionDirty = true
+	return nil
+}
+
+// SwitchBranch shows the previous (delta -1) or next (+1) version of the
+// conversation from m.Messages[idx] on. It reports false if there is none.
+func (m *Model) SwitchBranch(idx, delta int) bool {
+	if m.CurrentSession == nil {
+		return false
+	}
+	m.syncSessionMessages()
+	if !m.CurrentSession.SwitchBranch(m.sessionIndex(idx), delta) {
+		return false
+	}
+	m.LoadSessionMessages()
+	m.SessionDirty = true
+	return true
+}
+
+// BranchVersionAt reports which version of the conversation from
+// m.Messages[idx] on is showing
+func (m *Model) BranchVersionAt(idx int) BranchVersion {
+	if m.CurrentSession == nil {
+		return BranchVersion{Current: 1, Total: 1}
+	}
+	current, total := m.CurrentSession.BranchVersions(m.sessionIndex(idx))
+	return BranchVersion{Current: current, Total: total}
+}
+
+// BranchPoints maps the index in m.Messages of each message where versions
+// of the conversation diverge to the version showing, for marking in the chat
+func (m *Model) BranchPoints() map[int]BranchVersion {
+	if m.CurrentSession == nil || len(m.CurrentSession.Branches) == 0 {
+		return nil
+	}
+	points := make(map[int]bool)
+	for _, b := range m.CurrentSession.Branches {
+		points[b.At] = true
+	}
+
+	n := 0
+	byIndex := make(map[int]BranchVersion, len(points))
+	for i, msg := range m.Messages {
+		if !msg.isSaved() {
+			continue
+		}
+		if points[n] {
+			current, total := m.CurrentSession.BranchVersions(n)
+			byIndex[i] = BranchVersion{Current: current, Total: total}
+		}
+		n++
+	}
+	return byIndex
+}
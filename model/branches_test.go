@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBranchFrom(t *testing.T) {
+	m := newHeadlessTestModel(&stubProvider{response: "first"})
+	if _, err := m.RunHeadless("question", HeadlessOptions{}); err != nil {
+		t.Fatalf("RunHeadless: %v", err)
+	}
+	// Transient messages aren't part of the session, so indices differ from its own
+	m.Messages = append([]Message{{Role: "system", Content: "Welcome"}}, m.Messages...)
+
+	if err := m.BranchFrom(2); err != nil {
+		t.Fatalf("BranchFrom: %v", err)
+	}
+	if len(m.Messages) != 1 || m.Messages[0].Content != "question" {
+		t.Fatalf("messages after branching = %+v, want just the question", m.Messages)
+	}
+	m.Messages = append(m.Messages, Message{Role: "assistant", Content: "second", Timestamp: time.Now()})
+
+	if got := m.BranchVersionAt(1); got != (BranchVersion{Current: 2, Total: 2}) {
+		t.Errorf("BranchVersionAt = %+v, want 2 of 2", got)
+	}
+	if got := m.BranchPoints(); len(got) != 1 || got[1] != (BranchVersion{Current: 2, Total: 2}) {
+		t.Errorf("BranchPoints = %v, want version 2 of 2 at message 1", got)
+	}
+
+	if !m.SwitchBranch(1, -1) {
+		t.Fatal("SwitchBranch found no earlier version")
+	}
+	if len(m.Messages) != 2 || m.Messages[1].Content != "first" {
+		t.Errorf("messages after switching = %+v, want the first answer", m.Messages)
+	}
+	if !m.SessionDirty {
+		t.Error("switching versions should mark the session dirty")
+	}
+}
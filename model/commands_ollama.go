@@ -3,18 +3,24 @@ package model
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
 	mcptypes "github.com/mark3labs/mcp-go/mcp"
 
 	"otui/config"
+	"otui/mcp"
+	"otui/storage"
 )
 
 // BuildSystemPrompt returns the system prompt for the current session or default
@@ -28,6 +34,33 @@ func (m *Model) BuildSystemPrompt() string {
 	return ""
 }
 
+// GenerationParams returns the sampling settings for the current session:
+// its own values, then the [generation] defaults. Settings left unset are not
+// sent, so the provider's defaults apply; Ollama keeps its own num_ctx.
+func (m *Model) GenerationParams() config.GenerationParams {
+	var params config.GenerationParams
+	if m.CurrentSession != nil {
+		params = m.CurrentSession.Generation
+	}
+	return params.WithDefaults(m.Config.Generation)
+}
+
+// sessionProviderID returns the ID of the current session's provider
+func (m *Model) sessionProviderID() string {
+	if m.CurrentSession != nil && m.CurrentSession.Provider != "" {
+		return m.CurrentSession.Provider
+	}
+	return "ollama"
+}
+
+// applyGenerationParams passes the session's sampling settings to a provider
+// that sends them
+func (m *Model) applyGenerationParams(p Provider) {
+	if tunable, ok := p.(TunableProvider); ok {
+		tunable.SetGenerationParams(m.GenerationParams())
+	}
+}
+
 // escapeQuotesForOllama escapes quotes in system prompts to prevent Ollama server bugs
 // when tools are present. Ollama has a known issue where unescaped quotes in system prompts
 // can break tool calling, causing models to output malformed tool calls or use wrong formats.
@@ -97,12 +130,53 @@ func buildAPIMessages(uiMessages []Message, systemPrompt string, tools []mcptype
 	}
 
 	// Layer 3: Conversation messages (task)
-	for _, msg := range uiMessages {
-		if msg.Role == "user" || msg.Role == "assistant" {
+	// Tool results are replayed so the model remembers what it already gathered.
+	// Providers require every result to answer a call made by the preceding
+	// assistant message, so each run of tool messages gets its calls attached.
+	// Reasoning is never replayed; only the answers are.
+	for i := 0; i < len(uiMessages); i++ {
+		msg := uiMessages[i]
+		switch msg.Role {
+		case "user":
+			messages = append(messages, Message{
+				Role:        msg.Role,
+				Content:     msg.Content,
+				Attachments: msg.Attachments,
+			})
+		case "assistant":
+			content := stripThinkBlocks(msg.Content)
+			if content == "" {
+				continue // Only thought before calling tools
+			}
 			messages = append(messages, Message{
 				Role:    msg.Role,
-				Content: msg.Content,
+				Content: content,
 			})
+		case "tool":
+			var calls []ToolCall
+			var results []Message
+			for ; i < len(uiMessages) && uiMessages[i].Role == "tool"; i++ {
+				result := uiMessages[i]
+				call := ToolCall{}
+				if result.ToolCall != nil {
+					call = *result.ToolCall
+				}
+				// Sessions saved before call IDs existed have none - pair by position
+				if call.ID == "" {
+					call.ID = fmt.Sprintf("call_%d", i)
+				}
+				calls = append(calls, call)
+				results = append(results, Message{
+					Role:      result.Role,
+					Content:   result.Content,
+					ToolCall:  &call,
+					ToolError: result.ToolError,
+				})
+			}
+			i-- // Outer loop advances past the last tool message
+
+			messages = appendToolCallMessage(messages, "", calls)
+			messages = append(messages, results...)
 		}
 	}
 
@@ -137,6 +211,10 @@ func (m *Model) SendToOllama() tea.Cmd {
 
 	mcpManager := m.MCPManager
 	systemPrompt := m.BuildSystemPrompt()
+	var pinnedResources []storage.ResourceRef
+	if currentSession != nil {
+		pinnedResources = slices.Clone(currentSession.Resources)
+	}
 
 	// Filter messages based on compaction marker
 	// Use m.Messages directly (UI messages) and filter by compaction marker
@@ -154,6 +232,8 @@ func (m *Model) SendToOllama() tea.Cmd {
 		// Only send messages after the compaction marker
 		// The LLM summary is injected as synthetic user/assistant messages below
 		// Note: m.Messages includes system messages, but we only count user/assistant for marker
+		// Tool messages don't count towards the marker; they follow the
+		// message they belong to
 		userAssistantCount := 0
 		for _, msg := range m.Messages {
 			if msg.Role == "user" || msg.Role == "assistant" {
@@ -161,6 +241,10 @@ func (m *Model) SendToOllama() tea.Cmd {
 					uiMessages = append(uiMessages, msg)
 				}
 				userAssistantCount++
+				continue
+			}
+			if msg.Role == "tool" && userAssistantCount > compactionMarker {
+				uiMessages = append(uiMessages, msg)
 			}
 		}
 	}
@@ -188,7 +272,27 @@ func (m *Model) SendToOllama() tea.Cmd {
 			len(uiMessages), compactionMarker, len(m.Messages))
 	}
 
-	return func() tea.Msg {
+	// Fail early with a clear error rather than letting the provider reject
+	// images. Only the new prompt is checked; images earlier in the session
+	// are left out if the model can't see them, so switching to a model
+	// without vision doesn't end the conversation.
+	if last := len(uiMessages) - 1; last >= 0 && uiMessages[last].Role == "user" {
+		if err := m.CheckAttachments(uiMessages[last].Attachments); err != nil {
+			return func() tea.Msg { return StreamErrorMsg{Err: err} }
+		}
+	}
+	if !m.GetModelMetadata().SupportsVision {
+		uiMessages = omitImages(uiMessages)
+	}
+
+	targets := m.chatTargets(client)
+	retry := m.Config.Retry
+	listTimeout := m.Config.ToolListingTimeout()
+
+	stream := newResponseStream()
+	m.ActiveStream = stream
+
+	return stream.start(m.beginTurn(), func(ctx context.Context) tea.Msg {
 		// Reset iteration state for new user message (Phase 2)
 		m.CurrentIteration = 0
 		m.IterationHistory = []IterationStep{}
@@ -197,15 +301,14 @@ func (m *Model) SendToOllama() tea.Cmd {
 			config.DebugLog.Printf("sendToOllama goroutine started")
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-		defer cancel()
-
 		// Get enabled plugins and their tools (Phase 6)
 		var mcpTools []mcptypes.Tool
 		if mcpManager != nil && currentSession != nil {
 			// Get tools from all enabled plugins in current session
+			listCtx, cancelList := context.WithTimeout(ctx, listTimeout)
 			var err error
-			mcpTools, err = mcpManager.GetTools(ctx)
+			mcpTools, err = mcpManager.GetTools(listCtx)
+			cancelList()
 			if err == nil && len(mcpTools) > 0 {
 				if config.DebugLog != nil {
 					config.DebugLog.Printf("Loaded %d tools for current session", len(mcpTools))
@@ -234,21 +337,58 @@ func (m *Model) SendToOllama() tea.Cmd {
 			}
 		}
 		messages := buildAPIMessages(uiMessages, systemPrompt, mcpTools)
+		if mcpManager != nil && len(pinnedResources) > 0 {
+			readCtx, cancelRead := context.WithTimeout(ctx, listTimeout)
+			messages = withResourceContext(messages, m.pinnedResourceContext(readCtx, mcpManager, pinnedResources))
+			cancelRead()
+		}
 
-		var chunks []string
+		var chunkCount int
 		var responseBuilder strings.Builder
+		var reasoningBuilder strings.Builder
+		var splitter thinkSplitter
+		var filter leakFilter
 		var detectedToolCalls []ToolCall
+		var usage Usage
 		startTime := time.Now()
 
-		// Chat with tools
-		err := client.ChatWithTools(ctx, messages, mcpTools, func(chunk string, toolCalls []ToolCall) error {
-			responseBuilder.WriteString(chunk)
-			chunks = append(chunks, chunk)
-			if len(toolCalls) > 0 && len(detectedToolCalls) == 0 {
-				detectedToolCalls = toolCalls
+		// Chat with tools - text goes to the UI as it arrives, tool calls are
+		// collected (they may show up at any point during the stream).
+		// A failed request is retried, or sent to a fallback model, from scratch.
+		reset := func() {
+			chunkCount = 0
+			responseBuilder.Reset()
+			reasoningBuilder.Reset()
+			splitter = thinkSplitter{}
+			filter = leakFilter{}
+			detectedToolCalls = nil
+			usage = Usage{}
+		}
+		target, err := chatWithFallback(ctx, stream, targets, retry, messages, mcpTools, reset, func(chunk, reasoning string, toolCalls []ToolCall, reported *Usage) error {
+			if reported != nil {
+				usage = *reported
 			}
+			// Reasoning comes separately or inline in <think> tags
+			answer, inline := splitter.Write(chunk)
+			reasoning += inline
+			if reasoning != "" {
+				reasoningBuilder.WriteString(reasoning)
+				stream.send(StreamChunkMsg{Reasoning: reasoning, Stream: stream})
+			}
+			if answer != "" {
+				responseBuilder.WriteString(answer)
+				chunkCount++
+				if visible := filter.Write(answer); visible != "" {
+					stream.send(StreamChunkMsg{Chunk: visible, Stream: stream})
+				}
+			}
+			// Providers may report parallel calls one callback at a time
+			detectedToolCalls = append(detectedToolCalls, toolCalls...)
 			return nil
 		})
+		answer, reasoning := splitter.Flush()
+		responseBuilder.WriteString(answer)
+		reasoningBuilder.WriteString(reasoning)
 
 		elapsed := time.Since(startTime)
 
@@ -256,40 +396,47 @@ func (m *Model) SendToOllama() tea.Cmd {
 			if config.DebugLog != nil {
 				config.DebugLog.Printf("Ollama error after %v: %v", elapsed, err)
 			}
+			var timeoutErr *TimeoutError
+			if errors.As(err, &timeoutErr) {
+				return TimeoutErrorMsg{Err: timeoutErr}
+			}
 			return StreamErrorMsg{Err: err}
 		}
 
 		response := responseBuilder.String()
 		if config.DebugLog != nil {
-			config.DebugLog.Printf("Ollama response received after %v - %d chunks, %d chars", elapsed, len(chunks), len(response))
+			config.DebugLog.Printf("Ollama response received after %v - %d chunks, %d chars", elapsed, chunkCount, len(response))
 		}
+		m.recordUsage(ctx, currentSession, target.client, target.Responder, usage)
 
 		// If tool calls detected, return special message to trigger execution
 		if len(detectedToolCalls) > 0 {
+			ensureToolCallIDs(detectedToolCalls)
 			if config.DebugLog != nil {
 				config.DebugLog.Printf("Tool calls detected: %d", len(detectedToolCalls))
 			}
 			return ToolCallsDetectedMsg{
 				ToolCalls:       detectedToolCalls,
-				InitialResponse: response,
+				InitialResponse: cleanLeakedToolCalls(response),
+				Reasoning:       reasoningBuilder.String(),
 				ContextMessages: messages,
+				Usage:           usage,
+				AnsweredBy:      target.Responder,
 			}
 		}
 
 		// No tool calls - normal response
-		return StreamChunksCollectedMsg{
-			Chunks:       chunks,
-			FullResponse: response,
-		}
-	}
+		return StreamDoneMsg{FullResponse: response, Reasoning: reasoningBuilder.String(), Usage: usage, AnsweredBy: target.Responder, Stream: stream}
+	})
 }
 
 // FetchModelList retrieves the list of available Ollama models
 // showSelector: whether to auto-show model selector after fetch (user-initiated vs background)
 func (m *Model) FetchModelList(showSelector bool) tea.Cmd {
 	client := m.Provider
+	timeout := m.Config.ToolListingTimeout()
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 
 		models, err := client.ListModels(ctx)
@@ -439,14 +586,18 @@ func (m *Model) ExtractPurpose(contextMessages []Message, toolCall *ToolCall) st
 func (m *Model) ExecuteToolsAndContinue(msg ToolCallsDetectedMsg) tea.Cmd {
 	mcpManager := m.MCPManager
 	client := m.Provider
+	currentSession := m.CurrentSession
+	targets := m.chatTargets(client)
+	retry := m.Config.Retry
+	listTimeout := m.Config.ToolListingTimeout()
 
-	return func() tea.Msg {
+	stream := newResponseStream()
+	m.ActiveStream = stream
+
+	return stream.start(m.turnContext(), func(ctx context.Context) tea.Msg {
 		// Track step start time (Phase 2)
 		stepStartTime := time.Now()
 
-		ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-		defer cancel()
-
 		if config.DebugLog != nil {
 			config.DebugLog.Printf("Executing %d tool calls", len(msg.ToolCalls))
 		}
@@ -483,63 +634,33 @@ func (m *Model) ExecuteToolsAndContinue(msg ToolCallsDetectedMsg) tea.Cmd {
 			config.DebugLog.Printf("Starting iteration %d", m.CurrentIteration)
 		}
 
-		// Execute each tool call and collect results
-		var toolResultMsgs []Message
-
-		for i, toolCall := range msg.ToolCalls {
-			if config.DebugLog != nil {
-				config.DebugLog.Printf("Executing tool call %d: %s", i+1, toolCall.Name)
-			}
-
-			// ToolCall is already in provider-agnostic format (model.ToolCall)
-			// Extract name and arguments directly
-			toolName := toolCall.Name
-			args := toolCall.Arguments
-
-			// Execute tool via MCP manager
-			result, err := mcpManager.ExecuteTool(ctx, toolName, args)
-			if err != nil {
-				if config.DebugLog != nil {
-					config.DebugLog.Printf("Error executing tool %s: %v", toolName, err)
-				}
-				toolResultMsgs = append(toolResultMsgs, Message{
-					Role:    "tool",
-					Content: fmt.Sprintf("Error executing %s: %v", toolName, err),
-				})
-				continue
-			}
-
-			// Convert result to string
-			var resultContent string
-			if len(result.Content) > 0 {
-				// MCP result contains array of content items (interfaces)
-				// Need to type-assert to extract text
-				resultBytes, err := json.Marshal(result.Content)
-				if err == nil {
-					resultContent = string(resultBytes)
-				} else {
-					resultContent = fmt.Sprintf("Tool result (marshal error): %v", err)
-				}
-			} else {
-				resultContent = "Tool executed successfully (no output)"
-			}
+		// Stopped by the user (or timed out) - don't start any calls
+		if ctx.Err() != nil {
+			return ToolExecutionErrorMsg{Err: ctx.Err()}
+		}
 
-			if config.DebugLog != nil {
-				config.DebugLog.Printf("Tool %s result: %d chars", toolName, len(resultContent))
-			}
+		// Independent calls run in parallel; MCPManager limits how many
+		// hit the same plugin at once
+		toolResultMsgs := make([]Message, len(msg.ToolCalls))
+		var wg sync.WaitGroup
+		for i := range msg.ToolCalls {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				toolResultMsgs[i] = executeToolCall(ctx, mcpManager, msg.ToolCalls[i])
+			}(i)
+		}
+		wg.Wait()
 
-			toolResultMsgs = append(toolResultMsgs, Message{
-				Role:    "tool",
-				Content: resultContent,
-			})
+		if ctx.Err() != nil {
+			return ToolExecutionErrorMsg{Err: ctx.Err()}
 		}
 
+		// Show the results before the follow-up response starts streaming
+		stream.send(ToolResultsMsg{ToolMessages: toolResultMsgs, Stream: stream})
+
 		// Build complete message history for LLM
-		fullMessages := msg.ContextMessages
-		fullMessages = append(fullMessages, Message{
-			Role:    "assistant",
-			Content: msg.InitialResponse,
-		})
+		fullMessages := appendToolCallMessage(msg.ContextMessages, msg.InitialResponse, msg.ToolCalls)
 		fullMessages = append(fullMessages, toolResultMsgs...)
 
 		// Record step (Phase 2: ALL steps, not just tool executions)
@@ -558,9 +679,26 @@ func (m *Model) ExecuteToolsAndContinue(msg ToolCallsDetectedMsg) tea.Cmd {
 				StartTime:  stepStartTime,
 				EndTime:    time.Now(),
 				Success:    true,
+				ToolCalls:  make([]ToolCallRecord, len(toolResultMsgs)),
 			}
 			step.Duration = step.EndTime.Sub(step.StartTime)
 
+			failed := 0
+			for i, result := range toolResultMsgs {
+				step.ToolCalls[i] = ToolCallRecord{
+					ToolName: result.ToolCall.Name,
+					Duration: result.Duration,
+					Error:    result.ToolError,
+				}
+				if result.ToolError != "" {
+					failed++
+				}
+			}
+			if failed > 0 {
+				step.Success = false
+				step.ErrorMsg = fmt.Sprintf("%d of %d tool calls failed", failed, len(toolResultMsgs))
+			}
+
 			// Store tool info (internal use only, not displayed)
 			if firstToolCall != nil {
 				step.ToolName = firstToolCall.Name
@@ -583,9 +721,9 @@ func (m *Model) ExecuteToolsAndContinue(msg ToolCallsDetectedMsg) tea.Cmd {
 		// Get tools for next iteration
 		var nextTools []mcptypes.Tool
 		if mcpManager != nil && m.CurrentSession != nil {
-			ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Second)
-			defer cancel2()
-			mcpTools, err := mcpManager.GetTools(ctx2)
+			listCtx, cancelList := context.WithTimeout(ctx, listTimeout)
+			defer cancelList()
+			mcpTools, err := mcpManager.GetTools(listCtx)
 			if err == nil {
 				nextTools = mcpTools
 			}
@@ -602,18 +740,48 @@ func (m *Model) ExecuteToolsAndContinue(msg ToolCallsDetectedMsg) tea.Cmd {
 		}
 
 		// Send back to LLM
-		var chunks []string
+		var chunkCount int
 		var responseBuilder strings.Builder
+		var reasoningBuilder strings.Builder
+		var splitter thinkSplitter
+		var filter leakFilter
 		var detectedToolCalls []ToolCall
-
-		err := client.ChatWithTools(ctx, fullMessages, nextTools, func(chunk string, toolCalls []ToolCall) error {
-			responseBuilder.WriteString(chunk)
-			chunks = append(chunks, chunk)
-			if len(toolCalls) > 0 && len(detectedToolCalls) == 0 {
-				detectedToolCalls = toolCalls
+		var usage Usage
+
+		reset := func() {
+			chunkCount = 0
+			responseBuilder.Reset()
+			reasoningBuilder.Reset()
+			splitter = thinkSplitter{}
+			filter = leakFilter{}
+			detectedToolCalls = nil
+			usage = Usage{}
+		}
+		target, err := chatWithFallback(ctx, stream, targets, retry, fullMessages, nextTools, reset, func(chunk, reasoning string, toolCalls []ToolCall, reported *Usage) error {
+			if reported != nil {
+				usage = *reported
+			}
+			// Reasoning comes separately or inline in <think> tags
+			answer, inline := splitter.Write(chunk)
+			reasoning += inline
+			if reasoning != "" {
+				reasoningBuilder.WriteString(reasoning)
+				stream.send(StreamChunkMsg{Reasoning: reasoning, Stream: stream})
+			}
+			if answer != "" {
+				responseBuilder.WriteString(answer)
+				chunkCount++
+				if visible := filter.Write(answer); visible != "" {
+					stream.send(StreamChunkMsg{Chunk: visible, Stream: stream})
+				}
 			}
+			// Providers may report parallel calls one callback at a time
+			detectedToolCalls = append(detectedToolCalls, toolCalls...)
 			return nil
 		})
+		answer, reasoning := splitter.Flush()
+		responseBuilder.WriteString(answer)
+		reasoningBuilder.WriteString(reasoning)
 
 		if err != nil {
 			if config.DebugLog != nil {
@@ -621,21 +789,20 @@ func (m *Model) ExecuteToolsAndContinue(msg ToolCallsDetectedMsg) tea.Cmd {
 			}
 			m.CurrentIteration = 0
 			m.IterationHistory = []IterationStep{}
+			var timeoutErr *TimeoutError
+			if errors.As(err, &timeoutErr) {
+				return TimeoutErrorMsg{Err: timeoutErr}
+			}
 			return ToolExecutionErrorMsg{Err: err}
 		}
 
 		finalResponse := responseBuilder.String()
+		m.recordUsage(ctx, currentSession, target.client, target.Responder, usage)
 
 		// Debug: Log response details after tool execution
 		if config.DebugLog != nil {
-			nonEmptyChunks := 0
-			for _, c := range chunks {
-				if c != "" {
-					nonEmptyChunks++
-				}
-			}
-			config.DebugLog.Printf("Post-tool response: %d chunks (%d non-empty), %d chars, hasToolCalls=%v",
-				len(chunks), nonEmptyChunks, len(finalResponse), len(detectedToolCalls) > 0)
+			config.DebugLog.Printf("Post-tool response: %d chunks, %d chars, hasToolCalls=%v",
+				chunkCount, len(finalResponse), len(detectedToolCalls) > 0)
 			if len(finalResponse) > 0 && len(finalResponse) <= 200 {
 				config.DebugLog.Printf("Post-tool response content: %s", finalResponse)
 			} else if len(finalResponse) > 200 {
@@ -652,21 +819,10 @@ func (m *Model) ExecuteToolsAndContinue(msg ToolCallsDetectedMsg) tea.Cmd {
 					len(finalResponse), len(cleanedResponse))
 			}
 			finalResponse = cleanedResponse
-
-			// Rebuild chunks from cleaned response for typewriter display
-			chunks = []string{}
-			if len(finalResponse) > 0 {
-				// Create chunks of ~10 chars each to maintain typewriter effect
-				for i := 0; i < len(finalResponse); i += 10 {
-					end := i + 10
-					if end > len(finalResponse) {
-						end = len(finalResponse)
-					}
-					chunks = append(chunks, finalResponse[i:end])
-				}
-			}
 		}
 
+		ensureToolCallIDs(detectedToolCalls)
+
 		// Check completion
 		hasToolCalls := len(detectedToolCalls) > 0
 		isComplete := !hasToolCalls
@@ -688,10 +844,12 @@ func (m *Model) ExecuteToolsAndContinue(msg ToolCallsDetectedMsg) tea.Cmd {
 			m.CurrentIteration = 0
 
 			return ToolExecutionCompleteMsg{
-				Chunks:           chunks,
 				FullResponse:     finalResponse,
+				Reasoning:        reasoningBuilder.String(),
 				IterationSummary: summaryMsg,
 				HasMoreSteps:     false,
+				Usage:            usage,
+				AnsweredBy:       target.Responder,
 			}
 		}
 
@@ -711,10 +869,12 @@ func (m *Model) ExecuteToolsAndContinue(msg ToolCallsDetectedMsg) tea.Cmd {
 			m.CurrentIteration = 0
 
 			return ToolExecutionCompleteMsg{
-				Chunks:           chunks,
 				FullResponse:     finalResponse,
+				Reasoning:        reasoningBuilder.String(),
 				IterationSummary: summaryMsg,
 				HasMoreSteps:     false,
+				Usage:            usage,
+				AnsweredBy:       target.Responder,
 			}
 		}
 
@@ -734,13 +894,108 @@ func (m *Model) ExecuteToolsAndContinue(msg ToolCallsDetectedMsg) tea.Cmd {
 			})
 		}
 
-		// Return for typewriter display, then next step
+		// Finalize this response, then next step
 		return ToolExecutionCompleteMsg{
-			Chunks:        chunks,
 			FullResponse:  finalResponse,
+			Reasoning:     reasoningBuilder.String(),
 			HasMoreSteps:  true,
 			NextToolCalls: detectedToolCalls,
 			NextContext:   nextContext,
+			Usage:         usage,
+			AnsweredBy:    target.Responder,
+		}
+	})
+}
+
+// executeToolCall runs one tool call and returns its result as a tool
+// message. Failures become the message content so the model can react.
+func executeToolCall(ctx context.Context, mcpManager *mcp.MCPManager, call ToolCall) Message {
+	if config.DebugLog != nil {
+		config.DebugLog.Printf("Executing tool call: %s", call.Name)
+	}
+
+	toolStart := time.Now()
+	result, err := mcpManager.ExecuteTool(ctx, call.Name, call.Arguments)
+	if err != nil {
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("Error executing tool %s: %v", call.Name, err)
+		}
+		return Message{
+			Role:      "tool",
+			Content:   fmt.Sprintf("Error executing %s: %v", call.Name, err),
+			Timestamp: time.Now(),
+			ToolCall:  &call,
+			ToolError: err.Error(),
+			Duration:  time.Since(toolStart),
+		}
+	}
+
+	// Convert result to string
+	var resultContent string
+	if len(result.Content) > 0 {
+		// MCP result contains array of content items (interfaces)
+		// Need to type-assert to extract text
+		resultBytes, err := json.Marshal(result.Content)
+		if err == nil {
+			resultContent = string(resultBytes)
+		} else {
+			resultContent = fmt.Sprintf("Tool result (marshal error): %v", err)
+		}
+	} else {
+		resultContent = "Tool executed successfully (no output)"
+	}
+
+	if config.DebugLog != nil {
+		config.DebugLog.Printf("Tool %s result: %d chars", call.Name, len(resultContent))
+	}
+
+	// MCP reports tool-level failures in-band via IsError
+	toolError := ""
+	if result.IsError {
+		toolError = "tool reported an error"
+	}
+
+	return Message{
+		Role:      "tool",
+		Content:   resultContent,
+		Timestamp: time.Now(),
+		ToolCall:  &call,
+		ToolError: toolError,
+		Duration:  time.Since(toolStart),
+	}
+}
+
+// appendToolCallMessage appends the assistant message that requested calls.
+// When the model produced no text alongside the calls and the conversation
+// already ends in a plain assistant message, the calls are attached to that
+// message instead so providers don't see two assistant turns in a row.
+func appendToolCallMessage(messages []Message, content string, calls []ToolCall) []Message {
+	if content == "" && len(messages) > 0 {
+		last := messages[len(messages)-1]
+		if last.Role == "assistant" && len(last.ToolCalls) == 0 {
+			last.ToolCalls = calls
+			result := make([]Message, len(messages))
+			copy(result, messages)
+			result[len(result)-1] = last
+			return result
+		}
+	}
+
+	result := make([]Message, len(messages), len(messages)+1)
+	copy(result, messages)
+	return append(result, Message{
+		Role:      "assistant",
+		Content:   content,
+		ToolCalls: calls,
+	})
+}
+
+// ensureToolCallIDs assigns IDs to calls the provider didn't identify
+// (Ollama and leaked text tool calls have none) so results can be paired
+func ensureToolCallIDs(calls []ToolCall) {
+	for i := range calls {
+		if calls[i].ID == "" {
+			calls[i].ID = "call_" + uuid.New().String()[:8]
 		}
 	}
 }
@@ -835,33 +1090,35 @@ func (m *Model) OpenExternalEditor(currentContent string) tea.Cmd {
 	})
 }
 
-// cleanLeakedToolCalls removes leaked JSON/XML tool calls from content.
-// This prevents leaked tool call text from polluting LLM context and user display.
-func cleanLeakedToolCalls(content string) string {
-	// Remove JSON array tool calls (with argument variations)
-	jsonArrayRegex := regexp.MustCompile(`\[\s*\{\s*"name"\s*:\s*"[^"]+"\s*,\s*"(?:arguments|param|parameters|input)"\s*:\s*\{[^}]*\}\s*\}\s*\]`)
-	content = jsonArrayRegex.ReplaceAllString(content, "")
-
-	// Remove single JSON object tool calls (with argument variations)
-	jsonObjRegex := regexp.MustCompile(`\{\s*"name"\s*:\s*"[^"]+"\s*,\s*"(?:arguments|param|parameters|input)"\s*:\s*\{[^}]*\}\s*\}`)
-	content = jsonObjRegex.ReplaceAllString(content, "")
-
-	// Remove XML tool calls
-	xmlRegex := regexp.MustCompile(`<(?:tool_call|function_call)>\s*<name>[^<]+</name>\s*<arguments>[^<]*</arguments>\s*</(?:tool_call|function_call)>`)
-	content = xmlRegex.ReplaceAllString(content, "")
-
-	// Remove qwen3-coder style XML tool calls (with multiline support)
+// Leaked tool call patterns, removed from content by stripLeakedToolCalls
+var (
+	// JSON array tool calls (with argument variations)
+	leakedJSONArrayRegex = regexp.MustCompile(`\[\s*\{\s*"name"\s*:\s*"[^"]+"\s*,\s*"(?:arguments|param|parameters|input)"\s*:\s*\{[^}]*\}\s*\}\s*\]`)
+	// Single JSON object tool calls (with argument variations)
+	leakedJSONObjRegex = regexp.MustCompile(`\{\s*"name"\s*:\s*"[^"]+"\s*,\s*"(?:arguments|param|parameters|input)"\s*:\s*\{[^}]*\}\s*\}`)
+	// XML tool calls
+	leakedXMLRegex = regexp.MustCompile(`<(?:tool_call|function_call)>\s*<name>[^<]+</name>\s*<arguments>[^<]*</arguments>\s*</(?:tool_call|function_call)>`)
+	// qwen3-coder style XML tool calls (with multiline support)
 	// Pattern: <function=TOOL_NAME><parameter=PARAM_NAME>VALUE</parameter></function>
 	// (?s) enables dot to match newlines
-	qwenXmlRegex := regexp.MustCompile(`(?s)<function=[^>]+><parameter=[^>]+>.*?</parameter></function>(?:</tool_call>)?`)
-	content = qwenXmlRegex.ReplaceAllString(content, "")
-
-	// Remove system-reminder tags that may leak into content
-	sysReminderRegex := regexp.MustCompile(`(?s)<system-reminder>.*?</system-reminder>`)
-	content = sysReminderRegex.ReplaceAllString(content, "")
+	leakedQwenXMLRegex = regexp.MustCompile(`(?s)<function=[^>]+><parameter=[^>]+>.*?</parameter></function>(?:</tool_call>)?`)
+	// System-reminder tags that may leak into content
+	leakedSysReminderRegex = regexp.MustCompile(`(?s)<system-reminder>.*?</system-reminder>`)
+)
 
-	// Clean up extra whitespace
-	content = strings.TrimSpace(content)
+// cleanLeakedToolCalls removes leaked JSON/XML tool calls from content.
+// This prevents leaked tool call text from polluting LLM context and user display.
+func cleanLeakedToolCalls(content string) string {
+	return strings.TrimSpace(stripLeakedToolCalls(content))
+}
 
+// stripLeakedToolCalls removes complete leaked tool calls without touching
+// the surrounding whitespace, so text before a leak is left unchanged
+func stripLeakedToolCalls(content string) string {
+	content = leakedJSONArrayRegex.ReplaceAllString(content, "")
+	content = leakedJSONObjRegex.ReplaceAllString(content, "")
+	content = leakedXMLRegex.ReplaceAllString(content, "")
+	content = leakedQwenXMLRegex.ReplaceAllString(content, "")
+	content = leakedSysReminderRegex.ReplaceAllString(content, "")
 	return content
 }
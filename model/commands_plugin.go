@@ -8,6 +8,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"otui/config"
+	"otui/mcp"
 )
 
 // StartAllPlugins starts all enabled plugins for the current session
@@ -140,6 +141,62 @@ func (m *Model) DisablePlugin(pluginID string) tea.Cmd {
 	}
 }
 
+// CheckPluginUpdates looks up newer versions of the installed plugins in the
+// background
+func (m *Model) CheckPluginUpdates() tea.Cmd {
+	if m.Plugins == nil || m.Plugins.Registry == nil || m.Plugins.Installer == nil {
+		return nil
+	}
+	registry := m.Plugins.Registry
+	installer := m.Plugins.Installer
+	return func() tea.Msg {
+		installed, err := installer.ListInstalled()
+		if err != nil {
+			return nil
+		}
+		var plugins []*mcp.Plugin
+		for _, p := range installed {
+			if plugin := registry.GetByID(p.ID); plugin != nil {
+				plugins = append(plugins, plugin)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return PluginUpdatesMsg{Updates: installer.CheckUpdates(ctx, plugins)}
+	}
+}
+
+// UpgradePlugin installs the newest version of a plugin, rolling back if it
+// fails to start
+func (m *Model) UpgradePlugin(pluginID string) tea.Cmd {
+	if m.MCPManager == nil || m.Plugins == nil || m.Plugins.Installer == nil {
+		return nil
+	}
+	manager := m.MCPManager
+	installer := m.Plugins.Installer
+	return func() tea.Msg {
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("[UI] upgradePlugin: Upgrading plugin '%s'", pluginID)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		version, err := manager.UpgradePlugin(ctx, installer, pluginID)
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("[UI] upgradePlugin: Result for '%s': version=%s, err=%v", pluginID, version, err)
+		}
+		return PluginOperationCompleteMsg{
+			Operation: "upgrade",
+			PluginID:  pluginID,
+			Version:   version,
+			Success:   err == nil,
+			Err:       err,
+		}
+	}
+}
+
 // RefreshRegistry fetches the latest plugin list from the registry
 func (m *Model) RefreshRegistry() tea.Cmd {
 	if m.Plugins.Registry == nil {
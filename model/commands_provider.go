@@ -186,7 +186,7 @@ func (m *Model) SwitchModel(modelInfo ollama.ModelInfo) tea.Cmd {
 				modelInfo.Provider, modelInfo.Name)
 		}
 		m.Provider.SetModel(modelInfo.InternalName)
-		return nil
+		return m.LoadModelCapabilities()
 	}
 
 	m.Provider = provider
@@ -197,7 +197,32 @@ func (m *Model) SwitchModel(modelInfo ollama.ModelInfo) tea.Cmd {
 			modelInfo.Name, modelInfo.Provider, modelInfo.InternalName)
 	}
 
-	return nil
+	return m.LoadModelCapabilities()
+}
+
+// LoadModelCapabilities asks the active provider what its model can do (such
+// as whether it accepts images) in the background, for providers that have to
+// ask a server. Until the answer arrives, metadata lookups use name-based
+// guesses. Returns nil for providers that know without asking.
+func (m *Model) LoadModelCapabilities() tea.Cmd {
+	loader, ok := m.Provider.(CapabilityLoader)
+	if !ok {
+		return nil
+	}
+	modelName := m.Provider.GetModel()
+	return func() tea.Msg {
+		err := loader.LoadCapabilities(context.Background(), modelName)
+		return ModelCapabilitiesLoadedMsg{Model: modelName, Err: err}
+	}
+}
+
+// ForgetModelMetadata drops the cached metadata of modelName, so the next
+// lookup asks the provider again
+func (m *Model) ForgetModelMetadata(modelName string) {
+	if m.cachedModelName == modelName {
+		m.cachedModelName = ""
+		m.cachedModelMetadata = ModelMetadata{}
+	}
 }
 
 // SwitchToDefaultProvider switches the active provider to the configured default.
@@ -83,20 +83,8 @@ func (m *Model) SaveCurrentSession() tea.Cmd {
 	}
 
 	// Convert UI messages to storage messages
-	// Save user, assistant, and persistent system messages (like compaction markers)
-	var sessionMessages []storage.Message
-	for _, msg := range m.Messages {
-		if msg.Role == "user" || msg.Role == "assistant" || (msg.Role == "system" && msg.Persistent) {
-			sessionMessages = append(sessionMessages, storage.Message{
-				Role:      msg.Role,
-				Content:   msg.Content,
-				Rendered:  msg.Rendered,
-				Timestamp: msg.Timestamp,
-			})
-		}
-	}
-
-	m.CurrentSession.Messages = sessionMessages
+	// Save user, assistant, tool, and persistent system messages (like compaction markers)
+	m.syncSessionMessages()
 	m.CurrentSession.UpdatedAt = time.Now()
 	m.CurrentSession.Model = m.Provider.GetModel()
 	// Note: CurrentSession.Provider is set by SwitchModel() and preserved here
@@ -189,8 +177,46 @@ func (m *Model) RenameSessionCmd(sessionID, newName string) tea.Cmd {
 	}
 }
 
-// ExportSessionCmd exports a session to a JSON file
-func (m *Model) ExportSessionCmd(ctx context.Context, sessionID, exportPath string) tea.Cmd {
+// LatestSession returns a session as it is now: the current session with
+// the messages on screen, any other as saved
+func (m *Model) LatestSession(sessionID string) (*storage.Session, error) {
+	if m.CurrentSession != nil && m.CurrentSession.ID == sessionID {
+		m.syncSessionMessages()
+		return m.CurrentSession, nil
+	}
+	if m.SessionStorage == nil {
+		return nil, fmt.Errorf("session storage not initialized")
+	}
+	return m.SessionStorage.Load(sessionID)
+}
+
+// ForkSessionCmd copies a session up to and including a message into a new
+// session (see storage.SessionStorage.Fork). The current session is saved
+// first so the fork sees its latest messages.
+func (m *Model) ForkSessionCmd(sessionID string, atMessageIndex int) tea.Cmd {
+	var current *storage.Session
+	if m.CurrentSession != nil && m.CurrentSession.ID == sessionID {
+		m.syncSessionMessages()
+		current = m.CurrentSession
+	}
+	sessionStorage := m.SessionStorage
+
+	return func() tea.Msg {
+		if sessionStorage == nil {
+			return SessionForkedMsg{Err: fmt.Errorf("session storage not initialized")}
+		}
+		if current != nil {
+			if err := sessionStorage.Save(current); err != nil {
+				return SessionForkedMsg{Err: err}
+			}
+		}
+		fork, err := sessionStorage.Fork(sessionID, atMessageIndex)
+		return SessionForkedMsg{Session: fork, Err: err}
+	}
+}
+
+// ExportSessionCmd exports a session to a file in the format of opts
+func (m *Model) ExportSessionCmd(ctx context.Context, sessionID, exportPath string, opts storage.ExportOptions) tea.Cmd {
 	return func() tea.Msg {
 		// Cancellation point 1: Before loading
 		select {
@@ -209,15 +235,15 @@ func (m *Model) ExportSessionCmd(ctx context.Context, sessionID, exportPath stri
 			return SessionExportedMsg{Err: err}
 		}
 
-		// Cancellation point 2: Before marshaling
+		// Cancellation point 2: Before rendering
 		select {
 		case <-ctx.Done():
 			return SessionExportedMsg{Cancelled: true}
 		default:
 		}
 
-		// Marshal JSON (potentially slow for large sessions)
-		data, err := json.MarshalIndent(session, "", "  ")
+		// Render the export (potentially slow for large sessions)
+		data, err := storage.RenderExport(session, opts)
 		if err != nil {
 			return SessionExportedMsg{Err: err}
 		}
@@ -349,7 +375,7 @@ func fileExists(path string) bool {
 }
 
 // UpdateSessionPropertiesCmd updates session properties and refreshes the session list
-func (m *Model) UpdateSessionPropertiesCmd(sessionID, newName, newSystemPrompt string, enabledPlugins []string) tea.Cmd {
+func (m *Model) UpdateSessionPropertiesCmd(sessionID, newName, newSystemPrompt string, enabledPlugins []string, generation config.GenerationParams, fallback []config.FallbackTarget) tea.Cmd {
 	return func() tea.Msg {
 		if m.SessionStorage == nil {
 			return SessionsListMsg{Err: fmt.Errorf("session storage not initialized")}
@@ -365,6 +391,8 @@ func (m *Model) UpdateSessionPropertiesCmd(sessionID, newName, newSystemPrompt s
 		session.Name = newName
 		session.SystemPrompt = newSystemPrompt
 		session.EnabledPlugins = enabledPlugins
+		session.Generation = generation
+		session.Fallback = fallback
 
 		// Save back
 		if err := m.SessionStorage.Save(session); err != nil {
@@ -376,6 +404,8 @@ func (m *Model) UpdateSessionPropertiesCmd(sessionID, newName, newSystemPrompt s
 			m.CurrentSession.Name = newName
 			m.CurrentSession.SystemPrompt = newSystemPrompt
 			m.CurrentSession.EnabledPlugins = enabledPlugins
+			m.CurrentSession.Generation = generation
+			m.CurrentSession.Fallback = fallback
 		}
 
 		// Refresh list
@@ -439,8 +469,12 @@ func (m *Model) ApplyDataDirSwitch(newDataDir string, passphrase string) error {
 		config.DebugLog.Printf("[Model] STEP 2-3: Applying data dir switch to %s", newDataDir)
 	}
 
-	// Validate new data directory first
-	newStorage, err := storage.NewSessionStorage(newDataDir)
+	// Validate new data directory first, opening the session store its config asks for
+	sessionStore := ""
+	if userCfg, err := config.LoadUserConfigFromPath(filepath.Join(newDataDir, "config.toml")); err == nil && userCfg != nil {
+		sessionStore = userCfg.SessionStore
+	}
+	newStorage, err := storage.OpenSessionStorage(newDataDir, sessionStore)
 	if err != nil {
 		return fmt.Errorf("failed to initialize session storage: %w", err)
 	}
@@ -481,6 +515,9 @@ func (m *Model) ApplyDataDirSwitch(newDataDir string, passphrase string) error {
 		config.DebugLog.Printf("[Model] STEP 3b: Switching data directory")
 	}
 
+	if m.SessionStorage != nil {
+		_ = m.SessionStorage.Close()
+	}
 	m.SessionStorage = newStorage
 	m.SearchIndex = storage.NewSearchIndex(newStorage)
 
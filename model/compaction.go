@@ -10,7 +10,17 @@ import (
 	"otui/storage"
 )
 
-// TokenCounter estimates token count from text
+// Token usage estimation methods (storage.TokenUsage.EstimationMethod)
+const (
+	EstimationCharacterBased   = "character_based"
+	EstimationProviderReported = "provider_reported"
+)
+
+// imageTokenEstimate is roughly what one attached image costs; providers
+// scale images down to around a megapixel, which is 1000-1600 tokens
+const imageTokenEstimate = 1000
+
+// TokenCounter counts message tokens, preferring provider-reported counts
 type TokenCounter struct{}
 
 // CountTokens estimates token count using character-based estimation
@@ -19,18 +29,64 @@ func (tc *TokenCounter) CountTokens(text string) int {
 	return len(text) / 4
 }
 
+// CountMessage returns the provider-reported token count for a message,
+// or an estimate when the provider reported nothing
+func (tc *TokenCounter) CountMessage(msg storage.Message) int {
+	if msg.CompletionTokens > 0 {
+		return msg.CompletionTokens
+	}
+	tokens := tc.CountTokens(msg.Content)
+	for _, att := range msg.Attachments {
+		if strings.HasPrefix(att.MIMEType, "image/") {
+			tokens += imageTokenEstimate
+		} else {
+			tokens += tc.CountTokens(string(att.Data))
+		}
+	}
+	return tokens
+}
+
 // CountMessages calculates total token count for a slice of messages
 func (tc *TokenCounter) CountMessages(messages []storage.Message) int {
 	total := 0
 	for _, msg := range messages {
-		total += tc.CountTokens(msg.Content)
+		total += tc.CountMessage(msg)
 	}
 	return total
 }
 
+// CountContext returns the tokens the messages take up in the model's context.
+// The latest response with a reported prompt size gives an exact count up to
+// that point (including system prompt and tool definitions); only the
+// messages after it are counted individually. reportedAfter excludes counts
+// from before a compaction, when the prompt still held the compacted messages.
+func (tc *TokenCounter) CountContext(messages []storage.Message, reportedAfter time.Time) (int, string) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg.PromptTokens == 0 || !msg.Timestamp.After(reportedAfter) {
+			continue
+		}
+		return msg.PromptTokens + tc.CountMessage(msg) + tc.CountMessages(messages[i+1:]), EstimationProviderReported
+	}
+	return tc.CountMessages(messages), EstimationCharacterBased
+}
+
 // GetModelMetadata returns metadata for the current session's model
 // Checks user overrides first, then provider metadata, then fallbacks
 func (m *Model) GetModelMetadata() ModelMetadata {
+	metadata := m.lookupModelMetadata()
+
+	// Ollama only gives the model num_ctx tokens of context
+	if m.CurrentSession != nil && m.sessionProviderID() == "ollama" {
+		if numCtx := m.GenerationParams().NumCtx; numCtx > 0 && numCtx < metadata.ContextWindow {
+			metadata.ContextWindow = numCtx
+		}
+	}
+	return metadata
+}
+
+// lookupModelMetadata returns the model's own metadata, cached per model
+func (m *Model) lookupModelMetadata() ModelMetadata {
 	if m.CurrentSession == nil {
 		// No session - return conservative default
 		return ModelMetadata{
@@ -61,6 +117,13 @@ func (m *Model) GetModelMetadata() ModelMetadata {
 				SupportsTools: true,  // Assume yes if user is overriding
 			}
 
+			// The override only covers the context window; vision support still comes from the provider
+			if m.Provider != nil {
+				if meta, err := m.Provider.GetModelMetadata(context.Background(), modelName); err == nil {
+					metadata.SupportsVision = meta.SupportsVision
+				}
+			}
+
 			// Cache the result
 			m.cachedModelName = modelName
 			m.cachedModelMetadata = metadata
@@ -115,10 +178,7 @@ func (m *Model) CalculateTokenUsage() storage.TokenUsage {
 	var storageMessages []storage.Message
 	for _, msg := range m.Messages {
 		if msg.Role == "user" || msg.Role == "assistant" {
-			storageMessages = append(storageMessages, storage.Message{
-				Role:    msg.Role,
-				Content: msg.Content,
-			})
+			storageMessages = append(storageMessages, msg.ToStorage())
 		}
 	}
 
@@ -128,27 +188,29 @@ func (m *Model) CalculateTokenUsage() storage.TokenUsage {
 			len(m.Messages), len(storageMessages), m.CurrentSession.CompactionMarker)
 	}
 
-	// Total tokens in all messages
-	totalTokens := counter.CountMessages(storageMessages)
-
 	// Active tokens (after compaction marker)
 	activeMessages := storageMessages
+	var compactedMessages []storage.Message
+	var reportedAfter time.Time
 	compactionMarker := m.CurrentSession.CompactionMarker
 	if compactionMarker > 0 && compactionMarker <= len(storageMessages) {
 		activeMessages = storageMessages[compactionMarker:]
+		compactedMessages = storageMessages[:compactionMarker]
+		reportedAfter = m.CurrentSession.CompactionTimestamp
 		if config.Debug && config.DebugLog != nil {
 			config.DebugLog.Printf("[compaction] Applying compaction marker %d: keeping %d of %d messages",
 				compactionMarker, len(activeMessages), len(storageMessages))
 		}
 	}
-	activeTokens := counter.CountMessages(activeMessages)
+	activeTokens, method := counter.CountContext(activeMessages, reportedAfter)
 
 	// Compacted tokens
-	compactedTokens := totalTokens - activeTokens
+	compactedTokens := counter.CountMessages(compactedMessages)
+	totalTokens := activeTokens + compactedTokens
 
 	if config.Debug && config.DebugLog != nil {
-		config.DebugLog.Printf("[compaction] Token usage: total=%d active=%d compacted=%d",
-			totalTokens, activeTokens, compactedTokens)
+		config.DebugLog.Printf("[compaction] Token usage: total=%d active=%d compacted=%d (%s)",
+			totalTokens, activeTokens, compactedTokens, method)
 	}
 
 	return storage.TokenUsage{
@@ -156,7 +218,7 @@ func (m *Model) CalculateTokenUsage() storage.TokenUsage {
 		ActiveTokens:     activeTokens,
 		CompactedTokens:  compactedTokens,
 		LastUpdated:      time.Now(),
-		EstimationMethod: "character_based",
+		EstimationMethod: method,
 	}
 }
 
@@ -225,7 +287,7 @@ func (m *Model) SuggestCompactionPoint(keepPercentage float64) int {
 
 	// Count backwards from end until we reach target
 	for i := len(m.CurrentSession.Messages) - 1; i >= 0; i-- {
-		currentTokens += counter.CountTokens(m.CurrentSession.Messages[i].Content)
+		currentTokens += counter.CountMessage(m.CurrentSession.Messages[i])
 		if currentTokens >= targetTokens {
 			if config.Debug && config.DebugLog != nil {
 				config.DebugLog.Printf("[compaction] Suggested marker: %d (keeps %d tokens, target was %d)",
@@ -266,22 +328,38 @@ Summary:`, conversationText.String())
 		},
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	timeout := m.Config.CompactionTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	var summary strings.Builder
-	callback := func(chunk string, toolCalls []ToolCall) error {
+	var usage Usage
+	callback := func(chunk, reasoning string, toolCalls []ToolCall, reported *Usage) error {
+		if reported != nil {
+			usage = *reported
+		}
 		summary.WriteString(chunk)
 		return nil
 	}
 
 	// Use Chat method to get summary
+	m.applyGenerationParams(m.Provider)
 	err := m.Provider.Chat(ctx, summaryMessages, callback)
 	if err != nil {
+		if timeoutErr := timeoutError(ctx, "compaction summary", m.Provider.GetModel(), timeout); timeoutErr != nil {
+			err = timeoutErr
+		}
 		return "", fmt.Errorf("failed to generate summary: %w", err)
 	}
 
-	summaryText := strings.TrimSpace(summary.String())
+	providerID := "ollama"
+	if m.CurrentSession != nil && m.CurrentSession.Provider != "" {
+		providerID = m.CurrentSession.Provider
+	}
+	m.recordUsage(ctx, m.CurrentSession, m.Provider, Responder{Provider: providerID, Model: m.Provider.GetModel()}, usage)
+
+	// Reasoning models may think inline before the summary
+	summaryText := strings.TrimSpace(stripThinkBlocks(summary.String()))
 	if summaryText == "" {
 		return "", fmt.Errorf("LLM returned empty summary")
 	}
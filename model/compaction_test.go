@@ -114,6 +114,50 @@ func TestCalculateTokenUsage(t *testing.T) {
 	}
 }
 
+// TestCalculateTokenUsageReported tests that provider-reported counts
+// replace estimates
+func TestCalculateTokenUsageReported(t *testing.T) {
+	compactedAt := time.Now()
+	before := compactedAt.Add(-time.Minute)
+	after := compactedAt.Add(time.Minute)
+
+	t.Run("latest prompt size", func(t *testing.T) {
+		m := &Model{Config: &config.Config{}, CurrentSession: &storage.Session{}}
+		m.Messages = []Message{
+			{Role: "user", Content: "Hello there"},
+			{Role: "assistant", Content: "Hi!", Timestamp: after, Usage: Usage{PromptTokens: 500, CompletionTokens: 3}},
+			{Role: "user", Content: "I'm doing great!"}, // 16 chars = 4 tokens, not yet sent
+		}
+
+		usage := m.CalculateTokenUsage()
+		if usage.ActiveTokens != 500+3+4 {
+			t.Errorf("ActiveTokens = %d, expected %d", usage.ActiveTokens, 507)
+		}
+		if usage.EstimationMethod != EstimationProviderReported {
+			t.Errorf("EstimationMethod = %q, expected %q", usage.EstimationMethod, EstimationProviderReported)
+		}
+	})
+
+	t.Run("ignores counts from before compaction", func(t *testing.T) {
+		session := &storage.Session{CompactionMarker: 1, CompactionTimestamp: compactedAt}
+		m := &Model{Config: &config.Config{}, CurrentSession: session}
+		// The reported prompt size predates compaction, so only the
+		// completion count of the active message is used
+		m.Messages = []Message{
+			{Role: "user", Content: "Hello there"},
+			{Role: "assistant", Content: "Hi!", Timestamp: before, Usage: Usage{PromptTokens: 9000, CompletionTokens: 3}},
+		}
+
+		usage := m.CalculateTokenUsage()
+		if usage.ActiveTokens != 3 || usage.CompactedTokens != 2 {
+			t.Errorf("ActiveTokens = %d, CompactedTokens = %d, expected 3 and 2", usage.ActiveTokens, usage.CompactedTokens)
+		}
+		if usage.EstimationMethod != EstimationCharacterBased {
+			t.Errorf("EstimationMethod = %q, expected %q", usage.EstimationMethod, EstimationCharacterBased)
+		}
+	})
+}
+
 // TestGetContextUsagePercentage tests percentage calculation
 func TestGetContextUsagePercentage(t *testing.T) {
 	session := &storage.Session{
@@ -471,5 +515,44 @@ func TestCompactSession(t *testing.T) {
 	}
 }
 
+// TestGenerationParams tests session settings over config defaults, and that
+// num_ctx is only sent when it's set
+func TestGenerationParams(t *testing.T) {
+	low, high := 0.1, 0.9
+	cfg := &config.Config{
+		Generation:            config.GenerationParams{Temperature: &high, MaxTokens: 2048},
+		ModelContextOverrides: map[string]int{"test-model": 65536},
+	}
+	session := &storage.Session{Model: "test-model", Generation: config.GenerationParams{Temperature: &low}}
+	m := &Model{Config: cfg, CurrentSession: session}
+
+	got := m.GenerationParams()
+	if *got.Temperature != 0.1 || got.MaxTokens != 2048 {
+		t.Errorf("GenerationParams() = %s, want the session temperature and default max_tokens", got)
+	}
+	if got.NumCtx != 0 {
+		t.Errorf("num_ctx = %d, want unset so Ollama keeps its default", got.NumCtx)
+	}
+	if window := m.GetModelMetadata().ContextWindow; window != 65536 {
+		t.Errorf("context window = %d, want the model's own", window)
+	}
+
+	// A smaller num_ctx is sent, and is all the model sees
+	session.Generation.NumCtx = 16384
+	if got := m.GenerationParams(); got.NumCtx != 16384 {
+		t.Errorf("num_ctx = %d, want the session's", got.NumCtx)
+	}
+	if window := m.GetModelMetadata().ContextWindow; window != 16384 {
+		t.Errorf("context window = %d, want num_ctx", window)
+	}
+
+	// Other providers have no num_ctx
+	session.Provider = "openai"
+	session.Generation.NumCtx = 0
+	if got := m.GenerationParams(); got.NumCtx != 0 {
+		t.Errorf("num_ctx = %d for openai, want unset", got.NumCtx)
+	}
+}
+
 // Note: TestGetModelMetadata cannot be tested here due to import cycle with provider package
 // The metadata retrieval logic is tested through integration tests instead
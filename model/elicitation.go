@@ -0,0 +1,210 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ElicitationField is one property of the flat object schema a plugin asks
+// the user to fill in. MCP limits these to strings, numbers, integers,
+// booleans and string enums.
+type ElicitationField struct {
+	Name        string
+	Title       string
+	Description string
+	Type        string   // "string", "number", "integer" or "boolean"
+	Format      string   // "email", "uri", "date" or "date-time" (strings only)
+	Enum        []string // Allowed values, if limited
+	EnumNames   []string // Labels shown for Enum, if given
+	Required    bool
+	Default     string
+
+	MinLength, MaxLength *int
+	Minimum, Maximum     *float64
+}
+
+// Label returns the name the field is shown under
+func (f ElicitationField) Label() string {
+	if f.Title != "" {
+		return f.Title
+	}
+	return f.Name
+}
+
+// EnumLabel returns the label shown for the i-th allowed value
+func (f ElicitationField) EnumLabel(i int) string {
+	if i < len(f.EnumNames) && f.EnumNames[i] != "" {
+		return f.EnumNames[i]
+	}
+	return f.Enum[i]
+}
+
+type elicitationSchema struct {
+	Type       string                         `json:"type"`
+	Properties map[string]elicitationProperty `json:"properties"`
+	Required   []string                       `json:"required"`
+}
+
+type elicitationProperty struct {
+	Type        string   `json:"type"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Format      string   `json:"format"`
+	Enum        []any    `json:"enum"`
+	EnumNames   []string `json:"enumNames"`
+	Default     any      `json:"default"`
+	MinLength   *int     `json:"minLength"`
+	MaxLength   *int     `json:"maxLength"`
+	Minimum     *float64 `json:"minimum"`
+	Maximum     *float64 `json:"maximum"`
+}
+
+// ElicitationFields reads the form fields from a requested schema. The
+// schema's properties arrive unordered, so required fields come first and
+// each group is sorted by name.
+func ElicitationFields(schema any) ([]ElicitationField, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	var s elicitationSchema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if s.Type != "" && s.Type != "object" {
+		return nil, fmt.Errorf("unsupported schema type %q", s.Type)
+	}
+
+	var fields []ElicitationField
+	for name, prop := range s.Properties {
+		field := ElicitationField{
+			Name:        name,
+			Title:       prop.Title,
+			Description: prop.Description,
+			Type:        prop.Type,
+			Format:      prop.Format,
+			EnumNames:   prop.EnumNames,
+			Required:    slices.Contains(s.Required, name),
+			MinLength:   prop.MinLength,
+			MaxLength:   prop.MaxLength,
+			Minimum:     prop.Minimum,
+			Maximum:     prop.Maximum,
+		}
+		switch field.Type {
+		case "string", "number", "integer", "boolean":
+		case "":
+			field.Type = "string"
+		default:
+			return nil, fmt.Errorf("field %s has unsupported type %q", name, prop.Type)
+		}
+		for _, v := range prop.Enum {
+			field.Enum = append(field.Enum, fmt.Sprint(v))
+		}
+		if prop.Default != nil {
+			field.Default = fmt.Sprint(prop.Default)
+		} else if field.Type == "boolean" {
+			field.Default = "false"
+		}
+		fields = append(fields, field)
+	}
+
+	slices.SortFunc(fields, func(a, b ElicitationField) int {
+		switch {
+		case a.Required && !b.Required:
+			return -1
+		case !a.Required && b.Required:
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return fields, nil
+}
+
+// ElicitationContent checks the values entered for the fields and converts
+// them to the types the schema asks for. Empty optional fields are left out.
+func ElicitationContent(fields []ElicitationField, values map[string]string) (map[string]any, error) {
+	content := make(map[string]any)
+	for _, field := range fields {
+		value := strings.TrimSpace(values[field.Name])
+		if value == "" {
+			if field.Required {
+				return nil, fmt.Errorf("%s is required", field.Label())
+			}
+			continue
+		}
+
+		converted, err := convertElicitationValue(field, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", field.Label(), err)
+		}
+		content[field.Name] = converted
+	}
+	return content, nil
+}
+
+func convertElicitationValue(field ElicitationField, value string) (any, error) {
+	if len(field.Enum) > 0 && !slices.Contains(field.Enum, value) {
+		return nil, fmt.Errorf("must be one of %s", strings.Join(field.Enum, ", "))
+	}
+
+	switch field.Type {
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return b, nil
+
+	case "integer", "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		if field.Type == "integer" && n != float64(int64(n)) {
+			return nil, fmt.Errorf("must be a whole number")
+		}
+		if field.Minimum != nil && n < *field.Minimum {
+			return nil, fmt.Errorf("must be at least %v", *field.Minimum)
+		}
+		if field.Maximum != nil && n > *field.Maximum {
+			return nil, fmt.Errorf("must be at most %v", *field.Maximum)
+		}
+		if field.Type == "integer" {
+			return int64(n), nil
+		}
+		return n, nil
+	}
+
+	length := len([]rune(value))
+	if field.MinLength != nil && length < *field.MinLength {
+		return nil, fmt.Errorf("must be at least %d characters", *field.MinLength)
+	}
+	if field.MaxLength != nil && length > *field.MaxLength {
+		return nil, fmt.Errorf("must be at most %d characters", *field.MaxLength)
+	}
+
+	var err error
+	switch field.Format {
+	case "email":
+		_, err = mail.ParseAddress(value)
+	case "uri":
+		var u *url.URL
+		if u, err = url.Parse(value); err == nil && u.Scheme == "" {
+			err = fmt.Errorf("no scheme")
+		}
+	case "date":
+		_, err = time.Parse(time.DateOnly, value)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("must be a valid %s", field.Format)
+	}
+	return value, nil
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testElicitationSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"notify": map[string]any{"type": "boolean"},
+			"name":   map[string]any{"type": "string", "title": "Your name", "maxLength": 10},
+			"email":  map[string]any{"type": "string", "format": "email"},
+			"age":    map[string]any{"type": "integer", "minimum": 0},
+			"size":   map[string]any{"type": "string", "enum": []any{"s", "m", "l"}, "enumNames": []any{"Small", "Medium", "Large"}},
+		},
+		"required": []any{"name", "age"},
+	}
+}
+
+func TestElicitationFields(t *testing.T) {
+	fields, err := ElicitationFields(testElicitationSchema())
+	if err != nil {
+		t.Fatalf("ElicitationFields: %v", err)
+	}
+
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	if want := []string{"age", "name", "email", "notify", "size"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("fields = %v, want required first, then by name: %v", names, want)
+	}
+	if fields[1].Label() != "Your name" || !fields[1].Required {
+		t.Errorf("name field = %+v", fields[1])
+	}
+	if fields[3].Default != "false" {
+		t.Errorf("boolean default = %q, want false", fields[3].Default)
+	}
+	if fields[4].EnumLabel(1) != "Medium" {
+		t.Errorf("EnumLabel(1) = %q, want Medium", fields[4].EnumLabel(1))
+	}
+
+	if _, err := ElicitationFields(map[string]any{"type": "object", "properties": map[string]any{
+		"tags": map[string]any{"type": "array"},
+	}}); err == nil {
+		t.Error("expected an error for an array field")
+	}
+}
+
+func TestElicitationContent(t *testing.T) {
+	fields, err := ElicitationFields(testElicitationSchema())
+	if err != nil {
+		t.Fatalf("ElicitationFields: %v", err)
+	}
+
+	content, err := ElicitationContent(fields, map[string]string{"name": " Ada ", "age": "36", "notify": "true", "size": "m"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{"name": "Ada", "age": int64(36), "notify": true, "size": "m"}
+	if !reflect.DeepEqual(content, want) {
+		t.Errorf("content = %#v, want %#v", content, want)
+	}
+
+	invalid := []map[string]string{
+		{"age": "36"},                              // Missing required name
+		{"name": "Ada", "age": "3.5"},              // Not an integer
+		{"name": "Ada", "age": "-1"},               // Below minimum
+		{"name": "Ada Lovelace!", "age": "36"},     // Too long
+		{"name": "Ada", "age": "36", "size": "xl"}, // Not in enum
+		{"name": "Ada", "age": "36", "email": "ada"},
+	}
+	for _, values := range invalid {
+		if _, err := ElicitationContent(fields, values); err == nil {
+			t.Errorf("ElicitationContent(%v) succeeded, want an error", values)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"otui/config"
+	"otui/ollama"
+	"otui/storage"
+)
+
+// semanticSearchLimit is how many matches a semantic search returns
+const semanticSearchLimit = 50
+
+// embeddingMu lets one embedding index update run at a time, so messages
+// aren't embedded twice when saves come in quick succession
+var embeddingMu sync.Mutex
+
+// embedder returns a function embedding texts with the configured Ollama
+// embedding model
+func (m *Model) embedder(ctx context.Context) (storage.EmbedFunc, error) {
+	apiKey := ""
+	if m.Config.CredentialStore != nil {
+		apiKey = m.Config.CredentialStore.Get("ollama")
+	}
+	client, err := ollama.NewClient(m.Config.OllamaURL(), m.Config.SemanticSearch.EmbeddingModel(), apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return func(texts []string) ([][]float32, error) {
+		return client.Embed(ctx, texts)
+	}, nil
+}
+
+// SemanticSearchEnabled reports whether sessions are indexed for semantic search
+func (m *Model) SemanticSearchEnabled() bool {
+	return m.Config != nil && m.Config.SemanticSearch.Enabled && m.SessionStorage != nil
+}
+
+// UpdateEmbeddingsCmd embeds the messages of the current session that aren't
+// in the semantic search index yet. It runs after each save and returns no
+// message; failures (Ollama down, model not pulled) are only logged.
+func (m *Model) UpdateEmbeddingsCmd() tea.Cmd {
+	if !m.SemanticSearchEnabled() || m.CurrentSession == nil {
+		return nil
+	}
+
+	snapshot := *m.CurrentSession // Messages and branches are replaced, not modified, by later edits
+	index := m.SessionStorage.Embeddings()
+	return func() tea.Msg {
+		if err := m.updateEmbeddings(index, &snapshot); err != nil && config.DebugLog != nil {
+			config.DebugLog.Printf("[Embeddings] Failed to index session %s: %v", snapshot.ID, err)
+		}
+		return nil
+	}
+}
+
+func (m *Model) updateEmbeddings(index *storage.EmbeddingIndex, session *storage.Session) error {
+	embeddingMu.Lock()
+	defer embeddingMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.Config.GenerationTimeout("ollama"))
+	defer cancel()
+	embed, err := m.embedder(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = index.Update(session, m.Config.SemanticSearch.EmbeddingModel(), embed)
+	return err
+}
+
+// IndexSessionsCmd brings the semantic search index up to date for every
+// session, embedding only messages it doesn't have yet. It covers sessions
+// saved before semantic search was turned on, or by otui run.
+func (m *Model) IndexSessionsCmd() tea.Cmd {
+	if !m.SemanticSearchEnabled() {
+		return nil
+	}
+
+	sessionStorage := m.SessionStorage
+	index := sessionStorage.Embeddings()
+	return func() tea.Msg {
+		sessions, err := sessionStorage.List()
+		if err != nil {
+			return SessionsIndexedMsg{Err: err}
+		}
+
+		indexed := 0
+		for _, meta := range sessions {
+			session, err := sessionStorage.Load(meta.ID)
+			if err != nil {
+				continue
+			}
+			if err := m.updateEmbeddings(index, session); err != nil {
+				return SessionsIndexedMsg{Indexed: indexed, Err: err}
+			}
+			indexed++
+		}
+		return SessionsIndexedMsg{Indexed: indexed}
+	}
+}
+
+// SemanticSearchCmd embeds query and ranks the indexed messages of every
+// session by how close they are in meaning
+func (m *Model) SemanticSearchCmd(query string) tea.Cmd {
+	if !m.SemanticSearchEnabled() || m.SearchIndex == nil {
+		return nil
+	}
+
+	searchIndex := m.SearchIndex
+	model := m.Config.SemanticSearch.EmbeddingModel()
+	timeout := m.Config.GenerationTimeout("ollama")
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		embed, err := m.embedder(ctx)
+		if err != nil {
+			return SemanticSearchResultMsg{Query: query, Err: err}
+		}
+		vectors, err := embed([]string{query})
+		if err != nil {
+			return SemanticSearchResultMsg{Query: query, Err: err}
+		}
+		if len(vectors) != 1 {
+			return SemanticSearchResultMsg{Query: query, Err: fmt.Errorf("embedding model returned %d vectors", len(vectors))}
+		}
+
+		results, err := searchIndex.SearchSemantic(vectors[0], model, semanticSearchLimit)
+		return SemanticSearchResultMsg{Query: query, Results: results, Err: err}
+	}
+}
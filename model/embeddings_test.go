@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"otui/config"
+	"otui/storage"
+)
+
+// newEmbeddingServer fakes Ollama's embed API, embedding text by whether it
+// mentions TLS or Docker
+func newEmbeddingServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		*requests++
+
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "nomic-embed-text" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		embeddings := make([][]float32, len(req.Input))
+		for i, text := range req.Input {
+			text = strings.ToLower(text)
+			embeddings[i] = []float32{0.1, 0.1}
+			if strings.Contains(text, "tls") || strings.Contains(text, "certificate") {
+				embeddings[i][0] = 1
+			}
+			if strings.Contains(text, "docker") {
+				embeddings[i][1] = 1
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"model": req.Model, "embeddings": embeddings})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSemanticSearch(t *testing.T) {
+	requests := 0
+	server := newEmbeddingServer(t, &requests)
+
+	sessionStorage, err := storage.NewSessionStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+	cfg := &config.Config{
+		OllamaHost:     server.URL,
+		SemanticSearch: config.SemanticSearchConfig{Enabled: true},
+	}
+	session := &storage.Session{Name: "debugging", Provider: "stub", Model: "stub-model"}
+	m := NewModel(cfg, &stubProvider{response: "the certificate chain is incomplete"}, sessionStorage, session, nil, nil,
+		storage.NewSearchIndex(sessionStorage), "test", "test")
+	m.Providers = map[string]Provider{"stub": m.Provider}
+
+	if _, err := m.RunHeadless("why does the TLS handshake fail", HeadlessOptions{}); err != nil {
+		t.Fatalf("RunHeadless: %v", err)
+	}
+	if msg := m.SaveCurrentSession()(); msg.(SessionSavedMsg).Err != nil {
+		t.Fatalf("save: %v", msg.(SessionSavedMsg).Err)
+	}
+	other := &storage.Session{Name: "containers", Messages: []storage.Message{{Role: "user", Content: "docker compose up fails"}}}
+	if err := sessionStorage.Save(other); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	t.Run("indexed on save", func(t *testing.T) {
+		if msg := m.UpdateEmbeddingsCmd()(); msg != nil {
+			t.Errorf("UpdateEmbeddingsCmd returned %T, want nothing", msg)
+		}
+		embeddings, err := sessionStorage.Embeddings().Load(session.ID)
+		if err != nil || embeddings == nil || len(embeddings.Messages) != 2 {
+			t.Fatalf("embeddings = %+v, %v; want the prompt and reply", embeddings, err)
+		}
+	})
+
+	t.Run("backfill", func(t *testing.T) {
+		msg := m.IndexSessionsCmd()().(SessionsIndexedMsg)
+		if msg.Err != nil || msg.Indexed != 2 {
+			t.Errorf("IndexSessionsCmd = %+v, want both sessions", msg)
+		}
+		before := requests
+		m.IndexSessionsCmd()()
+		if requests != before {
+			t.Errorf("indexing again made %d requests, want none", requests-before)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		msg := m.SemanticSearchCmd("certificate problems")().(SemanticSearchResultMsg)
+		if msg.Err != nil {
+			t.Fatalf("SemanticSearchCmd: %v", msg.Err)
+		}
+		if msg.Query != "certificate problems" || len(msg.Results) != 3 {
+			t.Fatalf("results = %+v", msg)
+		}
+		if msg.Results[0].SessionID != session.ID || msg.Results[2].SessionID != other.ID {
+			t.Errorf("ranking = %+v, want the TLS session first", msg.Results)
+		}
+	})
+
+	t.Run("off", func(t *testing.T) {
+		cfg.SemanticSearch.Enabled = false
+		defer func() { cfg.SemanticSearch.Enabled = true }()
+		if m.UpdateEmbeddingsCmd() != nil || m.IndexSessionsCmd() != nil || m.SemanticSearchCmd("x") != nil {
+			t.Error("commands should be no-ops with semantic search off")
+		}
+	})
+
+	t.Run("ollama down", func(t *testing.T) {
+		cfg.OllamaHost = "http://127.0.0.1:1"
+		defer func() { cfg.OllamaHost = server.URL }()
+		if msg := m.SemanticSearchCmd("tls")().(SemanticSearchResultMsg); msg.Err == nil {
+			t.Error("expected an error when Ollama can't be reached")
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"otui/config"
+)
+
+// Headless run errors. Callers map these to process exit codes with errors.Is.
+var (
+	ErrModelFailed   = errors.New("model request failed")
+	ErrToolDenied    = errors.New("tool permission denied")
+	ErrMaxIterations = errors.New("maximum tool iterations reached")
+)
+
+// ApprovalPolicy decides how tool permission requests are answered when
+// there is no user around to press y/a/n (headless mode).
+type ApprovalPolicy string
+
+const (
+	// ApprovalDeny denies every tool that is not already allowed
+	// (global AllowedTools, session AllowedTools or HeadlessOptions.AllowTools)
+	ApprovalDeny ApprovalPolicy = "deny"
+	// ApprovalAll approves every tool request for the duration of the run
+	ApprovalAll ApprovalPolicy = "all"
+)
+
+// ParseApprovalPolicy validates a policy name given on the command line
+func ParseApprovalPolicy(s string) (ApprovalPolicy, error) {
+	switch ApprovalPolicy(s) {
+	case ApprovalDeny, ApprovalAll:
+		return ApprovalPolicy(s), nil
+	case "":
+		return ApprovalDeny, nil
+	}
+	return "", fmt.Errorf("unknown approval policy %q (expected %q or %q)", s, ApprovalDeny, ApprovalAll)
+}
+
+// HeadlessEvent is a single progress event emitted during a headless run.
+// It is serialized as one JSON object per line when --json is used.
+type HeadlessEvent struct {
+	Type       string         `json:"type"` // tool_call, tool_result, permission, step, text, answer, retry, error
+	Content    string         `json:"content,omitempty"`
+	ToolName   string         `json:"tool,omitempty"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+	Approved   *bool          `json:"approved,omitempty"`
+	DurationMs int64          `json:"duration_ms,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// HeadlessOptions controls a headless run
+type HeadlessOptions struct {
+	Approval   ApprovalPolicy
+	AllowTools []string            // Extra tools approved for this run only
+	OnEvent    func(HeadlessEvent) // Optional progress callback
+}
+
+// RunHeadless sends prompt to the current session's provider and drives the
+// full tool loop synchronously, without a Bubble Tea program.
+//
+// It reuses SendToOllama and ExecuteToolsAndContinue by invoking their
+// commands directly, so headless runs behave exactly like the TUI (same
+// compaction handling, permission checks and iteration limits).
+//
+// The user prompt and every assistant response are appended to m.Messages;
+// persisting them is left to the caller (see SaveCurrentSession).
+//
+// Returns the final assistant answer. The error wraps ErrModelFailed,
+// ErrToolDenied or ErrMaxIterations so callers can tell the cases apart;
+// a tool step that times out wraps context.DeadlineExceeded instead.
+// ErrToolDenied also covers tool requests made while no plugins are running.
+// On ErrMaxIterations (and missing plugins) the partial answer is still returned.
+func (m *Model) RunHeadless(prompt string, opts HeadlessOptions) (string, error) {
+	emit := func(ev HeadlessEvent) {
+		if opts.OnEvent != nil {
+			opts.OnEvent(ev)
+		}
+	}
+
+	// Tools approved for this run only - removed again before returning so
+	// they never end up persisted in the session's allowlist
+	var temporarilyAllowed []string
+	if m.CurrentSession != nil {
+		originalAllowed := m.CurrentSession.AllowedTools
+		defer func() {
+			m.CurrentSession.AllowedTools = originalAllowed
+		}()
+		m.CurrentSession.AllowedTools = append(append([]string{}, originalAllowed...), opts.AllowTools...)
+	}
+
+	m.Messages = append(m.Messages, Message{
+		Role:      "user",
+		Content:   prompt,
+		Rendered:  prompt,
+		Timestamp: time.Now(),
+	})
+
+	m.Streaming = true
+	defer func() { m.Streaming = false }()
+
+	var pending ToolCallsDetectedMsg
+	var cmd tea.Cmd = m.SendToOllama()
+
+	for {
+		switch msg := cmd().(type) {
+		case StreamErrorMsg:
+			emit(HeadlessEvent{Type: "error", Error: msg.Err.Error()})
+			return "", fmt.Errorf("%w: %w", ErrModelFailed, msg.Err)
+
+		case TimeoutErrorMsg:
+			emit(HeadlessEvent{Type: "error", Error: msg.Err.Error()})
+			return "", msg.Err
+
+		case ToolExecutionErrorMsg:
+			emit(HeadlessEvent{Type: "error", Error: msg.Err.Error()})
+			// A step that ran out of time is not the model's fault
+			if errors.Is(msg.Err, context.DeadlineExceeded) {
+				return "", fmt.Errorf("tool step timed out: %w", msg.Err)
+			}
+			return "", fmt.Errorf("%w: %w", ErrModelFailed, msg.Err)
+
+		case StreamChunkMsg:
+			// The complete text is reported with the final message
+			cmd = msg.Stream.Next()
+
+		case RetryNoticeMsg:
+			emit(HeadlessEvent{Type: "retry", Content: msg.Notice})
+			cmd = msg.Stream.Next()
+
+		case ToolResultsMsg:
+			m.Messages = append(m.Messages, msg.ToolMessages...)
+			for _, tm := range msg.ToolMessages {
+				emit(HeadlessEvent{
+					Type:       "tool_result",
+					ToolName:   tm.ToolCall.Name,
+					Content:    tm.Content,
+					Error:      tm.ToolError,
+					DurationMs: tm.Duration.Milliseconds(),
+				})
+			}
+			cmd = msg.Stream.Next()
+
+		case StreamDoneMsg:
+			m.appendHeadlessAnswer(msg.FullResponse, msg.Reasoning, msg.Usage, msg.AnsweredBy)
+			emit(HeadlessEvent{Type: "answer", Content: msg.FullResponse})
+			return msg.FullResponse, nil
+
+		case ToolCallsDetectedMsg:
+			// Without a plugin manager there is nothing to execute the calls
+			// with - the text so far is only a partial answer
+			if m.MCPManager == nil {
+				m.appendHeadlessAnswer(msg.InitialResponse, msg.Reasoning, msg.Usage, msg.AnsweredBy)
+				err := fmt.Errorf("%w: model requested %s but no plugins are running", ErrToolDenied, msg.ToolCalls[0].Name)
+				emit(HeadlessEvent{Type: "error", Content: msg.InitialResponse, Error: err.Error()})
+				return msg.InitialResponse, err
+			}
+
+			if msg.InitialResponse != "" {
+				m.appendHeadlessAnswer(msg.InitialResponse, msg.Reasoning, msg.Usage, msg.AnsweredBy)
+				emit(HeadlessEvent{Type: "text", Content: msg.InitialResponse})
+			}
+			for _, tc := range msg.ToolCalls {
+				emit(HeadlessEvent{Type: "tool_call", ToolName: tc.Name, Arguments: tc.Arguments})
+			}
+			pending = msg
+			cmd = m.ExecuteToolsAndContinue(msg)
+
+		case ToolPermissionRequestMsg:
+			approved := opts.Approval == ApprovalAll
+			emit(HeadlessEvent{Type: "permission", ToolName: msg.ToolName, Content: msg.Purpose, Approved: &approved})
+
+			if !approved {
+				if config.DebugLog != nil {
+					config.DebugLog.Printf("[Headless] Denied tool %s (policy: %s)", msg.ToolName, opts.Approval)
+				}
+				return "", fmt.Errorf("%w: %s", ErrToolDenied, msg.ToolName)
+			}
+
+			temporarilyAllowed = append(temporarilyAllowed, msg.ToolName)
+			if m.CurrentSession != nil {
+				m.CurrentSession.AllowedTools = append(m.CurrentSession.AllowedTools, msg.ToolName)
+			}
+
+			if config.DebugLog != nil {
+				config.DebugLog.Printf("[Headless] Auto-approved tool %s (approved this run: %v)", msg.ToolName, temporarilyAllowed)
+			}
+
+			// Re-run the whole batch: the permission check stops at the first
+			// tool that is not allowed, so remaining calls are checked again
+			cmd = m.ExecuteToolsAndContinue(pending)
+
+		case ToolExecutionCompleteMsg:
+			for _, step := range msg.IterationSummary.Steps {
+				emit(HeadlessEvent{
+					Type:       "step",
+					Content:    step.Purpose,
+					ToolName:   step.ToolName,
+					DurationMs: step.Duration.Milliseconds(),
+				})
+			}
+
+			if msg.HasMoreSteps {
+				if msg.FullResponse != "" {
+					m.appendHeadlessAnswer(msg.FullResponse, msg.Reasoning, msg.Usage, msg.AnsweredBy)
+					emit(HeadlessEvent{Type: "text", Content: msg.FullResponse})
+				}
+				for _, tc := range msg.NextToolCalls {
+					emit(HeadlessEvent{Type: "tool_call", ToolName: tc.Name, Arguments: tc.Arguments})
+				}
+				pending = ToolCallsDetectedMsg{
+					ToolCalls:       msg.NextToolCalls,
+					InitialResponse: "",
+					ContextMessages: msg.NextContext,
+				}
+				cmd = m.ExecuteToolsAndContinue(pending)
+				continue
+			}
+
+			m.appendHeadlessAnswer(msg.FullResponse, msg.Reasoning, msg.Usage, msg.AnsweredBy)
+			emit(HeadlessEvent{Type: "answer", Content: msg.FullResponse})
+			if msg.IterationSummary.MaxReached {
+				return msg.FullResponse, fmt.Errorf("%w (%d)", ErrMaxIterations, m.MaxIterations)
+			}
+			return msg.FullResponse, nil
+
+		default:
+			return "", fmt.Errorf("%w: unexpected message %T", ErrModelFailed, msg)
+		}
+	}
+}
+
+// appendHeadlessAnswer records an assistant response in the conversation
+func (m *Model) appendHeadlessAnswer(content, reasoning string, usage Usage, answeredBy Responder) {
+	if content == "" {
+		return
+	}
+	m.Messages = append(m.Messages, Message{
+		Role:       "assistant",
+		Content:    content,
+		Reasoning:  reasoning,
+		Rendered:   content,
+		Timestamp:  time.Now(),
+		Usage:      usage,
+		AnsweredBy: answeredBy,
+	})
+}
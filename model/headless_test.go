@@ -0,0 +1,384 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	"otui/config"
+	"otui/mcp"
+	"otui/ollama"
+	"otui/storage"
+)
+
+// stubProvider is a minimal Provider for driving RunHeadless
+type stubProvider struct {
+	response  string
+	reasoning string // reported separately with the response
+	err       error
+	model     string
+	hang      bool   // block until the request context ends
+	usage     *Usage // reported after the response, if set
+	vision    bool   // model accepts images
+}
+
+func (p *stubProvider) Chat(ctx context.Context, messages []Message, callback StreamCallback) error {
+	return p.ChatWithTools(ctx, messages, nil, callback)
+}
+
+func (p *stubProvider) ChatWithTools(ctx context.Context, messages []Message, tools []mcptypes.Tool, callback StreamCallback) error {
+	if p.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if p.err != nil {
+		return p.err
+	}
+	if err := callback(p.response, p.reasoning, nil, nil); err != nil {
+		return err
+	}
+	if p.usage != nil {
+		return callback("", "", nil, p.usage)
+	}
+	return nil
+}
+
+func (p *stubProvider) ListModels(ctx context.Context) ([]ollama.ModelInfo, error) { return nil, nil }
+func (p *stubProvider) GetModel() string                                           { return p.model }
+func (p *stubProvider) GetDisplayName() string                                     { return p.model }
+func (p *stubProvider) SetModel(model string)                                      { p.model = model }
+func (p *stubProvider) Ping(ctx context.Context) error                             { return nil }
+func (p *stubProvider) GetModelMetadata(ctx context.Context, modelName string) (ModelMetadata, error) {
+	return ModelMetadata{ContextWindow: 4096, SupportsVision: p.vision}, nil
+}
+
+// scriptedTurn is one provider response: text plus the tool calls it requests
+type scriptedTurn struct {
+	text  string
+	calls []ToolCall
+}
+
+// scriptedProvider replays turns in order, repeating the last one
+type scriptedProvider struct {
+	stubProvider
+	turns []scriptedTurn
+	calls int
+}
+
+func (p *scriptedProvider) ChatWithTools(ctx context.Context, messages []Message, tools []mcptypes.Tool, callback StreamCallback) error {
+	turn := p.turns[min(p.calls, len(p.turns)-1)]
+	p.calls++
+	return callback(turn.text, "", turn.calls, nil)
+}
+
+// newStubMCPManager returns a plugin manager with no running plugins.
+// Tool calls go through the full permission and iteration logic and fail
+// at execution, which is recorded as a tool error.
+func newStubMCPManager(t *testing.T) *mcp.MCPManager {
+	return mcp.NewMCPManager(&config.Config{}, nil, nil, nil, t.TempDir())
+}
+
+func newHeadlessTestModel(p Provider) *Model {
+	session := &storage.Session{Name: "test", Provider: "stub", Model: "stub-model"}
+	m := NewModel(&config.Config{}, p, nil, session, nil, nil, nil, "test", "test")
+	m.Providers = map[string]Provider{"stub": p}
+	return m
+}
+
+func TestRunHeadless(t *testing.T) {
+	t.Run("answer", func(t *testing.T) {
+		m := newHeadlessTestModel(&stubProvider{response: "42"})
+
+		var events []HeadlessEvent
+		answer, err := m.RunHeadless("question", HeadlessOptions{
+			OnEvent: func(ev HeadlessEvent) { events = append(events, ev) },
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if answer != "42" {
+			t.Errorf("answer = %q, want %q", answer, "42")
+		}
+		if len(m.Messages) != 2 || m.Messages[0].Role != "user" || m.Messages[1].Role != "assistant" {
+			t.Errorf("unexpected messages: %+v", m.Messages)
+		}
+		if len(events) != 1 || events[0].Type != "answer" {
+			t.Errorf("unexpected events: %+v", events)
+		}
+		if m.Streaming {
+			t.Error("Streaming should be reset after the run")
+		}
+	})
+
+	t.Run("model_error", func(t *testing.T) {
+		providerErr := errors.New("connection refused")
+		m := newHeadlessTestModel(&stubProvider{err: providerErr})
+
+		_, err := m.RunHeadless("question", HeadlessOptions{})
+		if !errors.Is(err, ErrModelFailed) {
+			t.Errorf("expected ErrModelFailed, got %v", err)
+		}
+		if !errors.Is(err, providerErr) {
+			t.Errorf("expected wrapped provider error, got %v", err)
+		}
+	})
+
+	t.Run("usage_recorded", func(t *testing.T) {
+		usage := Usage{PromptTokens: 120, CompletionTokens: 2}
+		m := newHeadlessTestModel(&stubProvider{response: "42", usage: &usage})
+
+		if _, err := m.RunHeadless("question", HeadlessOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := m.Messages[len(m.Messages)-1].Usage; got != usage {
+			t.Errorf("assistant Usage = %+v, want %+v", got, usage)
+		}
+		if method := m.CalculateTokenUsage().EstimationMethod; method != EstimationProviderReported {
+			t.Errorf("EstimationMethod = %q, want %q", method, EstimationProviderReported)
+		}
+	})
+
+	t.Run("reasoning", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			provider *stubProvider
+		}{
+			{"reported separately", &stubProvider{response: "42", reasoning: "six times seven"}},
+			{"inline think tags", &stubProvider{response: "<think>\nsix times seven\n</think>\n\n42"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				m := newHeadlessTestModel(tt.provider)
+
+				answer, err := m.RunHeadless("question", HeadlessOptions{})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if answer != "42" {
+					t.Errorf("answer = %q, want %q", answer, "42")
+				}
+				if got := m.Messages[len(m.Messages)-1].Reasoning; strings.TrimSpace(got) != "six times seven" {
+					t.Errorf("Reasoning = %q, want the thinking kept apart from the answer", got)
+				}
+			})
+		}
+	})
+
+	t.Run("usage_ledger", func(t *testing.T) {
+		usage := Usage{PromptTokens: 1000, CompletionTokens: 500}
+		m := newHeadlessTestModel(&stubProvider{response: "42", usage: &usage})
+		sessionStorage, err := storage.NewSessionStorage(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewSessionStorage: %v", err)
+		}
+		m.SessionStorage = sessionStorage
+		m.CurrentSession.ID = "s1"
+		m.Config.Prices = config.PriceTable{"stub": {"stub-model": {Input: 2, Output: 10}}}
+
+		if _, err := m.RunHeadless("question", HeadlessOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		records, err := sessionStorage.Usage().Records("s1")
+		if err != nil {
+			t.Fatalf("Records: %v", err)
+		}
+		if len(records) != 1 {
+			t.Fatalf("got %d usage records, want 1", len(records))
+		}
+		rec := records[0]
+		if rec.Provider != "stub" || rec.Model != "stub-model" || rec.InputTokens != 1000 || rec.OutputTokens != 500 {
+			t.Errorf("unexpected record: %+v", rec)
+		}
+		if !rec.Priced || math.Abs(rec.Cost-0.007) > 1e-9 {
+			t.Errorf("Cost = %v (priced %v), want 0.007", rec.Cost, rec.Priced)
+		}
+		if totals := m.SessionUsage(); totals.Requests != 1 || math.Abs(totals.Cost-0.007) > 1e-9 {
+			t.Errorf("SessionUsage = %+v, want 1 request costing 0.007", totals)
+		}
+	})
+
+	t.Run("generation_timeout", func(t *testing.T) {
+		m := newHeadlessTestModel(&stubProvider{hang: true})
+		m.Config.Providers = []config.ProviderConfig{{ID: "stub", Timeout: 1}}
+
+		_, err := m.RunHeadless("question", HeadlessOptions{})
+		var timeoutErr *TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected TimeoutError, got %v", err)
+		}
+		if timeoutErr.Name != "stub" || timeoutErr.Timeout != time.Second {
+			t.Errorf("unexpected timeout details: %+v", timeoutErr)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Error("TimeoutError should unwrap to context.DeadlineExceeded")
+		}
+	})
+
+	t.Run("allow_tools_not_persisted", func(t *testing.T) {
+		m := newHeadlessTestModel(&stubProvider{response: "ok"})
+		m.CurrentSession.AllowedTools = []string{"fs.read_file"}
+
+		_, err := m.RunHeadless("question", HeadlessOptions{AllowTools: []string{"fs.write_file"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(m.CurrentSession.AllowedTools) != 1 || m.CurrentSession.AllowedTools[0] != "fs.read_file" {
+			t.Errorf("AllowedTools = %v, want only the original entry", m.CurrentSession.AllowedTools)
+		}
+	})
+}
+
+func TestRunHeadlessTools(t *testing.T) {
+	readCall := ToolCall{Name: "fs.read_file", Arguments: map[string]any{"path": "a.txt"}}
+	listCall := ToolCall{Name: "fs.list_dir", Arguments: map[string]any{"path": "."}}
+
+	t.Run("approve_deny", func(t *testing.T) {
+		p := &scriptedProvider{turns: []scriptedTurn{{calls: []ToolCall{readCall}}}}
+		m := newHeadlessTestModel(p)
+		m.Config.RequireApproval = true
+		m.MCPManager = newStubMCPManager(t)
+
+		var events []HeadlessEvent
+		_, err := m.RunHeadless("question", HeadlessOptions{
+			Approval: ApprovalDeny,
+			OnEvent:  func(ev HeadlessEvent) { events = append(events, ev) },
+		})
+		if !errors.Is(err, ErrToolDenied) {
+			t.Fatalf("expected ErrToolDenied, got %v", err)
+		}
+		last := events[len(events)-1]
+		if last.Type != "permission" || last.ToolName != readCall.Name || last.Approved == nil || *last.Approved {
+			t.Errorf("last event = %+v, want denied permission for %s", last, readCall.Name)
+		}
+	})
+
+	t.Run("approve_all", func(t *testing.T) {
+		p := &scriptedProvider{turns: []scriptedTurn{
+			{calls: []ToolCall{readCall, listCall}},
+			{text: "done"},
+		}}
+		m := newHeadlessTestModel(p)
+		m.Config.RequireApproval = true
+		m.MCPManager = newStubMCPManager(t)
+
+		var permissions []string
+		answer, err := m.RunHeadless("question", HeadlessOptions{
+			Approval: ApprovalAll,
+			OnEvent: func(ev HeadlessEvent) {
+				if ev.Type == "permission" {
+					permissions = append(permissions, ev.ToolName)
+				}
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if answer != "done" {
+			t.Errorf("answer = %q, want %q", answer, "done")
+		}
+		// The batch is re-run after each approval until every call is allowed
+		if len(permissions) != 2 || permissions[0] != readCall.Name || permissions[1] != listCall.Name {
+			t.Errorf("permission requests = %v, want [%s %s]", permissions, readCall.Name, listCall.Name)
+		}
+
+		var toolResults int
+		for _, msg := range m.Messages {
+			if msg.Role == "tool" {
+				toolResults++
+			}
+		}
+		if toolResults != 2 {
+			t.Errorf("got %d tool results, want 2 (each call executed once)", toolResults)
+		}
+		if len(m.CurrentSession.AllowedTools) != 0 {
+			t.Errorf("AllowedTools = %v, run approvals must not persist", m.CurrentSession.AllowedTools)
+		}
+	})
+
+	t.Run("parallel_results_in_call_order", func(t *testing.T) {
+		calls := []ToolCall{readCall, listCall, {Name: "fs.stat", Arguments: map[string]any{"path": "b"}}}
+		p := &scriptedProvider{turns: []scriptedTurn{{calls: calls}, {text: "done"}}}
+		m := newHeadlessTestModel(p)
+		m.MCPManager = newStubMCPManager(t)
+
+		if _, err := m.RunHeadless("question", HeadlessOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var got []string
+		for _, msg := range m.Messages {
+			if msg.Role == "tool" {
+				got = append(got, msg.ToolCall.Name)
+				// The stub manager has plugins disabled, so every call fails
+				if msg.ToolError == "" {
+					t.Errorf("%s: expected a per-call error", msg.ToolCall.Name)
+				}
+			}
+		}
+		if len(got) != len(calls) {
+			t.Fatalf("got %d tool results, want %d", len(got), len(calls))
+		}
+		for i, call := range calls {
+			if got[i] != call.Name {
+				t.Errorf("result %d = %s, want %s", i, got[i], call.Name)
+			}
+		}
+	})
+
+	t.Run("max_iterations", func(t *testing.T) {
+		p := &scriptedProvider{turns: []scriptedTurn{{text: "partial", calls: []ToolCall{readCall}}}}
+		m := newHeadlessTestModel(p)
+		m.Config.EnableMultiStep = true
+		m.MaxIterations = 2
+		m.MCPManager = newStubMCPManager(t)
+
+		answer, err := m.RunHeadless("question", HeadlessOptions{})
+		if !errors.Is(err, ErrMaxIterations) {
+			t.Fatalf("expected ErrMaxIterations, got %v", err)
+		}
+		if answer != "partial" {
+			t.Errorf("answer = %q, want the partial answer %q", answer, "partial")
+		}
+		// Initial request plus one follow-up per iteration
+		if p.calls != 3 {
+			t.Errorf("provider called %d times, want 3", p.calls)
+		}
+	})
+
+	t.Run("no_plugins", func(t *testing.T) {
+		p := &scriptedProvider{turns: []scriptedTurn{{text: "Let me look.", calls: []ToolCall{readCall}}}}
+		m := newHeadlessTestModel(p)
+
+		answer, err := m.RunHeadless("question", HeadlessOptions{})
+		if !errors.Is(err, ErrToolDenied) {
+			t.Fatalf("expected ErrToolDenied, got %v", err)
+		}
+		if answer != "Let me look." {
+			t.Errorf("answer = %q, want the partial answer", answer)
+		}
+	})
+}
+
+func TestParseApprovalPolicy(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ApprovalPolicy
+		wantErr bool
+	}{
+		{"", ApprovalDeny, false},
+		{"deny", ApprovalDeny, false},
+		{"all", ApprovalAll, false},
+		{"maybe", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseApprovalPolicy(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseApprovalPolicy(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
@@ -1,20 +1,117 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"otui/storage"
+)
 
 // Message represents a chat message in the conversation
 type Message struct {
 	Role       string
 	Content    string // Raw content from Ollama
+	Reasoning  string // Thinking the model did before answering (role "assistant" only, never sent back)
 	Rendered   string // Cached rendered markdown (optimize if storage becomes a concern)
 	Timestamp  time.Time
 	Persistent bool // If true, don't auto-remove (e.g., step messages)
+
+	// Files attached to the message (role "user" only)
+	Attachments []Attachment
+
+	// Interrupted is set when the user stopped the response (role "assistant" only)
+	Interrupted bool
+
+	// Token counts reported by the provider (role "assistant" only, zero if unknown)
+	Usage Usage
+
+	// Provider and model that produced the response (role "assistant" only,
+	// empty if unknown). Differs from the session's when a fallback answered.
+	AnsweredBy Responder
+
+	// Tool calls requested by this message (role "assistant" only, API context)
+	ToolCalls []ToolCall
+
+	// Tool execution (role "tool" only)
+	ToolCall  *ToolCall     // The call that produced this result
+	ToolError string        // Set if the tool failed (Content then holds the error text sent to the model)
+	Duration  time.Duration // How long the tool took to run
 }
 
 // ToolCall represents a provider-agnostic tool call request.
 // This allows us to abstract away provider-specific tool call formats
 // (Ollama's api.ToolCall, OpenAI's function calls, etc.).
 type ToolCall struct {
+	ID        string // Provider-assigned call ID, used to pair results with calls
 	Name      string
 	Arguments map[string]any
 }
+
+// MessageFromStorage converts a persisted session message to a UI message
+func MessageFromStorage(sMsg storage.Message) Message {
+	msg := Message{
+		Role:        sMsg.Role,
+		Content:     sMsg.Content,
+		Reasoning:   sMsg.Reasoning,
+		Rendered:    sMsg.Rendered,
+		Timestamp:   sMsg.Timestamp,
+		Interrupted: sMsg.Interrupted,
+		ToolError:   sMsg.ToolError,
+		Duration:    time.Duration(sMsg.DurationMs) * time.Millisecond,
+		Usage: Usage{
+			PromptTokens:     sMsg.PromptTokens,
+			CompletionTokens: sMsg.CompletionTokens,
+		},
+		AnsweredBy: Responder{
+			Provider: sMsg.Provider,
+			Model:    sMsg.Model,
+		},
+	}
+	for _, att := range sMsg.Attachments {
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Name:     att.Name,
+			MIMEType: att.MIMEType,
+			Data:     att.Data,
+		})
+	}
+	if sMsg.ToolCall != nil {
+		msg.ToolCall = &ToolCall{
+			ID:        sMsg.ToolCall.ID,
+			Name:      sMsg.ToolCall.Name,
+			Arguments: sMsg.ToolCall.Arguments,
+		}
+	}
+	return msg
+}
+
+// ToStorage converts a UI message to its persisted form
+func (msg Message) ToStorage() storage.Message {
+	sMsg := storage.Message{
+		Role:             msg.Role,
+		Content:          msg.Content,
+		Reasoning:        msg.Reasoning,
+		Rendered:         msg.Rendered,
+		Timestamp:        msg.Timestamp,
+		Interrupted:      msg.Interrupted,
+		PromptTokens:     msg.Usage.PromptTokens,
+		CompletionTokens: msg.Usage.CompletionTokens,
+		Provider:         msg.AnsweredBy.Provider,
+		Model:            msg.AnsweredBy.Model,
+		ToolError:        msg.ToolError,
+		DurationMs:       msg.Duration.Milliseconds(),
+	}
+	for _, att := range msg.Attachments {
+		sMsg.Attachments = append(sMsg.Attachments, storage.Attachment{
+			Name:     att.Name,
+			MIMEType: att.MIMEType,
+			Data:     att.Data,
+		})
+	}
+	if msg.ToolCall != nil {
+		sMsg.ToolCall = &storage.ToolCallRecord{
+			ID:        msg.ToolCall.ID,
+			Name:      msg.ToolCall.Name,
+			Arguments: msg.ToolCall.Arguments,
+		}
+	}
+	return sMsg
+}
@@ -0,0 +1,174 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"otui/storage"
+)
+
+func TestMessageStorageRoundTrip(t *testing.T) {
+	msg := Message{
+		Role:      "tool",
+		Content:   "result",
+		Timestamp: time.Now(),
+		ToolCall:  &ToolCall{Name: "fs.read_file", Arguments: map[string]any{"path": "a.txt"}},
+		ToolError: "boom",
+		Duration:  1500 * time.Millisecond,
+	}
+
+	sMsg := msg.ToStorage()
+	if sMsg.ToolCall == nil || sMsg.ToolCall.Name != "fs.read_file" {
+		t.Fatalf("tool call not converted: %+v", sMsg.ToolCall)
+	}
+	if sMsg.DurationMs != 1500 {
+		t.Errorf("DurationMs = %d, want 1500", sMsg.DurationMs)
+	}
+
+	back := MessageFromStorage(sMsg)
+	if back.ToolCall == nil || back.ToolCall.Arguments["path"] != "a.txt" {
+		t.Errorf("arguments lost in round trip: %+v", back.ToolCall)
+	}
+	if back.ToolError != "boom" || back.Duration != 1500*time.Millisecond {
+		t.Errorf("unexpected round trip result: %+v", back)
+	}
+
+	partial := Message{Role: "assistant", Content: "half an ans", Interrupted: true}
+	if !MessageFromStorage(partial.ToStorage()).Interrupted {
+		t.Error("Interrupted lost in round trip")
+	}
+
+	counted := Message{Role: "assistant", Content: "ok", Usage: Usage{PromptTokens: 1200, CompletionTokens: 40}}
+	if got := MessageFromStorage(counted.ToStorage()).Usage; got != counted.Usage {
+		t.Errorf("Usage = %+v after round trip, want %+v", got, counted.Usage)
+	}
+
+	attached := Message{Role: "user", Content: "see", Attachments: []Attachment{{Name: "a.png", MIMEType: "image/png", Data: []byte{1, 2}}}}
+	if got := MessageFromStorage(attached.ToStorage()).Attachments; len(got) != 1 || got[0].Name != "a.png" || string(got[0].Data) != "\x01\x02" {
+		t.Errorf("Attachments = %+v after round trip", got)
+	}
+
+	thought := Message{Role: "assistant", Content: "4", Reasoning: "two plus two"}
+	if got := MessageFromStorage(thought.ToStorage()).Reasoning; got != thought.Reasoning {
+		t.Errorf("Reasoning = %q after round trip, want %q", got, thought.Reasoning)
+	}
+
+	plain := MessageFromStorage(storage.Message{Role: "user", Content: "hi"})
+	if plain.ToolCall != nil {
+		t.Error("plain message should not get a tool call")
+	}
+}
+
+func TestBuildAPIMessagesReplaysToolResults(t *testing.T) {
+	call := &ToolCall{Name: "fs.read_file"}
+	ui := []Message{
+		{Role: "user", Content: "read it"},
+		{Role: "system", Content: "🔧 Step 1: Reading file ✓", Persistent: true},
+		{Role: "tool", Content: "file contents", ToolCall: call},
+		{Role: "assistant", Content: "done"},
+	}
+
+	got := buildAPIMessages(ui, "", nil)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 messages (system step dropped, call message added), got %d: %+v", len(got), got)
+	}
+	if got[1].Role != "assistant" || len(got[1].ToolCalls) != 1 || got[1].ToolCalls[0].Name != "fs.read_file" {
+		t.Errorf("expected assistant message requesting the call, got %+v", got[1])
+	}
+	if got[2].Role != "tool" || got[2].Content != "file contents" || got[2].ToolCall == nil {
+		t.Fatalf("tool result not replayed: %+v", got[2])
+	}
+	if got[2].ToolCall.ID == "" || got[2].ToolCall.ID != got[1].ToolCalls[0].ID {
+		t.Errorf("result ID %q does not match call ID %q", got[2].ToolCall.ID, got[1].ToolCalls[0].ID)
+	}
+}
+
+func TestBuildAPIMessagesPairsParallelToolCalls(t *testing.T) {
+	ui := []Message{
+		{Role: "user", Content: "compare the files"},
+		{Role: "assistant", Content: "Reading both files."},
+		{Role: "tool", Content: "a", ToolCall: &ToolCall{ID: "call_a", Name: "fs.read_file"}},
+		{Role: "tool", Content: "b", ToolCall: &ToolCall{ID: "call_b", Name: "fs.read_file"}, ToolError: "not found"},
+		{Role: "assistant", Content: "They differ."},
+	}
+
+	got := buildAPIMessages(ui, "", nil)
+	if len(got) != 5 {
+		t.Fatalf("expected calls attached to existing assistant message, got %d messages: %+v", len(got), got)
+	}
+
+	assistant := got[1]
+	if assistant.Content != "Reading both files." || len(assistant.ToolCalls) != 2 {
+		t.Fatalf("expected both calls on the assistant message, got %+v", assistant)
+	}
+	for i, id := range []string{"call_a", "call_b"} {
+		if assistant.ToolCalls[i].ID != id {
+			t.Errorf("call %d: got ID %q, want %q", i, assistant.ToolCalls[i].ID, id)
+		}
+		if got[2+i].ToolCall.ID != id {
+			t.Errorf("result %d: got ID %q, want %q", i, got[2+i].ToolCall.ID, id)
+		}
+	}
+	if got[3].ToolError != "not found" {
+		t.Errorf("expected tool error to be replayed, got %q", got[3].ToolError)
+	}
+}
+
+func TestAppendToolCallMessage(t *testing.T) {
+	calls := []ToolCall{{ID: "call_1", Name: "fs.list"}}
+
+	t.Run("merges into trailing assistant message", func(t *testing.T) {
+		context := []Message{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "Looking."}}
+		got := appendToolCallMessage(context, "", calls)
+		if len(got) != 2 || len(got[1].ToolCalls) != 1 {
+			t.Fatalf("expected calls merged into last message, got %+v", got)
+		}
+		if len(context[1].ToolCalls) != 0 {
+			t.Error("input slice was modified")
+		}
+	})
+
+	t.Run("appends when response has text", func(t *testing.T) {
+		context := []Message{{Role: "user", Content: "hi"}}
+		got := appendToolCallMessage(context, "Let me check.", calls)
+		if len(got) != 2 || got[1].Content != "Let me check." || len(got[1].ToolCalls) != 1 {
+			t.Fatalf("expected new assistant message, got %+v", got)
+		}
+	})
+
+	t.Run("does not merge into message that already has calls", func(t *testing.T) {
+		context := []Message{{Role: "assistant", ToolCalls: calls}}
+		got := appendToolCallMessage(context, "", calls)
+		if len(got) != 2 {
+			t.Fatalf("expected new assistant message, got %+v", got)
+		}
+	})
+}
+
+func TestBuildAPIMessagesDropsReasoning(t *testing.T) {
+	ui := []Message{
+		{Role: "user", Content: "what is 2+2?"},
+		{Role: "assistant", Content: "4", Reasoning: "two plus two"},
+		{Role: "user", Content: "and 3+3?"},
+		{Role: "assistant", Content: "<think>three plus three</think>\n\n6"}, // Saved before reasoning was split out
+		{Role: "assistant", Reasoning: "I should look it up"},                // Thought, then called tools
+		{Role: "tool", Content: "6", ToolCall: &ToolCall{ID: "call_a", Name: "calc.add"}},
+	}
+
+	got := buildAPIMessages(ui, "", nil)
+	if len(got) != 5 {
+		t.Fatalf("expected 5 messages (reasoning-only message dropped), got %d: %+v", len(got), got)
+	}
+	for _, msg := range got {
+		if msg.Reasoning != "" || strings.Contains(msg.Content, "three plus three") {
+			t.Errorf("reasoning sent back to the model: %+v", msg)
+		}
+	}
+	if got[3].Content != "6" {
+		t.Errorf("inline think block not stripped: %q", got[3].Content)
+	}
+	if len(got[3].ToolCalls) != 1 || got[3].ToolCalls[0].ID != "call_a" {
+		t.Errorf("expected calls attached to the last answer, got %+v", got[3])
+	}
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"time"
 
+	"otui/mcp"
 	"otui/ollama"
 	"otui/storage"
 )
@@ -19,8 +20,16 @@ type IterationStep struct {
 	ErrorMsg   string // If failed
 
 	// Internal fields (not displayed to users)
-	ToolName  string // e.g., "mcp-filesystem.read_dir"
-	ShortName string // e.g., "read_dir"
+	ToolName  string           // e.g., "mcp-filesystem.read_dir"
+	ShortName string           // e.g., "read_dir"
+	ToolCalls []ToolCallRecord // Every call made in this step, in call order
+}
+
+// ToolCallRecord is the outcome of one tool call within a step
+type ToolCallRecord struct {
+	ToolName string
+	Duration time.Duration
+	Error    string // Empty if the call succeeded
 }
 
 // IterationSummaryMsg contains summary of all steps (Phase 2)
@@ -30,41 +39,70 @@ type IterationSummaryMsg struct {
 	MaxReached bool // True if max iterations reached (warning)
 }
 
+// StreamChunkMsg carries text as soon as the provider produces it.
+// Stream.Next() yields the message that follows.
 type StreamChunkMsg struct {
-	Chunk string
+	Chunk     string
+	Reasoning string // Thinking streamed before (or between) answer text
+	Stream    *ResponseStream
 }
 
 type StreamDoneMsg struct {
 	FullResponse string
+	Reasoning    string    // Thinking reported separately from the answer (empty if none)
+	Usage        Usage     // Token counts reported by the provider (zero if none)
+	AnsweredBy   Responder // Provider and model that produced the response
+	Stream       *ResponseStream
+}
+
+// RetryNoticeMsg is sent when a failed request is about to be retried or
+// handed to a fallback model. Stream.Next() yields the message that follows.
+type RetryNoticeMsg struct {
+	Notice string
+	Stream *ResponseStream
 }
 
 type StreamErrorMsg struct {
 	Err error
 }
 
-type StreamChunksCollectedMsg struct {
-	Chunks       []string
-	FullResponse string
+// TimeoutErrorMsg is sent instead of StreamErrorMsg/ToolExecutionErrorMsg
+// when the provider didn't respond within the configured timeout
+type TimeoutErrorMsg struct {
+	Err *TimeoutError
 }
 
-type DisplayChunkTickMsg struct{}
-
 // Tool execution messages (Phase 6)
 type ToolCallsDetectedMsg struct {
 	ToolCalls       []ToolCall
 	InitialResponse string
+	Reasoning       string // Thinking that led to the calls (empty if none)
 	ContextMessages []Message
+	Usage           Usage     // Token counts for the response that requested the calls
+	AnsweredBy      Responder // Provider and model that requested the calls
+}
+
+// ToolResultsMsg is sent once a step's tools have run, before the model's
+// follow-up response starts streaming. Stream.Next() yields the message that follows.
+type ToolResultsMsg struct {
+	// Tool calls executed in this step with their results (role "tool"),
+	// to be kept in the conversation and persisted with the session
+	ToolMessages []Message
+	Stream       *ResponseStream
 }
 
 type ToolExecutionCompleteMsg struct {
-	Chunks           []string
 	FullResponse     string
+	Reasoning        string              // Thinking reported with FullResponse (empty if none)
 	IterationSummary IterationSummaryMsg // Phase 2
 
 	// Phase 2: Multi-step continuation
-	HasMoreSteps  bool       // Continue iteration after typewriter?
+	HasMoreSteps  bool       // Continue iteration after this response?
 	NextToolCalls []ToolCall // Tools to execute in next step
 	NextContext   []Message  // Context for next iteration
+
+	Usage      Usage     // Token counts for FullResponse (zero if the provider reported none)
+	AnsweredBy Responder // Provider and model that produced FullResponse
 }
 
 type ToolExecutionErrorMsg struct {
@@ -83,6 +121,13 @@ type ModelsListMsg struct {
 	ShowSelector bool // Whether to auto-show model selector (user-initiated vs background fetch)
 }
 
+// ModelCapabilitiesLoadedMsg reports that the provider has loaded what a
+// model can do (see Model.LoadModelCapabilities)
+type ModelCapabilitiesLoadedMsg struct {
+	Model string
+	Err   error
+}
+
 type SessionsListMsg struct {
 	Sessions []storage.SessionMetadata
 	Err      error
@@ -113,6 +158,24 @@ type SessionImportedMsg struct {
 	Cancelled bool
 }
 
+type SessionForkedMsg struct {
+	Session *storage.Session // The new session
+	Err     error
+}
+
+// SemanticSearchResultMsg carries the matches of a semantic search
+type SemanticSearchResultMsg struct {
+	Query   string // The search the results are for
+	Results []storage.SessionMessageMatch
+	Err     error
+}
+
+// SessionsIndexedMsg is sent when IndexSessionsCmd has finished
+type SessionsIndexedMsg struct {
+	Indexed int // Sessions checked and brought up to date
+	Err     error
+}
+
 type ExportCleanupDoneMsg struct{}
 
 type DataExportedMsg struct {
@@ -126,8 +189,9 @@ type DataExportCleanupDoneMsg struct{}
 type FlashTickMsg struct{}
 
 type PluginOperationCompleteMsg struct {
-	Operation string // "enable" or "disable"
+	Operation string // "enable", "disable" or "upgrade"
 	PluginID  string
+	Version   string // Installed by an upgrade
 	Success   bool
 	Err       error
 }
@@ -136,11 +200,60 @@ type PluginStartupCompleteMsg struct {
 	Failures map[string]error // pluginID → error
 }
 
+// ResourceAttachedMsg carries a plugin resource read to attach to the next prompt
+type ResourceAttachedMsg struct {
+	Name        string
+	Attachments []Attachment
+	Err         error
+}
+
+// ResourcePinnedMsg reports a resource pinned to or unpinned from the session
+type ResourcePinnedMsg struct {
+	Name   string
+	Pinned bool
+	Err    error
+}
+
+// ResourceUpdatedMsg reports that a pinned resource changed and was read again
+type ResourceUpdatedMsg struct {
+	Name string
+	Err  error
+}
+
+// PromptRenderedMsg carries the messages of a plugin prompt run as a command
+type PromptRenderedMsg struct {
+	Name     string
+	Messages []Message
+	Err      error
+}
+
+// SamplingRequestMsg carries a plugin's request for a completion, which the
+// user approves or declines
+type SamplingRequestMsg struct {
+	Request *mcp.SamplingRequest
+}
+
+// SamplingCompleteMsg reports that an approved sampling request was answered
+type SamplingCompleteMsg struct {
+	PluginID string
+	Err      error
+}
+
+// ElicitationRequestMsg carries a plugin's request for the user to fill in a form
+type ElicitationRequestMsg struct {
+	Request *mcp.ElicitationRequest
+}
+
 type RegistryRefreshCompleteMsg struct {
 	Success bool
 	Err     error
 }
 
+// PluginUpdatesMsg carries the installed plugins that have newer versions
+type PluginUpdatesMsg struct {
+	Updates map[string]mcp.PluginUpdate // pluginID → update
+}
+
 type EditorContentMsg struct {
 	Content string
 }
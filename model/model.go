@@ -1,6 +1,8 @@
 package model
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"otui/config"
@@ -30,6 +32,9 @@ type Model struct {
 
 	// Runtime state (not UI)
 	Streaming          bool
+	ActiveStream       *ResponseStream // In-flight provider request (nil when idle)
+	turnCtx            context.Context // Shared by every step of the current user turn
+	turnCancel         context.CancelFunc
 	SessionDirty       bool
 	NeedsInitialRender bool
 	Quitting           bool
@@ -46,6 +51,16 @@ type Model struct {
 	cachedModelName        string
 	cachedModelMetadata    ModelMetadata
 
+	// Usage ledger totals for the current session (see SessionUsage)
+	usageMu            sync.Mutex
+	sessionUsageID     string
+	sessionUsage       storage.UsageTotals
+	sessionUsageLoaded bool
+
+	// Current contents of the resources pinned to the session, by plugin and URI
+	resourcesMu   sync.Mutex
+	resourceCache map[string]string
+
 	// Application metadata
 	Version string
 	License string
@@ -63,12 +78,7 @@ func NewModel(cfg *config.Config, providerClient Provider, sessionStorage *stora
 	needsRender := false
 	if lastSession != nil {
 		for _, sMsg := range lastSession.Messages {
-			messages = append(messages, Message{
-				Role:      sMsg.Role,
-				Content:   sMsg.Content,
-				Rendered:  sMsg.Rendered,
-				Timestamp: sMsg.Timestamp,
-			})
+			messages = append(messages, MessageFromStorage(sMsg))
 		}
 		needsRender = len(messages) > 0
 	}
@@ -0,0 +1,280 @@
+package model
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+)
+
+// PromptCommands returns the prompts of the session's plugins, named
+// "plugin:prompt" and typed as "/plugin:prompt name=value ..."
+func (m *Model) PromptCommands() []mcptypes.Prompt {
+	if m.MCPManager == nil {
+		return nil
+	}
+	return m.MCPManager.GetPrompts()
+}
+
+// FindPrompt returns the prompt with the given "plugin:prompt" name
+func FindPrompt(prompts []mcptypes.Prompt, name string) (mcptypes.Prompt, bool) {
+	for _, p := range prompts {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return mcptypes.Prompt{}, false
+}
+
+// ParsePromptCommand splits "/plugin:prompt name=value name2="two words""
+// into the prompt name and its arguments. The name is empty if the input
+// isn't a prompt command.
+func ParsePromptCommand(input string) (string, map[string]string, error) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "/") {
+		return "", nil, nil
+	}
+	command, rest, _ := strings.Cut(input[1:], " ")
+	if !strings.Contains(command, ":") || strings.ContainsFunc(command, unicode.IsSpace) {
+		return "", nil, nil
+	}
+
+	args := make(map[string]string)
+	for _, field := range splitArguments(rest) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			return command, nil, fmt.Errorf("expected name=value, got %q", field)
+		}
+		args[key] = value
+	}
+	return command, args, nil
+}
+
+// splitArguments splits on whitespace outside double or single quotes,
+// dropping the quotes
+func splitArguments(s string) []string {
+	var fields []string
+	var current strings.Builder
+	var quote rune
+	inField := false
+
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+			inField = true
+		case quote == 0 && unicode.IsSpace(r):
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+// CheckPromptArguments reports required arguments that are missing and
+// arguments the prompt doesn't take
+func CheckPromptArguments(prompt mcptypes.Prompt, args map[string]string) error {
+	var missing, names []string
+	for _, arg := range prompt.Arguments {
+		names = append(names, arg.Name)
+		if arg.Required && args[arg.Name] == "" {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("/%s needs %s", prompt.Name, strings.Join(missing, ", "))
+	}
+
+	for key := range args {
+		if !slices.Contains(names, key) {
+			if len(names) == 0 {
+				return fmt.Errorf("/%s takes no arguments", prompt.Name)
+			}
+			return fmt.Errorf("/%s has no argument %q (it takes %s)", prompt.Name, key, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// CompletePromptInput completes what's typed in the input box: the command
+// name as far as the matching prompts agree, then the next argument the
+// prompt takes - required ones first. It returns the input unchanged when
+// there's nothing to complete.
+func CompletePromptInput(input string, prompts []mcptypes.Prompt) string {
+	if !strings.HasPrefix(input, "/") || strings.Contains(input, "\n") {
+		return input
+	}
+
+	command, rest, hasArgs := strings.Cut(input[1:], " ")
+	if !hasArgs {
+		var matches []string
+		for _, p := range prompts {
+			if strings.HasPrefix(p.Name, command) {
+				matches = append(matches, p.Name)
+			}
+		}
+		if len(matches) == 0 {
+			return input
+		}
+		if len(matches) == 1 {
+			return "/" + matches[0] + " "
+		}
+		return "/" + commonPrefix(matches)
+	}
+
+	prompt, ok := FindPrompt(prompts, command)
+	if !ok {
+		return input
+	}
+
+	// Finish an argument name being typed
+	fields := splitArguments(rest)
+	if len(fields) > 0 && !strings.HasSuffix(rest, " ") && !strings.Contains(fields[len(fields)-1], "=") {
+		typed := fields[len(fields)-1]
+		for _, arg := range prompt.Arguments {
+			if strings.HasPrefix(arg.Name, typed) {
+				return input + strings.TrimPrefix(arg.Name, typed) + "="
+			}
+		}
+		return input
+	}
+
+	given := make(map[string]bool)
+	for _, field := range fields {
+		key, _, _ := strings.Cut(field, "=")
+		given[key] = true
+	}
+	ordered := slices.Clone(prompt.Arguments)
+	slices.SortStableFunc(ordered, func(a, b mcptypes.PromptArgument) int {
+		switch {
+		case a.Required && !b.Required:
+			return -1
+		case !a.Required && b.Required:
+			return 1
+		}
+		return 0
+	})
+	for _, arg := range ordered {
+		if given[arg.Name] {
+			continue
+		}
+		if !strings.HasSuffix(input, " ") {
+			input += " "
+		}
+		return input + arg.Name + "="
+	}
+	return input
+}
+
+func commonPrefix(names []string) string {
+	prefix := names[0]
+	for _, name := range names[1:] {
+		for !strings.HasPrefix(name, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// PromptUsage describes a prompt's arguments, e.g. "path=… [focus=…]"
+func PromptUsage(prompt mcptypes.Prompt) string {
+	var parts []string
+	for _, arg := range prompt.Arguments {
+		if arg.Required {
+			parts = append(parts, arg.Name+"=…")
+		} else {
+			parts = append(parts, "["+arg.Name+"=…]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// RunPromptCmd renders a plugin's prompt with the given arguments
+func (m *Model) RunPromptCmd(name string, args map[string]string) tea.Cmd {
+	manager := m.MCPManager
+	timeout := m.Config.ToolExecutionTimeout()
+
+	return func() tea.Msg {
+		if manager == nil {
+			return PromptRenderedMsg{Name: name, Err: fmt.Errorf("plugins are not available")}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		result, err := manager.GetPrompt(ctx, name, args)
+		if err != nil {
+			return PromptRenderedMsg{Name: name, Err: err}
+		}
+		messages := PromptMessages(result)
+		if len(messages) == 0 {
+			return PromptRenderedMsg{Name: name, Err: fmt.Errorf("/%s returned no messages", name)}
+		}
+		return PromptRenderedMsg{Name: name, Messages: messages}
+	}
+}
+
+// PromptMessages turns the messages of a rendered prompt into conversation
+// messages. Embedded text resources are added to the content like attached
+// files; images become attachments of user messages.
+func PromptMessages(result *mcptypes.GetPromptResult) []Message {
+	var messages []Message
+	for _, pm := range result.Messages {
+		if msg, ok := contentMessage(pm.Role, pm.Content); ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// contentMessage turns a piece of MCP content into a message. It reports
+// false for content types it doesn't know.
+func contentMessage(role mcptypes.Role, content any) (Message, bool) {
+	msg := Message{Role: string(role), Timestamp: time.Now()}
+	if msg.Role != "assistant" {
+		msg.Role = "user"
+	}
+
+	switch c := content.(type) {
+	case mcptypes.TextContent:
+		msg.Content = c.Text
+	case mcptypes.ImageContent:
+		data, err := base64.StdEncoding.DecodeString(c.Data)
+		if err != nil || msg.Role != "user" {
+			msg.Content = fmt.Sprintf("[%s image not included]", c.MIMEType)
+			break
+		}
+		msg.Attachments = []Attachment{{Name: "image", MIMEType: c.MIMEType, Data: data}}
+	case mcptypes.EmbeddedResource:
+		switch r := c.Resource.(type) {
+		case mcptypes.TextResourceContents:
+			msg.Content = fmt.Sprintf("Resource: %s\n```\n%s\n```", r.URI, strings.TrimRight(r.Text, "\n"))
+		case mcptypes.BlobResourceContents:
+			msg.Content = fmt.Sprintf("[Resource %s (%s) not included]", r.URI, r.MIMEType)
+		}
+	case mcptypes.ResourceLink:
+		msg.Content = fmt.Sprintf("Resource: %s (%s)", c.Name, c.URI)
+	case mcptypes.AudioContent:
+		msg.Content = fmt.Sprintf("[%s audio not included]", c.MIMEType)
+	default:
+		return Message{}, false
+	}
+	return msg, true
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+)
+
+func testPrompts() []mcptypes.Prompt {
+	return []mcptypes.Prompt{
+		{Name: "git:commit", Arguments: []mcptypes.PromptArgument{
+			{Name: "style"},
+			{Name: "scope", Required: true},
+		}},
+		{Name: "git:changelog"},
+		{Name: "notes:today"},
+	}
+}
+
+func TestParsePromptCommand(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantName string
+		wantArgs map[string]string
+		wantErr  bool
+	}{
+		{"/git:commit", "git:commit", map[string]string{}, false},
+		{`/git:commit scope=ui style="very terse"`, "git:commit", map[string]string{"scope": "ui", "style": "very terse"}, false},
+		{"/git:commit scope='a b'  ", "git:commit", map[string]string{"scope": "a b"}, false},
+		{"/git:commit ui", "git:commit", nil, true},
+		{"hello /git:commit", "", nil, false},
+		{"/usr/bin/env", "", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			name, args, err := ParsePromptCommand(tt.input)
+			if name != tt.wantName || (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePromptCommand = %q, %v; want %q, error %v", name, err, tt.wantName, tt.wantErr)
+			}
+			if !tt.wantErr && tt.wantName != "" && !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCompletePromptInput(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/g", "/git:c"},                       // Common prefix of the matches
+		{"/git:com", "/git:commit "},           // Unique match
+		{"/n", "/notes:today "},                // Unique match
+		{"/x", "/x"},                           // No match
+		{"/git:commit ", "/git:commit scope="}, // Required first
+		{"/git:commit scope=ui", "/git:commit scope=ui style="},          // Then optional
+		{"/git:commit scope=ui style=a", "/git:commit scope=ui style=a"}, // All given
+		{"/git:commit sty", "/git:commit style="},                        // Name being typed
+		{"/git:changelog ", "/git:changelog "},                           // No arguments
+		{"plain text", "plain text"},
+	}
+	for _, tt := range tests {
+		if got := CompletePromptInput(tt.input, testPrompts()); got != tt.want {
+			t.Errorf("CompletePromptInput(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPromptArguments(t *testing.T) {
+	commit := testPrompts()[0]
+	if err := CheckPromptArguments(commit, map[string]string{"scope": "ui"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := CheckPromptArguments(commit, map[string]string{"style": "terse"}); err == nil {
+		t.Error("expected an error for a missing required argument")
+	}
+	if err := CheckPromptArguments(commit, map[string]string{"scope": "ui", "mood": "happy"}); err == nil {
+		t.Error("expected an error for an unknown argument")
+	}
+}
+
+func TestPromptMessages(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte{0x89, 'P', 'N', 'G'})
+	result := &mcptypes.GetPromptResult{Messages: []mcptypes.PromptMessage{
+		mcptypes.NewPromptMessage(mcptypes.RoleUser, mcptypes.NewTextContent("Summarize this")),
+		mcptypes.NewPromptMessage(mcptypes.RoleUser, mcptypes.NewEmbeddedResource(
+			mcptypes.TextResourceContents{URI: "file:///notes.md", Text: "- milk\n"})),
+		mcptypes.NewPromptMessage(mcptypes.RoleUser, mcptypes.NewImageContent(png, "image/png")),
+		mcptypes.NewPromptMessage(mcptypes.RoleAssistant, mcptypes.NewTextContent("Sure.")),
+	}}
+
+	messages := PromptMessages(result)
+	if len(messages) != 4 {
+		t.Fatalf("got %d messages, want 4", len(messages))
+	}
+	if messages[0].Role != "user" || messages[0].Content != "Summarize this" {
+		t.Errorf("text message = %+v", messages[0])
+	}
+	if want := "Resource: file:///notes.md\n```\n- milk\n```"; messages[1].Content != want {
+		t.Errorf("resource message = %q, want %q", messages[1].Content, want)
+	}
+	if len(messages[2].Attachments) != 1 || !messages[2].Attachments[0].IsImage() {
+		t.Errorf("image message = %+v, want an image attachment", messages[2])
+	}
+	if messages[3].Role != "assistant" || messages[3].Content != "Sure." {
+		t.Errorf("assistant message = %+v", messages[3])
+	}
+}
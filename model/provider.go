@@ -44,15 +44,65 @@ type Provider interface {
 	GetModelMetadata(ctx context.Context, modelName string) (ModelMetadata, error)
 }
 
+// PricedProvider is implemented by providers whose API publishes model prices
+// (OpenRouter). Prices in the user's [prices] table take precedence.
+type PricedProvider interface {
+	// ModelPrice returns the price of a model, or false if the provider has none.
+	ModelPrice(ctx context.Context, modelName string) (config.ModelPrice, bool)
+}
+
+// TunableProvider is implemented by providers that send sampling settings
+// with their requests. The session's settings are set before each request;
+// a request made with a context from WithGenerationParams uses its own.
+type TunableProvider interface {
+	// SetGenerationParams sets the parameters for the requests that follow.
+	SetGenerationParams(params config.GenerationParams)
+}
+
+type generationParamsKey struct{}
+
+// WithGenerationParams returns a context for a one-off request that uses
+// params instead of what the provider was set to, leaving the provider,
+// which other requests may be using at the same time, untouched
+func WithGenerationParams(ctx context.Context, params config.GenerationParams) context.Context {
+	return context.WithValue(ctx, generationParamsKey{}, params)
+}
+
+// RequestGenerationParams returns the parameters attached to ctx by
+// WithGenerationParams, if any
+func RequestGenerationParams(ctx context.Context) (config.GenerationParams, bool) {
+	params, ok := ctx.Value(generationParamsKey{}).(config.GenerationParams)
+	return params, ok
+}
+
+// CapabilityLoader is implemented by providers that ask their server what a
+// model can do (Ollama). Their GetModelMetadata only uses answers already
+// loaded, so it never blocks; see Model.LoadModelCapabilities.
+type CapabilityLoader interface {
+	// LoadCapabilities fetches and caches the model's capabilities.
+	LoadCapabilities(ctx context.Context, modelName string) error
+}
+
 // ModelMetadata contains metadata about a model's capabilities
 type ModelMetadata struct {
-	ContextWindow int
-	MaxOutput     int
-	SupportsTools bool
+	ContextWindow  int
+	MaxOutput      int
+	SupportsTools  bool
+	SupportsVision bool // Accepts image attachments
 }
 
 // StreamCallback is called for each chunk of streamed response.
-type StreamCallback func(chunk string, toolCalls []ToolCall) error
+// reasoning carries thinking the model reported separately from its answer
+// (empty for models and providers that don't).
+// Providers that report token counts call it once more after the last chunk,
+// with an empty chunk and usage set; usage is nil on every other call.
+type StreamCallback func(chunk, reasoning string, toolCalls []ToolCall, usage *Usage) error
+
+// Usage holds the token counts a provider reported for one response
+type Usage struct {
+	PromptTokens     int // Everything sent: system prompt, tool definitions and history
+	CompletionTokens int // The generated response
+}
 
 // ShouldBlockOnOllamaValidation returns true if Ollama validation errors should prevent saving settings.
 // This is business logic that determines when Ollama must be reachable vs when it's optional.
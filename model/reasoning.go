@@ -0,0 +1,99 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// thinkBlockRegex matches reasoning a model wrote inline, including a block
+// left open by a response that was cut off
+var thinkBlockRegex = regexp.MustCompile(`(?s)<think>.*?(?:</think>|$)`)
+
+// stripThinkBlocks removes inline <think> blocks from an answer. Sessions
+// saved before reasoning was stored separately have them in the content.
+func stripThinkBlocks(content string) string {
+	if !strings.Contains(content, thinkOpenTag) {
+		return content
+	}
+	return strings.TrimSpace(thinkBlockRegex.ReplaceAllString(content, ""))
+}
+
+// thinkSplitter separates reasoning that models write inline between
+// <think> and </think> (DeepSeek R1, QwQ and Qwen3 on servers that don't
+// parse it out) from the answer while streaming. Only a block at the start
+// of the response counts; tags the model mentions later are left alone.
+type thinkSplitter struct {
+	pending  string // Text that may be the start of a tag
+	inThink  bool
+	answered bool // Answer text has been released, stop looking for tags
+}
+
+// Write adds a chunk and returns the answer and reasoning text it completes
+func (s *thinkSplitter) Write(chunk string) (answer, reasoning string) {
+	text := s.pending + chunk
+	s.pending = ""
+
+	var answerBuf, reasoningBuf strings.Builder
+	for text != "" {
+		if s.answered {
+			answerBuf.WriteString(text)
+			break
+		}
+
+		if s.inThink {
+			if i := strings.Index(text, thinkCloseTag); i >= 0 {
+				reasoningBuf.WriteString(text[:i])
+				text = text[i+len(thinkCloseTag):]
+				s.inThink = false
+				continue
+			}
+			keep := partialTagLength(text, thinkCloseTag)
+			reasoningBuf.WriteString(text[:len(text)-keep])
+			s.pending = text[len(text)-keep:]
+			break
+		}
+
+		// Whitespace before the answer (or before <think>) is dropped
+		text = strings.TrimLeft(text, " \t\r\n")
+		if strings.HasPrefix(text, thinkOpenTag) {
+			text = text[len(thinkOpenTag):]
+			s.inThink = true
+			continue
+		}
+		if text != "" && strings.HasPrefix(thinkOpenTag, text) {
+			s.pending = text
+			break
+		}
+		if text != "" {
+			s.answered = true
+		}
+	}
+
+	return answerBuf.String(), reasoningBuf.String()
+}
+
+// Flush returns text held back at the end of the stream
+func (s *thinkSplitter) Flush() (answer, reasoning string) {
+	pending := s.pending
+	s.pending = ""
+	if s.inThink {
+		return "", pending
+	}
+	return pending, ""
+}
+
+// partialTagLength returns how many characters at the end of text could be
+// the beginning of tag
+func partialTagLength(text, tag string) int {
+	for n := min(len(tag)-1, len(text)); n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
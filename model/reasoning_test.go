@@ -0,0 +1,77 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThinkSplitter(t *testing.T) {
+	tests := []struct {
+		name          string
+		chunks        []string
+		wantAnswer    string
+		wantReasoning string
+	}{
+		{
+			name:       "no reasoning",
+			chunks:     []string{"Hello", " world"},
+			wantAnswer: "Hello world",
+		},
+		{
+			name:          "tags split across chunks",
+			chunks:        []string{"<thi", "nk>\nLet me", " think.</th", "ink>\n\nThe answer", " is 4."},
+			wantAnswer:    "The answer is 4.",
+			wantReasoning: "\nLet me think.",
+		},
+		{
+			name:       "tags after the answer started are text",
+			chunks:     []string{"Use ", "<think>", " tags"},
+			wantAnswer: "Use <think> tags",
+		},
+		{
+			name:          "cut off while thinking",
+			chunks:        []string{"<think>still going</thi"},
+			wantReasoning: "still going</thi",
+		},
+		{
+			name:       "lone partial tag",
+			chunks:     []string{"<th"},
+			wantAnswer: "<th",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s thinkSplitter
+			var answer, reasoning strings.Builder
+			for _, chunk := range tt.chunks {
+				a, r := s.Write(chunk)
+				answer.WriteString(a)
+				reasoning.WriteString(r)
+			}
+			a, r := s.Flush()
+			answer.WriteString(a)
+			reasoning.WriteString(r)
+
+			if answer.String() != tt.wantAnswer {
+				t.Errorf("answer = %q, want %q", answer.String(), tt.wantAnswer)
+			}
+			if reasoning.String() != tt.wantReasoning {
+				t.Errorf("reasoning = %q, want %q", reasoning.String(), tt.wantReasoning)
+			}
+		})
+	}
+}
+
+func TestStripThinkBlocks(t *testing.T) {
+	tests := map[string]string{
+		"plain answer":                    "plain answer",
+		"<think>hmm</think>\n\nAnswer":    "Answer",
+		"<think>cut off before answering": "",
+	}
+	for input, want := range tests {
+		if got := stripThinkBlocks(input); got != want {
+			t.Errorf("stripThinkBlocks(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package model
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+
+	"otui/config"
+	"otui/mcp"
+	"otui/storage"
+)
+
+func resourceKey(ref storage.ResourceRef) string {
+	return ref.PluginID + "\n" + ref.URI
+}
+
+// resourceName returns the name a resource is shown under
+func resourceName(ref storage.ResourceRef) string {
+	if ref.Name != "" {
+		return ref.Name
+	}
+	return ref.URI
+}
+
+// ResourceAttachments turns the contents of a resource into attachments:
+// text as text files and images as images. Other binary contents can't be
+// sent to a model.
+func ResourceAttachments(name string, result *mcptypes.ReadResourceResult) ([]Attachment, error) {
+	var attachments []Attachment
+	for _, contents := range result.Contents {
+		// Resources with several parts name each after its own URI
+		partName := name
+		if len(result.Contents) > 1 {
+			partName = resourceContentsURI(contents)
+		}
+
+		var att Attachment
+		switch c := contents.(type) {
+		case mcptypes.TextResourceContents:
+			att = Attachment{Name: partName, MIMEType: "text/plain", Data: []byte(c.Text)}
+		case mcptypes.BlobResourceContents:
+			data, err := base64.StdEncoding.DecodeString(c.Blob)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid contents: %w", partName, err)
+			}
+			switch {
+			case slices.Contains([]string{"image/png", "image/jpeg", "image/gif", "image/webp"}, c.MIMEType):
+				att = Attachment{Name: partName, MIMEType: c.MIMEType, Data: data}
+			case utf8.Valid(data) && !strings.ContainsRune(string(data), 0):
+				att = Attachment{Name: partName, MIMEType: "text/plain", Data: data}
+			default:
+				return nil, fmt.Errorf("%s is %s, which can't be sent to a model", partName, c.MIMEType)
+			}
+		default:
+			continue
+		}
+
+		if len(att.Data) > MaxAttachmentSize {
+			return nil, fmt.Errorf("%s is %d MB; attachments are limited to %d MB",
+				partName, len(att.Data)>>20, MaxAttachmentSize>>20)
+		}
+		attachments = append(attachments, att)
+	}
+
+	if len(attachments) == 0 {
+		return nil, fmt.Errorf("%s is empty", name)
+	}
+	return attachments, nil
+}
+
+func resourceContentsURI(contents mcptypes.ResourceContents) string {
+	switch c := contents.(type) {
+	case mcptypes.TextResourceContents:
+		return c.URI
+	case mcptypes.BlobResourceContents:
+		return c.URI
+	}
+	return ""
+}
+
+// resourceText returns the text of a resource for the prompt. Images and
+// other binary parts are only mentioned.
+func resourceText(result *mcptypes.ReadResourceResult) string {
+	var parts []string
+	for _, contents := range result.Contents {
+		switch c := contents.(type) {
+		case mcptypes.TextResourceContents:
+			parts = append(parts, c.Text)
+		case mcptypes.BlobResourceContents:
+			parts = append(parts, fmt.Sprintf("[%s content not included]", c.MIMEType))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// AttachResourceCmd reads a plugin resource to attach to the next prompt
+func (m *Model) AttachResourceCmd(pluginID, uri, name string) tea.Cmd {
+	manager := m.MCPManager
+	timeout := m.Config.ToolExecutionTimeout()
+	ref := storage.ResourceRef{PluginID: pluginID, URI: uri, Name: name}
+
+	return func() tea.Msg {
+		if manager == nil {
+			return ResourceAttachedMsg{Name: resourceName(ref), Err: fmt.Errorf("plugins are not available")}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		result, err := manager.ReadResource(ctx, pluginID, uri)
+		if err != nil {
+			return ResourceAttachedMsg{Name: resourceName(ref), Err: err}
+		}
+		attachments, err := ResourceAttachments(resourceName(ref), result)
+		return ResourceAttachedMsg{Name: resourceName(ref), Attachments: attachments, Err: err}
+	}
+}
+
+// IsResourcePinned reports whether a resource is pinned to the current session
+func (m *Model) IsResourcePinned(pluginID, uri string) bool {
+	if m.CurrentSession == nil {
+		return false
+	}
+	return slices.ContainsFunc(m.CurrentSession.Resources, func(r storage.ResourceRef) bool {
+		return r.PluginID == pluginID && r.URI == uri
+	})
+}
+
+// TogglePinnedResource pins a resource to the current session, so its
+// contents go with every prompt, or unpins it. The returned command reads a
+// newly pinned resource and subscribes to its updates.
+func (m *Model) TogglePinnedResource(ref storage.ResourceRef) (bool, tea.Cmd) {
+	if m.CurrentSession == nil {
+		return false, nil
+	}
+
+	pinned := !m.IsResourcePinned(ref.PluginID, ref.URI)
+	if pinned {
+		m.CurrentSession.Resources = append(m.CurrentSession.Resources, ref)
+	} else {
+		m.CurrentSession.Resources = slices.DeleteFunc(m.CurrentSession.Resources, func(r storage.ResourceRef) bool {
+			return r.PluginID == ref.PluginID && r.URI == ref.URI
+		})
+		m.setCachedResource(ref, "", false)
+	}
+	m.SessionDirty = true
+
+	manager := m.MCPManager
+	if manager == nil {
+		return pinned, nil
+	}
+	timeout := m.Config.ToolExecutionTimeout()
+	return pinned, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		manager.SyncResourceSubscriptions(ctx)
+		var err error
+		if pinned {
+			err = m.refreshResource(ctx, manager, ref)
+		}
+		return ResourcePinnedMsg{Name: resourceName(ref), Pinned: pinned, Err: err}
+	}
+}
+
+func (m *Model) cachedResource(ref storage.ResourceRef) (string, bool) {
+	m.resourcesMu.Lock()
+	defer m.resourcesMu.Unlock()
+	text, ok := m.resourceCache[resourceKey(ref)]
+	return text, ok
+}
+
+func (m *Model) setCachedResource(ref storage.ResourceRef, text string, keep bool) {
+	m.resourcesMu.Lock()
+	defer m.resourcesMu.Unlock()
+	if !keep {
+		delete(m.resourceCache, resourceKey(ref))
+		return
+	}
+	if m.resourceCache == nil {
+		m.resourceCache = make(map[string]string)
+	}
+	m.resourceCache[resourceKey(ref)] = text
+}
+
+// refreshResource reads a pinned resource into the cache
+func (m *Model) refreshResource(ctx context.Context, manager *mcp.MCPManager, ref storage.ResourceRef) error {
+	result, err := manager.ReadResource(ctx, ref.PluginID, ref.URI)
+	if err != nil {
+		return err
+	}
+	m.setCachedResource(ref, resourceText(result), true)
+	return nil
+}
+
+// WatchResourceUpdatesCmd waits for a subscribed resource to change and, if
+// it is pinned to the current session, reads it again. The UI runs it again
+// after each ResourceUpdatedMsg.
+func (m *Model) WatchResourceUpdatesCmd() tea.Cmd {
+	manager := m.MCPManager
+	if manager == nil {
+		return nil
+	}
+	timeout := m.Config.ToolExecutionTimeout()
+
+	return func() tea.Msg {
+		for update := range manager.ResourceUpdates() {
+			var ref storage.ResourceRef
+			if session := m.CurrentSession; session != nil {
+				for _, r := range session.Resources {
+					if r.PluginID == update.PluginID && r.URI == update.URI {
+						ref = r
+					}
+				}
+			}
+			if ref.URI == "" {
+				continue // Unpinned since it was subscribed
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			err := m.refreshResource(ctx, manager, ref)
+			cancel()
+			if err != nil && config.DebugLog != nil {
+				config.DebugLog.Printf("[Resources] Failed to refresh %s: %v", ref.URI, err)
+			}
+			return ResourceUpdatedMsg{Name: resourceName(ref), Err: err}
+		}
+		return nil
+	}
+}
+
+// pinnedResourceContext returns the contents of the resources pinned to a
+// session as a system message, reading those that aren't cached yet.
+// Resources that can't be read are mentioned rather than left out silently.
+func (m *Model) pinnedResourceContext(ctx context.Context, manager *mcp.MCPManager, refs []storage.ResourceRef) string {
+	if len(refs) == 0 || manager == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("The user pinned these resources to the conversation. Their current contents:")
+	for _, ref := range refs {
+		text, ok := m.cachedResource(ref)
+		if !ok {
+			if err := m.refreshResource(ctx, manager, ref); err != nil {
+				fmt.Fprintf(&b, "\n\nResource: %s (%s) could not be read: %v", resourceName(ref), ref.URI, err)
+				continue
+			}
+			text, _ = m.cachedResource(ref)
+		}
+		fmt.Fprintf(&b, "\n\nResource: %s (%s)\n```\n%s\n```", resourceName(ref), ref.URI, strings.TrimRight(text, "\n"))
+	}
+	return b.String()
+}
+
+// withResourceContext adds the pinned resources after the system messages
+func withResourceContext(messages []Message, resourceContext string) []Message {
+	if resourceContext == "" {
+		return messages
+	}
+	i := 0
+	for i < len(messages) && messages[i].Role == "system" {
+		i++
+	}
+	return slices.Insert(messages, i, Message{Role: "system", Content: resourceContext})
+}
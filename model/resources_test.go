@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestResourceAttachments(t *testing.T) {
+	blob := func(mimeType string, data []byte) mcptypes.BlobResourceContents {
+		return mcptypes.BlobResourceContents{URI: "note://blob", MIMEType: mimeType, Blob: base64.StdEncoding.EncodeToString(data)}
+	}
+	result := func(contents ...mcptypes.ResourceContents) *mcptypes.ReadResourceResult {
+		return &mcptypes.ReadResourceResult{Contents: contents}
+	}
+
+	t.Run("text", func(t *testing.T) {
+		attachments, err := ResourceAttachments("Todo", result(mcptypes.TextResourceContents{URI: "note://todo", Text: "buy milk"}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(attachments) != 1 || attachments[0].Name != "Todo" || attachments[0].MIMEType != "text/plain" || string(attachments[0].Data) != "buy milk" {
+			t.Errorf("attachments = %+v", attachments)
+		}
+	})
+
+	t.Run("image", func(t *testing.T) {
+		attachments, err := ResourceAttachments("Logo", result(blob("image/png", []byte{0x89, 'P', 'N', 'G'})))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(attachments) != 1 || !attachments[0].IsImage() {
+			t.Errorf("attachments = %+v, want one image", attachments)
+		}
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		if _, err := ResourceAttachments("Archive", result(blob("application/zip", []byte{'P', 'K', 0, 0}))); err == nil {
+			t.Error("expected an error for binary contents")
+		}
+	})
+
+	t.Run("too_large", func(t *testing.T) {
+		text := strings.Repeat("x", MaxAttachmentSize+1)
+		if _, err := ResourceAttachments("Big", result(mcptypes.TextResourceContents{Text: text})); err == nil {
+			t.Error("expected an error over the attachment limit")
+		}
+	})
+
+	t.Run("several_parts", func(t *testing.T) {
+		attachments, err := ResourceAttachments("Dir", result(
+			mcptypes.TextResourceContents{URI: "file:///a.txt", Text: "a"},
+			mcptypes.TextResourceContents{URI: "file:///b.txt", Text: "b"},
+		))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(attachments) != 2 || attachments[0].Name != "file:///a.txt" || attachments[1].Name != "file:///b.txt" {
+			t.Errorf("attachments = %+v, want one per part named by URI", attachments)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		if _, err := ResourceAttachments("Nothing", result()); err == nil {
+			t.Error("expected an error for an empty resource")
+		}
+	})
+}
+
+func TestWithResourceContext(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "Be terse."},
+		{Role: "user", Content: "What's on my list?"},
+	}
+
+	got := withResourceContext(messages, "Resource: Todo")
+	if len(got) != 3 || got[1].Role != "system" || got[1].Content != "Resource: Todo" || got[2].Role != "user" {
+		t.Errorf("resource context not placed after the system prompt: %+v", got)
+	}
+
+	if got := withResourceContext(messages[:1], ""); len(got) != 1 {
+		t.Errorf("empty context added a message: %+v", got)
+	}
+}
@@ -0,0 +1,233 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+
+	"otui/config"
+)
+
+// APIError is an error response from a provider's API. Providers wrap HTTP
+// errors in it so failed requests can be retried (see config.RetryConfig).
+type APIError struct {
+	StatusCode int
+	RetryAfter time.Duration // How long the server asked us to wait (0 if it didn't say)
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Responder identifies the provider and model that produced a response.
+// It differs from the session's model when a fallback answered.
+type Responder struct {
+	Provider string // Provider ID
+	Model    string // InternalName
+}
+
+// chatTarget is a model a turn's requests can be sent to
+type chatTarget struct {
+	Responder
+	client  Provider
+	params  config.GenerationParams
+	timeout time.Duration
+}
+
+// chatTargets returns where the current session's requests go: client with
+// the session's model, then the session's fallback list or, if it has none,
+// the [[fallback]] list from config.toml. Fallbacks on providers that aren't
+// set up are skipped.
+func (m *Model) chatTargets(client Provider) []chatTarget {
+	providerID := m.sessionProviderID()
+	primary := chatTarget{
+		Responder: Responder{Provider: providerID, Model: client.GetModel()},
+		client:    client,
+		params:    m.GenerationParams(),
+		timeout:   m.Config.GenerationTimeout(providerID),
+	}
+	targets := []chatTarget{primary}
+
+	fallbacks := m.Config.Fallback
+	if m.CurrentSession != nil && len(m.CurrentSession.Fallback) > 0 {
+		fallbacks = m.CurrentSession.Fallback
+	}
+	for _, fb := range fallbacks {
+		fbClient, ok := m.Providers[fb.Provider]
+		if !ok {
+			if config.DebugLog != nil {
+				config.DebugLog.Printf("[Model] Skipping fallback %s: provider not enabled", fb)
+			}
+			continue
+		}
+		if fb.Provider == primary.Provider && fb.Model == primary.Model {
+			continue
+		}
+		targets = append(targets, chatTarget{
+			Responder: Responder{Provider: fb.Provider, Model: fb.Model},
+			client:    fbClient,
+			params:    m.GenerationParams(),
+			timeout:   m.Config.GenerationTimeout(fb.Provider),
+		})
+	}
+	return targets
+}
+
+// use points the target's provider at its model and sampling settings
+func (t chatTarget) use() {
+	t.client.SetModel(t.Model)
+	if tunable, ok := t.client.(TunableProvider); ok {
+		tunable.SetGenerationParams(t.params)
+	}
+}
+
+// chatWithFallback sends messages to each target in turn until one answers.
+// Transient failures are retried with exponential backoff first; other
+// failures move straight on to the next target. Nothing is retried once part
+// of a response has arrived, so the UI never shows a response twice, and
+// neither a timeout nor a stopped turn is retried. reset is called before
+// every try to drop what the last one collected. Retries and fallbacks are
+// announced on stream with RetryNoticeMsg.
+//
+// It returns the target that answered; on failure, the error of the last
+// try (a *TimeoutError if it ran out of time).
+func chatWithFallback(ctx context.Context, stream *ResponseStream, targets []chatTarget, retry config.RetryConfig,
+	messages []Message, tools []mcptypes.Tool, reset func(), callback StreamCallback) (chatTarget, error) {
+	// Later requests go to the session's own model again
+	defer targets[0].use()
+
+	var err error
+	for i, target := range targets {
+		target.use()
+		for attempt := 1; ; attempt++ {
+			var received bool
+			reset()
+			genCtx, cancelGen := context.WithTimeout(ctx, target.timeout)
+			err = target.client.ChatWithTools(genCtx, messages, tools, func(chunk, reasoning string, toolCalls []ToolCall, usage *Usage) error {
+				received = true
+				if err := callback(chunk, reasoning, toolCalls, usage); err != nil {
+					return err
+				}
+				return genCtx.Err()
+			})
+			timeoutErr := timeoutError(genCtx, "generation", target.Provider, target.timeout)
+			cancelGen()
+
+			switch {
+			case err == nil:
+				return target, nil
+			case timeoutErr != nil:
+				return target, timeoutErr
+			case received || ctx.Err() != nil:
+				return target, err
+			}
+
+			if config.DebugLog != nil {
+				config.DebugLog.Printf("[Model] %s (%s) attempt %d failed: %v", target.Model, target.Provider, attempt, err)
+			}
+
+			wait, ok := retryDelay(err, attempt, retry)
+			if !ok {
+				break
+			}
+			stream.send(RetryNoticeMsg{
+				Notice: fmt.Sprintf("⚠️ %s: %s, retrying in %s (attempt %d of %d)",
+					target.Model, failureReason(err), wait.Round(100*time.Millisecond), attempt+1, retry.Attempts()),
+				Stream: stream,
+			})
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return target, ctx.Err()
+			}
+		}
+
+		if i+1 < len(targets) {
+			next := targets[i+1]
+			stream.send(RetryNoticeMsg{
+				Notice: fmt.Sprintf("⚠️ %s: %s, falling back to %s (%s)", target.Model, failureReason(err), next.Model, next.Provider),
+				Stream: stream,
+			})
+		}
+	}
+
+	last := targets[len(targets)-1]
+	if len(targets) > 1 {
+		err = fmt.Errorf("all %d models failed, last %s (%s): %w", len(targets), last.Model, last.Provider, err)
+	}
+	return last, err
+}
+
+// retryDelay returns how long to wait before trying the same model again
+// after attempt failed with err, or false if it shouldn't be retried. A
+// server asking for a longer wait than max_backoff is given up on.
+func retryDelay(err error, attempt int, retry config.RetryConfig) (time.Duration, bool) {
+	if attempt >= retry.Attempts() || !isTransient(err) {
+		return 0, false
+	}
+	wait := retry.Backoff(attempt)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+		if apiErr.RetryAfter > retry.MaxWait() {
+			return 0, false
+		}
+		wait = apiErr.RetryAfter
+	}
+	return wait, true
+}
+
+// isTransient reports whether a failed request may succeed if sent again:
+// rate limits, server errors and connection failures
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+			http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout, 529: // 529: Anthropic is overloaded
+			return true
+		}
+		return false
+	}
+	return isConnectionError(err)
+}
+
+// isConnectionError reports whether err means the server couldn't be reached
+// or dropped the connection
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// failureReason describes why a request failed, for retry notices
+func failureReason(err error) string {
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests:
+		return "rate limited"
+	case errors.As(err, &apiErr):
+		return fmt.Sprintf("HTTP %d", apiErr.StatusCode)
+	case isConnectionError(err):
+		return "unreachable"
+	default:
+		return "failed"
+	}
+}
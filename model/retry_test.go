@@ -0,0 +1,154 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+
+	"otui/config"
+)
+
+// flakyProvider fails with each of errs in turn, then answers like stubProvider
+type flakyProvider struct {
+	stubProvider
+	errs    []error
+	partial bool // stream a chunk before failing
+	calls   int
+}
+
+func (p *flakyProvider) ChatWithTools(ctx context.Context, messages []Message, tools []mcptypes.Tool, callback StreamCallback) error {
+	p.calls++
+	if p.calls <= len(p.errs) {
+		if p.partial {
+			callback("par", "", nil, nil)
+		}
+		return p.errs[p.calls-1]
+	}
+	return p.stubProvider.ChatWithTools(ctx, messages, tools, callback)
+}
+
+func TestRetryAndFallback(t *testing.T) {
+	unavailable := &APIError{StatusCode: http.StatusServiceUnavailable, Err: errors.New("503 Service Unavailable")}
+
+	t.Run("transient error retried", func(t *testing.T) {
+		p := &flakyProvider{stubProvider: stubProvider{response: "42"}, errs: []error{unavailable}}
+		m := newHeadlessTestModel(p)
+
+		var events []HeadlessEvent
+		answer, err := m.RunHeadless("question", HeadlessOptions{
+			OnEvent: func(ev HeadlessEvent) { events = append(events, ev) },
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if answer != "42" || p.calls != 2 {
+			t.Errorf("answer = %q after %d calls, want %q after 2", answer, p.calls, "42")
+		}
+		if len(events) != 2 || events[0].Type != "retry" {
+			t.Errorf("unexpected events: %+v", events)
+		}
+	})
+
+	t.Run("fallback answers", func(t *testing.T) {
+		primary := &stubProvider{err: &APIError{StatusCode: http.StatusPaymentRequired, Err: errors.New("402 Payment Required")}}
+		backup := &stubProvider{response: "from backup"}
+		m := newHeadlessTestModel(primary)
+		m.Providers["backup"] = backup
+		m.CurrentSession.Fallback = []config.FallbackTarget{
+			{Provider: "missing", Model: "skipped"},
+			{Provider: "backup", Model: "backup-model"},
+		}
+
+		answer, err := m.RunHeadless("question", HeadlessOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if answer != "from backup" {
+			t.Errorf("answer = %q, want %q", answer, "from backup")
+		}
+		want := Responder{Provider: "backup", Model: "backup-model"}
+		if got := m.Messages[len(m.Messages)-1].AnsweredBy; got != want {
+			t.Errorf("AnsweredBy = %+v, want %+v", got, want)
+		}
+		if got := m.Messages[len(m.Messages)-1].ToStorage(); got.Provider != "backup" || got.Model != "backup-model" {
+			t.Errorf("stored responder = %s/%s, want backup/backup-model", got.Provider, got.Model)
+		}
+	})
+
+	t.Run("config fallback when session has none", func(t *testing.T) {
+		primary := &stubProvider{err: unavailable}
+		backup := &stubProvider{response: "from backup"}
+		m := newHeadlessTestModel(primary)
+		m.Providers["backup"] = backup
+		m.Config.Retry = config.RetryConfig{MaxAttempts: 1}
+		m.Config.Fallback = []config.FallbackTarget{{Provider: "backup", Model: "backup-model"}}
+
+		if answer, err := m.RunHeadless("question", HeadlessOptions{}); err != nil || answer != "from backup" {
+			t.Errorf("RunHeadless = %q, %v; want the backup's answer", answer, err)
+		}
+		if primary.model != "stub-model" {
+			t.Errorf("primary model = %q, want it restored to stub-model", primary.model)
+		}
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		m := newHeadlessTestModel(&stubProvider{err: unavailable})
+		m.Providers["backup"] = &stubProvider{err: unavailable}
+		m.Config.Retry = config.RetryConfig{MaxAttempts: 1}
+		m.CurrentSession.Fallback = []config.FallbackTarget{{Provider: "backup", Model: "backup-model"}}
+
+		_, err := m.RunHeadless("question", HeadlessOptions{})
+		if !errors.Is(err, ErrModelFailed) || !errors.Is(err, unavailable) {
+			t.Errorf("expected ErrModelFailed wrapping the last error, got %v", err)
+		}
+	})
+
+	t.Run("partial response not retried", func(t *testing.T) {
+		p := &flakyProvider{stubProvider: stubProvider{response: "42"}, errs: []error{unavailable}, partial: true}
+		m := newHeadlessTestModel(p)
+
+		if _, err := m.RunHeadless("question", HeadlessOptions{}); !errors.Is(err, unavailable) {
+			t.Errorf("expected the provider error, got %v", err)
+		}
+		if p.calls != 1 {
+			t.Errorf("provider called %d times, want 1", p.calls)
+		}
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	retry := config.RetryConfig{MaxAttempts: 3, InitialBackoff: 1, MaxBackoff: 10}
+	refused := fmt.Errorf("dial: %w", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED})
+
+	tests := []struct {
+		name    string
+		err     error
+		attempt int
+		want    time.Duration
+		retried bool
+	}{
+		{"rate limited", &APIError{StatusCode: 429}, 1, time.Second, true},
+		{"backoff doubles", &APIError{StatusCode: 500}, 2, 2 * time.Second, true},
+		{"retry-after honoured", &APIError{StatusCode: 429, RetryAfter: 5 * time.Second}, 1, 5 * time.Second, true},
+		{"retry-after too long", &APIError{StatusCode: 429, RetryAfter: time.Minute}, 1, 0, false},
+		{"unreachable", refused, 1, time.Second, true},
+		{"out of attempts", &APIError{StatusCode: 503}, 3, 0, false},
+		{"client error", &APIError{StatusCode: 400}, 1, 0, false},
+		{"cancelled", context.Canceled, 1, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, retried := retryDelay(tt.err, tt.attempt, retry)
+			if got != tt.want || retried != tt.retried {
+				t.Errorf("retryDelay = %v, %v; want %v, %v", got, retried, tt.want, tt.retried)
+			}
+		})
+	}
+}
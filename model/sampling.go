@@ -0,0 +1,123 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+
+	"otui/mcp"
+)
+
+// WatchPluginRequestsCmd waits for the next sampling or elicitation request
+// of a running plugin. Plugins wait for an answer, so it is started again
+// once the request has been answered.
+func (m *Model) WatchPluginRequestsCmd() tea.Cmd {
+	manager := m.MCPManager
+	if manager == nil {
+		return nil
+	}
+	requests := manager.PluginRequests()
+
+	return func() tea.Msg {
+		for request := range requests {
+			switch r := request.(type) {
+			case *mcp.SamplingRequest:
+				return SamplingRequestMsg{Request: r}
+			case *mcp.ElicitationRequest:
+				return ElicitationRequestMsg{Request: r}
+			}
+		}
+		return nil
+	}
+}
+
+// SamplingMessages turns the conversation a plugin asks to complete into
+// messages for the provider, starting with its system prompt if it gave one
+func SamplingMessages(params mcptypes.CreateMessageParams) []Message {
+	var messages []Message
+	if params.SystemPrompt != "" {
+		messages = append(messages, Message{Role: "system", Content: params.SystemPrompt, Timestamp: time.Now()})
+	}
+	for _, sm := range params.Messages {
+		if msg, ok := contentMessage(sm.Role, sm.Content); ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// RunSamplingCmd answers an approved sampling request with a completion from
+// the session's model. The plugin's token limit and temperature replace the
+// session's own for this request.
+func (m *Model) RunSamplingCmd(request *mcp.SamplingRequest) tea.Cmd {
+	provider := m.Provider
+	session := m.CurrentSession
+	providerID := m.sessionProviderID()
+	timeout := m.Config.GenerationTimeout(providerID)
+
+	params := m.GenerationParams()
+	if request.Params.MaxTokens > 0 {
+		params.MaxTokens = request.Params.MaxTokens
+	}
+	if request.Params.Temperature > 0 {
+		temperature := request.Params.Temperature
+		params.Temperature = &temperature
+	}
+
+	messages := SamplingMessages(request.Params)
+	var attachments []Attachment
+	for _, msg := range messages {
+		attachments = append(attachments, msg.Attachments...)
+	}
+	attachErr := m.CheckAttachments(attachments)
+
+	return func() tea.Msg {
+		err := attachErr
+		if err == nil && provider == nil {
+			err = fmt.Errorf("no model is available")
+		}
+		if err == nil && len(messages) == 0 {
+			err = fmt.Errorf("the request has no messages")
+		}
+		if err != nil {
+			request.Respond(nil, err)
+			return SamplingCompleteMsg{PluginID: request.PluginID, Err: err}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var response strings.Builder
+		var usage Usage
+		callback := func(chunk, reasoning string, toolCalls []ToolCall, reported *Usage) error {
+			if reported != nil {
+				usage = *reported
+			}
+			response.WriteString(chunk)
+			return nil
+		}
+
+		if err := provider.Chat(WithGenerationParams(ctx, params), messages, callback); err != nil {
+			if timeoutErr := timeoutError(ctx, "sampling", provider.GetModel(), timeout); timeoutErr != nil {
+				err = timeoutErr
+			}
+			request.Respond(nil, fmt.Errorf("sampling failed: %w", err))
+			return SamplingCompleteMsg{PluginID: request.PluginID, Err: err}
+		}
+		m.recordUsage(ctx, session, provider, Responder{Provider: providerID, Model: provider.GetModel()}, usage)
+
+		request.Respond(&mcptypes.CreateMessageResult{
+			SamplingMessage: mcptypes.SamplingMessage{
+				Role:    mcptypes.RoleAssistant,
+				Content: mcptypes.NewTextContent(strings.TrimSpace(stripThinkBlocks(response.String()))),
+			},
+			Model:      provider.GetModel(),
+			StopReason: "endTurn",
+		}, nil)
+		return SamplingCompleteMsg{PluginID: request.PluginID}
+	}
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSamplingMessages(t *testing.T) {
+	messages := SamplingMessages(mcptypes.CreateMessageParams{
+		SystemPrompt: "Be brief",
+		Messages: []mcptypes.SamplingMessage{
+			{Role: mcptypes.RoleUser, Content: mcptypes.NewTextContent("What is 6 x 7?")},
+			{Role: mcptypes.RoleAssistant, Content: mcptypes.NewTextContent("42")},
+			{Role: mcptypes.RoleUser, Content: map[string]any{"type": "unknown"}},
+		},
+	})
+
+	if len(messages) != 3 {
+		t.Fatalf("got %d messages, want 3 (unknown content dropped)", len(messages))
+	}
+	if messages[0].Role != "system" || messages[0].Content != "Be brief" {
+		t.Errorf("system message = %+v", messages[0])
+	}
+	if messages[2].Role != "assistant" || messages[2].Content != "42" {
+		t.Errorf("assistant message = %+v", messages[2])
+	}
+}
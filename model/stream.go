@@ -0,0 +1,322 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ResponseStream delivers a running provider request to the UI as it happens.
+//
+// The request runs in its own goroutine and pushes intermediate messages
+// (StreamChunkMsg, ToolResultsMsg) followed by exactly one final message
+// (StreamDoneMsg, ToolCallsDetectedMsg, ToolExecutionCompleteMsg, ...).
+// The UI pulls them one at a time with Next, so every chunk reaches the
+// viewport as soon as the provider produces it.
+type ResponseStream struct {
+	msgs chan tea.Msg
+	done chan struct{} // Closed on Cancel
+
+	mu        sync.Mutex
+	cancelled bool
+	cancel    context.CancelFunc
+}
+
+// newResponseStream creates an idle stream; the request starts when the
+// command returned by start is executed
+func newResponseStream() *ResponseStream {
+	return &ResponseStream{
+		msgs: make(chan tea.Msg, 64),
+		done: make(chan struct{}),
+	}
+}
+
+// start returns a command that runs fn in the background with a context
+// derived from parent, and yields the stream's first message. fn sends
+// intermediate messages with send and returns the final one. fn applies
+// its own timeouts, since generation and tool calls have different limits.
+func (s *ResponseStream) start(parent context.Context, fn func(ctx context.Context) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		s.mu.Lock()
+		// Cancel may have been requested before the command ran
+		if s.cancelled {
+			s.mu.Unlock()
+			return nil
+		}
+		ctx, cancel := context.WithCancel(parent)
+		s.cancel = cancel
+		s.mu.Unlock()
+
+		go func() {
+			defer cancel()
+			defer close(s.msgs)
+			s.send(fn(ctx))
+		}()
+
+		return s.next()
+	}
+}
+
+// send queues msg for the UI. Messages sent after Cancel are dropped so the
+// producer never blocks on a reader that has gone away.
+func (s *ResponseStream) send(msg tea.Msg) {
+	select {
+	case s.msgs <- msg:
+	case <-s.done:
+	}
+}
+
+// Next returns a command that waits for the stream's next message.
+// It yields nil once the stream is finished or cancelled.
+func (s *ResponseStream) Next() tea.Cmd {
+	return s.next
+}
+
+func (s *ResponseStream) next() tea.Msg {
+	select {
+	case msg, ok := <-s.msgs:
+		if !ok {
+			return nil
+		}
+		// A message may have been buffered just before cancellation
+		select {
+		case <-s.done:
+			return nil
+		default:
+			return msg
+		}
+	case <-s.done:
+		return nil
+	}
+}
+
+// Cancel aborts the request. Safe to call more than once.
+func (s *ResponseStream) Cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.cancelled {
+		s.cancelled = true
+		close(s.done)
+	}
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// beginTurn starts a new user turn. Every step of the turn (the first
+// response, tool runs and follow-up responses) shares the returned context,
+// so a single CancelStream stops all of them.
+func (m *Model) beginTurn() context.Context {
+	if m.turnCancel != nil {
+		m.turnCancel()
+	}
+	m.turnCtx, m.turnCancel = context.WithCancel(context.Background())
+	return m.turnCtx
+}
+
+// turnContext returns the current turn's context, starting a new turn if
+// there is none or the last one was cancelled
+func (m *Model) turnContext() context.Context {
+	if m.turnCtx == nil || m.turnCtx.Err() != nil {
+		return m.beginTurn()
+	}
+	return m.turnCtx
+}
+
+// CancelStream stops the current turn: the in-flight provider request and
+// any running tool calls. MCP plugins keep running; a cancelled tool call
+// only abandons the pending response.
+func (m *Model) CancelStream() {
+	if m.turnCancel != nil {
+		m.turnCancel()
+	}
+	if m.ActiveStream == nil {
+		return
+	}
+	m.ActiveStream.Cancel()
+	m.ActiveStream = nil
+}
+
+// TimeoutError reports that a provider or plugin didn't answer within its
+// configured timeout (see config.TimeoutConfig)
+type TimeoutError struct {
+	Operation string // What timed out: "generation", "compaction", ...
+	Name      string // Provider ID or tool name
+	Timeout   time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s (%s) timed out after %s", e.Operation, e.Name, e.Timeout)
+}
+
+// Unwrap lets errors.Is(err, context.DeadlineExceeded) match timeouts
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// timeoutError returns a *TimeoutError if ctx ran out of time, nil otherwise.
+// A cancelled parent (the user stopped the turn) is not a timeout.
+func timeoutError(ctx context.Context, operation, name string, timeout time.Duration) *TimeoutError {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil
+	}
+	return &TimeoutError{Operation: operation, Name: name, Timeout: timeout}
+}
+
+// leakOpenerRegex matches the start of a leaked tool call
+var leakOpenerRegex = regexp.MustCompile(`(?:\[\s*)?\{\s*"name"\s*:|<(?:tool_call|function_call|function=|system-reminder)`)
+
+// leakFilter keeps leaked tool call text out of the viewport while streaming.
+// Text from the start of a possible leak is held back until the leak is
+// complete (and dropped) or turns out to be ordinary text; everything before
+// it is released as it arrives.
+type leakFilter struct {
+	held string // Text from the start of a possible leak, not yet released
+}
+
+// Write adds a chunk and returns the text that is now safe to display
+func (f *leakFilter) Write(chunk string) string {
+	f.held += chunk
+
+	var released strings.Builder
+	for f.held != "" {
+		start, full := leakStart(f.held)
+		released.WriteString(f.held[:start])
+		f.held = f.held[start:]
+		if f.held == "" || !full {
+			break
+		}
+
+		end, decided := leakEnd(f.held)
+		if !decided {
+			break
+		}
+		if n := leakLength(f.held[:end]); n > 0 {
+			f.held = f.held[n:]
+			continue
+		}
+		// Not a tool call: release the opener and look further on
+		released.WriteString(f.held[:1])
+		f.held = f.held[1:]
+	}
+	return released.String()
+}
+
+// leakStart returns where a possible leak starts in text, len(text) if
+// nowhere. full is false when only the last few characters may be the
+// beginning of an opener.
+func leakStart(text string) (start int, full bool) {
+	if loc := leakOpenerRegex.FindStringIndex(text); loc != nil {
+		return loc[0], true
+	}
+
+	// A leak may be starting in the last few characters
+	i := strings.LastIndexAny(text, "[{<")
+	if i < 0 || len(text)-i > 32 || strings.ContainsAny(text[i+1:], "]}>") {
+		return len(text), false
+	}
+	// Hold back "[ {" together
+	if text[i] == '{' {
+		j := len(strings.TrimRight(text[:i], " \t"))
+		if j > 0 && text[j-1] == '[' {
+			i = j - 1
+		}
+	}
+	return i, false
+}
+
+// leakClosers are the tags that end the XML-style leaks, by opener
+var leakClosers = []struct{ opener, closer string }{
+	{"<tool_call", "</tool_call>"},
+	{"<function_call", "</function_call>"},
+	{"<function=", "</function>"},
+	{"<system-reminder", "</system-reminder>"},
+}
+
+// leakEnd returns where the possible leak at the start of held ends, once
+// that is known. An end of 0 means held can no longer be a tool call.
+func leakEnd(held string) (end int, decided bool) {
+	if held[0] != '<' {
+		i := strings.IndexByte(held, '{')
+		end, decided = jsonObjectEnd(held[i:])
+		if !decided || end == 0 {
+			return end, decided
+		}
+		end += i
+		if held[0] == '[' {
+			rest := held[end:]
+			trimmed := strings.TrimLeft(rest, " \t\r\n")
+			if trimmed == "" {
+				return 0, false
+			}
+			if trimmed[0] == ']' {
+				end += len(rest) - len(trimmed) + 1
+			}
+		}
+		return end, true
+	}
+
+	for _, tag := range leakClosers {
+		if !strings.HasPrefix(held, tag.opener) {
+			continue
+		}
+		i := strings.Index(held, tag.closer)
+		if i < 0 {
+			return 0, false
+		}
+		end = i + len(tag.closer)
+		// qwen3-coder may close the surrounding <tool_call> as well
+		if tag.opener == "<function=" {
+			const wrapper = "</tool_call>"
+			rest := held[end:]
+			if len(rest) < len(wrapper) && strings.HasPrefix(wrapper, rest) {
+				return 0, false
+			}
+			if strings.HasPrefix(rest, wrapper) {
+				end += len(wrapper)
+			}
+		}
+		return end, true
+	}
+	return 0, true
+}
+
+// jsonObjectEnd returns the length of the JSON object text starts with, once
+// its braces are balanced. Tool call arguments are flat, so deeper nesting
+// means text isn't one (an end of 0).
+func jsonObjectEnd(text string) (end int, decided bool) {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+			if depth > 2 {
+				return 0, true
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// leakLength returns the length of the leaked tool call text starts with, 0
+// if it doesn't start with one
+func leakLength(text string) int {
+	for _, re := range []*regexp.Regexp{leakedJSONArrayRegex, leakedJSONObjRegex, leakedXMLRegex, leakedQwenXMLRegex, leakedSysReminderRegex} {
+		if loc := re.FindStringIndex(text); loc != nil && loc[0] == 0 {
+			return loc[1]
+		}
+	}
+	return 0
+}
@@ -0,0 +1,210 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// drainStream reads messages until the stream yields nil
+func drainStream(t *testing.T, s *ResponseStream, first tea.Msg) []tea.Msg {
+	t.Helper()
+
+	var msgs []tea.Msg
+	for msg := first; msg != nil; msg = s.Next()() {
+		msgs = append(msgs, msg)
+		if len(msgs) > 1000 {
+			t.Fatal("stream did not finish")
+		}
+	}
+	return msgs
+}
+
+func TestResponseStreamOrdering(t *testing.T) {
+	// More chunks than the channel buffers, so the producer has to wait
+	const chunkCount = 200
+
+	s := newResponseStream()
+	first := s.start(context.Background(), func(ctx context.Context) tea.Msg {
+		for i := 0; i < chunkCount; i++ {
+			s.send(StreamChunkMsg{Chunk: fmt.Sprint(i), Stream: s})
+		}
+		return StreamDoneMsg{FullResponse: "done"}
+	})()
+
+	msgs := drainStream(t, s, first)
+	if len(msgs) != chunkCount+1 {
+		t.Fatalf("got %d messages, want %d", len(msgs), chunkCount+1)
+	}
+	for i := 0; i < chunkCount; i++ {
+		chunk, ok := msgs[i].(StreamChunkMsg)
+		if !ok || chunk.Chunk != fmt.Sprint(i) {
+			t.Fatalf("message %d = %#v, want chunk %d", i, msgs[i], i)
+		}
+	}
+	if done, ok := msgs[chunkCount].(StreamDoneMsg); !ok || done.FullResponse != "done" {
+		t.Errorf("last message = %#v, want StreamDoneMsg", msgs[chunkCount])
+	}
+}
+
+func TestResponseStreamCancelBeforeStart(t *testing.T) {
+	s := newResponseStream()
+	s.Cancel()
+
+	ran := false
+	msg := s.start(context.Background(), func(ctx context.Context) tea.Msg {
+		ran = true
+		return StreamDoneMsg{}
+	})()
+
+	if msg != nil {
+		t.Errorf("first message = %#v, want nil", msg)
+	}
+	if ran {
+		t.Error("request should not run after Cancel")
+	}
+}
+
+func TestResponseStreamCancelWhileSending(t *testing.T) {
+	s := newResponseStream()
+	finished := make(chan error, 1)
+
+	first := s.start(context.Background(), func(ctx context.Context) tea.Msg {
+		// Never read beyond the first message, so send blocks once the buffer is full
+		for i := 0; i < 1000; i++ {
+			s.send(StreamChunkMsg{Chunk: "x", Stream: s})
+		}
+		finished <- ctx.Err()
+		return StreamDoneMsg{}
+	})()
+	if _, ok := first.(StreamChunkMsg); !ok {
+		t.Fatalf("first message = %#v, want StreamChunkMsg", first)
+	}
+
+	s.Cancel()
+
+	select {
+	case err := <-finished:
+		if err == nil {
+			t.Error("request context should be cancelled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("producer still blocked after Cancel")
+	}
+
+	if msg := s.Next()(); msg != nil {
+		t.Errorf("Next after Cancel = %#v, want nil", msg)
+	}
+}
+
+func TestResponseStreamNextAfterClose(t *testing.T) {
+	s := newResponseStream()
+	first := s.start(context.Background(), func(ctx context.Context) tea.Msg {
+		return StreamDoneMsg{FullResponse: "only"}
+	})()
+
+	if _, ok := first.(StreamDoneMsg); !ok {
+		t.Fatalf("first message = %#v, want StreamDoneMsg", first)
+	}
+	for i := 0; i < 2; i++ {
+		if msg := s.Next()(); msg != nil {
+			t.Errorf("Next after close = %#v, want nil", msg)
+		}
+	}
+}
+
+func TestLeakFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{
+			name:   "plain text",
+			chunks: []string{"Hello ", "world"},
+			want:   "Hello world",
+		},
+		{
+			name:   "json tool call",
+			chunks: []string{"Let me check.", ` {"na`, `me": "read_file", "arguments": {"path": "a"}}`, " Done."},
+			want:   "Let me check.  Done.",
+		},
+		{
+			name:   "xml tool call",
+			chunks: []string{"Checking <func", "tion=read_file><parameter=path>a</parameter></function>"},
+			want:   "Checking ",
+		},
+		{
+			name:   "brace that is not a tool call",
+			chunks: []string{"Use {", "x} here"},
+			want:   "Use {x} here",
+		},
+		{
+			name:   "json array tool call",
+			chunks: []string{"Reading [ ", `{"name": "read_file", "input": {"path": "a"}}`, " ]", " now"},
+			want:   "Reading  now",
+		},
+		{
+			name:   "system reminder",
+			chunks: []string{"Sure.<system-", "reminder>hidden</system-reminder> Next"},
+			want:   "Sure. Next",
+		},
+		{
+			name:   "nested json",
+			chunks: []string{`Config: {"name": {"first": {"x": 1}}}`, " ok"},
+			want:   `Config: {"name": {"first": {"x": 1}}} ok`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f leakFilter
+			var got string
+			for _, chunk := range tt.chunks {
+				got += f.Write(chunk)
+			}
+			if got != tt.want {
+				t.Errorf("streamed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeakFilterReleasesOrdinaryJSON(t *testing.T) {
+	var f leakFilter
+	if got := f.Write(`The user is {"name": "Ada",`); got != "The user is " {
+		t.Errorf("first chunk released %q, want the text before the object", got)
+	}
+	if got := f.Write(` "age": 36}`); got != `{"name": "Ada", "age": 36}` {
+		t.Errorf("second chunk released %q, want the object once it closed", got)
+	}
+	if got := f.Write(" and more"); got != " and more" {
+		t.Errorf("third chunk released %q", got)
+	}
+}
+
+func TestCancelStreamCancelsTurn(t *testing.T) {
+	m := &Model{}
+	turn := m.beginTurn()
+
+	// Later steps of the same turn share its context
+	if m.turnContext() != turn {
+		t.Fatal("turnContext should return the running turn")
+	}
+
+	m.ActiveStream = newResponseStream()
+	m.CancelStream()
+
+	if turn.Err() == nil {
+		t.Error("turn context should be cancelled")
+	}
+	if m.ActiveStream != nil {
+		t.Error("ActiveStream should be cleared")
+	}
+	if next := m.turnContext(); next == turn || next.Err() != nil {
+		t.Error("a new turn should start after cancellation")
+	}
+}
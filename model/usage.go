@@ -0,0 +1,82 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"otui/config"
+	"otui/storage"
+)
+
+// priceLookupTimeout bounds fetching a provider's price list on first use
+const priceLookupTimeout = 10 * time.Second
+
+// recordUsage appends the token usage of one provider response to the usage
+// ledger. The price comes from the user's [prices] table, then from the
+// provider's own pricing; without either the request is recorded unpriced.
+// answeredBy names the model that produced the response, on client.
+func (m *Model) recordUsage(ctx context.Context, session *storage.Session, client Provider, answeredBy Responder, usage Usage) {
+	if m.SessionStorage == nil || (usage.PromptTokens == 0 && usage.CompletionTokens == 0) {
+		return
+	}
+
+	rec := storage.UsageRecord{
+		Timestamp:    time.Now(),
+		Provider:     answeredBy.Provider,
+		Model:        answeredBy.Model,
+		InputTokens:  usage.PromptTokens,
+		OutputTokens: usage.CompletionTokens,
+	}
+	if session != nil {
+		rec.SessionID = session.ID
+	}
+
+	price, ok := m.Config.LookupPrice(rec.Provider, rec.Model)
+	if !ok {
+		if priced, isPriced := client.(PricedProvider); isPriced {
+			priceCtx, cancel := context.WithTimeout(ctx, priceLookupTimeout)
+			price, ok = priced.ModelPrice(priceCtx, rec.Model)
+			cancel()
+		}
+	}
+	if ok {
+		rec.Cost = price.Cost(rec.InputTokens, rec.OutputTokens)
+		rec.Priced = true
+	}
+
+	if err := m.SessionStorage.Usage().Record(rec); err != nil {
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("[usage] Failed to record usage: %v", err)
+		}
+		return
+	}
+
+	m.usageMu.Lock()
+	if m.sessionUsageLoaded && m.sessionUsageID == rec.SessionID {
+		m.sessionUsage.Add(rec)
+	}
+	m.usageMu.Unlock()
+}
+
+// SessionUsage returns the recorded usage of the current session. The ledger
+// is read once per session and kept up to date as requests finish.
+func (m *Model) SessionUsage() storage.UsageTotals {
+	if m.SessionStorage == nil || m.CurrentSession == nil {
+		return storage.UsageTotals{}
+	}
+	sessionID := m.CurrentSession.ID
+
+	m.usageMu.Lock()
+	defer m.usageMu.Unlock()
+
+	if !m.sessionUsageLoaded || m.sessionUsageID != sessionID {
+		totals, err := m.SessionStorage.Usage().SessionTotals(sessionID)
+		if err != nil && config.DebugLog != nil {
+			config.DebugLog.Printf("[usage] Failed to load session usage: %v", err)
+		}
+		m.sessionUsageID = sessionID
+		m.sessionUsage = totals
+		m.sessionUsageLoaded = true
+	}
+	return m.sessionUsage
+}
@@ -17,7 +17,10 @@ type Client struct {
 	baseURL string
 }
 
-type StreamCallback func(chunk string, toolCalls []api.ToolCall) error
+// StreamCallback receives each streamed response. thinking is the model's
+// reasoning when the server separates it from the answer. metrics is set only
+// on the final response and carries the prompt and output token counts.
+type StreamCallback func(chunk, thinking string, toolCalls []api.ToolCall, metrics *api.Metrics) error
 
 // authTransport injects an Authorization: Bearer header on every request.
 type authTransport struct {
@@ -69,17 +72,39 @@ func (c *Client) Chat(ctx context.Context, messages []api.Message, callback Stre
 
 // ChatWithTools sends a chat request with optional tool definitions
 func (c *Client) ChatWithTools(ctx context.Context, messages []api.Message, tools []api.Tool, callback StreamCallback) error {
+	return c.ChatWithOptions(ctx, messages, tools, ChatOptions{}, callback)
+}
+
+// ChatOptions are per-request settings for ChatWithOptions
+type ChatOptions struct {
+	// Think nil leaves thinking to the server, otherwise true/false or a
+	// level ("low", "medium", "high") for models that support levels
+	Think *api.ThinkValue
+
+	// Options are model parameters such as "temperature" and "num_ctx"
+	// (nil = the model's defaults)
+	Options map[string]any
+}
+
+// ChatWithOptions is ChatWithTools with control over thinking and model parameters
+func (c *Client) ChatWithOptions(ctx context.Context, messages []api.Message, tools []api.Tool, opts ChatOptions, callback StreamCallback) error {
 	req := &api.ChatRequest{
 		Model:    c.model,
 		Messages: messages,
 		Tools:    tools,
 		Stream:   func(b bool) *bool { return &b }(true),
+		Think:    opts.Think,
+		Options:  opts.Options,
 	}
 
 	respFunc := func(resp api.ChatResponse) error {
 		if callback != nil {
+			var metrics *api.Metrics
+			if resp.Done {
+				metrics = &resp.Metrics
+			}
 			// Pass tool calls if present, otherwise nil
-			return callback(resp.Message.Content, resp.Message.ToolCalls)
+			return callback(resp.Message.Content, resp.Message.Thinking, resp.Message.ToolCalls, metrics)
 		}
 		return nil
 	}
@@ -113,6 +138,40 @@ func (c *Client) ListModels(ctx context.Context) ([]ModelInfo, error) {
 	return models, nil
 }
 
+// Capabilities returns what a model supports ("completion", "tools",
+// "vision", ...) as reported by the server's show API
+func (c *Client) Capabilities(ctx context.Context, model string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := c.client.Show(ctx, &api.ShowRequest{Model: model})
+	if err != nil {
+		return nil, fmt.Errorf("failed to show model %s: %w", model, err)
+	}
+
+	capabilities := make([]string, len(resp.Capabilities))
+	for i, capability := range resp.Capabilities {
+		capabilities[i] = string(capability)
+	}
+	return capabilities, nil
+}
+
+// Embed returns an embedding vector for each of texts from the client's
+// model, which must be an embedding model such as nomic-embed-text. Texts
+// longer than the model's context are truncated.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	truncate := true
+	resp, err := c.client.Embed(ctx, &api.EmbedRequest{
+		Model:    c.model,
+		Input:    texts,
+		Truncate: &truncate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed with %s: %w", c.model, err)
+	}
+	return resp.Embeddings, nil
+}
+
 func (c *Client) SetModel(model string) {
 	c.model = model
 }
@@ -2,8 +2,10 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"otui/config"
 	"otui/mcp"
 	"otui/model"
 	"otui/ollama"
@@ -21,8 +23,14 @@ type AnthropicProvider struct {
 	model   anthropic.Model
 	baseURL string
 	apiKey  string
+
+	reasoning  config.ReasoningConfig
+	generation config.GenerationParams
 }
 
+// minThinkingBudget is the smallest thinking budget the API accepts
+const minThinkingBudget = 1024
+
 // NewAnthropicProvider creates a new Anthropic provider instance.
 //
 // Parameters:
@@ -49,9 +57,11 @@ func NewAnthropicProvider(baseURL, apiKey, model string) (*AnthropicProvider, er
 	}
 
 	// Create Anthropic client
+	// Retries are left to the model layer ([retry] in config.toml)
 	client := anthropic.NewClient(
 		option.WithBaseURL(baseURL),
 		option.WithAPIKey(apiKey),
+		option.WithMaxRetries(0),
 	)
 
 	return &AnthropicProvider{
@@ -62,6 +72,18 @@ func NewAnthropicProvider(baseURL, apiKey, model string) (*AnthropicProvider, er
 	}, nil
 }
 
+// SetReasoning sets the extended thinking budget. Thinking stays off unless
+// reasoning_effort or thinking_budget is set.
+func (p *AnthropicProvider) SetReasoning(r config.ReasoningConfig) {
+	p.reasoning = r
+}
+
+// SetGenerationParams implements model.TunableProvider. Anthropic has no
+// seed and ignores it.
+func (p *AnthropicProvider) SetGenerationParams(params config.GenerationParams) {
+	p.generation = params
+}
+
 // Chat implements Provider.Chat by delegating to ChatWithTools with no tools.
 func (p *AnthropicProvider) Chat(ctx context.Context, messages []model.Message, callback model.StreamCallback) error {
 	return p.ChatWithTools(ctx, messages, nil, callback)
@@ -83,10 +105,15 @@ func (p *AnthropicProvider) ChatWithTools(ctx context.Context, messages []model.
 	}
 
 	// Build request parameters
+	generation := requestParams(ctx, &p.generation)
+	maxTokens := int64(4096) // Required by Anthropic API
+	if generation.MaxTokens > 0 {
+		maxTokens = int64(generation.MaxTokens)
+	}
 	params := anthropic.MessageNewParams{
 		Model:     p.model,
 		Messages:  anthropicMessages,
-		MaxTokens: 4096, // Required by Anthropic API
+		MaxTokens: maxTokens,
 	}
 
 	// Add system prompt if present
@@ -100,6 +127,24 @@ func (p *AnthropicProvider) ChatWithTools(ctx context.Context, messages []model.
 		params.Tools = anthropicTools
 	}
 
+	// Extended thinking. Continuing after tool results would require the
+	// signed thinking blocks of the turn, which aren't kept, so the model
+	// only thinks at the start of a turn.
+	continuingTools := len(messages) > 0 && messages[len(messages)-1].Role == "tool"
+	if budget := thinkingBudget(p.reasoning); budget > 0 && !continuingTools {
+		budget = max(budget, minThinkingBudget)
+		params.Thinking = anthropic.ThinkingConfigParamOfEnabled(int64(budget))
+		params.MaxTokens += int64(budget) // Thinking counts against max_tokens
+	} else {
+		// Thinking requires the default temperature, and newer models
+		// reject temperature and top_p together
+		if t := generation.Temperature; t != nil {
+			params.Temperature = anthropic.Float(min(*t, 1)) // Anthropic's range is 0-1
+		} else if topP := generation.TopP; topP != nil {
+			params.TopP = anthropic.Float(*topP)
+		}
+	}
+
 	// Create streaming request
 	stream := p.client.Messages.NewStreaming(ctx, params)
 
@@ -122,12 +167,16 @@ func (p *AnthropicProvider) ChatWithTools(ctx context.Context, messages []model.
 		// Handle different event types
 		switch eventVariant := event.AsAny().(type) {
 		case anthropic.ContentBlockDeltaEvent:
-			// Handle text deltas
+			// Handle text and thinking deltas
 			switch deltaVariant := eventVariant.Delta.AsAny().(type) {
 			case anthropic.TextDelta:
 				contentBuilder.WriteString(deltaVariant.Text)
 				if callback != nil {
-					callback(deltaVariant.Text, nil)
+					callback(deltaVariant.Text, "", nil, nil)
+				}
+			case anthropic.ThinkingDelta:
+				if callback != nil {
+					callback("", deltaVariant.Thinking, nil, nil)
 				}
 			}
 		}
@@ -135,28 +184,39 @@ func (p *AnthropicProvider) ChatWithTools(ctx context.Context, messages []model.
 
 	// Check for errors
 	if err := stream.Err(); err != nil {
-		return fmt.Errorf("Anthropic streaming error: %w", err)
+		return fmt.Errorf("Anthropic streaming error: %w", apiError(err))
 	}
 
 	// After stream completes, check for tool calls in the final message
 	if callback != nil {
 		toolCalls := extractToolCalls(msg.Content)
 		if len(toolCalls) > 0 {
-			callback("", toolCalls)
+			callback("", "", toolCalls, nil)
 		} else {
 			// Safety check: detect leaked tool calls if none were detected via API
 			fullContent := contentBuilder.String()
 
 			// Check for JSON leaked tool calls
 			if leakedCalls := ParseLeakedJSONToolCalls(fullContent); len(leakedCalls) > 0 {
-				callback("", leakedCalls)
+				callback("", "", leakedCalls, nil)
 			}
 
 			// Check for XML leaked tool calls
 			if leakedCalls := ParseLeakedXMLToolCalls(fullContent); len(leakedCalls) > 0 {
-				callback("", leakedCalls)
+				callback("", "", leakedCalls, nil)
 			}
 		}
+
+		// message_start reports input tokens, message_delta the output so far.
+		// Cached prompt tokens are counted separately but still fill the context.
+		usage := msg.Usage
+		promptTokens := usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens
+		if promptTokens > 0 || usage.OutputTokens > 0 {
+			return callback("", "", nil, &model.Usage{
+				PromptTokens:     int(promptTokens),
+				CompletionTokens: int(usage.OutputTokens),
+			})
+		}
 	}
 
 	return nil
@@ -227,19 +287,25 @@ func (p *AnthropicProvider) GetModelMetadata(ctx context.Context, modelName stri
 	meta := GetFallbackMetadata(modelName, AnthropicFallbackMetadata)
 
 	return model.ModelMetadata{
-		ContextWindow: meta.ContextWindow,
-		MaxOutput:     meta.MaxOutput,
-		SupportsTools: meta.SupportsTools,
+		ContextWindow:  meta.ContextWindow,
+		MaxOutput:      meta.MaxOutput,
+		SupportsTools:  meta.SupportsTools,
+		SupportsVision: meta.SupportsVision,
 	}, nil
 }
 
 // convertToAnthropicMessages converts OTUI messages to Anthropic format.
 // Returns the message array and any system prompt found.
+//
+// Assistant tool calls become tool_use blocks. Anthropic expects all results
+// for one assistant turn in a single user message, so consecutive tool
+// messages are grouped into one message of tool_result blocks.
 func convertToAnthropicMessages(messages []model.Message) ([]anthropic.MessageParam, []anthropic.TextBlockParam) {
 	var systemBlocks []anthropic.TextBlockParam
 	anthropicMsgs := make([]anthropic.MessageParam, 0, len(messages))
 
-	for _, msg := range messages {
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
 		switch msg.Role {
 		case "system":
 			// Anthropic uses a separate system parameter, not in messages array
@@ -248,21 +314,44 @@ func convertToAnthropicMessages(messages []model.Message) ([]anthropic.MessagePa
 			})
 
 		case "user":
-			anthropicMsgs = append(anthropicMsgs,
-				anthropic.NewUserMessage(anthropic.NewTextBlock(msg.Content)),
-			)
+			// Images go first, as Anthropic recommends
+			var blocks []anthropic.ContentBlockParamUnion
+			for _, image := range msg.Images() {
+				blocks = append(blocks, anthropic.NewImageBlockBase64(image.MIMEType, base64.StdEncoding.EncodeToString(image.Data)))
+			}
+			if text := msg.ContentWithFiles(); text != "" || len(blocks) == 0 {
+				blocks = append(blocks, anthropic.NewTextBlock(text))
+			}
+			anthropicMsgs = append(anthropicMsgs, anthropic.NewUserMessage(blocks...))
 
 		case "assistant":
-			anthropicMsgs = append(anthropicMsgs,
-				anthropic.NewAssistantMessage(anthropic.NewTextBlock(msg.Content)),
-			)
+			var blocks []anthropic.ContentBlockParamUnion
+			// Empty text blocks are rejected, but a turn may consist of tool calls only
+			if msg.Content != "" || len(msg.ToolCalls) == 0 {
+				blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
+			}
+			for _, call := range msg.ToolCalls {
+				input := call.Arguments
+				if input == nil {
+					input = map[string]any{}
+				}
+				blocks = append(blocks, anthropic.NewToolUseBlock(call.ID, input, call.Name))
+			}
+			anthropicMsgs = append(anthropicMsgs, anthropic.NewAssistantMessage(blocks...))
 
 		case "tool":
-			// Tool results go to user messages for now
-			// TODO: Properly handle tool result format when implementing tool support
-			anthropicMsgs = append(anthropicMsgs,
-				anthropic.NewUserMessage(anthropic.NewTextBlock(msg.Content)),
-			)
+			var blocks []anthropic.ContentBlockParamUnion
+			for ; i < len(messages) && messages[i].Role == "tool"; i++ {
+				result := messages[i]
+				if result.ToolCall == nil || result.ToolCall.ID == "" {
+					// Unpaired result - send as plain text so it isn't lost
+					blocks = append(blocks, anthropic.NewTextBlock(result.Content))
+					continue
+				}
+				blocks = append(blocks, anthropic.NewToolResultBlock(result.ToolCall.ID, result.Content, result.ToolError != ""))
+			}
+			i-- // Outer loop advances past the last tool message
+			anthropicMsgs = append(anthropicMsgs, anthropic.NewUserMessage(blocks...))
 
 		default:
 			// Default to user message
@@ -290,6 +379,7 @@ func extractToolCalls(content []anthropic.ContentBlockUnion) []model.ToolCall {
 			}
 
 			toolCalls = append(toolCalls, model.ToolCall{
+				ID:        toolUse.ID,
 				Name:      toolUse.Name,
 				Arguments: args,
 			})
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"otui/model"
+	"testing"
+)
+
+func TestConvertToAnthropicMessagesToolResults(t *testing.T) {
+	callA := model.ToolCall{ID: "toolu_a", Name: "fs.read_file", Arguments: map[string]any{"path": "a.txt"}}
+	callB := model.ToolCall{ID: "toolu_b", Name: "fs.list"}
+	input := []model.Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "look around"},
+		{Role: "assistant", ToolCalls: []model.ToolCall{callA, callB}},
+		{Role: "tool", Content: "a", ToolCall: &callA},
+		{Role: "tool", Content: "permission denied", ToolCall: &callB, ToolError: "permission denied"},
+		{Role: "assistant", Content: "Done."},
+	}
+
+	msgs, system := convertToAnthropicMessages(input)
+
+	if len(system) != 1 {
+		t.Errorf("expected 1 system block, got %d", len(system))
+	}
+	if len(msgs) != 4 {
+		t.Fatalf("expected parallel results grouped into one user message (4 messages), got %d", len(msgs))
+	}
+
+	assistant := msgs[1]
+	if len(assistant.Content) != 2 {
+		t.Fatalf("expected only tool_use blocks (no empty text), got %d blocks", len(assistant.Content))
+	}
+	for i, want := range []model.ToolCall{callA, callB} {
+		use := assistant.Content[i].OfToolUse
+		if use == nil || use.ID != want.ID || use.Name != want.Name {
+			t.Errorf("block %d: got %+v, want tool_use %q", i, use, want.ID)
+		}
+	}
+
+	results := msgs[2]
+	if results.Role != "user" || len(results.Content) != 2 {
+		t.Fatalf("expected user message with 2 tool_result blocks, got %+v", results)
+	}
+	for i, wantID := range []string{"toolu_a", "toolu_b"} {
+		res := results.Content[i].OfToolResult
+		if res == nil || res.ToolUseID != wantID {
+			t.Errorf("result %d: got %+v, want tool_use_id %q", i, res, wantID)
+		}
+	}
+	if !results.Content[1].OfToolResult.IsError.Value {
+		t.Error("expected failed tool result to be marked is_error")
+	}
+}
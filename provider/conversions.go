@@ -14,6 +14,17 @@ import (
 //
 // This conversion is used when sending messages to the Ollama provider. It performs
 // a simple field mapping since both types have compatible Role and Content fields.
+// Assistant tool calls are carried over, and tool results are tagged with the name
+// of the tool that produced them.
+//
+// Attached images go in the message's Images field; attached text files are
+// appended to the content.
+//
+// Call IDs are dropped: api.ToolCall in ollama v0.12.6 has no ID field, so Ollama
+// pairs results with calls by tool name and position instead. Callers must keep
+// each run of tool results in the same order as the calls that produced them
+// (buildAPIMessages and ExecuteToolsAndContinue do), which keeps several parallel
+// calls to the same tool matched correctly.
 //
 // Note: The Timestamp and Rendered fields from model.Message are not preserved, as
 // the Ollama API does not support these fields. Timestamps should be managed at the
@@ -31,8 +42,15 @@ func ConvertToOllamaMessages(messages []model.Message) []api.Message {
 	result := make([]api.Message, len(messages))
 	for i, msg := range messages {
 		result[i] = api.Message{
-			Role:    msg.Role,
-			Content: msg.Content,
+			Role:      msg.Role,
+			Content:   msg.ContentWithFiles(),
+			ToolCalls: ConvertFromProviderToolCalls(msg.ToolCalls),
+		}
+		for _, image := range msg.Images() {
+			result[i].Images = append(result[i].Images, api.ImageData(image.Data))
+		}
+		if msg.Role == "tool" && msg.ToolCall != nil {
+			result[i].ToolName = msg.ToolCall.Name
 		}
 	}
 	return result
@@ -278,6 +296,7 @@ func ConvertToProviderToolCalls(ollamaCalls []api.ToolCall) []model.ToolCall {
 		return nil
 	}
 
+	// Ollama issues no call IDs; the slice keeps the calls in Index order
 	result := make([]model.ToolCall, len(ollamaCalls))
 	for i, call := range ollamaCalls {
 		result[i] = model.ToolCall{
@@ -312,6 +331,7 @@ func ConvertFromProviderToolCalls(providerCalls []model.ToolCall) []api.ToolCall
 	for i, call := range providerCalls {
 		result[i] = api.ToolCall{
 			Function: api.ToolCallFunction{
+				Index:     i, // Position in the batch - Ollama's only way to tell calls apart
 				Name:      call.Name,
 				Arguments: call.Arguments,
 			},
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"otui/model"
+	"strings"
 	"testing"
 	"time"
 
@@ -325,3 +326,152 @@ func TestRoundTripConversions(t *testing.T) {
 		}
 	})
 }
+
+func TestConvertToOllamaMessagesToolCalls(t *testing.T) {
+	call := model.ToolCall{ID: "call_1", Name: "fs.read_file", Arguments: map[string]any{"path": "a.txt"}}
+	input := []model.Message{
+		{Role: "assistant", ToolCalls: []model.ToolCall{call}},
+		{Role: "tool", Content: "contents", ToolCall: &call},
+	}
+
+	result := ConvertToOllamaMessages(input)
+
+	if len(result[0].ToolCalls) != 1 || result[0].ToolCalls[0].Function.Name != "fs.read_file" {
+		t.Errorf("assistant tool calls not carried over: %+v", result[0].ToolCalls)
+	}
+	if result[1].ToolName != "fs.read_file" {
+		t.Errorf("tool result name: got %q, want %q", result[1].ToolName, "fs.read_file")
+	}
+}
+
+func TestConvertToOllamaMessagesParallelSameTool(t *testing.T) {
+	// Ollama has no call IDs, so two calls to the same tool are told apart
+	// only by position - results must replay in call order
+	first := model.ToolCall{ID: "call_a", Name: "fs.read_file", Arguments: map[string]any{"path": "a.txt"}}
+	second := model.ToolCall{ID: "call_b", Name: "fs.read_file", Arguments: map[string]any{"path": "b.txt"}}
+	input := []model.Message{
+		{Role: "user", Content: "read both"},
+		{Role: "assistant", ToolCalls: []model.ToolCall{first, second}},
+		{Role: "tool", Content: "contents of a", ToolCall: &first},
+		{Role: "tool", Content: "contents of b", ToolCall: &second},
+	}
+
+	result := ConvertToOllamaMessages(input)
+
+	calls := result[1].ToolCalls
+	if len(calls) != 2 {
+		t.Fatalf("got %d tool calls, want 2", len(calls))
+	}
+	for i, wantPath := range []string{"a.txt", "b.txt"} {
+		if calls[i].Function.Index != i {
+			t.Errorf("call %d: Index = %d, want %d", i, calls[i].Function.Index, i)
+		}
+		if calls[i].Function.Arguments["path"] != wantPath {
+			t.Errorf("call %d: path = %v, want %s", i, calls[i].Function.Arguments["path"], wantPath)
+		}
+	}
+
+	for i, want := range []string{"contents of a", "contents of b"} {
+		msg := result[2+i]
+		if msg.Role != "tool" || msg.ToolName != "fs.read_file" || msg.Content != want {
+			t.Errorf("result %d = {%s %s %q}, want tool result %q", i, msg.Role, msg.ToolName, msg.Content, want)
+		}
+	}
+}
+
+func TestConvertToOpenAIMessagesToolCalls(t *testing.T) {
+	callA := model.ToolCall{ID: "call_a", Name: "fs.read_file", Arguments: map[string]any{"path": "a.txt"}}
+	callB := model.ToolCall{ID: "call_b", Name: "fs.read_file", Arguments: map[string]any{"path": "b.txt"}}
+	input := []model.Message{
+		{Role: "user", Content: "compare a and b"},
+		{Role: "assistant", ToolCalls: []model.ToolCall{callA, callB}},
+		{Role: "tool", Content: "a", ToolCall: &callA},
+		{Role: "tool", Content: "b", ToolCall: &callB},
+		{Role: "tool", Content: "legacy result"},
+	}
+
+	result := ConvertToOpenAIMessages(input)
+
+	assistant := result[1].OfAssistant
+	if assistant == nil || len(assistant.ToolCalls) != 2 {
+		t.Fatalf("expected assistant message with 2 tool calls, got %+v", result[1])
+	}
+	for i, want := range []model.ToolCall{callA, callB} {
+		fn := assistant.ToolCalls[i].OfFunction
+		if fn == nil || fn.ID != want.ID || fn.Function.Name != want.Name {
+			t.Errorf("tool call %d: got %+v, want ID %q", i, fn, want.ID)
+		}
+	}
+	if fn := assistant.ToolCalls[0].OfFunction; fn != nil && fn.Function.Arguments != `{"path":"a.txt"}` {
+		t.Errorf("tool call arguments: got %s", fn.Function.Arguments)
+	}
+
+	for i, wantID := range []string{"call_a", "call_b"} {
+		tool := result[2+i].OfTool
+		if tool == nil || tool.ToolCallID != wantID {
+			t.Errorf("result %d: expected tool message for %q, got %+v", i, wantID, result[2+i])
+		}
+	}
+
+	if result[4].OfUser == nil {
+		t.Errorf("result without call ID should fall back to a user message, got %+v", result[4])
+	}
+}
+
+func TestConvertMessageToolNamesForOpenRouter(t *testing.T) {
+	input := []model.Message{
+		{Role: "assistant", ToolCalls: []model.ToolCall{{ID: "call_1", Name: "server-filesystem.read_file"}}},
+	}
+
+	result := convertMessageToolNamesForOpenRouter(input)
+
+	if got := result[0].ToolCalls[0].Name; got != "server-filesystem__read_file" {
+		t.Errorf("got %q, want %q", got, "server-filesystem__read_file")
+	}
+	if input[0].ToolCalls[0].Name != "server-filesystem.read_file" {
+		t.Error("input messages were modified")
+	}
+}
+
+func TestConvertAttachments(t *testing.T) {
+	png := model.Attachment{Name: "shot.png", MIMEType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}}
+	notes := model.Attachment{Name: "notes.txt", MIMEType: "text/plain", Data: []byte("remember this\n")}
+	input := []model.Message{
+		{Role: "user", Content: "what is this?", Attachments: []model.Attachment{png, notes}},
+	}
+
+	t.Run("ollama", func(t *testing.T) {
+		msg := ConvertToOllamaMessages(input)[0]
+		if len(msg.Images) != 1 || string(msg.Images[0]) != string(png.Data) {
+			t.Errorf("images = %v, want the PNG bytes", msg.Images)
+		}
+		if !strings.Contains(msg.Content, "File: notes.txt") || !strings.Contains(msg.Content, "remember this") {
+			t.Errorf("text file not inlined: %q", msg.Content)
+		}
+	})
+
+	t.Run("openai", func(t *testing.T) {
+		user := ConvertToOpenAIMessages(input)[0].OfUser
+		if user == nil {
+			t.Fatal("expected a user message")
+		}
+		parts := user.Content.OfArrayOfContentParts
+		if len(parts) != 2 || parts[0].OfText == nil || parts[1].OfImageURL == nil {
+			t.Fatalf("expected text and image parts, got %+v", parts)
+		}
+		if url := parts[1].OfImageURL.ImageURL.URL; url != "data:image/png;base64,iVBORw==" {
+			t.Errorf("image URL = %q", url)
+		}
+	})
+
+	t.Run("anthropic", func(t *testing.T) {
+		msgs, _ := convertToAnthropicMessages(input)
+		blocks := msgs[0].Content
+		if len(blocks) != 2 || blocks[0].OfImage == nil || blocks[1].OfText == nil {
+			t.Fatalf("expected image then text block, got %+v", blocks)
+		}
+		if !strings.Contains(blocks[1].OfText.Text, "remember this") {
+			t.Errorf("text block = %q, want the file contents", blocks[1].OfText.Text)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+	"otui/model"
+	"strconv"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/ollama/ollama/api"
+	"github.com/openai/openai-go/v3"
+)
+
+// apiError wraps an HTTP error response from one of the provider SDKs in a
+// model.APIError, so the model layer can retry rate limits and server errors.
+// Other errors are returned unchanged.
+func apiError(err error) error {
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return &model.APIError{StatusCode: openaiErr.StatusCode, RetryAfter: responseRetryAfter(openaiErr.Response), Err: err}
+	}
+	var anthropicErr *anthropic.Error
+	if errors.As(err, &anthropicErr) {
+		return &model.APIError{StatusCode: anthropicErr.StatusCode, RetryAfter: responseRetryAfter(anthropicErr.Response), Err: err}
+	}
+	var ollamaErr api.StatusError
+	if errors.As(err, &ollamaErr) {
+		return &model.APIError{StatusCode: ollamaErr.StatusCode, Err: err}
+	}
+	return err
+}
+
+// responseRetryAfter returns how long the server asked us to wait before
+// trying again, 0 if it didn't say
+func responseRetryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	return retryAfter(resp.Header)
+}
+
+// retryAfter reads the Retry-After header (seconds or an HTTP date) and
+// OpenAI's more precise Retry-After-Ms
+func retryAfter(h http.Header) time.Duration {
+	if ms, err := strconv.ParseFloat(h.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	value := h.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return max(time.Duration(seconds*float64(time.Second)), 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
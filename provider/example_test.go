@@ -63,7 +63,7 @@ func ExampleOllamaProvider_Chat() {
 
 	// Chat with streaming callback
 	ctx := context.Background()
-	err = p.Chat(ctx, messages, func(chunk string, toolCalls []model.ToolCall) error {
+	err = p.Chat(ctx, messages, func(chunk, reasoning string, toolCalls []model.ToolCall, usage *model.Usage) error {
 		// Print each chunk as it arrives
 		fmt.Print(chunk)
 		return nil
@@ -95,7 +95,7 @@ func ExampleOllamaProvider_ChatWithTools() {
 
 	// Chat with tools and streaming callback
 	ctx := context.Background()
-	err = p.ChatWithTools(ctx, messages, nil, func(chunk string, toolCalls []model.ToolCall) error {
+	err = p.ChatWithTools(ctx, messages, nil, func(chunk, reasoning string, toolCalls []model.ToolCall, usage *model.Usage) error {
 		// Handle tool calls
 		if len(toolCalls) > 0 {
 			for _, call := range toolCalls {
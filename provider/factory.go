@@ -2,6 +2,7 @@ package provider
 
 import (
 	"fmt"
+	"otui/config"
 	"otui/model"
 )
 
@@ -15,6 +16,8 @@ import (
 //   - ProviderTypeOllama: Local Ollama server (implemented)
 //   - ProviderTypeOpenAI: OpenAI API (not yet implemented)
 //   - ProviderTypeAnthropic: Anthropic API (not yet implemented)
+//   - ProviderTypeGemini: Google Gemini API
+//   - ProviderTypeOpenAICompatible: Custom OpenAI-compatible server (needs ID and BaseURL)
 //
 // Returns an error if:
 //   - The provider type is unknown
@@ -44,6 +47,18 @@ import (
 //	p, err := provider.NewProvider(cfg)
 //	// Returns error: "OpenAI provider not yet implemented"
 func NewProvider(cfg Config) (model.Provider, error) {
+	p, err := newProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := p.(reasoningConfigurable); ok {
+		r.SetReasoning(cfg.Reasoning)
+	}
+	return p, nil
+}
+
+// newProvider dispatches to the constructor for cfg.Type
+func newProvider(cfg Config) (model.Provider, error) {
 	switch cfg.Type {
 	case ProviderTypeOllama:
 		return NewOllamaProvider(cfg.BaseURL, cfg.Model, cfg.APIKey)
@@ -53,6 +68,10 @@ func NewProvider(cfg Config) (model.Provider, error) {
 		return NewOpenAIProvider(cfg.BaseURL, cfg.APIKey, cfg.Model)
 	case ProviderTypeAnthropic:
 		return NewAnthropicProvider(cfg.BaseURL, cfg.APIKey, cfg.Model)
+	case ProviderTypeGemini:
+		return NewGeminiProvider(cfg.BaseURL, cfg.APIKey, cfg.Model)
+	case ProviderTypeOpenAICompatible:
+		return NewOpenAICompatibleProvider(cfg.ID, cfg.Name, cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.Headers)
 	default:
 		return nil, fmt.Errorf("unknown provider type: %s", cfg.Type)
 	}
@@ -68,6 +87,7 @@ func NewProvider(cfg Config) (model.Provider, error) {
 //   - "openrouter" → ProviderTypeOpenAI (OpenRouter is OpenAI-compatible)
 //   - "openai" → ProviderTypeOpenAI
 //   - "anthropic" → ProviderTypeAnthropic
+//   - "gemini" → ProviderTypeGemini
 //
 // For unknown IDs, returns the ID cast as ProviderType (factory will error).
 func MapProviderIDToType(id string) ProviderType {
@@ -80,8 +100,19 @@ func MapProviderIDToType(id string) ProviderType {
 		return ProviderTypeOpenAI
 	case "anthropic":
 		return ProviderTypeAnthropic
+	case "gemini":
+		return ProviderTypeGemini
 	default:
 		// Fallback: pass ID as-is (factory will return error)
 		return ProviderType(id)
 	}
 }
+
+// ProviderTypeForConfig returns the factory ProviderType for a [[providers]]
+// entry. Custom providers declare their type; built-ins are mapped by ID.
+func ProviderTypeForConfig(cfg config.ProviderConfig) ProviderType {
+	if cfg.Type != "" {
+		return ProviderType(cfg.Type)
+	}
+	return MapProviderIDToType(cfg.ID)
+}
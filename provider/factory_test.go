@@ -54,6 +54,16 @@ func TestNewProvider(t *testing.T) {
 			expectError: false,
 			expectNil:   false,
 		},
+		{
+			name: "gemini provider",
+			config: Config{
+				Type:   ProviderTypeGemini,
+				Model:  "gemini-2.5-flash",
+				APIKey: "test-key",
+			},
+			expectError: false,
+			expectNil:   false,
+		},
 		{
 			name: "unknown provider type",
 			config: Config{
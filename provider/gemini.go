@@ -0,0 +1,548 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"otui/config"
+	"otui/mcp"
+	"otui/model"
+	"otui/ollama"
+	"slices"
+	"strings"
+	"sync"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+)
+
+// GeminiProvider implements the Provider interface using the Gemini API
+// (generativelanguage.googleapis.com). There is no Gemini SDK dependency;
+// requests go through the REST API directly.
+type GeminiProvider struct {
+	httpClient *http.Client
+	model      string
+	baseURL    string
+	apiKey     string
+
+	modelsMu sync.Mutex
+	models   map[string]geminiModel // Model name -> limits, filled by ListModels and GetModelMetadata
+
+	reasoning  config.ReasoningConfig
+	generation config.GenerationParams
+}
+
+// NewGeminiProvider creates a new Gemini provider instance.
+//
+// Parameters:
+//   - baseURL: Gemini API base URL (default: "https://generativelanguage.googleapis.com/v1beta")
+//   - apiKey: Gemini API key from Google AI Studio (required)
+//   - model: Initial model to use (default: "gemini-2.5-flash")
+//
+// Returns an error if the API key is missing.
+func NewGeminiProvider(baseURL, apiKey, model string) (*GeminiProvider, error) {
+	if baseURL == "" {
+		baseURL = "https://generativelanguage.googleapis.com/v1beta"
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("Gemini API key is required")
+	}
+	if model == "" {
+		model = "gemini-2.5-flash"
+	}
+
+	return &GeminiProvider{
+		httpClient: &http.Client{},
+		model:      model,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		models:     make(map[string]geminiModel),
+	}, nil
+}
+
+// Gemini REST API types (only the fields OTUI uses)
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" or "model"
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"` // Thinking summary, not part of the answer
+	InlineData       *geminiBlob             `json:"inlineData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiBlob struct {
+	MIMEType string `json:"mimeType"`
+	Data     string `json:"data"` // Base64
+}
+
+type geminiFunctionCall struct {
+	ID   string         `json:"id,omitempty"`
+	Name string         `json:"name"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []mcp.GeminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiRequest struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature     *float64              `json:"temperature,omitempty"`
+	TopP            *float64              `json:"topP,omitempty"`
+	MaxOutputTokens int                   `json:"maxOutputTokens,omitempty"`
+	Seed            *int                  `json:"seed,omitempty"`
+	ThinkingConfig  *geminiThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+type geminiThinkingConfig struct {
+	IncludeThoughts bool `json:"includeThoughts,omitempty"` // Stream thought summaries
+	ThinkingBudget  *int `json:"thinkingBudget,omitempty"`  // 0 turns thinking off where allowed
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+type geminiModel struct {
+	Name                       string   `json:"name"` // "models/gemini-2.5-flash"
+	DisplayName                string   `json:"displayName"`
+	InputTokenLimit            int      `json:"inputTokenLimit"`
+	OutputTokenLimit           int      `json:"outputTokenLimit"`
+	SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+	Thinking                   bool     `json:"thinking"`
+}
+
+type geminiModelList struct {
+	Models        []geminiModel `json:"models"`
+	NextPageToken string        `json:"nextPageToken"`
+}
+
+// SetReasoning sets the thinking budget for Gemini 2.5 and later models
+func (p *GeminiProvider) SetReasoning(r config.ReasoningConfig) {
+	p.reasoning = r
+}
+
+// SetGenerationParams implements model.TunableProvider
+func (p *GeminiProvider) SetGenerationParams(params config.GenerationParams) {
+	p.generation = params
+}
+
+// generationConfig returns the sampling and thinking settings for a
+// request, nil when there are none
+func (p *GeminiProvider) generationConfig(ctx context.Context) *geminiGenerationConfig {
+	g := requestParams(ctx, &p.generation)
+	cfg := &geminiGenerationConfig{
+		Temperature:     g.Temperature,
+		TopP:            g.TopP,
+		MaxOutputTokens: g.MaxTokens,
+		Seed:            g.Seed,
+		ThinkingConfig:  p.thinkingConfig(),
+	}
+	if *cfg == (geminiGenerationConfig{}) {
+		return nil
+	}
+	return cfg
+}
+
+// thinkingConfig returns the thinking settings for a request: thought
+// summaries for models that think, plus the user's budget if one is set
+func (p *GeminiProvider) thinkingConfig() *geminiThinkingConfig {
+	if p.reasoning.ReasoningEffort == config.ReasoningOff {
+		off := 0
+		return &geminiThinkingConfig{ThinkingBudget: &off}
+	}
+
+	p.modelsMu.Lock()
+	thinks := p.models[p.model].Thinking
+	p.modelsMu.Unlock()
+
+	budget := thinkingBudget(p.reasoning)
+	if !thinks && budget == 0 {
+		return nil
+	}
+	cfg := &geminiThinkingConfig{IncludeThoughts: true}
+	if budget > 0 {
+		cfg.ThinkingBudget = &budget
+	}
+	return cfg
+}
+
+// Chat implements Provider.Chat by delegating to ChatWithTools with no tools.
+func (p *GeminiProvider) Chat(ctx context.Context, messages []model.Message, callback model.StreamCallback) error {
+	return p.ChatWithTools(ctx, messages, nil, callback)
+}
+
+// ChatWithTools implements Provider.ChatWithTools with streaming support.
+func (p *GeminiProvider) ChatWithTools(ctx context.Context, messages []model.Message, tools []mcptypes.Tool, callback model.StreamCallback) error {
+	contents, systemPrompt := convertToGeminiContents(convertMessageToolNamesForOpenRouter(messages))
+
+	// Tool instructions go FIRST (Layer 1), then user prompts (Layer 2)
+	if len(tools) > 0 {
+		systemPrompt = append([]string{buildGeminiToolInstructions(tools)}, systemPrompt...)
+	}
+
+	req := geminiRequest{Contents: contents}
+	if len(systemPrompt) > 0 {
+		req.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: strings.Join(systemPrompt, "\n\n")}}}
+	}
+
+	// Function names share OpenRouter's restrictions, so dots become "__"
+	if len(tools) > 0 {
+		req.Tools = []geminiTool{{FunctionDeclarations: mcp.ConvertMCPToolsToGeminiFormat(convertToolNamesForOpenRouter(tools))}}
+	}
+
+	req.GenerationConfig = p.generationConfig(ctx)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode Gemini request: %w", err)
+	}
+
+	resp, err := p.do(ctx, http.MethodPost, "/models/"+url.PathEscape(p.model)+":streamGenerateContent?alt=sse", body)
+	if err != nil {
+		return fmt.Errorf("Gemini streaming error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var contentBuilder strings.Builder
+	var toolCalls []model.ToolCall
+	var usage model.Usage
+
+	// Server-sent events, one JSON response per "data:" line
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("Gemini streaming error: invalid response: %w", err)
+		}
+		if reason := chunk.PromptFeedback.BlockReason; reason != "" {
+			return fmt.Errorf("Gemini blocked the prompt (%s)", reason)
+		}
+
+		// Each chunk repeats the usage so far; the last one has the totals
+		if chunk.UsageMetadata.PromptTokenCount > 0 {
+			usage = model.Usage{
+				PromptTokens:     chunk.UsageMetadata.PromptTokenCount,
+				CompletionTokens: chunk.UsageMetadata.CandidatesTokenCount + chunk.UsageMetadata.ThoughtsTokenCount,
+			}
+		}
+
+		if len(chunk.Candidates) == 0 {
+			continue
+		}
+		for _, part := range chunk.Candidates[0].Content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				toolCalls = append(toolCalls, model.ToolCall{
+					ID:        part.FunctionCall.ID, // Usually empty; the model layer assigns one
+					Name:      convertToolNameFromOpenRouter(part.FunctionCall.Name),
+					Arguments: part.FunctionCall.Args,
+				})
+			case part.Text != "" && part.Thought:
+				if callback != nil {
+					callback("", part.Text, nil, nil)
+				}
+			case part.Text != "":
+				contentBuilder.WriteString(part.Text)
+				if callback != nil {
+					callback(part.Text, "", nil, nil)
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Gemini streaming error: %w", err)
+	}
+
+	if callback == nil {
+		return nil
+	}
+
+	if len(toolCalls) > 0 {
+		callback("", "", toolCalls, nil)
+	} else {
+		// Safety check: detect leaked tool calls if none were detected via API
+		fullContent := contentBuilder.String()
+
+		if leakedCalls := ParseLeakedJSONToolCalls(fullContent); len(leakedCalls) > 0 {
+			for i := range leakedCalls {
+				leakedCalls[i].Name = convertToolNameFromOpenRouter(leakedCalls[i].Name)
+			}
+			callback("", "", leakedCalls, nil)
+		}
+
+		if leakedCalls := ParseLeakedXMLToolCalls(fullContent); len(leakedCalls) > 0 {
+			for i := range leakedCalls {
+				leakedCalls[i].Name = convertToolNameFromOpenRouter(leakedCalls[i].Name)
+			}
+			callback("", "", leakedCalls, nil)
+		}
+	}
+
+	if usage.PromptTokens > 0 || usage.CompletionTokens > 0 {
+		return callback("", "", nil, &usage)
+	}
+	return nil
+}
+
+// ListModels implements Provider.ListModels. Only models that can generate
+// content are listed (no embedding or image models).
+func (p *GeminiProvider) ListModels(ctx context.Context) ([]ollama.ModelInfo, error) {
+	var result []ollama.ModelInfo
+	pageToken := ""
+
+	for {
+		path := "/models?pageSize=1000"
+		if pageToken != "" {
+			path += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		var page geminiModelList
+		if err := p.getJSON(ctx, path, &page); err != nil {
+			return nil, fmt.Errorf("failed to list Gemini models: %w", err)
+		}
+
+		for _, m := range page.Models {
+			if !slices.Contains(m.SupportedGenerationMethods, "generateContent") {
+				continue
+			}
+			name := strings.TrimPrefix(m.Name, "models/")
+			p.cacheModel(name, m)
+			result = append(result, ollama.ModelInfo{
+				Name:         name,
+				InternalName: name,
+				Size:         0,        // Gemini doesn't provide size info
+				Provider:     "gemini", // CRITICAL: Must match provider ID
+			})
+		}
+
+		if page.NextPageToken == "" {
+			return result, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// GetModel implements Provider.GetModel.
+func (p *GeminiProvider) GetModel() string {
+	return p.model
+}
+
+// GetDisplayName implements Provider.GetDisplayName (same as GetModel for Gemini).
+func (p *GeminiProvider) GetDisplayName() string {
+	return p.model
+}
+
+// SetModel implements Provider.SetModel.
+func (p *GeminiProvider) SetModel(model string) {
+	p.model = model
+}
+
+// Ping implements Provider.Ping by listing a single model.
+func (p *GeminiProvider) Ping(ctx context.Context) error {
+	var page geminiModelList
+	if err := p.getJSON(ctx, "/models?pageSize=1", &page); err != nil {
+		return fmt.Errorf("Gemini ping failed: %w", err)
+	}
+	return nil
+}
+
+// GetModelMetadata returns metadata for the specified model. The context
+// window and output limit come from the API's inputTokenLimit and
+// outputTokenLimit, with fallback metadata when the lookup fails.
+func (p *GeminiProvider) GetModelMetadata(ctx context.Context, modelName string) (model.ModelMetadata, error) {
+	// Gemini models accept images and call functions; Gemma models served
+	// through the same API do neither
+	isGemini := strings.HasPrefix(modelName, "gemini")
+
+	p.modelsMu.Lock()
+	info, ok := p.models[modelName]
+	p.modelsMu.Unlock()
+
+	if !ok {
+		if err := p.getJSON(ctx, "/models/"+url.PathEscape(modelName), &info); err == nil {
+			p.cacheModel(modelName, info)
+			ok = true
+		} else if config.DebugLog != nil {
+			config.DebugLog.Printf("[Gemini] Model lookup for %s failed, using fallback metadata: %v", modelName, err)
+		}
+	}
+
+	if ok && info.InputTokenLimit > 0 {
+		return model.ModelMetadata{
+			ContextWindow:  info.InputTokenLimit,
+			MaxOutput:      info.OutputTokenLimit,
+			SupportsTools:  isGemini,
+			SupportsVision: isGemini,
+		}, nil
+	}
+
+	meta := GetFallbackMetadata(modelName, GeminiFallbackMetadata)
+	return model.ModelMetadata{
+		ContextWindow:  meta.ContextWindow,
+		MaxOutput:      meta.MaxOutput,
+		SupportsTools:  meta.SupportsTools,
+		SupportsVision: meta.SupportsVision,
+	}, nil
+}
+
+// cacheModel remembers a model's limits for GetModelMetadata
+func (p *GeminiProvider) cacheModel(name string, m geminiModel) {
+	p.modelsMu.Lock()
+	p.models[name] = m
+	p.modelsMu.Unlock()
+}
+
+// getJSON performs a GET request and decodes the JSON response into v
+func (p *GeminiProvider) getJSON(ctx context.Context, path string, v any) error {
+	resp, err := p.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// do sends an authenticated request and turns error responses into errors
+// carrying the API's message
+func (p *GeminiProvider) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-goog-api-key", p.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	// {"error": {"code": 400, "message": "...", "status": "INVALID_ARGUMENT"}}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	err = fmt.Errorf("%s", resp.Status)
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+		err = fmt.Errorf("%s: %s", resp.Status, apiErr.Error.Message)
+	}
+	return nil, &model.APIError{StatusCode: resp.StatusCode, RetryAfter: retryAfter(resp.Header), Err: err}
+}
+
+// convertToGeminiContents converts OTUI messages to Gemini contents.
+// Returns the contents and the system prompts, which Gemini takes separately.
+//
+// Assistant tool calls become functionCall parts. Results for one assistant
+// turn go back together as functionResponse parts of a single user turn;
+// Gemini pairs them with the calls by name and order, so call IDs are not sent.
+func convertToGeminiContents(messages []model.Message) ([]geminiContent, []string) {
+	var systemPrompt []string
+	contents := make([]geminiContent, 0, len(messages))
+
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
+		switch msg.Role {
+		case "system":
+			systemPrompt = append(systemPrompt, msg.Content)
+
+		case "user":
+			var parts []geminiPart
+			for _, image := range msg.Images() {
+				parts = append(parts, geminiPart{InlineData: &geminiBlob{
+					MIMEType: image.MIMEType,
+					Data:     base64.StdEncoding.EncodeToString(image.Data),
+				}})
+			}
+			if text := msg.ContentWithFiles(); text != "" || len(parts) == 0 {
+				parts = append(parts, geminiPart{Text: text})
+			}
+			contents = append(contents, geminiContent{Role: "user", Parts: parts})
+
+		case "assistant":
+			var parts []geminiPart
+			if msg.Content != "" || len(msg.ToolCalls) == 0 {
+				parts = append(parts, geminiPart{Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{
+					Name: call.Name,
+					Args: call.Arguments,
+				}})
+			}
+			contents = append(contents, geminiContent{Role: "model", Parts: parts})
+
+		case "tool":
+			var parts []geminiPart
+			for ; i < len(messages) && messages[i].Role == "tool"; i++ {
+				result := messages[i]
+				if result.ToolCall == nil {
+					// Unpaired result - send as plain text so it isn't lost
+					parts = append(parts, geminiPart{Text: result.Content})
+					continue
+				}
+				response := map[string]any{"content": result.Content}
+				if result.ToolError != "" {
+					response = map[string]any{"error": result.Content}
+				}
+				parts = append(parts, geminiPart{FunctionResponse: &geminiFunctionResponse{
+					Name:     strings.ReplaceAll(result.ToolCall.Name, ".", "__"),
+					Response: response,
+				}})
+			}
+			i-- // Outer loop advances past the last tool message
+			contents = append(contents, geminiContent{Role: "user", Parts: parts})
+
+		default:
+			contents = append(contents, geminiContent{Role: "user", Parts: []geminiPart{{Text: msg.Content}}})
+		}
+	}
+
+	return contents, systemPrompt
+}
@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"otui/config"
+	"otui/model"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+)
+
+// newGeminiStub serves recorded Gemini API responses from testdata/gemini.
+// routes maps "METHOD path" to a fixture file; anything else is a 404.
+func newGeminiStub(t *testing.T, routes map[string]string, onRequest func(*http.Request, []byte)) *GeminiProvider {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			t.Errorf("%s %s: missing API key header", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if onRequest != nil {
+			onRequest(r, body)
+		}
+
+		fixture, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			http.Error(w, `{"error": {"code": 404, "message": "not found", "status": "NOT_FOUND"}}`, http.StatusNotFound)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", "gemini", fixture))
+		if err != nil {
+			t.Fatalf("read fixture: %v", err)
+		}
+		if strings.HasPrefix(fixture, "error_") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := NewGeminiProvider(server.URL, "test-key", "gemini-2.5-flash")
+	if err != nil {
+		t.Fatalf("NewGeminiProvider: %v", err)
+	}
+	return p
+}
+
+func TestGeminiChatWithTools(t *testing.T) {
+	var sent geminiRequest
+	p := newGeminiStub(t, map[string]string{
+		"POST /models/gemini-2.5-flash:streamGenerateContent": "stream_tool_call.sse",
+	}, func(r *http.Request, body []byte) {
+		if r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("expected alt=sse, got %q", r.URL.RawQuery)
+		}
+		if err := json.Unmarshal(body, &sent); err != nil {
+			t.Errorf("request body: %v", err)
+		}
+	})
+	p.SetReasoning(config.ReasoningConfig{ThinkingBudget: 2048})
+	temperature := 0.3
+	p.SetGenerationParams(config.GenerationParams{Temperature: &temperature, MaxTokens: 1024})
+
+	tools := []mcptypes.Tool{{
+		Name:        "server-filesystem.read_file",
+		Description: "Read a file",
+		InputSchema: mcptypes.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]any{"path": map[string]any{"type": "string"}},
+			Required:   []string{"path"},
+		},
+	}}
+	messages := []model.Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "read the Dockerfile"},
+	}
+
+	var text, thoughts strings.Builder
+	var calls []model.ToolCall
+	var usage *model.Usage
+	err := p.ChatWithTools(context.Background(), messages, tools, func(chunk, reasoning string, toolCalls []model.ToolCall, u *model.Usage) error {
+		text.WriteString(chunk)
+		thoughts.WriteString(reasoning)
+		calls = append(calls, toolCalls...)
+		if u != nil {
+			usage = u
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatWithTools: %v", err)
+	}
+
+	if got := text.String(); got != "Let me read the file." {
+		t.Errorf("streamed text = %q", got)
+	}
+	if got := thoughts.String(); !strings.Contains(got, "wants the Dockerfile") {
+		t.Errorf("reasoning = %q, want the thought part", got)
+	}
+	if len(calls) != 1 || calls[0].Name != "server-filesystem.read_file" || calls[0].Arguments["path"] != "Dockerfile" {
+		t.Errorf("tool calls = %+v, want read_file(Dockerfile)", calls)
+	}
+	if usage == nil || usage.PromptTokens != 412 || usage.CompletionTokens != 68 {
+		t.Errorf("usage = %+v, want 412 prompt and 68 completion tokens (thoughts included)", usage)
+	}
+
+	if sent.SystemInstruction == nil || !strings.HasSuffix(sent.SystemInstruction.Parts[0].Text, "be brief") {
+		t.Errorf("system prompt not sent as systemInstruction: %+v", sent.SystemInstruction)
+	}
+	if len(sent.Contents) != 1 || sent.Contents[0].Role != "user" {
+		t.Errorf("contents = %+v, want one user turn", sent.Contents)
+	}
+	if len(sent.Tools) != 1 || sent.Tools[0].FunctionDeclarations[0].Name != "server-filesystem__read_file" {
+		t.Errorf("tools = %+v, want read_file declared with an API-safe name", sent.Tools)
+	}
+	if sent.GenerationConfig == nil || sent.GenerationConfig.ThinkingConfig == nil ||
+		!sent.GenerationConfig.ThinkingConfig.IncludeThoughts || sent.GenerationConfig.ThinkingConfig.ThinkingBudget == nil ||
+		*sent.GenerationConfig.ThinkingConfig.ThinkingBudget != 2048 {
+		t.Errorf("generationConfig = %+v, want thoughts with a 2048 token budget", sent.GenerationConfig)
+	}
+	if gen := sent.GenerationConfig; gen == nil || gen.Temperature == nil || *gen.Temperature != 0.3 || gen.MaxOutputTokens != 1024 || gen.Seed != nil {
+		t.Errorf("generationConfig = %+v, want temperature 0.3 and 1024 output tokens", gen)
+	}
+}
+
+func TestGeminiChatError(t *testing.T) {
+	p := newGeminiStub(t, map[string]string{
+		"POST /models/gemini-2.5-flash:streamGenerateContent": "error_invalid_argument.json",
+	}, nil)
+
+	err := p.Chat(context.Background(), []model.Message{{Role: "user", Content: "hi"}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "should be non-empty for OBJECT type") {
+		t.Errorf("err = %v, want the API's error message", err)
+	}
+}
+
+func TestGeminiListModelsAndMetadata(t *testing.T) {
+	p := newGeminiStub(t, map[string]string{
+		"GET /models": "models.json",
+	}, nil)
+
+	models, err := p.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("got %d models, want 2 (embedding model filtered out)", len(models))
+	}
+	if models[0].InternalName != "gemini-2.5-flash" || models[0].Provider != "gemini" {
+		t.Errorf("first model = %+v", models[0])
+	}
+
+	meta, err := p.GetModelMetadata(context.Background(), "gemini-2.0-flash-lite")
+	if err != nil {
+		t.Fatalf("GetModelMetadata: %v", err)
+	}
+	if meta.ContextWindow != 1048576 || meta.MaxOutput != 8192 || !meta.SupportsTools || !meta.SupportsVision {
+		t.Errorf("metadata = %+v, want the API's limits", meta)
+	}
+
+	// Unknown to the API - falls back to the built-in table
+	meta, _ = p.GetModelMetadata(context.Background(), "gemini-1.5-pro-002")
+	if meta.ContextWindow != 2097152 {
+		t.Errorf("fallback context window = %d, want 2097152", meta.ContextWindow)
+	}
+}
+
+func TestConvertToGeminiContents(t *testing.T) {
+	callA := model.ToolCall{ID: "call_a", Name: "fs__read_file", Arguments: map[string]any{"path": "a"}}
+	callB := model.ToolCall{ID: "call_b", Name: "fs__list"}
+	input := []model.Message{
+		{Role: "user", Content: "look", Attachments: []model.Attachment{{Name: "x.png", MIMEType: "image/png", Data: []byte{1}}}},
+		{Role: "assistant", ToolCalls: []model.ToolCall{callA, callB}},
+		{Role: "tool", Content: "contents", ToolCall: &callA},
+		{Role: "tool", Content: "denied", ToolCall: &callB, ToolError: "denied"},
+		{Role: "assistant", Content: "Done."},
+	}
+
+	contents, system := convertToGeminiContents(input)
+
+	if len(system) != 0 || len(contents) != 4 {
+		t.Fatalf("got %d contents and %d system prompts, want 4 and 0", len(contents), len(system))
+	}
+	if user := contents[0].Parts; len(user) != 2 || user[0].InlineData == nil || user[0].InlineData.Data != "AQ==" {
+		t.Errorf("user parts = %+v, want image then text", user)
+	}
+	if turn := contents[1]; turn.Role != "model" || len(turn.Parts) != 2 || turn.Parts[0].FunctionCall == nil {
+		t.Errorf("assistant turn = %+v, want two functionCall parts", turn)
+	}
+
+	results := contents[2]
+	if results.Role != "user" || len(results.Parts) != 2 {
+		t.Fatalf("results = %+v, want one user turn with both responses", results)
+	}
+	if r := results.Parts[0].FunctionResponse; r == nil || r.Name != "fs__read_file" || r.Response["content"] != "contents" {
+		t.Errorf("first result = %+v", r)
+	}
+	if r := results.Parts[1].FunctionResponse; r == nil || r.Response["error"] != "denied" {
+		t.Errorf("failed result = %+v, want an error response", r)
+	}
+}
@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"strings"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+)
+
+// buildGeminiToolInstructions creates tool instructions for Gemini models.
+// Gemini calls functions reliably but tends to describe a plan first.
+func buildGeminiToolInstructions(tools []mcptypes.Tool) string {
+	toolNames := []string{}
+	for _, tool := range tools {
+		toolNames = append(toolNames, tool.Name)
+	}
+
+	return strings.Join([]string{
+		"TOOLS: " + strings.Join(toolNames, ", "),
+		"",
+		"When the user asks you to do something that requires a tool:",
+		"1. Determine which tool is needed",
+		"2. Check if you have all required parameters",
+		"3. If yes: Call the function IMMEDIATELY, without describing a plan first",
+		"4. If no: Ask for the missing parameter ONLY",
+		"",
+		"DO NOT:",
+		"- List available tools",
+		"- Explain what you're about to do",
+		"- Write out a function call as text instead of calling it",
+		"",
+		"Example:",
+		"User: 'Read Dockerfile'",
+		"You: [call read_file('Dockerfile')]",
+		"NOT: 'I can read files. What would you like?'",
+	}, "\n")
+}
@@ -11,6 +11,7 @@ import (
 // It handles:
 //   - Creating the Ollama provider (if configured)
 //   - Creating all enabled cloud providers (OpenRouter, Anthropic, etc.)
+//   - Creating user-defined OpenAI-compatible providers (vLLM, llama.cpp, etc.)
 //   - Loading API keys from credential store
 //   - Mapping provider IDs to provider types
 //   - Graceful degradation (logs warnings but doesn't fail)
@@ -59,8 +60,8 @@ func InitializeProviders(cfg *config.Config) map[string]model.Provider {
 			apiKey = cfg.CredentialStore.Get(providerCfg.ID)
 		}
 
-		// Map provider ID to Type (handles openrouter→openai mapping)
-		providerType := MapProviderIDToType(providerCfg.ID)
+		// Map provider ID to Type (custom providers declare their own type)
+		providerType := ProviderTypeForConfig(providerCfg)
 
 		// Create provider via factory
 		p, err := NewProvider(Config{
@@ -68,6 +69,11 @@ func InitializeProviders(cfg *config.Config) map[string]model.Provider {
 			BaseURL: providerCfg.BaseURL,
 			APIKey:  apiKey,
 			Model:   "", // Will be set when session loads
+			ID:      providerCfg.ID,
+			Name:    providerCfg.Name,
+			Headers: providerCfg.Headers,
+
+			Reasoning: providerCfg.ReasoningConfig,
 		})
 
 		if err != nil {
@@ -100,6 +106,8 @@ func initializeOllama(cfg *config.Config) model.Provider {
 		BaseURL: cfg.OllamaURL(),
 		Model:   cfg.Model(),
 		APIKey:  apiKey,
+
+		Reasoning: cfg.OllamaReasoning,
 	}
 
 	p, err := NewProvider(providerCfg)
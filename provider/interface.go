@@ -42,6 +42,8 @@
 //	err = p.Chat(ctx, messages, callback)
 package provider
 
+import "otui/config"
+
 // Note: The Provider interface and StreamCallback are defined in the model package
 // (model/provider.go) to avoid import cycles. This package implements model.Provider.
 
@@ -53,6 +55,11 @@ const (
 	ProviderTypeOpenRouter ProviderType = "openrouter"
 	ProviderTypeOpenAI     ProviderType = "openai"
 	ProviderTypeAnthropic  ProviderType = "anthropic"
+	ProviderTypeGemini     ProviderType = "gemini"
+
+	// ProviderTypeOpenAICompatible is a user-defined server speaking the
+	// OpenAI API (vLLM, llama.cpp, LM Studio, LiteLLM)
+	ProviderTypeOpenAICompatible ProviderType = config.ProviderTypeOpenAICompatible
 )
 
 // Config holds provider-specific configuration.
@@ -60,5 +67,11 @@ type Config struct {
 	Type    ProviderType
 	BaseURL string
 	Model   string
-	APIKey  string // For OpenAI/Anthropic (unused for Ollama)
+	APIKey  string            // For OpenAI/Anthropic (unused for Ollama)
+	ID      string            // Provider ID models are listed under (custom providers)
+	Name    string            // Display name used in errors (custom providers)
+	Headers map[string]string // Extra HTTP headers (custom providers)
+
+	// Reasoning sets how much reasoning models think (reasoning_effort, thinking_budget)
+	Reasoning config.ReasoningConfig
 }
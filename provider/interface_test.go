@@ -46,7 +46,7 @@ func testProviderBasicChat(t *testing.T, p model.Provider) {
 	messages := testutil.SingleUserMessage("Hello")
 	var receivedChunk string
 
-	err := p.Chat(ctx, messages, func(chunk string, toolCalls []model.ToolCall) error {
+	err := p.Chat(ctx, messages, func(chunk, reasoning string, toolCalls []model.ToolCall, usage *model.Usage) error {
 		receivedChunk = chunk
 		return nil
 	})
@@ -68,7 +68,7 @@ func testProviderChatWithTools(t *testing.T, p model.Provider) {
 	tools := testutil.TestMCPTools()
 	var receivedChunk string
 
-	err := p.ChatWithTools(ctx, messages, tools, func(chunk string, toolCalls []model.ToolCall) error {
+	err := p.ChatWithTools(ctx, messages, tools, func(chunk, reasoning string, toolCalls []model.ToolCall, usage *model.Usage) error {
 		receivedChunk = chunk
 		return nil
 	})
@@ -2,9 +2,10 @@ package provider
 
 // ModelMetadata contains metadata about a model's capabilities
 type ModelMetadata struct {
-	ContextWindow int  `json:"context_window"`
-	MaxOutput     int  `json:"max_output"`
-	SupportsTools bool `json:"supports_tools"`
+	ContextWindow  int  `json:"context_window"`
+	MaxOutput      int  `json:"max_output"`
+	SupportsTools  bool `json:"supports_tools"`
+	SupportsVision bool `json:"supports_vision"`
 }
 
 // Fallback metadata for when API doesn't provide context window info
@@ -35,30 +36,50 @@ var OllamaFallbackMetadata = map[string]ModelMetadata{
 
 	// CodeLlama
 	"codellama":       {ContextWindow: 16384, MaxOutput: 4096, SupportsTools: false},
+
+	// Vision models (Ollama's show API reports this too; these cover older servers)
+	"llava":           {ContextWindow: 4096, MaxOutput: 2048, SupportsTools: false, SupportsVision: true},
+	"bakllava":        {ContextWindow: 4096, MaxOutput: 2048, SupportsTools: false, SupportsVision: true},
+	"llama3.2-vision": {ContextWindow: 128000, MaxOutput: 8192, SupportsTools: false, SupportsVision: true},
+	"qwen2.5vl":       {ContextWindow: 128000, MaxOutput: 8192, SupportsTools: true, SupportsVision: true},
+	"gemma3":          {ContextWindow: 128000, MaxOutput: 8192, SupportsTools: false, SupportsVision: true},
+	"minicpm-v":       {ContextWindow: 32768, MaxOutput: 4096, SupportsTools: false, SupportsVision: true},
+	"moondream":       {ContextWindow: 2048, MaxOutput: 1024, SupportsTools: false, SupportsVision: true},
 }
 
 // AnthropicFallbackMetadata contains fallback metadata for Anthropic models
 var AnthropicFallbackMetadata = map[string]ModelMetadata{
-	"claude-sonnet-4":              {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true},
-	"claude-sonnet-4-5":            {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true},
-	"claude-opus-4":                {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true},
-	"claude-opus-4-5":              {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true},
-	"claude-3-5-sonnet-20241022":   {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true},
-	"claude-3-5-sonnet-20240620":   {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true},
-	"claude-3-opus-20240229":       {ContextWindow: 200000, MaxOutput: 4096, SupportsTools: true},
-	"claude-3-sonnet-20240229":     {ContextWindow: 200000, MaxOutput: 4096, SupportsTools: true},
-	"claude-3-haiku-20240307":      {ContextWindow: 200000, MaxOutput: 4096, SupportsTools: true},
+	"claude-sonnet-4":              {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true, SupportsVision: true},
+	"claude-sonnet-4-5":            {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true, SupportsVision: true},
+	"claude-opus-4":                {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true, SupportsVision: true},
+	"claude-opus-4-5":              {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true, SupportsVision: true},
+	"claude-3-5-sonnet-20241022":   {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true, SupportsVision: true},
+	"claude-3-5-sonnet-20240620":   {ContextWindow: 200000, MaxOutput: 8192, SupportsTools: true, SupportsVision: true},
+	"claude-3-opus-20240229":       {ContextWindow: 200000, MaxOutput: 4096, SupportsTools: true, SupportsVision: true},
+	"claude-3-sonnet-20240229":     {ContextWindow: 200000, MaxOutput: 4096, SupportsTools: true, SupportsVision: true},
+	"claude-3-haiku-20240307":      {ContextWindow: 200000, MaxOutput: 4096, SupportsTools: true, SupportsVision: true},
 }
 
 // OpenAIFallbackMetadata contains fallback metadata for OpenAI models
 var OpenAIFallbackMetadata = map[string]ModelMetadata{
-	"gpt-4o":                  {ContextWindow: 128000, MaxOutput: 16384, SupportsTools: true},
-	"gpt-4o-mini":             {ContextWindow: 128000, MaxOutput: 16384, SupportsTools: true},
-	"gpt-4-turbo":             {ContextWindow: 128000, MaxOutput: 4096, SupportsTools: true},
+	"gpt-4o":                  {ContextWindow: 128000, MaxOutput: 16384, SupportsTools: true, SupportsVision: true},
+	"gpt-4o-mini":             {ContextWindow: 128000, MaxOutput: 16384, SupportsTools: true, SupportsVision: true},
+	"gpt-4-turbo":             {ContextWindow: 128000, MaxOutput: 4096, SupportsTools: true, SupportsVision: true},
 	"gpt-4":                   {ContextWindow: 8192, MaxOutput: 4096, SupportsTools: true},
 	"gpt-3.5-turbo":           {ContextWindow: 16385, MaxOutput: 4096, SupportsTools: true},
 }
 
+// GeminiFallbackMetadata contains fallback metadata for Gemini models, used
+// when the model can't be looked up through the API
+var GeminiFallbackMetadata = map[string]ModelMetadata{
+	"gemini-2.5-pro":        {ContextWindow: 1048576, MaxOutput: 65536, SupportsTools: true, SupportsVision: true},
+	"gemini-2.5-flash":      {ContextWindow: 1048576, MaxOutput: 65536, SupportsTools: true, SupportsVision: true},
+	"gemini-2.5-flash-lite": {ContextWindow: 1048576, MaxOutput: 65536, SupportsTools: true, SupportsVision: true},
+	"gemini-2.0-flash":      {ContextWindow: 1048576, MaxOutput: 8192, SupportsTools: true, SupportsVision: true},
+	"gemini-1.5-pro":        {ContextWindow: 2097152, MaxOutput: 8192, SupportsTools: true, SupportsVision: true},
+	"gemini-1.5-flash":      {ContextWindow: 1048576, MaxOutput: 8192, SupportsTools: true, SupportsVision: true},
+}
+
 // GetFallbackMetadata returns fallback metadata for a model based on its name
 // This uses pattern matching on the model name to find the best match
 func GetFallbackMetadata(modelName string, fallbackMap map[string]ModelMetadata) ModelMetadata {
@@ -3,10 +3,13 @@ package provider
 import (
 	"context"
 	"fmt"
+	"otui/config"
 	"otui/mcp"
 	"otui/model"
 	"otui/ollama"
+	"slices"
 	"strings"
+	"sync"
 
 	mcptypes "github.com/mark3labs/mcp-go/mcp"
 	"github.com/ollama/ollama/api"
@@ -18,7 +21,19 @@ import (
 // and Ollama's specific API types. It converts model.Message to api.Message,
 // mcptypes.Tool to api.Tool, and api.ToolCall to provider.ToolCall.
 type OllamaProvider struct {
-	client *ollama.Client
+	client     *ollama.Client
+	reasoning  config.ReasoningConfig
+	generation config.GenerationParams
+
+	capabilitiesMu sync.Mutex
+	capabilities   map[string]modelCapabilities // Model name -> what the server reported, looked up once
+}
+
+// modelCapabilities is the server's answer about one model; err is kept so a
+// failed lookup isn't repeated on every request
+type modelCapabilities struct {
+	names []string
+	err   error
 }
 
 // NewOllamaProvider creates a new Ollama provider instance.
@@ -63,7 +78,7 @@ func NewOllamaProvider(baseURL, model, apiKey string) (*OllamaProvider, error) {
 //	messages := []model.Message{
 //	    {Role: "user", Content: "Hello!"},
 //	}
-//	err := provider.Chat(ctx, messages, func(chunk string, tools []ToolCall) error {
+//	err := provider.Chat(ctx, messages, func(chunk, reasoning string, tools []ToolCall, usage *Usage) error {
 //	    fmt.Print(chunk)
 //	    return nil
 //	})
@@ -78,15 +93,17 @@ func (p *OllamaProvider) Chat(ctx context.Context, messages []model.Message, cal
 //   - Converts mcptypes.Tool to api.Tool (MCP → Ollama tools)
 //   - Converts api.ToolCall to provider.ToolCall (Ollama → provider tool calls)
 //
-// The response is streamed back through the callback, which receives both text chunks
-// and any tool calls requested by the model. Tool calls are converted to provider-agnostic
-// format before being passed to the callback.
+// The response is streamed back through the callback, which receives text chunks,
+// the model's thinking (which Ollama separates for thinking models) and any tool
+// calls requested by the model. Tool calls are converted to provider-agnostic
+// format before being passed to the callback. Ollama's prompt_eval_count and
+// eval_count are passed on as usage in a final callback.
 //
 // Example:
 //
 //	messages := []model.Message{{Role: "user", Content: "What's the weather?"}}
 //	tools := []mcptypes.Tool{weatherTool} // MCP tool definition
-//	err := provider.ChatWithTools(ctx, messages, tools, func(chunk string, toolCalls []ToolCall) error {
+//	err := provider.ChatWithTools(ctx, messages, tools, func(chunk, reasoning string, toolCalls []ToolCall, usage *Usage) error {
 //	    if len(toolCalls) > 0 {
 //	        // Handle tool calls
 //	        for _, call := range toolCalls {
@@ -109,13 +126,22 @@ func (p *OllamaProvider) ChatWithTools(ctx context.Context, messages []model.Mes
 	// Track content and tool calls for leak detection
 	var contentBuilder strings.Builder
 	var apiToolCallsDetected bool
+	var usage *model.Usage
 
 	// Wrap the provider callback to convert Ollama tool calls and track for leaks
-	ollamaCallback := func(chunk string, ollamaCalls []api.ToolCall) error {
+	ollamaCallback := func(chunk, thinking string, ollamaCalls []api.ToolCall, metrics *api.Metrics) error {
 		if callback == nil {
 			return nil
 		}
 
+		// Reported with the final response; passed on after leak detection
+		if metrics != nil && (metrics.PromptEvalCount > 0 || metrics.EvalCount > 0) {
+			usage = &model.Usage{
+				PromptTokens:     metrics.PromptEvalCount,
+				CompletionTokens: metrics.EvalCount,
+			}
+		}
+
 		// Track content for leak detection
 		contentBuilder.WriteString(chunk)
 
@@ -124,12 +150,13 @@ func (p *OllamaProvider) ChatWithTools(ctx context.Context, messages []model.Mes
 		if len(providerCalls) > 0 {
 			apiToolCallsDetected = true
 		}
-		return callback(chunk, providerCalls)
+		return callback(chunk, thinking, providerCalls, nil)
 	}
 
-	err := p.client.ChatWithTools(ctx, ollamaMessages, ollamaTools, ollamaCallback)
+	opts := ollama.ChatOptions{Think: p.thinkValue(ctx), Options: p.modelOptions(ctx)}
+	err := p.client.ChatWithOptions(ctx, ollamaMessages, ollamaTools, opts, ollamaCallback)
 	if err != nil {
-		return err
+		return apiError(err)
 	}
 
 	// Safety check: detect leaked tool calls if none were detected via API
@@ -138,18 +165,120 @@ func (p *OllamaProvider) ChatWithTools(ctx context.Context, messages []model.Mes
 
 		// Check for JSON leaked tool calls
 		if leakedCalls := ParseLeakedJSONToolCalls(fullContent); len(leakedCalls) > 0 {
-			callback("", leakedCalls)
+			callback("", "", leakedCalls, nil)
 		}
 
 		// Check for XML leaked tool calls
 		if leakedCalls := ParseLeakedXMLToolCalls(fullContent); len(leakedCalls) > 0 {
-			callback("", leakedCalls)
+			callback("", "", leakedCalls, nil)
 		}
 	}
 
+	if usage != nil && callback != nil {
+		return callback("", "", nil, usage)
+	}
+
 	return nil
 }
 
+// SetReasoning sets the reasoning_effort applied to thinking models.
+// Ollama has no token budget, so ThinkingBudget is ignored.
+func (p *OllamaProvider) SetReasoning(r config.ReasoningConfig) {
+	p.reasoning = r
+}
+
+// SetGenerationParams implements model.TunableProvider
+func (p *OllamaProvider) SetGenerationParams(params config.GenerationParams) {
+	p.generation = params
+}
+
+// modelOptions returns the generation parameters as Ollama model options,
+// nil when none are set
+func (p *OllamaProvider) modelOptions(ctx context.Context) map[string]any {
+	g := requestParams(ctx, &p.generation)
+	options := map[string]any{}
+	if g.Temperature != nil {
+		options["temperature"] = *g.Temperature
+	}
+	if g.TopP != nil {
+		options["top_p"] = *g.TopP
+	}
+	if g.MaxTokens > 0 {
+		options["num_predict"] = g.MaxTokens
+	}
+	if g.Seed != nil {
+		options["seed"] = *g.Seed
+	}
+	if g.NumCtx > 0 {
+		options["num_ctx"] = g.NumCtx
+	}
+	if len(options) == 0 {
+		return nil
+	}
+	return options
+}
+
+// thinkValue returns the think option for the current model, nil to leave it
+// to the server (which turns thinking on for models that can think)
+func (p *OllamaProvider) thinkValue(ctx context.Context) *api.ThinkValue {
+	effort := p.reasoning.ReasoningEffort
+	modelName := p.client.GetModel()
+	isGPTOSS := strings.HasPrefix(modelName, "gpt-oss")
+
+	switch {
+	case effort == "":
+		return nil
+	case effort == config.ReasoningOff:
+		// gpt-oss always thinks and rejects turning it off
+		if isGPTOSS {
+			return nil
+		}
+		return &api.ThinkValue{Value: false}
+	case !p.canThink(ctx, modelName):
+		// Asking a model that can't think to think is an error
+		return nil
+	case isGPTOSS:
+		// Only gpt-oss takes a level; other models just think
+		return &api.ThinkValue{Value: effort}
+	default:
+		return &api.ThinkValue{Value: true}
+	}
+}
+
+// canThink reports whether the server lists "thinking" among the model's capabilities
+func (p *OllamaProvider) canThink(ctx context.Context, modelName string) bool {
+	return slices.Contains(p.loadCapabilities(ctx, modelName).names, "thinking")
+}
+
+// LoadCapabilities implements model.CapabilityLoader. The server is asked once
+// per model; a failure is cached as well, leaving the name-based guesses.
+func (p *OllamaProvider) LoadCapabilities(ctx context.Context, modelName string) error {
+	return p.loadCapabilities(ctx, modelName).err
+}
+
+// loadCapabilities returns the model's capabilities, asking the server on first use
+func (p *OllamaProvider) loadCapabilities(ctx context.Context, modelName string) modelCapabilities {
+	p.capabilitiesMu.Lock()
+	defer p.capabilitiesMu.Unlock()
+
+	if caps, ok := p.capabilities[modelName]; ok {
+		return caps
+	}
+	names, err := p.client.Capabilities(ctx, modelName)
+	if p.capabilities == nil {
+		p.capabilities = make(map[string]modelCapabilities)
+	}
+	p.capabilities[modelName] = modelCapabilities{names: names, err: err}
+	return p.capabilities[modelName]
+}
+
+// cachedCapabilities returns the model's capabilities if they were loaded
+func (p *OllamaProvider) cachedCapabilities(modelName string) []string {
+	p.capabilitiesMu.Lock()
+	defer p.capabilitiesMu.Unlock()
+	return p.capabilities[modelName].names
+}
+
 // ListModels implements Provider.ListModels (direct passthrough).
 //
 // Returns a list of all models available on the Ollama server. This is a direct
@@ -217,13 +346,20 @@ func (p *OllamaProvider) Ping(ctx context.Context) error {
 
 // GetModelMetadata returns metadata for the specified model
 // Ollama API doesn't currently expose context window info, so we use fallback metadata
-// based on model name patterns (e.g., "llama3.1:8b" matches "llama3.1")
+// based on model name patterns (e.g., "llama3.1:8b" matches "llama3.1").
+// Vision support comes from the server's model capabilities once LoadCapabilities
+// has fetched them; this never calls the server, so it is safe to use while rendering.
 func (p *OllamaProvider) GetModelMetadata(ctx context.Context, modelName string) (model.ModelMetadata, error) {
 	meta := GetFallbackMetadata(modelName, OllamaFallbackMetadata)
 
+	if capabilities := p.cachedCapabilities(modelName); len(capabilities) > 0 {
+		meta.SupportsVision = slices.Contains(capabilities, "vision")
+	}
+
 	return model.ModelMetadata{
-		ContextWindow: meta.ContextWindow,
-		MaxOutput:     meta.MaxOutput,
-		SupportsTools: meta.SupportsTools,
+		ContextWindow:  meta.ContextWindow,
+		MaxOutput:      meta.MaxOutput,
+		SupportsTools:  meta.SupportsTools,
+		SupportsVision: meta.SupportsVision,
 	}, nil
 }
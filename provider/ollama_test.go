@@ -1,6 +1,11 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"otui/config"
 	"otui/model"
 	"testing"
 )
@@ -10,6 +15,107 @@ import (
 // is not satisfied.
 func TestOllamaProviderImplementsInterface(t *testing.T) {
 	var _ model.Provider = (*OllamaProvider)(nil)
+	var _ model.TunableProvider = (*OllamaProvider)(nil)
+	var _ model.CapabilityLoader = (*OllamaProvider)(nil)
+}
+
+func TestOllamaGenerationOptions(t *testing.T) {
+	var sent struct {
+		Options map[string]any `json:"options"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"model":"llama3.1","message":{"role":"assistant","content":"hi"},"done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	p, err := NewOllamaProvider(server.URL, "llama3.1", "")
+	if err != nil {
+		t.Fatalf("NewOllamaProvider: %v", err)
+	}
+	chatWith := func(ctx context.Context) {
+		t.Helper()
+		sent.Options = nil
+		if err := p.Chat(ctx, []model.Message{{Role: "user", Content: "hi"}}, nil); err != nil {
+			t.Fatalf("Chat: %v", err)
+		}
+	}
+	chat := func() {
+		t.Helper()
+		chatWith(context.Background())
+	}
+
+	chat()
+	if sent.Options != nil {
+		t.Errorf("options = %v, want none when nothing is set", sent.Options)
+	}
+
+	temperature, seed := 0.0, 7
+	p.SetGenerationParams(config.GenerationParams{Temperature: &temperature, MaxTokens: 512, Seed: &seed, NumCtx: 32768})
+	chat()
+	want := map[string]float64{"temperature": 0, "num_predict": 512, "seed": 7, "num_ctx": 32768}
+	if len(sent.Options) != len(want) {
+		t.Errorf("options = %v, want %v", sent.Options, want)
+	}
+	for name, value := range want {
+		if got, ok := sent.Options[name].(float64); !ok || got != value {
+			t.Errorf("options[%s] = %v, want %v", name, sent.Options[name], value)
+		}
+	}
+
+	// A one-off request's parameters replace the provider's for that request only
+	chatWith(model.WithGenerationParams(context.Background(), config.GenerationParams{MaxTokens: 64}))
+	if len(sent.Options) != 1 || sent.Options["num_predict"] != float64(64) {
+		t.Errorf("one-off options = %v, want only num_predict 64", sent.Options)
+	}
+	chat()
+	if len(sent.Options) != len(want) {
+		t.Errorf("options after a one-off request = %v, want %v", sent.Options, want)
+	}
+}
+
+func TestOllamaCapabilities(t *testing.T) {
+	shown := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		shown[req.Model]++
+		if r.URL.Path != "/api/show" || req.Model != "eyes" {
+			http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"capabilities":["completion","vision"]}`))
+	}))
+	defer server.Close()
+
+	p, err := NewOllamaProvider(server.URL, "eyes", "")
+	if err != nil {
+		t.Fatalf("NewOllamaProvider: %v", err)
+	}
+
+	if meta, _ := p.GetModelMetadata(context.Background(), "eyes"); meta.SupportsVision || shown["eyes"] != 0 {
+		t.Errorf("before loading: vision = %v after %d requests, want the name-based guess without asking", meta.SupportsVision, shown["eyes"])
+	}
+	for range 2 {
+		if err := p.LoadCapabilities(context.Background(), "eyes"); err != nil {
+			t.Fatalf("LoadCapabilities: %v", err)
+		}
+		if err := p.LoadCapabilities(context.Background(), "missing"); err == nil {
+			t.Error("expected an error for a missing model")
+		}
+	}
+	if meta, _ := p.GetModelMetadata(context.Background(), "eyes"); !meta.SupportsVision {
+		t.Error("vision = false, want the server's capabilities")
+	}
+	if shown["eyes"] != 1 || shown["missing"] != 1 {
+		t.Errorf("requests = %v, want one per model, failures included", shown)
+	}
 }
 
 // Note: Integration tests that require a running Ollama server are deferred to Phase 8.
@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"otui/config"
 	"otui/mcp"
 	"otui/model"
 	"otui/ollama"
@@ -11,6 +12,7 @@ import (
 	mcptypes "github.com/mark3labs/mcp-go/mcp"
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/shared"
 )
 
 // OpenAIProvider implements the Provider interface using OpenAI's official API.
@@ -20,6 +22,9 @@ type OpenAIProvider struct {
 	model   string
 	baseURL string
 	apiKey  string
+
+	reasoning  config.ReasoningConfig
+	generation config.GenerationParams
 }
 
 // NewOpenAIProvider creates a new OpenAI provider instance.
@@ -42,9 +47,11 @@ func NewOpenAIProvider(baseURL, apiKey, model string) (*OpenAIProvider, error) {
 	}
 
 	// Create OpenAI client
+	// Retries are left to the model layer ([retry] in config.toml)
 	client := openai.NewClient(
 		option.WithBaseURL(baseURL),
 		option.WithAPIKey(apiKey),
+		option.WithMaxRetries(0),
 	)
 
 	return &OpenAIProvider{
@@ -55,6 +62,26 @@ func NewOpenAIProvider(baseURL, apiKey, model string) (*OpenAIProvider, error) {
 	}, nil
 }
 
+// SetReasoning sets the reasoning effort for o-series and GPT-5 models.
+// OpenAI takes no token budget and doesn't stream the reasoning itself.
+func (p *OpenAIProvider) SetReasoning(r config.ReasoningConfig) {
+	p.reasoning = r
+}
+
+// SetGenerationParams implements model.TunableProvider
+func (p *OpenAIProvider) SetGenerationParams(params config.GenerationParams) {
+	p.generation = params
+}
+
+// isOpenAIReasoningModel reports whether a model accepts reasoning_effort;
+// other models reject the parameter
+func isOpenAIReasoningModel(modelName string) bool {
+	if strings.HasPrefix(modelName, "gpt-5") {
+		return true
+	}
+	return len(modelName) > 1 && modelName[0] == 'o' && modelName[1] >= '0' && modelName[1] <= '9'
+}
+
 // Chat implements Provider.Chat by delegating to ChatWithTools with no tools.
 func (p *OpenAIProvider) Chat(ctx context.Context, messages []model.Message, callback model.StreamCallback) error {
 	return p.ChatWithTools(ctx, messages, nil, callback)
@@ -75,10 +102,13 @@ func (p *OpenAIProvider) ChatWithTools(ctx context.Context, messages []model.Mes
 	// Convert OTUI messages to OpenAI format
 	openaiMessages := ConvertToOpenAIMessages(messagesWithInstructions)
 
-	// Build request parameters
+	// Build request parameters (ask for token counts in the final chunk)
 	params := openai.ChatCompletionNewParams{
 		Messages: openaiMessages,
 		Model:    openai.ChatModel(p.model),
+		StreamOptions: openai.ChatCompletionStreamOptionsParam{
+			IncludeUsage: openai.Bool(true),
+		},
 	}
 
 	// Add tools if provided
@@ -87,6 +117,28 @@ func (p *OpenAIProvider) ChatWithTools(ctx context.Context, messages []model.Mes
 		params.Tools = openaiTools
 	}
 
+	if effort := p.reasoning.ReasoningEffort; effortBudgets[effort] > 0 && isOpenAIReasoningModel(p.model) {
+		params.ReasoningEffort = shared.ReasoningEffort(effort)
+	}
+
+	// OpenAI has deprecated max_tokens for max_completion_tokens, and
+	// reasoning models reject temperature and top_p
+	g := requestParams(ctx, &p.generation)
+	if g.MaxTokens > 0 {
+		params.MaxCompletionTokens = openai.Int(int64(g.MaxTokens))
+	}
+	if g.Seed != nil {
+		params.Seed = openai.Int(int64(*g.Seed))
+	}
+	if !isOpenAIReasoningModel(p.model) {
+		if g.Temperature != nil {
+			params.Temperature = openai.Float(*g.Temperature)
+		}
+		if g.TopP != nil {
+			params.TopP = openai.Float(*g.TopP)
+		}
+	}
+
 	// Create streaming request
 	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
 	acc := openai.ChatCompletionAccumulator{}
@@ -107,10 +159,11 @@ func (p *OpenAIProvider) ChatWithTools(ctx context.Context, messages []model.Mes
 				// Convert to provider tool call
 				args := ParseToolArguments(tool.Arguments)
 				toolCall := model.ToolCall{
+					ID:        tool.ID,
 					Name:      tool.Name,
 					Arguments: args,
 				}
-				callback("", []model.ToolCall{toolCall})
+				callback("", "", []model.ToolCall{toolCall}, nil)
 			}
 		}
 
@@ -119,14 +172,14 @@ func (p *OpenAIProvider) ChatWithTools(ctx context.Context, messages []model.Mes
 			content := chunk.Choices[0].Delta.Content
 			contentBuilder.WriteString(content)
 			if callback != nil {
-				callback(content, nil)
+				callback(content, "", nil, nil)
 			}
 		}
 	}
 
 	// Check for errors
 	if err := stream.Err(); err != nil {
-		return fmt.Errorf("OpenAI streaming error: %w", err)
+		return fmt.Errorf("OpenAI streaming error: %w", apiError(err))
 	}
 
 	// Safety check: detect leaked tool calls if none were detected via API
@@ -135,15 +188,23 @@ func (p *OpenAIProvider) ChatWithTools(ctx context.Context, messages []model.Mes
 
 		// Check for JSON leaked tool calls
 		if leakedCalls := ParseLeakedJSONToolCalls(fullContent); len(leakedCalls) > 0 {
-			callback("", leakedCalls)
+			callback("", "", leakedCalls, nil)
 		}
 
 		// Check for XML leaked tool calls
 		if leakedCalls := ParseLeakedXMLToolCalls(fullContent); len(leakedCalls) > 0 {
-			callback("", leakedCalls)
+			callback("", "", leakedCalls, nil)
 		}
 	}
 
+	// Usage arrives in the last chunk (zero if the server doesn't report it)
+	if callback != nil && (acc.Usage.PromptTokens > 0 || acc.Usage.CompletionTokens > 0) {
+		return callback("", "", nil, &model.Usage{
+			PromptTokens:     int(acc.Usage.PromptTokens),
+			CompletionTokens: int(acc.Usage.CompletionTokens),
+		})
+	}
+
 	return nil
 }
 
@@ -201,8 +262,9 @@ func (p *OpenAIProvider) GetModelMetadata(ctx context.Context, modelName string)
 	meta := GetFallbackMetadata(modelName, OpenAIFallbackMetadata)
 
 	return model.ModelMetadata{
-		ContextWindow: meta.ContextWindow,
-		MaxOutput:     meta.MaxOutput,
-		SupportsTools: meta.SupportsTools,
+		ContextWindow:  meta.ContextWindow,
+		MaxOutput:      meta.MaxOutput,
+		SupportsTools:  meta.SupportsTools,
+		SupportsVision: meta.SupportsVision,
 	}, nil
 }
@@ -2,16 +2,22 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"otui/config"
 	"otui/mcp"
 	"otui/model"
 	"otui/ollama"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
 	mcptypes "github.com/mark3labs/mcp-go/mcp"
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/shared"
 )
 
 // OpenRouterProvider implements the Provider interface using OpenAI's official Go SDK.
@@ -21,6 +27,15 @@ type OpenRouterProvider struct {
 	model   string
 	baseURL string
 	apiKey  string
+	id      string // Provider ID models are listed under ("openrouter" or a custom ID)
+	name    string // Display name used in errors
+
+	reasoning  config.ReasoningConfig
+	generation config.GenerationParams
+
+	modelsMu sync.Mutex
+	prices   map[string]config.ModelPrice // Model ID -> price, filled by ListModels
+	vision   map[string]bool              // Model IDs that accept image input
 }
 
 // NewOpenRouterProvider creates a new OpenRouter provider instance.
@@ -43,9 +58,11 @@ func NewOpenRouterProvider(baseURL, apiKey, model string) (*OpenRouterProvider,
 	}
 
 	// Create OpenAI client with custom base URL for OpenRouter
+	// Retries are left to the model layer ([retry] in config.toml)
 	client := openai.NewClient(
 		option.WithBaseURL(baseURL),
 		option.WithAPIKey(apiKey),
+		option.WithMaxRetries(0),
 	)
 
 	return &OpenRouterProvider{
@@ -53,6 +70,53 @@ func NewOpenRouterProvider(baseURL, apiKey, model string) (*OpenRouterProvider,
 		model:   model,
 		baseURL: baseURL,
 		apiKey:  apiKey,
+		id:      "openrouter",
+		name:    "OpenRouter",
+	}, nil
+}
+
+// NewOpenAICompatibleProvider creates a provider for a user-defined server
+// speaking the OpenAI API (vLLM, llama.cpp, LM Studio, LiteLLM). It shares
+// the OpenRouter code path; models are listed under the given provider ID.
+//
+// Parameters:
+//   - id: Provider ID from [[providers]] (e.g., "vllm")
+//   - name: Display name (defaults to id)
+//   - baseURL: Server base URL including the version path ("http://gpu-box:8000/v1")
+//   - apiKey: API key, optional - local servers often run without one
+//   - model: Model name (can be empty, set when a session loads)
+//   - headers: Extra HTTP headers sent with every request
+func NewOpenAICompatibleProvider(id, name, baseURL, apiKey, model string, headers map[string]string) (*OpenRouterProvider, error) {
+	if id == "" {
+		return nil, fmt.Errorf("custom provider id is required")
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("base_url is required for custom provider %s", id)
+	}
+	if name == "" {
+		name = id
+	}
+
+	opts := []option.RequestOption{
+		option.WithBaseURL(baseURL),
+		// Always set the key so OPENAI_API_KEY from the environment is never sent to a custom server
+		option.WithAPIKey(apiKey),
+		option.WithMaxRetries(0),
+	}
+	if apiKey == "" {
+		opts = append(opts, option.WithHeaderDel("authorization"))
+	}
+	for key, value := range headers {
+		opts = append(opts, option.WithHeader(key, value))
+	}
+
+	return &OpenRouterProvider{
+		client:  openai.NewClient(opts...),
+		model:   model,
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		id:      id,
+		name:    name,
 	}, nil
 }
 
@@ -96,6 +160,75 @@ func convertToolNameFromOpenRouter(toolName string) string {
 	return strings.ReplaceAll(toolName, "__", ".")
 }
 
+// convertMessageToolNamesForOpenRouter applies the same dot-to-underscore conversion
+// to tool calls replayed in assistant messages, so they match the declared tools.
+func convertMessageToolNamesForOpenRouter(messages []model.Message) []model.Message {
+	converted := make([]model.Message, len(messages))
+	for i, msg := range messages {
+		converted[i] = msg
+		if len(msg.ToolCalls) == 0 {
+			continue
+		}
+		converted[i].ToolCalls = make([]model.ToolCall, len(msg.ToolCalls))
+		for j, call := range msg.ToolCalls {
+			converted[i].ToolCalls[j] = call
+			converted[i].ToolCalls[j].Name = strings.ReplaceAll(call.Name, ".", "__")
+		}
+	}
+	return converted
+}
+
+// SetReasoning sets how much reasoning models think. OpenRouter takes an
+// effort or a token budget; custom servers only an effort.
+func (p *OpenRouterProvider) SetReasoning(r config.ReasoningConfig) {
+	p.reasoning = r
+}
+
+// SetGenerationParams implements model.TunableProvider
+func (p *OpenRouterProvider) SetGenerationParams(params config.GenerationParams) {
+	p.generation = params
+}
+
+// samplingParams adds the generation parameters to a request. max_tokens is
+// used over max_completion_tokens because more servers understand it.
+func (p *OpenRouterProvider) samplingParams(ctx context.Context, params *openai.ChatCompletionNewParams) {
+	g := requestParams(ctx, &p.generation)
+	if g.Temperature != nil {
+		params.Temperature = openai.Float(*g.Temperature)
+	}
+	if g.TopP != nil {
+		params.TopP = openai.Float(*g.TopP)
+	}
+	if g.MaxTokens > 0 {
+		params.MaxTokens = openai.Int(int64(g.MaxTokens))
+	}
+	if g.Seed != nil {
+		params.Seed = openai.Int(int64(*g.Seed))
+	}
+}
+
+// reasoningOptions adds the user's reasoning settings to a request
+func (p *OpenRouterProvider) reasoningOptions(params *openai.ChatCompletionNewParams) []option.RequestOption {
+	effort := p.reasoning.ReasoningEffort
+
+	if p.id != "openrouter" {
+		if effortBudgets[effort] > 0 {
+			params.ReasoningEffort = shared.ReasoningEffort(effort)
+		}
+		return nil
+	}
+
+	switch {
+	case effort == config.ReasoningOff:
+		return []option.RequestOption{option.WithJSONSet("reasoning", map[string]any{"enabled": false})}
+	case p.reasoning.ThinkingBudget > 0:
+		return []option.RequestOption{option.WithJSONSet("reasoning", map[string]any{"max_tokens": p.reasoning.ThinkingBudget})}
+	case effort != "":
+		return []option.RequestOption{option.WithJSONSet("reasoning", map[string]any{"effort": effort})}
+	}
+	return nil
+}
+
 // Chat implements Provider.Chat by delegating to ChatWithTools with no tools.
 func (p *OpenRouterProvider) Chat(ctx context.Context, messages []model.Message, callback model.StreamCallback) error {
 	return p.ChatWithTools(ctx, messages, nil, callback)
@@ -122,13 +255,16 @@ func (p *OpenRouterProvider) ChatWithTools(ctx context.Context, messages []model
 		config.DebugLog.Printf("[OpenRouter] Model '%s': Adding tool instructions", p.model)
 	}
 
-	// Convert OTUI messages to OpenAI format
-	openaiMessages := ConvertToOpenAIMessages(messagesWithInstructions)
+	// Convert OTUI messages to OpenAI format (replayed tool calls need API-safe names too)
+	openaiMessages := ConvertToOpenAIMessages(convertMessageToolNamesForOpenRouter(messagesWithInstructions))
 
-	// Build request parameters
+	// Build request parameters (ask for token counts in the final chunk)
 	params := openai.ChatCompletionNewParams{
 		Messages: openaiMessages,
 		Model:    openai.ChatModel(p.model),
+		StreamOptions: openai.ChatCompletionStreamOptionsParam{
+			IncludeUsage: openai.Bool(true),
+		},
 	}
 
 	// Add tools if provided (convert dots to underscores for OpenRouter API)
@@ -138,8 +274,11 @@ func (p *OpenRouterProvider) ChatWithTools(ctx context.Context, messages []model
 		params.Tools = openaiTools
 	}
 
+	p.samplingParams(ctx, &params)
+
 	// Create streaming request
-	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
+	opts := p.reasoningOptions(&params)
+	stream := p.client.Chat.Completions.NewStreaming(ctx, params, opts...)
 	acc := openai.ChatCompletionAccumulator{}
 
 	// Track if we got tool calls via API
@@ -158,26 +297,31 @@ func (p *OpenRouterProvider) ChatWithTools(ctx context.Context, messages []model
 				// Convert to provider tool call (convert underscores back to dots)
 				args := ParseToolArguments(tool.Arguments)
 				toolCall := model.ToolCall{
+					ID:        tool.ID,
 					Name:      convertToolNameFromOpenRouter(tool.Name),
 					Arguments: args,
 				}
-				callback("", []model.ToolCall{toolCall})
+				callback("", "", []model.ToolCall{toolCall}, nil)
 			}
 		}
 
+		if reasoning := deltaReasoning(chunk); reasoning != "" && callback != nil {
+			callback("", reasoning, nil, nil)
+		}
+
 		// Send content delta and accumulate for leak detection
 		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
 			content := chunk.Choices[0].Delta.Content
 			contentBuilder.WriteString(content)
 			if callback != nil {
-				callback(content, nil)
+				callback(content, "", nil, nil)
 			}
 		}
 	}
 
 	// Check for errors
 	if err := stream.Err(); err != nil {
-		return fmt.Errorf("OpenRouter streaming error: %w", err)
+		return fmt.Errorf("%s streaming error: %w", p.name, apiError(err))
 	}
 
 	// Safety check: detect leaked tool calls if none were detected via API
@@ -190,7 +334,7 @@ func (p *OpenRouterProvider) ChatWithTools(ctx context.Context, messages []model
 			for i := range leakedCalls {
 				leakedCalls[i].Name = convertToolNameFromOpenRouter(leakedCalls[i].Name)
 			}
-			callback("", leakedCalls)
+			callback("", "", leakedCalls, nil)
 
 			// Note: Content was already streamed, but we could clean it in future
 			// by tracking and re-sending cleaned content
@@ -202,10 +346,18 @@ func (p *OpenRouterProvider) ChatWithTools(ctx context.Context, messages []model
 			for i := range leakedCalls {
 				leakedCalls[i].Name = convertToolNameFromOpenRouter(leakedCalls[i].Name)
 			}
-			callback("", leakedCalls)
+			callback("", "", leakedCalls, nil)
 		}
 	}
 
+	// Usage arrives in the last chunk (zero if the server doesn't report it)
+	if callback != nil && (acc.Usage.PromptTokens > 0 || acc.Usage.CompletionTokens > 0) {
+		return callback("", "", nil, &model.Usage{
+			PromptTokens:     int(acc.Usage.PromptTokens),
+			CompletionTokens: int(acc.Usage.CompletionTokens),
+		})
+	}
+
 	return nil
 }
 
@@ -214,9 +366,11 @@ func (p *OpenRouterProvider) ListModels(ctx context.Context) ([]ollama.ModelInfo
 	// Fetch models from OpenRouter
 	modelsPage, err := p.client.Models.List(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list OpenRouter models: %w", err)
+		return nil, fmt.Errorf("failed to list %s models: %w", p.name, err)
 	}
 
+	p.cacheModels(modelsPage.Data)
+
 	// Convert to ModelInfo with prefix stripping
 	result := make([]ollama.ModelInfo, 0, len(modelsPage.Data))
 	for _, m := range modelsPage.Data {
@@ -224,13 +378,73 @@ func (p *OpenRouterProvider) ListModels(ctx context.Context) ([]ollama.ModelInfo
 			Name:         stripProviderPrefix(m.ID), // Display: "llama-3.2-90b-instruct"
 			InternalName: m.ID,                      // API: "meta-llama/llama-3.2-90b-instruct"
 			Size:         0,                         // OpenRouter doesn't provide size
-			Provider:     "openrouter",
+			Provider:     p.id,
 		})
 	}
 
 	return result, nil
 }
 
+// ModelPrice implements model.PricedProvider using the pricing OpenRouter
+// publishes with its model list. The list is fetched once if ListModels
+// hasn't run yet.
+func (p *OpenRouterProvider) ModelPrice(ctx context.Context, modelName string) (config.ModelPrice, bool) {
+	p.modelsMu.Lock()
+	cached := p.prices != nil
+	p.modelsMu.Unlock()
+
+	if !cached {
+		modelsPage, err := p.client.Models.List(ctx)
+		if err != nil {
+			return config.ModelPrice{}, false
+		}
+		p.cacheModels(modelsPage.Data)
+	}
+
+	p.modelsMu.Lock()
+	defer p.modelsMu.Unlock()
+	price, ok := p.prices[modelName]
+	return price, ok
+}
+
+// cacheModels stores the prices and input modalities from a model list.
+// OpenRouter reports prices as USD per token strings, e.g.
+// "pricing": {"prompt": "0.000003", ...}, and image support as
+// "architecture": {"input_modalities": ["text", "image"]}
+func (p *OpenRouterProvider) cacheModels(models []openai.Model) {
+	prices := make(map[string]config.ModelPrice, len(models))
+	vision := make(map[string]bool)
+	for _, m := range models {
+		var raw struct {
+			Pricing struct {
+				Prompt     string `json:"prompt"`
+				Completion string `json:"completion"`
+			} `json:"pricing"`
+			Architecture struct {
+				InputModalities []string `json:"input_modalities"`
+			} `json:"architecture"`
+		}
+		if err := json.Unmarshal([]byte(m.RawJSON()), &raw); err != nil {
+			continue
+		}
+		if slices.Contains(raw.Architecture.InputModalities, "image") {
+			vision[m.ID] = true
+		}
+		input, err1 := strconv.ParseFloat(raw.Pricing.Prompt, 64)
+		output, err2 := strconv.ParseFloat(raw.Pricing.Completion, 64)
+		// Routers such as openrouter/auto report -1: the price depends on the routed model
+		if err1 != nil || err2 != nil || input < 0 || output < 0 {
+			continue
+		}
+		prices[m.ID] = config.ModelPrice{Input: input * 1_000_000, Output: output * 1_000_000}
+	}
+
+	p.modelsMu.Lock()
+	p.prices = prices
+	p.vision = vision
+	p.modelsMu.Unlock()
+}
+
 // GetModel implements Provider.GetModel.
 // Returns the full model name with vendor prefix for API calls.
 // Example: "qwen/qwen3-coder:free"
@@ -254,29 +468,26 @@ func (p *OpenRouterProvider) SetModel(model string) {
 func (p *OpenRouterProvider) Ping(ctx context.Context) error {
 	_, err := p.client.Models.List(ctx)
 	if err != nil {
-		return fmt.Errorf("OpenRouter ping failed: %w", err)
+		return fmt.Errorf("%s ping failed: %w", p.name, err)
 	}
 	return nil
 }
 
 // GetModelMetadata returns metadata for the specified model
 // TODO: OpenRouter API returns context_length in model data - we should parse it from the API response
-// For now, using fallback metadata based on model name patterns
+// For now, using fallback metadata based on model name patterns. Vision
+// support comes from the model's input modalities in the API response.
 func (p *OpenRouterProvider) GetModelMetadata(ctx context.Context, modelName string) (model.ModelMetadata, error) {
 	// Try to fetch from API first
 	modelsPage, err := p.client.Models.List(ctx)
 	if err == nil {
-		// Search for the model in the response
-		for _, m := range modelsPage.Data {
-			if m.ID == modelName {
-				// Check if context window info is available in the model object
-				// The OpenAI SDK model object might have this field - needs investigation
-				// For now, fall through to fallback
-				break
-			}
-		}
+		p.cacheModels(modelsPage.Data)
 	}
 
+	p.modelsMu.Lock()
+	vision := p.vision[modelName]
+	p.modelsMu.Unlock()
+
 	// Fallback to hardcoded metadata
 	// Strip provider prefix for lookup (e.g., "anthropic/claude-sonnet-4" → "claude-sonnet-4")
 	lookupName := stripProviderPrefix(modelName)
@@ -284,35 +495,39 @@ func (p *OpenRouterProvider) GetModelMetadata(ctx context.Context, modelName str
 	// Try Anthropic models first (common on OpenRouter)
 	if meta := GetFallbackMetadata(lookupName, AnthropicFallbackMetadata); meta.ContextWindow > 8192 {
 		return model.ModelMetadata{
-			ContextWindow: meta.ContextWindow,
-			MaxOutput:     meta.MaxOutput,
-			SupportsTools: meta.SupportsTools,
+			ContextWindow:  meta.ContextWindow,
+			MaxOutput:      meta.MaxOutput,
+			SupportsTools:  meta.SupportsTools,
+			SupportsVision: vision,
 		}, nil
 	}
 
 	// Try OpenAI models
 	if meta := GetFallbackMetadata(lookupName, OpenAIFallbackMetadata); meta.ContextWindow > 8192 {
 		return model.ModelMetadata{
-			ContextWindow: meta.ContextWindow,
-			MaxOutput:     meta.MaxOutput,
-			SupportsTools: meta.SupportsTools,
+			ContextWindow:  meta.ContextWindow,
+			MaxOutput:      meta.MaxOutput,
+			SupportsTools:  meta.SupportsTools,
+			SupportsVision: vision,
 		}, nil
 	}
 
 	// Try Ollama models (for local models exposed via OpenRouter)
 	if meta := GetFallbackMetadata(lookupName, OllamaFallbackMetadata); meta.ContextWindow > 8192 {
 		return model.ModelMetadata{
-			ContextWindow: meta.ContextWindow,
-			MaxOutput:     meta.MaxOutput,
-			SupportsTools: meta.SupportsTools,
+			ContextWindow:  meta.ContextWindow,
+			MaxOutput:      meta.MaxOutput,
+			SupportsTools:  meta.SupportsTools,
+			SupportsVision: vision,
 		}, nil
 	}
 
 	// Ultimate fallback
 	return model.ModelMetadata{
-		ContextWindow: 8192,
-		MaxOutput:     2048,
-		SupportsTools: false,
+		ContextWindow:  8192,
+		MaxOutput:      2048,
+		SupportsTools:  false,
+		SupportsVision: vision,
 	}, nil
 }
 
@@ -327,6 +542,9 @@ func stripProviderPrefix(modelName string) string {
 }
 
 // ConvertToOpenAIMessages converts OTUI messages to OpenAI format.
+// Assistant messages carry their tool calls and tool results reference the
+// call they answer by ID, as the Chat Completions API requires. User messages
+// with images become content parts, with each image as a base64 data URL.
 func ConvertToOpenAIMessages(messages []model.Message) []openai.ChatCompletionMessageParamUnion {
 	result := make([]openai.ChatCompletionMessageParamUnion, len(messages))
 
@@ -335,13 +553,46 @@ func ConvertToOpenAIMessages(messages []model.Message) []openai.ChatCompletionMe
 		case "system":
 			result[i] = openai.SystemMessage(msg.Content)
 		case "user":
-			result[i] = openai.UserMessage(msg.Content)
+			images := msg.Images()
+			if len(images) == 0 {
+				result[i] = openai.UserMessage(msg.ContentWithFiles())
+				continue
+			}
+			var parts []openai.ChatCompletionContentPartUnionParam
+			if text := msg.ContentWithFiles(); text != "" {
+				parts = append(parts, openai.TextContentPart(text))
+			}
+			for _, image := range images {
+				parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+					URL: "data:" + image.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(image.Data),
+				}))
+			}
+			result[i] = openai.UserMessage(parts)
 		case "assistant":
 			result[i] = openai.AssistantMessage(msg.Content)
+			for _, call := range msg.ToolCalls {
+				argsJSON, err := json.Marshal(call.Arguments)
+				if err != nil || call.Arguments == nil {
+					argsJSON = []byte("{}")
+				}
+				result[i].OfAssistant.ToolCalls = append(result[i].OfAssistant.ToolCalls,
+					openai.ChatCompletionMessageToolCallUnionParam{
+						OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+							ID: call.ID,
+							Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+								Name:      call.Name,
+								Arguments: string(argsJSON),
+							},
+						},
+					})
+			}
 		case "tool":
-			// Tool messages are sent as user messages for now
-			// TODO: Add ToolCallID to Message struct to properly handle tool responses
-			result[i] = openai.UserMessage(msg.Content)
+			if msg.ToolCall == nil || msg.ToolCall.ID == "" {
+				// Unpaired result - the API rejects tool messages without a call ID
+				result[i] = openai.UserMessage(msg.Content)
+				continue
+			}
+			result[i] = openai.ToolMessage(msg.Content, msg.ToolCall.ID)
 		default:
 			// Default to user message
 			result[i] = openai.UserMessage(msg.Content)
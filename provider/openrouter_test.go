@@ -0,0 +1,251 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"otui/config"
+	"otui/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+)
+
+func TestOpenRouterCacheModels(t *testing.T) {
+	var models []openai.Model
+	data := `[
+		{"id": "anthropic/claude-sonnet-4", "object": "model", "created": 0, "owned_by": "",
+		 "pricing": {"prompt": "0.000003", "completion": "0.000015"},
+		 "architecture": {"input_modalities": ["text", "image"]}},
+		{"id": "qwen/qwen3-coder:free", "object": "model", "created": 0, "owned_by": "",
+		 "pricing": {"prompt": "0", "completion": "0"}},
+		{"id": "openrouter/auto", "object": "model", "created": 0, "owned_by": "",
+		 "pricing": {"prompt": "-1", "completion": "-1"}}
+	]`
+	if err := json.Unmarshal([]byte(data), &models); err != nil {
+		t.Fatalf("unmarshal models: %v", err)
+	}
+
+	p := &OpenRouterProvider{}
+	p.cacheModels(models)
+
+	price, ok := p.ModelPrice(context.Background(), "anthropic/claude-sonnet-4")
+	if !ok {
+		t.Fatal("expected a price for anthropic/claude-sonnet-4")
+	}
+	if math.Abs(price.Input-3) > 1e-9 || math.Abs(price.Output-15) > 1e-9 {
+		t.Errorf("price = %+v, want input 3, output 15 per million", price)
+	}
+
+	if price, ok := p.ModelPrice(context.Background(), "qwen/qwen3-coder:free"); !ok || price.Input != 0 {
+		t.Errorf("free model price = %+v, %v; want zero and priced", price, ok)
+	}
+	if _, ok := p.ModelPrice(context.Background(), "openrouter/auto"); ok {
+		t.Error("router with a variable price should be unpriced")
+	}
+
+	if !p.vision["anthropic/claude-sonnet-4"] {
+		t.Error("model with image input should support vision")
+	}
+	if p.vision["qwen/qwen3-coder:free"] {
+		t.Error("text-only model should not support vision")
+	}
+}
+
+func TestOpenAICompatibleProvider(t *testing.T) {
+	var gotAuth, gotTeam string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotTeam = r.Header.Get("X-Team")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object": "list", "data": [{"id": "meta-llama/Llama-3.1-8B-Instruct", "object": "model", "created": 0, "owned_by": "vllm"}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_API_KEY", "sk-must-not-leak")
+
+	p, err := NewProvider(Config{
+		Type:    ProviderTypeOpenAICompatible,
+		ID:      "vllm",
+		BaseURL: server.URL,
+		Headers: map[string]string{"X-Team": "research"},
+	})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	models, err := p.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if len(models) != 1 || models[0].Provider != "vllm" || models[0].InternalName != "meta-llama/Llama-3.1-8B-Instruct" {
+		t.Errorf("models = %+v, want one model listed under vllm", models)
+	}
+	if gotAuth != "" {
+		t.Errorf("Authorization = %q, want none without an API key", gotAuth)
+	}
+	if gotTeam != "research" {
+		t.Errorf("X-Team = %q, want the configured header", gotTeam)
+	}
+
+	if _, err := NewProvider(Config{Type: ProviderTypeOpenAICompatible, ID: "vllm"}); err == nil {
+		t.Error("expected an error without a base URL")
+	}
+}
+
+func TestOpenAICompatibleReasoning(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"Two plus two"}}]}`,
+		`data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"reasoning":" is four."}}]}`,
+		`data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"content":"4"},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	}, "\n\n") + "\n\n"
+
+	tests := []struct {
+		name      string
+		cfg       Config
+		wantField string // Request field carrying the setting
+		wantValue string
+	}{
+		{
+			name:      "custom server takes reasoning_effort",
+			cfg:       Config{Type: ProviderTypeOpenAICompatible, ID: "vllm", Reasoning: config.ReasoningConfig{ReasoningEffort: "high"}},
+			wantField: "reasoning_effort",
+			wantValue: `"high"`,
+		},
+		{
+			name:      "OpenRouter takes a reasoning budget",
+			cfg:       Config{Type: ProviderTypeOpenRouter, APIKey: "sk-or", Reasoning: config.ReasoningConfig{ReasoningEffort: "low", ThinkingBudget: 4000}},
+			wantField: "reasoning",
+			wantValue: `{"max_tokens":4000}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent map[string]json.RawMessage
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				json.Unmarshal(body, &sent)
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte(stream))
+			}))
+			defer server.Close()
+
+			tt.cfg.BaseURL = server.URL
+			tt.cfg.Model = "m"
+			p, err := NewProvider(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+
+			var answer, reasoning strings.Builder
+			err = p.Chat(context.Background(), []model.Message{{Role: "user", Content: "2+2?"}}, func(chunk, thought string, _ []model.ToolCall, _ *model.Usage) error {
+				answer.WriteString(chunk)
+				reasoning.WriteString(thought)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Chat: %v", err)
+			}
+
+			if answer.String() != "4" || reasoning.String() != "Two plus two is four." {
+				t.Errorf("answer = %q, reasoning = %q; want them streamed separately", answer.String(), reasoning.String())
+			}
+			if got := string(sent[tt.wantField]); got != tt.wantValue {
+				t.Errorf("%s = %s, want %s", tt.wantField, got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestOpenAICompatibleGenerationParams(t *testing.T) {
+	var sent map[string]json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &sent)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"id":"1","object":"chat.completion.chunk","created":0,"model":"m","choices":[{"index":0,"delta":{"content":"ok"},"finish_reason":"stop"}]}` + "\n\ndata: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	p, err := NewProvider(Config{Type: ProviderTypeOpenAICompatible, ID: "vllm", BaseURL: server.URL, Model: "m"})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	temperature, seed := 0.2, 42
+	p.(model.TunableProvider).SetGenerationParams(config.GenerationParams{Temperature: &temperature, MaxTokens: 256, Seed: &seed, NumCtx: 8192})
+
+	if err := p.Chat(context.Background(), []model.Message{{Role: "user", Content: "hi"}}, nil); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	want := map[string]string{"temperature": "0.2", "max_tokens": "256", "seed": "42"}
+	for field, value := range want {
+		if got := string(sent[field]); got != value {
+			t.Errorf("%s = %s, want %s", field, got, value)
+		}
+	}
+	for _, field := range []string{"top_p", "num_ctx"} {
+		if _, ok := sent[field]; ok {
+			t.Errorf("%s was sent, want it left out", field)
+		}
+	}
+}
+
+func TestOpenAICompatibleRateLimit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"rate limited","type":"rate_limit"}}`))
+	}))
+	defer server.Close()
+
+	p, err := NewProvider(Config{Type: ProviderTypeOpenAICompatible, ID: "vllm", BaseURL: server.URL, Model: "m"})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	err = p.Chat(context.Background(), []model.Message{{Role: "user", Content: "hi"}}, nil)
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Chat error = %v, want a *model.APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter != 7*time.Second {
+		t.Errorf("APIError = %d after %v, want 429 after 7s", apiErr.StatusCode, apiErr.RetryAfter)
+	}
+	// Retrying is up to the model layer, not the SDK
+	if requests != 1 {
+		t.Errorf("server got %d requests, want 1", requests)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"none", http.Header{}, 0},
+		{"seconds", http.Header{"Retry-After": {"3"}}, 3 * time.Second},
+		{"milliseconds", http.Header{"Retry-After-Ms": {"250"}, "Retry-After": {"1"}}, 250 * time.Millisecond},
+		{"past date", http.Header{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}}, 0},
+		{"garbage", http.Header{"Retry-After": {"soon"}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfter(tt.header); got != tt.want {
+				t.Errorf("retryAfter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"otui/config"
+	"otui/model"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// Thinking token budgets used when only reasoning_effort is set
+var effortBudgets = map[string]int{
+	config.ReasoningLow:    2048,
+	config.ReasoningMedium: 8192,
+	config.ReasoningHigh:   24576,
+}
+
+// reasoningConfigurable is implemented by providers that can ask reasoning
+// models to think more or less; NewProvider passes them the user's settings
+type reasoningConfigurable interface {
+	SetReasoning(config.ReasoningConfig)
+}
+
+// thinkingBudget returns the thinking token budget to request, 0 for none
+func thinkingBudget(r config.ReasoningConfig) int {
+	if r.ThinkingBudget > 0 {
+		return r.ThinkingBudget
+	}
+	return effortBudgets[r.ReasoningEffort]
+}
+
+// requestParams returns the generation parameters for a request made with
+// ctx: those of a one-off request (model.WithGenerationParams), else the
+// provider's own. set is only read when used, as a chat may be changing it.
+func requestParams(ctx context.Context, set *config.GenerationParams) config.GenerationParams {
+	if params, ok := model.RequestGenerationParams(ctx); ok {
+		return params
+	}
+	return *set
+}
+
+// deltaReasoning returns reasoning text from a streamed chunk. OpenAI doesn't
+// stream reasoning, but OpenRouter sends "reasoning" and servers such as vLLM,
+// DeepSeek and LM Studio send "reasoning_content".
+func deltaReasoning(chunk openai.ChatCompletionChunk) string {
+	if len(chunk.Choices) == 0 {
+		return ""
+	}
+	extra := chunk.Choices[0].Delta.JSON.ExtraFields
+	for _, key := range []string{"reasoning", "reasoning_content"} {
+		field, ok := extra[key]
+		if !ok {
+			continue
+		}
+		var text string
+		if err := json.Unmarshal([]byte(field.Raw()), &text); err == nil && text != "" {
+			return text
+		}
+	}
+	return ""
+}
@@ -81,7 +81,7 @@ func TestMockProvider_DefaultChat(t *testing.T) {
 	mock := NewMockProvider("test")
 	var received string
 
-	callback := func(chunk string, toolCalls []model.ToolCall) error {
+	callback := func(chunk, reasoning string, toolCalls []model.ToolCall, usage *model.Usage) error {
 		received = chunk
 		return nil
 	}
@@ -35,14 +35,14 @@ func NewMockProvider(modelName string) *MockProvider {
 func (m *MockProvider) defaultChat(ctx context.Context, messages []model.Message, callback model.StreamCallback) error {
 	// Default: echo back a mock response
 	if len(messages) > 0 {
-		return callback("Mock response", nil)
+		return callback("Mock response", "", nil, nil)
 	}
 	return nil
 }
 
 func (m *MockProvider) defaultChatWithTools(ctx context.Context, messages []model.Message, tools []mcptypes.Tool, callback model.StreamCallback) error {
 	// Default: mock response with tools available
-	return callback("Mock response with tools", nil)
+	return callback("Mock response with tools", "", nil, nil)
 }
 
 func (m *MockProvider) defaultListModels(ctx context.Context) ([]ollama.ModelInfo, error) {
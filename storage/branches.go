@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Branch is an inactive version of a conversation from message At on. It is
+// created when an earlier prompt is edited or a reply regenerated, and swapped
+// back in when the user switches versions.
+type Branch struct {
+	ID       string    `json:"id"`
+	At       int       `json:"at"` // Index into the session's messages where this version starts
+	Messages []Message `json:"messages"`
+
+	// Versions of later messages that were stashed while this one was active.
+	// Their At is an index into the full conversation, like At here.
+	Branches []Branch `json:"branches,omitempty"`
+
+	// Compaction state while this version was active
+	CompactionMarker    int       `json:"compaction_marker,omitempty"`
+	CompactedSummary    string    `json:"compacted_summary,omitempty"`
+	LLMSummary          string    `json:"llm_summary,omitempty"`
+	CompactionTimestamp time.Time `json:"compaction_timestamp,omitempty"`
+}
+
+// started is when this version of the conversation began; empty versions sort last
+func (b Branch) started() time.Time {
+	if len(b.Messages) == 0 {
+		return time.Time{}
+	}
+	return b.Messages[0].Timestamp
+}
+
+// Branch stashes the messages from index at on as an inactive version and
+// truncates the conversation there, so a new version can be started: an
+// edited prompt, or another reply to the prompt before at. Versions of later
+// messages move into the stash with them.
+//
+// The compaction marker counts user and assistant messages, so a compaction
+// that reached past at no longer applies to the new version and is cleared.
+// It stays with the stashed version.
+func (s *Session) Branch(at int) error {
+	if at < 0 || at > len(s.Messages) {
+		return fmt.Errorf("branch point %d out of range (%d messages)", at, len(s.Messages))
+	}
+	if at == len(s.Messages) {
+		return nil // Nothing to keep
+	}
+
+	s.Branches = append(s.Branches, s.stashFrom(at))
+	if s.CompactionMarker > countConversation(s.Messages) {
+		s.setCompaction(Branch{})
+	}
+	return nil
+}
+
+// BranchVersions reports which version of the conversation from index at is
+// showing (1-based) and how many there are. total is 1 if the messages there
+// were never edited or regenerated.
+func (s *Session) BranchVersions(at int) (current, total int) {
+	versions := s.versionsAt(at)
+	for i, v := range versions {
+		if v < 0 {
+			return i + 1, len(versions)
+		}
+	}
+	return 1, 1
+}
+
+// SwitchBranch shows the version delta steps away (-1 previous, +1 next) from
+// the current one at index at. Versions are ordered by when they started.
+// It reports false if there is no version in that direction.
+func (s *Session) SwitchBranch(at, delta int) bool {
+	versions := s.versionsAt(at)
+	for i, v := range versions {
+		if v >= 0 {
+			continue
+		}
+		target := i + delta
+		if target < 0 || target >= len(versions) || target == i {
+			return false
+		}
+		s.activate(versions[target])
+		return true
+	}
+	return false
+}
+
+// SwitchToBranch makes the version with the given ID (see SearchAllSessions)
+// the one showing, switching any versions it was stashed inside of first.
+// It reports false if no stashed version has that ID.
+func (s *Session) SwitchToBranch(id string) bool {
+	for i, b := range s.Branches {
+		if b.ID == id {
+			s.activate(i)
+			return true
+		}
+		if containsBranch(b.Branches, id) {
+			s.activate(i)
+			return s.SwitchToBranch(id)
+		}
+	}
+	return false
+}
+
+// versionsAt lists the versions of the conversation from index at, ordered by
+// when they started: indices into s.Branches, with -1 for the one showing.
+// It is empty if the messages there have only one version.
+func (s *Session) versionsAt(at int) []int {
+	var versions []int
+	for i, b := range s.Branches {
+		if b.At == at {
+			versions = append(versions, i)
+		}
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+	versions = append(versions, -1)
+
+	current := Branch{}
+	if at < len(s.Messages) {
+		current.Messages = s.Messages[at:]
+	}
+	started := func(v int) time.Time {
+		if v < 0 {
+			return current.started()
+		}
+		return s.Branches[v].started()
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := started(versions[i]), started(versions[j])
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.Before(b)
+	})
+	return versions
+}
+
+// activate swaps the stashed version s.Branches[i] in for the messages
+// showing from its branch point on
+func (s *Session) activate(i int) {
+	target := s.Branches[i]
+	s.Branches = append(s.Branches[:i:i], s.Branches[i+1:]...)
+
+	stash := s.stashFrom(target.At)
+	// A compaction of the messages before the branch point applies to every
+	// version, so it is kept unless the target was compacted further
+	shared := s.CompactionMarker <= countConversation(s.Messages)
+	if !shared || target.CompactionMarker > s.CompactionMarker {
+		s.setCompaction(target)
+	}
+	s.Messages = append(s.Messages, target.Messages...)
+	s.Branches = append(s.Branches, stash)
+	s.Branches = append(s.Branches, target.Branches...)
+}
+
+// stashFrom cuts the messages from index at on, together with the versions of
+// later messages and the compaction state, into a new Branch
+func (s *Session) stashFrom(at int) Branch {
+	stash := Branch{
+		ID:                  uuid.New().String(),
+		At:                  at,
+		Messages:            append([]Message(nil), s.Messages[at:]...),
+		CompactionMarker:    s.CompactionMarker,
+		CompactedSummary:    s.CompactedSummary,
+		LLMSummary:          s.LLMSummary,
+		CompactionTimestamp: s.CompactionTimestamp,
+	}
+
+	var kept []Branch
+	for _, b := range s.Branches {
+		if b.At > at {
+			stash.Branches = append(stash.Branches, b)
+		} else {
+			kept = append(kept, b)
+		}
+	}
+	s.Branches = kept
+	s.Messages = s.Messages[:at:at]
+	return stash
+}
+
+// setCompaction restores the compaction state recorded in b
+func (s *Session) setCompaction(b Branch) {
+	s.CompactionMarker = b.CompactionMarker
+	s.CompactedSummary = b.CompactedSummary
+	s.LLMSummary = b.LLMSummary
+	s.CompactionTimestamp = b.CompactionTimestamp
+}
+
+// countConversation counts the user and assistant messages, the unit the
+// compaction marker is measured in
+func countConversation(messages []Message) int {
+	count := 0
+	for _, msg := range messages {
+		if msg.Role == "user" || msg.Role == "assistant" {
+			count++
+		}
+	}
+	return count
+}
+
+func containsBranch(branches []Branch, id string) bool {
+	for _, b := range branches {
+		if b.ID == id || containsBranch(b.Branches, id) {
+			return true
+		}
+	}
+	return false
+}
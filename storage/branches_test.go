@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+// conversation builds alternating user/assistant messages, one minute apart
+func conversation(start time.Time, contents ...string) []Message {
+	messages := make([]Message, len(contents))
+	for i, content := range contents {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		messages[i] = Message{Role: role, Content: content, Timestamp: start.Add(time.Duration(i) * time.Minute)}
+	}
+	return messages
+}
+
+func contents(messages []Message) []string {
+	out := make([]string, len(messages))
+	for i, msg := range messages {
+		out[i] = msg.Content
+	}
+	return out
+}
+
+func sameContents(got []Message, want ...string) bool {
+	gotContents := contents(got)
+	if len(gotContents) != len(want) {
+		return false
+	}
+	for i := range want {
+		if gotContents[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSessionBranches(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("regenerate and switch back", func(t *testing.T) {
+		s := &Session{Messages: conversation(start, "q1", "a1", "q2", "a2")}
+
+		if err := s.Branch(3); err != nil {
+			t.Fatalf("Branch: %v", err)
+		}
+		if !sameContents(s.Messages, "q1", "a1", "q2") {
+			t.Fatalf("messages after branching = %v", contents(s.Messages))
+		}
+		s.Messages = append(s.Messages, Message{Role: "assistant", Content: "a2'", Timestamp: start.Add(time.Hour)})
+
+		if current, total := s.BranchVersions(3); current != 2 || total != 2 {
+			t.Errorf("BranchVersions = %d/%d, want 2/2", current, total)
+		}
+		if s.SwitchBranch(3, 1) {
+			t.Error("switched past the newest version")
+		}
+		if !s.SwitchBranch(3, -1) {
+			t.Fatal("SwitchBranch(-1) found no earlier version")
+		}
+		if !sameContents(s.Messages, "q1", "a1", "q2", "a2") {
+			t.Errorf("messages after switching back = %v", contents(s.Messages))
+		}
+		if current, total := s.BranchVersions(3); current != 1 || total != 2 {
+			t.Errorf("BranchVersions = %d/%d, want 1/2", current, total)
+		}
+		if !s.SwitchBranch(3, 1) || !sameContents(s.Messages, "q1", "a1", "q2", "a2'") {
+			t.Errorf("messages after switching forward = %v", contents(s.Messages))
+		}
+	})
+
+	t.Run("edit keeps later versions with the original", func(t *testing.T) {
+		s := &Session{Messages: conversation(start, "q1", "a1", "q2", "a2")}
+		_ = s.Branch(3) // Regenerate a2
+		s.Messages = append(s.Messages, Message{Role: "assistant", Content: "a2'", Timestamp: start.Add(time.Hour)})
+
+		_ = s.Branch(0) // Edit q1
+		s.Messages = conversation(start.Add(2*time.Hour), "q1 edited", "b1")
+
+		if _, total := s.BranchVersions(3); total != 1 {
+			t.Errorf("the edited version has %d versions at 3, want 1", total)
+		}
+		if !s.SwitchBranch(0, -1) {
+			t.Fatal("no earlier version of q1")
+		}
+		if !sameContents(s.Messages, "q1", "a1", "q2", "a2'") {
+			t.Errorf("messages = %v", contents(s.Messages))
+		}
+		if current, total := s.BranchVersions(3); current != 2 || total != 2 {
+			t.Errorf("nested BranchVersions = %d/%d, want 2/2", current, total)
+		}
+	})
+
+	t.Run("switch to a nested version by ID", func(t *testing.T) {
+		s := &Session{Messages: conversation(start, "q1", "a1", "q2", "a2")}
+		_ = s.Branch(3)
+		s.Messages = append(s.Messages, Message{Role: "assistant", Content: "a2'", Timestamp: start.Add(time.Hour)})
+		_ = s.Branch(0)
+		s.Messages = conversation(start.Add(2*time.Hour), "q1 edited", "b1")
+
+		var nestedID string
+		for _, b := range s.Branches {
+			for _, nested := range b.Branches {
+				nestedID = nested.ID
+			}
+		}
+		if nestedID == "" {
+			t.Fatal("the a2 version wasn't stashed inside the q1 version")
+		}
+		if !s.SwitchToBranch(nestedID) {
+			t.Fatal("SwitchToBranch found nothing")
+		}
+		if !sameContents(s.Messages, "q1", "a1", "q2", "a2") {
+			t.Errorf("messages = %v", contents(s.Messages))
+		}
+		if s.SwitchToBranch("missing") {
+			t.Error("SwitchToBranch reported success for an unknown ID")
+		}
+	})
+
+	t.Run("compaction", func(t *testing.T) {
+		s := &Session{
+			Messages:         conversation(start, "q1", "a1", "q2", "a2"),
+			CompactionMarker: 3,
+			LLMSummary:       "summary",
+		}
+
+		_ = s.Branch(2) // The compaction covered q2, which the new version replaces
+		if s.CompactionMarker != 0 || s.LLMSummary != "" {
+			t.Errorf("compaction kept: marker=%d summary=%q", s.CompactionMarker, s.LLMSummary)
+		}
+		s.Messages = append(s.Messages, conversation(start.Add(time.Hour), "q2 edited")...)
+
+		s.SwitchBranch(2, -1)
+		if s.CompactionMarker != 3 || s.LLMSummary != "summary" {
+			t.Errorf("compaction not restored: marker=%d summary=%q", s.CompactionMarker, s.LLMSummary)
+		}
+
+		// A compaction before the branch point applies to every version
+		s.CompactionMarker, s.LLMSummary = 1, "shared"
+		_ = s.Branch(3)
+		if s.CompactionMarker != 1 || s.LLMSummary != "shared" {
+			t.Errorf("shared compaction dropped: marker=%d summary=%q", s.CompactionMarker, s.LLMSummary)
+		}
+	})
+
+	t.Run("nothing to branch", func(t *testing.T) {
+		s := &Session{Messages: conversation(start, "q1")}
+		if err := s.Branch(1); err != nil || len(s.Branches) != 0 {
+			t.Errorf("Branch at the end = %v with %d branches, want a no-op", err, len(s.Branches))
+		}
+		if err := s.Branch(5); err == nil {
+			t.Error("expected an error for a branch point past the end")
+		}
+		if current, total := s.BranchVersions(0); current != 1 || total != 1 {
+			t.Errorf("BranchVersions = %d/%d, want 1/1", current, total)
+		}
+	})
+}
+
+func TestSearchAllSessionsBranches(t *testing.T) {
+	s, err := NewSessionStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := &Session{Name: "branched", Messages: conversation(start, "q1", "the old answer")}
+	_ = session.Branch(1)
+	session.Messages = append(session.Messages, Message{Role: "assistant", Content: "the new answer", Timestamp: start.Add(time.Hour)})
+	if err := s.Save(session); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	matches, err := NewSearchIndex(s).SearchAllSessions("answer")
+	if err != nil {
+		t.Fatalf("SearchAllSessions: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(matches))
+	}
+
+	var old SessionMessageMatch
+	for _, m := range matches {
+		if m.Content == "the old answer" {
+			old = m
+		} else if m.BranchID != "" {
+			t.Errorf("match in the showing version has BranchID %q", m.BranchID)
+		}
+	}
+	if old.BranchID == "" || old.MessageIndex != 1 {
+		t.Fatalf("old answer match = %+v, want a BranchID and index 1", old)
+	}
+
+	loaded, err := s.Load(session.ID)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !loaded.SwitchToBranch(old.BranchID) || loaded.Messages[old.MessageIndex].Content != "the old answer" {
+		t.Errorf("switching to the match shows %v", contents(loaded.Messages))
+	}
+}
@@ -0,0 +1,311 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// embedBatchSize is how many messages are sent to the embedding model at once
+const embedBatchSize = 32
+
+// EmbeddingIndex keeps embeddings of session messages for semantic search:
+// one file per session in the embeddings directory next to the sessions
+type EmbeddingIndex struct {
+	dir string
+}
+
+// SessionEmbeddings are the embeddings of one session's messages
+type SessionEmbeddings struct {
+	SessionID string             `json:"session_id"`
+	Model     string             `json:"model"` // Embedding model; vectors from another model aren't comparable
+	Messages  []MessageEmbedding `json:"messages"`
+}
+
+// MessageEmbedding is the embedding of a prompt or reply, in any version of
+// the conversation
+type MessageEmbedding struct {
+	BranchID     string `json:"branch_id,omitempty"` // As in SessionMessageMatch
+	MessageIndex int    `json:"message_index"`
+	Hash         string `json:"hash"` // Of the content, so unchanged messages aren't embedded again
+	Vector       Vector `json:"vector"`
+}
+
+// Vector is an embedding, stored as base64 little-endian float32s to keep
+// the files small
+type Vector []float32
+
+func (v Vector) MarshalJSON() ([]byte, error) {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(buf))
+}
+
+func (v *Vector) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	buf, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	if len(buf)%4 != 0 {
+		return fmt.Errorf("vector has %d bytes, not a multiple of 4", len(buf))
+	}
+	*v = make(Vector, len(buf)/4)
+	for i := range *v {
+		(*v)[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return nil
+}
+
+// EmbedFunc turns texts into embedding vectors, one per text
+type EmbedFunc func(texts []string) ([][]float32, error)
+
+// Embeddings returns the embedding index stored alongside the sessions
+func (s *SessionStorage) Embeddings() *EmbeddingIndex {
+	return &EmbeddingIndex{dir: filepath.Join(filepath.Dir(s.sessionsDir), "embeddings")}
+}
+
+func (e *EmbeddingIndex) path(sessionID string) string {
+	return filepath.Join(e.dir, sessionID+".json")
+}
+
+// Load returns the embeddings of a session, or nil if it has none
+func (e *EmbeddingIndex) Load(sessionID string) (*SessionEmbeddings, error) {
+	data, err := os.ReadFile(e.path(sessionID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embeddings: %w", err)
+	}
+
+	var embeddings SessionEmbeddings
+	if err := json.Unmarshal(data, &embeddings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embeddings: %w", err)
+	}
+	return &embeddings, nil
+}
+
+func (e *EmbeddingIndex) save(embeddings *SessionEmbeddings) error {
+	// 0700/0600 - embeddings can be partially inverted back to the text
+	if err := os.MkdirAll(e.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create embeddings directory: %w", err)
+	}
+
+	data, err := json.Marshal(embeddings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embeddings: %w", err)
+	}
+	if err := os.WriteFile(e.path(embeddings.SessionID), data, 0600); err != nil {
+		return fmt.Errorf("failed to write embeddings: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the embeddings of a session
+func (e *EmbeddingIndex) Delete(sessionID string) error {
+	err := os.Remove(e.path(sessionID))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Update embeds the prompts and replies of a session that aren't in the index
+// yet, in every version of the conversation, and drops those that are gone.
+// It reports whether anything changed. Switching models re-embeds everything.
+func (e *EmbeddingIndex) Update(session *Session, model string, embed EmbedFunc) (bool, error) {
+	existing, err := e.Load(session.ID)
+	if err != nil {
+		return false, err
+	}
+
+	known := make(map[string]Vector)
+	if existing != nil && existing.Model == model {
+		for _, m := range existing.Messages {
+			known[m.Hash] = m.Vector
+		}
+	}
+
+	updated := &SessionEmbeddings{SessionID: session.ID, Model: model}
+	var missing []int // Into updated.Messages
+	var texts []string
+	walkConversation(session, func(branchID string, index int, msg Message) {
+		if (msg.Role != "user" && msg.Role != "assistant") || msg.Content == "" {
+			return
+		}
+		hash := contentHash(msg.Content)
+		updated.Messages = append(updated.Messages, MessageEmbedding{
+			BranchID:     branchID,
+			MessageIndex: index,
+			Hash:         hash,
+			Vector:       known[hash],
+		})
+		if known[hash] == nil {
+			missing = append(missing, len(updated.Messages)-1)
+			texts = append(texts, msg.Content)
+		}
+	})
+
+	if existing != nil && existing.Model == model && len(missing) == 0 && sameEmbeddings(existing.Messages, updated.Messages) {
+		return false, nil
+	}
+
+	for start := 0; start < len(texts); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(texts))
+		vectors, err := embed(texts[start:end])
+		if err != nil {
+			return false, err
+		}
+		if len(vectors) != end-start {
+			return false, fmt.Errorf("embedding model returned %d vectors for %d messages", len(vectors), end-start)
+		}
+		for i, vector := range vectors {
+			updated.Messages[missing[start+i]].Vector = vector
+		}
+	}
+
+	return true, e.save(updated)
+}
+
+// sameEmbeddings reports whether two lists embed the same messages at the same places
+func sameEmbeddings(a, b []MessageEmbedding) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].BranchID != b[i].BranchID || a[i].MessageIndex != b[i].MessageIndex || a[i].Hash != b[i].Hash {
+			return false
+		}
+	}
+	return true
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// walkConversation calls fn for every message of a session, in every version
+// of the conversation, with its index once that version is showing
+func walkConversation(session *Session, fn func(branchID string, index int, msg Message)) {
+	var walk func(branchID string, offset int, messages []Message, branches []Branch)
+	walk = func(branchID string, offset int, messages []Message, branches []Branch) {
+		for i, msg := range messages {
+			fn(branchID, offset+i, msg)
+		}
+		for _, b := range branches {
+			walk(b.ID, b.At, b.Messages, b.Branches)
+		}
+	}
+	walk("", 0, session.Messages, session.Branches)
+}
+
+// messageAt returns the message at index in the version of the conversation
+// with the given ID ("" for the one showing)
+func (s *Session) messageAt(branchID string, index int) (Message, bool) {
+	var found Message
+	ok := false
+	walkConversation(s, func(id string, i int, msg Message) {
+		if id == branchID && i == index {
+			found, ok = msg, true
+		}
+	})
+	return found, ok
+}
+
+// CosineSimilarity returns the cosine of the angle between two vectors, from
+// -1 to 1 (0 if either is empty or their lengths differ)
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// SearchSemantic ranks the indexed messages of every session by cosine
+// similarity to the embedding of a search and returns the closest limit of
+// them, best first. Score is the similarity in thousandths.
+func (si *SearchIndex) SearchSemantic(query []float32, model string, limit int) ([]SessionMessageMatch, error) {
+	sessionList, err := si.storage.List()
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		sessionID  string
+		embedding  MessageEmbedding
+		similarity float64
+	}
+	var ranked []scored
+
+	index := si.storage.Embeddings()
+	for _, meta := range sessionList {
+		embeddings, err := index.Load(meta.ID)
+		if err != nil || embeddings == nil || embeddings.Model != model {
+			continue
+		}
+		for _, m := range embeddings.Messages {
+			ranked = append(ranked, scored{meta.ID, m, CosineSimilarity(query, m.Vector)})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].similarity > ranked[j].similarity
+	})
+
+	var matches []SessionMessageMatch
+	sessions := make(map[string]*Session)
+	for _, r := range ranked {
+		if len(matches) == limit {
+			break
+		}
+
+		session, ok := sessions[r.sessionID]
+		if !ok {
+			session, _ = si.storage.Load(r.sessionID)
+			sessions[r.sessionID] = session
+		}
+		if session == nil {
+			continue
+		}
+		msg, ok := session.messageAt(r.embedding.BranchID, r.embedding.MessageIndex)
+		if !ok || contentHash(msg.Content) != r.embedding.Hash {
+			continue // Changed since it was indexed
+		}
+
+		matches = append(matches, SessionMessageMatch{
+			SessionID:    session.ID,
+			SessionName:  session.Name,
+			BranchID:     r.embedding.BranchID,
+			MessageIndex: r.embedding.MessageIndex,
+			Role:         msg.Role,
+			Content:      msg.Content,
+			Preview:      previewContent(msg.Content),
+			Timestamp:    msg.Timestamp,
+			Score:        int(r.similarity * 1000),
+		})
+	}
+	return matches, nil
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeEmbed embeds text as word counts over a tiny vocabulary, and records
+// what it was asked to embed
+type fakeEmbed struct {
+	calls [][]string
+}
+
+var fakeVocabulary = []string{"tls", "handshake", "certificate", "docker", "container", "recipe"}
+
+func (f *fakeEmbed) embed(texts []string) ([][]float32, error) {
+	f.calls = append(f.calls, texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = fakeVector(text)
+	}
+	return vectors, nil
+}
+
+func fakeVector(text string) []float32 {
+	vector := make([]float32, len(fakeVocabulary))
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		for j, v := range fakeVocabulary {
+			if strings.HasPrefix(word, v) {
+				vector[j]++
+			}
+		}
+	}
+	return vector
+}
+
+func (f *fakeEmbed) embedded() int {
+	n := 0
+	for _, call := range f.calls {
+		n += len(call)
+	}
+	return n
+}
+
+func TestEmbeddingIndexUpdate(t *testing.T) {
+	s, err := NewSessionStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+	index := s.Embeddings()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := &Session{ID: "s1", Messages: conversation(start, "why does the tls handshake fail", "the certificate expired")}
+	session.Messages = append(session.Messages, Message{Role: "system", Content: "Compacted", Timestamp: start})
+
+	embed := &fakeEmbed{}
+	changed, err := index.Update(session, "nomic-embed-text", embed.embed)
+	if err != nil || !changed {
+		t.Fatalf("Update = %v, %v", changed, err)
+	}
+	if embed.embedded() != 2 {
+		t.Errorf("embedded %d messages, want the prompt and reply only", embed.embedded())
+	}
+
+	t.Run("only new messages are embedded", func(t *testing.T) {
+		embed := &fakeEmbed{}
+		if changed, _ := index.Update(session, "nomic-embed-text", embed.embed); changed || embed.embedded() != 0 {
+			t.Errorf("unchanged session: changed=%v, embedded %d", changed, embed.embedded())
+		}
+
+		_ = session.Branch(1) // Regenerate the reply: the old one moves to another version
+		session.Messages = append(session.Messages, Message{Role: "assistant", Content: "renew the certificate", Timestamp: start.Add(time.Hour)})
+		changed, err := index.Update(session, "nomic-embed-text", embed.embed)
+		if err != nil || !changed || embed.embedded() != 1 {
+			t.Fatalf("Update = %v, %v after embedding %d messages, want just the new reply", changed, err, embed.embedded())
+		}
+
+		loaded, err := index.Load(session.ID)
+		if err != nil || loaded == nil {
+			t.Fatalf("Load = %v, %v", loaded, err)
+		}
+		if len(loaded.Messages) != 3 {
+			t.Errorf("index has %d messages, want 3 across both versions", len(loaded.Messages))
+		}
+	})
+
+	t.Run("another model starts over", func(t *testing.T) {
+		embed := &fakeEmbed{}
+		if _, err := index.Update(session, "mxbai-embed-large", embed.embed); err != nil || embed.embedded() != 3 {
+			t.Errorf("embedded %d messages (%v), want all 3 again", embed.embedded(), err)
+		}
+	})
+
+	t.Run("deleted with the session", func(t *testing.T) {
+		if err := s.Save(session); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if err := s.Delete(session.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if loaded, err := index.Load(session.ID); err != nil || loaded != nil {
+			t.Errorf("embeddings left behind: %v, %v", loaded, err)
+		}
+	})
+}
+
+func TestVectorJSON(t *testing.T) {
+	in := Vector{0.25, -1.5, 3e-7}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out Vector
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("round trip = %v, want %v", out, in)
+	}
+	for i := range in {
+		if out[i] != in[i] {
+			t.Errorf("round trip = %v, want %v", out, in)
+		}
+	}
+}
+
+func TestSearchSemantic(t *testing.T) {
+	s, err := NewSessionStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	sessions := []*Session{
+		{Name: "tls", Messages: conversation(start, "my server rejects the certificate", "check the handshake with openssl")},
+		{Name: "docker", Messages: conversation(start, "my container won't start", "look at the docker logs")},
+		{Name: "cooking", Messages: conversation(start, "a recipe for bread")},
+	}
+	embed := &fakeEmbed{}
+	for _, session := range sessions {
+		if err := s.Save(session); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if _, err := s.Embeddings().Update(session, "nomic-embed-text", embed.embed); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+
+	query := fakeVector("tls handshake certificate")
+	matches, err := NewSearchIndex(s).SearchSemantic(query, "nomic-embed-text", 3)
+	if err != nil {
+		t.Fatalf("SearchSemantic: %v", err)
+	}
+	if len(matches) != 3 {
+		t.Fatalf("found %d matches, want the limit of 3", len(matches))
+	}
+	for _, m := range matches[:2] {
+		if m.SessionName != "tls" {
+			t.Errorf("top matches = %+v, want the tls session's messages first", matches)
+		}
+	}
+	if matches[0].Score < matches[1].Score || matches[0].Score > 1000 {
+		t.Errorf("scores = %d, %d", matches[0].Score, matches[1].Score)
+	}
+
+	if matches, _ := NewSearchIndex(s).SearchSemantic(query, "other-model", 3); len(matches) != 0 {
+		t.Errorf("vectors from another model were compared: %+v", matches)
+	}
+
+	// A message edited since it was indexed isn't returned with stale content
+	sessions[0].Messages[0].Content = "edited"
+	if err := s.Save(sessions[0]); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	matches, _ = NewSearchIndex(s).SearchSemantic(query, "nomic-embed-text", 10)
+	for _, m := range matches {
+		if m.Content == "edited" {
+			t.Errorf("returned a message whose embedding is out of date")
+		}
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b []float32
+		want float64
+	}{
+		{[]float32{1, 0}, []float32{2, 0}, 1},
+		{[]float32{1, 0}, []float32{0, 1}, 0},
+		{[]float32{1, 0}, []float32{-1, 0}, -1},
+		{[]float32{1, 0}, []float32{1, 0, 0}, 0},
+		{[]float32{0, 0}, []float32{1, 0}, 0},
+	}
+	for _, tt := range tests {
+		if got := CosineSimilarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("CosineSimilarity(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
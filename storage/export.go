@@ -0,0 +1,454 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"path/filepath"
+	"strings"
+
+	gomarkdown "github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// ExportFormat is a file format sessions can be exported to
+type ExportFormat string
+
+const (
+	ExportJSON     ExportFormat = "json" // The whole session, can be imported again
+	ExportMarkdown ExportFormat = "markdown"
+	ExportHTML     ExportFormat = "html" // A self-contained page
+	ExportText     ExportFormat = "text"
+)
+
+// ExportFormats lists the export formats in the order the export modal cycles them
+var ExportFormats = []ExportFormat{ExportJSON, ExportMarkdown, ExportHTML, ExportText}
+
+// Extension returns the file extension of the format, with the dot
+func (f ExportFormat) Extension() string {
+	switch f {
+	case ExportMarkdown:
+		return ".md"
+	case ExportHTML:
+		return ".html"
+	case ExportText:
+		return ".txt"
+	default:
+		return ".json"
+	}
+}
+
+// Label returns the name of the format shown to the user
+func (f ExportFormat) Label() string {
+	switch f {
+	case ExportMarkdown:
+		return "Markdown"
+	case ExportHTML:
+		return "HTML"
+	case ExportText:
+		return "Plain text"
+	default:
+		return "JSON"
+	}
+}
+
+// ExportOptions chooses the format of an export and, for the readable
+// formats, what goes in it besides the prompts and replies. JSON always
+// holds the whole session so it can be imported again.
+type ExportOptions struct {
+	Format            ExportFormat
+	ToolCalls         bool // Tool calls with their arguments and results
+	SystemPrompt      bool
+	CompactionSummary bool // Where the conversation was compacted, and the summary the model got instead
+}
+
+// DefaultExportOptions exports to JSON, with everything included once
+// another format is picked
+func DefaultExportOptions() ExportOptions {
+	return ExportOptions{
+		Format:            ExportJSON,
+		ToolCalls:         true,
+		SystemPrompt:      true,
+		CompactionSummary: true,
+	}
+}
+
+// ExportPathWithFormat replaces the extension of an export path with the
+// one of format. Paths with another extension get it appended instead.
+func ExportPathWithFormat(path string, format ExportFormat) string {
+	ext := filepath.Ext(path)
+	for _, f := range ExportFormats {
+		if ext != "" && strings.EqualFold(ext, f.Extension()) {
+			return strings.TrimSuffix(path, ext) + format.Extension()
+		}
+	}
+	return path + format.Extension()
+}
+
+// RenderExport renders a session in the format of opts
+func RenderExport(session *Session, opts ExportOptions) ([]byte, error) {
+	switch opts.Format {
+	case ExportMarkdown:
+		return []byte(renderMarkdownExport(session, opts)), nil
+	case ExportHTML:
+		return []byte(renderHTMLExport(session, opts)), nil
+	case ExportText:
+		return []byte(renderTextExport(session, opts)), nil
+	case ExportJSON, "":
+		return json.MarshalIndent(session, "", "  ")
+	default:
+		return nil, fmt.Errorf("unknown export format %q", opts.Format)
+	}
+}
+
+// exportItem is a message to export, or the compaction summary when msg is nil
+type exportItem struct {
+	msg *Message
+}
+
+// exportItems lists what goes in a readable export of the conversation
+// showing, with the compaction summary where the compacted messages end
+func exportItems(session *Session, opts ExportOptions) []exportItem {
+	summary := opts.CompactionSummary && session.CompactionMarker > 0 &&
+		(session.LLMSummary != "" || session.CompactedSummary != "")
+
+	var items []exportItem
+	for i := range session.Messages {
+		if summary && i == session.CompactionMarker {
+			items = append(items, exportItem{})
+		}
+		msg := &session.Messages[i]
+		if msg.Role == "tool" && !opts.ToolCalls {
+			continue
+		}
+		items = append(items, exportItem{msg: msg})
+	}
+	if summary && session.CompactionMarker >= len(session.Messages) {
+		items = append(items, exportItem{})
+	}
+	return items
+}
+
+func exportTimestamp(msg *Message) string {
+	if msg.Timestamp.IsZero() {
+		return ""
+	}
+	return msg.Timestamp.Local().Format("2006-01-02 15:04")
+}
+
+// exportRole returns the heading of a message: who wrote it and, for replies
+// from a model other than the session's, which model
+func exportRole(session *Session, msg *Message) string {
+	switch msg.Role {
+	case "user":
+		return "You"
+	case "assistant":
+		if msg.Model != "" && msg.Model != session.Model {
+			return "Assistant (" + msg.Model + ")"
+		}
+		return "Assistant"
+	case "tool":
+		if msg.ToolCall != nil {
+			return "Tool: " + msg.ToolCall.Name
+		}
+		return "Tool"
+	case "system":
+		return "System"
+	default:
+		return msg.Role
+	}
+}
+
+// exportMetadata returns the label/value lines describing a session
+func exportMetadata(session *Session) [][2]string {
+	lines := [][2]string{{"Model", session.Model}}
+	if session.Provider != "" {
+		lines = append(lines, [2]string{"Provider", session.Provider})
+	}
+	if !session.CreatedAt.IsZero() {
+		lines = append(lines, [2]string{"Created", session.CreatedAt.Local().Format("2006-01-02 15:04")})
+	}
+	if !session.UpdatedAt.IsZero() {
+		lines = append(lines, [2]string{"Updated", session.UpdatedAt.Local().Format("2006-01-02 15:04")})
+	}
+	return lines
+}
+
+// toolArguments returns the arguments of a tool call as indented JSON
+func toolArguments(call *ToolCallRecord) string {
+	if call == nil || len(call.Arguments) == 0 {
+		return ""
+	}
+	data, err := json.MarshalIndent(call.Arguments, "", "  ")
+	if err != nil {
+		return fmt.Sprint(call.Arguments)
+	}
+	return string(data)
+}
+
+// compactionSummary returns the summary that replaced the compacted messages
+func compactionSummary(session *Session) string {
+	if session.LLMSummary != "" {
+		return session.LLMSummary
+	}
+	return session.CompactedSummary
+}
+
+// codeFence wraps text in a fenced code block whose fence is longer than any
+// run of backticks inside it
+func codeFence(text, lang string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence + "\n"
+}
+
+func renderMarkdownExport(session *Session, opts ExportOptions) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", session.Name)
+	for _, line := range exportMetadata(session) {
+		fmt.Fprintf(&b, "- **%s:** %s\n", line[0], line[1])
+	}
+	b.WriteString("\n")
+
+	if opts.SystemPrompt && session.SystemPrompt != "" {
+		b.WriteString("## System prompt\n\n")
+		b.WriteString(codeFence(session.SystemPrompt, ""))
+		b.WriteString("\n")
+	}
+
+	for _, item := range exportItems(session, opts) {
+		b.WriteString("---\n\n")
+		msg := item.msg
+		if msg == nil {
+			b.WriteString("## Compaction summary\n\n")
+			if session.CompactedSummary != "" {
+				fmt.Fprintf(&b, "*%s*\n\n", session.CompactedSummary)
+			}
+			if session.LLMSummary != "" {
+				b.WriteString(session.LLMSummary + "\n\n")
+			}
+			continue
+		}
+
+		heading := exportRole(session, msg)
+		if ts := exportTimestamp(msg); ts != "" {
+			heading += " · " + ts
+		}
+		fmt.Fprintf(&b, "## %s\n\n", heading)
+
+		if msg.Role == "tool" {
+			if args := toolArguments(msg.ToolCall); args != "" {
+				b.WriteString("Arguments:\n\n" + codeFence(args, "json") + "\n")
+			}
+			if msg.ToolError != "" {
+				fmt.Fprintf(&b, "Error: %s\n\n", msg.ToolError)
+			}
+			if msg.Content != "" {
+				b.WriteString("Result:\n\n" + codeFence(msg.Content, "") + "\n")
+			}
+			continue
+		}
+
+		if msg.Content != "" {
+			b.WriteString(strings.TrimRight(msg.Content, "\n") + "\n\n")
+		}
+		if msg.Interrupted {
+			b.WriteString("*(interrupted)*\n\n")
+		}
+		for _, a := range msg.Attachments {
+			fmt.Fprintf(&b, "📎 %s\n\n", a.Name)
+		}
+	}
+	return b.String()
+}
+
+func renderTextExport(session *Session, opts ExportOptions) string {
+	var b strings.Builder
+	b.WriteString(session.Name + "\n")
+	b.WriteString(strings.Repeat("=", max(len([]rune(session.Name)), 3)) + "\n")
+	for _, line := range exportMetadata(session) {
+		fmt.Fprintf(&b, "%s: %s\n", line[0], line[1])
+	}
+	b.WriteString("\n")
+
+	if opts.SystemPrompt && session.SystemPrompt != "" {
+		b.WriteString("System prompt:\n" + session.SystemPrompt + "\n\n")
+	}
+
+	for _, item := range exportItems(session, opts) {
+		b.WriteString(strings.Repeat("-", 40) + "\n")
+		msg := item.msg
+		if msg == nil {
+			b.WriteString("Compaction summary")
+			if session.CompactedSummary != "" {
+				b.WriteString(" (" + session.CompactedSummary + ")")
+			}
+			b.WriteString(":\n")
+			if session.LLMSummary != "" {
+				b.WriteString(session.LLMSummary + "\n")
+			}
+			b.WriteString("\n")
+			continue
+		}
+
+		heading := exportRole(session, msg)
+		if ts := exportTimestamp(msg); ts != "" {
+			heading += " [" + ts + "]"
+		}
+		b.WriteString(heading + ":\n")
+
+		if msg.Role == "tool" {
+			if args := toolArguments(msg.ToolCall); args != "" {
+				b.WriteString("Arguments: " + args + "\n")
+			}
+			if msg.ToolError != "" {
+				b.WriteString("Error: " + msg.ToolError + "\n")
+			}
+			if msg.Content != "" {
+				b.WriteString("Result:\n" + strings.TrimRight(msg.Content, "\n") + "\n")
+			}
+			b.WriteString("\n")
+			continue
+		}
+
+		if msg.Content != "" {
+			b.WriteString(strings.TrimRight(msg.Content, "\n") + "\n")
+		}
+		if msg.Interrupted {
+			b.WriteString("(interrupted)\n")
+		}
+		for _, a := range msg.Attachments {
+			b.WriteString("Attachment: " + a.Name + "\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// exportCSS styles HTML exports; it's inlined so the page needs nothing else
+const exportCSS = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;max-width:860px;margin:2em auto;padding:0 1em;line-height:1.5;color:#1f2328;background:#fff}
+h1{margin-bottom:.2em}
+.meta{color:#656d76;font-size:.9em;margin-bottom:2em}
+.meta span{margin-right:1.5em}
+.message{border-left:4px solid #d0d7de;padding:.2em 1em;margin:1.2em 0}
+.message.user{border-color:#1a7f37}
+.message.assistant{border-color:#0969da}
+.message.tool{border-color:#8250df}
+.message.system,.message.summary{border-color:#9a6700;background:#fff8c5}
+.role{font-weight:600}
+.time{color:#656d76;font-size:.85em;margin-left:.8em}
+.error{color:#cf222e}
+.note{color:#656d76;font-style:italic}
+pre{background:#f6f8fa;padding:.8em;overflow-x:auto;border-radius:6px}
+code{font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,monospace;font-size:.9em}
+img.attachment{max-width:100%;border-radius:6px}
+details summary{cursor:pointer}
+@media (prefers-color-scheme:dark){body{color:#e6edf3;background:#0d1117}pre{background:#161b22}.message.system,.message.summary{background:#272115}.meta,.time,.note{color:#8d96a0}}
+`
+
+// markdownToHTML renders message content. Raw HTML in it is shown as text
+// rather than interpreted, and only safe links are kept.
+func markdownToHTML(content string) string {
+	p := parser.NewWithExtensions(parser.CommonExtensions)
+	doc := p.Parse([]byte(content))
+	r := mdhtml.NewRenderer(mdhtml.RendererOptions{
+		Flags: mdhtml.Safelink | mdhtml.NofollowLinks | mdhtml.NoreferrerLinks | mdhtml.HrefTargetBlank | mdhtml.SkipImages,
+		RenderNodeHook: func(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+			switch n := node.(type) {
+			case *ast.HTMLBlock:
+				io.WriteString(w, "<p>"+html.EscapeString(string(n.Literal))+"</p>\n")
+				return ast.GoToNext, true
+			case *ast.HTMLSpan:
+				io.WriteString(w, html.EscapeString(string(n.Literal)))
+				return ast.GoToNext, true
+			}
+			return ast.GoToNext, false
+		},
+	})
+	return string(gomarkdown.Render(doc, r))
+}
+
+func renderHTMLExport(session *Session, opts ExportOptions) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", html.EscapeString(session.Name), exportCSS)
+
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<div class=\"meta\">", html.EscapeString(session.Name))
+	for _, line := range exportMetadata(session) {
+		fmt.Fprintf(&b, "<span><strong>%s:</strong> %s</span>", line[0], html.EscapeString(line[1]))
+	}
+	b.WriteString("</div>\n")
+
+	if opts.SystemPrompt && session.SystemPrompt != "" {
+		b.WriteString("<div class=\"message system\"><details><summary class=\"role\">System prompt</summary>\n")
+		fmt.Fprintf(&b, "<pre>%s</pre>\n</details></div>\n", html.EscapeString(session.SystemPrompt))
+	}
+
+	for _, item := range exportItems(session, opts) {
+		msg := item.msg
+		if msg == nil {
+			b.WriteString("<div class=\"message summary\"><p class=\"role\">Compaction summary</p>\n")
+			if session.CompactedSummary != "" {
+				fmt.Fprintf(&b, "<p class=\"note\">%s</p>\n", html.EscapeString(session.CompactedSummary))
+			}
+			if session.LLMSummary != "" {
+				b.WriteString(markdownToHTML(session.LLMSummary))
+			}
+			b.WriteString("</div>\n")
+			continue
+		}
+
+		fmt.Fprintf(&b, "<div class=\"message %s\">\n<p><span class=\"role\">%s</span>",
+			html.EscapeString(msg.Role), html.EscapeString(exportRole(session, msg)))
+		if ts := exportTimestamp(msg); ts != "" {
+			fmt.Fprintf(&b, "<span class=\"time\">%s</span>", ts)
+		}
+		b.WriteString("</p>\n")
+
+		if msg.Role == "tool" {
+			if args := toolArguments(msg.ToolCall); args != "" {
+				fmt.Fprintf(&b, "<details><summary>Arguments</summary><pre><code>%s</code></pre></details>\n", html.EscapeString(args))
+			}
+			if msg.ToolError != "" {
+				fmt.Fprintf(&b, "<p class=\"error\">%s</p>\n", html.EscapeString(msg.ToolError))
+			}
+			if msg.Content != "" {
+				fmt.Fprintf(&b, "<details><summary>Result</summary><pre><code>%s</code></pre></details>\n", html.EscapeString(msg.Content))
+			}
+			b.WriteString("</div>\n")
+			continue
+		}
+
+		if msg.Content != "" {
+			b.WriteString(markdownToHTML(msg.Content))
+		}
+		if msg.Interrupted {
+			b.WriteString("<p class=\"note\">(interrupted)</p>\n")
+		}
+		for _, a := range msg.Attachments {
+			if strings.HasPrefix(a.MIMEType, "image/") {
+				fmt.Fprintf(&b, "<p><img class=\"attachment\" alt=\"%s\" src=\"data:%s;base64,%s\"></p>\n",
+					html.EscapeString(a.Name), html.EscapeString(a.MIMEType), base64.StdEncoding.EncodeToString(a.Data))
+			} else {
+				fmt.Fprintf(&b, "<p class=\"note\">📎 %s</p>\n", html.EscapeString(a.Name))
+			}
+		}
+		b.WriteString("</div>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportTestSession() *Session {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	messages := conversation(start, "old question", "old answer", "How do I list files?", "Use `ls`:\n\n```sh\nls -la\n```")
+	messages = append(messages,
+		Message{
+			Role:      "tool",
+			Content:   "a.txt\nb.txt",
+			Timestamp: start.Add(5 * time.Minute),
+			ToolCall:  &ToolCallRecord{Name: "fs.list", Arguments: map[string]any{"path": "/tmp"}},
+		},
+		Message{Role: "assistant", Content: "Two files. <script>alert(1)</script>", Timestamp: start.Add(6 * time.Minute)},
+	)
+	return &Session{
+		Name:             "Files",
+		Model:            "llama3",
+		Provider:         "ollama",
+		Messages:         messages,
+		SystemPrompt:     "Be terse.",
+		CompactionMarker: 2,
+		CompactedSummary: "Compacted 2 messages (1 user, 1 assistant)",
+		LLMSummary:       "The user asked an old question.",
+	}
+}
+
+func TestRenderExport(t *testing.T) {
+	all := DefaultExportOptions()
+
+	t.Run("markdown", func(t *testing.T) {
+		opts := all
+		opts.Format = ExportMarkdown
+		data, err := RenderExport(exportTestSession(), opts)
+		if err != nil {
+			t.Fatalf("RenderExport: %v", err)
+		}
+		out := string(data)
+		for _, want := range []string{
+			"# Files",
+			"## System prompt",
+			"## Compaction summary",
+			"The user asked an old question.",
+			"## You · ",
+			"## Assistant · ",
+			"## Tool: fs.list",
+			`"path": "/tmp"`,
+			"a.txt\nb.txt",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("markdown is missing %q:\n%s", want, out)
+			}
+		}
+		if strings.Index(out, "## Compaction summary") > strings.Index(out, "How do I list files?") ||
+			strings.Index(out, "## Compaction summary") < strings.Index(out, "old answer") {
+			t.Error("compaction summary isn't where the compacted messages end")
+		}
+	})
+
+	t.Run("options leave things out", func(t *testing.T) {
+		for _, format := range []ExportFormat{ExportMarkdown, ExportHTML, ExportText} {
+			opts := ExportOptions{Format: format}
+			data, err := RenderExport(exportTestSession(), opts)
+			if err != nil {
+				t.Fatalf("RenderExport(%s): %v", format, err)
+			}
+			out := string(data)
+			for _, unwanted := range []string{"Be terse.", "fs.list", "The user asked an old question."} {
+				if strings.Contains(out, unwanted) {
+					t.Errorf("%s export contains %q with every option off", format, unwanted)
+				}
+			}
+			if !strings.Contains(out, "How do I list files?") {
+				t.Errorf("%s export is missing the conversation", format)
+			}
+		}
+	})
+
+	t.Run("html escapes raw html", func(t *testing.T) {
+		opts := all
+		opts.Format = ExportHTML
+		data, err := RenderExport(exportTestSession(), opts)
+		if err != nil {
+			t.Fatalf("RenderExport: %v", err)
+		}
+		out := string(data)
+		if strings.Contains(out, "<script>") {
+			t.Error("html export contains a raw <script> tag")
+		}
+		if !strings.Contains(out, "&lt;script&gt;") {
+			t.Error("html export dropped the escaped script text")
+		}
+		if !strings.Contains(out, "<style>") || !strings.Contains(out, "<code") {
+			t.Error("html export should be styled and render code blocks")
+		}
+	})
+
+	t.Run("plain text", func(t *testing.T) {
+		opts := all
+		opts.Format = ExportText
+		data, err := RenderExport(exportTestSession(), opts)
+		if err != nil {
+			t.Fatalf("RenderExport: %v", err)
+		}
+		out := string(data)
+		if !strings.Contains(out, "You [") || !strings.Contains(out, "Tool: fs.list [") {
+			t.Errorf("plain text is missing role headings:\n%s", out)
+		}
+	})
+
+	t.Run("json round-trips", func(t *testing.T) {
+		data, err := RenderExport(exportTestSession(), all)
+		if err != nil {
+			t.Fatalf("RenderExport: %v", err)
+		}
+		var session Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if len(session.Messages) != 6 || session.LLMSummary == "" {
+			t.Errorf("json export lost data: %d messages, summary %q", len(session.Messages), session.LLMSummary)
+		}
+	})
+}
+
+func TestCodeFence(t *testing.T) {
+	got := codeFence("has ``` inside", "")
+	if !strings.HasPrefix(got, "````\n") || !strings.HasSuffix(got, "\n````\n") {
+		t.Errorf("codeFence = %q, want a four-backtick fence", got)
+	}
+}
+
+func TestExportPathWithFormat(t *testing.T) {
+	tests := []struct {
+		path   string
+		format ExportFormat
+		want   string
+	}{
+		{"/tmp/session.json", ExportMarkdown, "/tmp/session.md"},
+		{"/tmp/session.md", ExportHTML, "/tmp/session.html"},
+		{"/tmp/session.HTML", ExportText, "/tmp/session.txt"},
+		{"/tmp/session", ExportJSON, "/tmp/session.json"},
+		{"/tmp/v1.2", ExportText, "/tmp/v1.2.txt"},
+	}
+	for _, tt := range tests {
+		if got := ExportPathWithFormat(tt.path, tt.format); got != tt.want {
+			t.Errorf("ExportPathWithFormat(%q, %s) = %q, want %q", tt.path, tt.format, got, tt.want)
+		}
+	}
+}
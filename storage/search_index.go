@@ -3,17 +3,158 @@ package storage
 import (
 	"strings"
 	"time"
+	"unicode"
 )
 
 type SessionMessageMatch struct {
 	SessionID    string
 	SessionName  string
-	MessageIndex int
+	BranchID     string // Set if the message is in another version of the conversation (see Session.SwitchToBranch)
+	MessageIndex int    // Index into the conversation once that version is showing
 	Role         string
 	Content      string
 	Preview      string
 	Timestamp    time.Time
-	Score        int
+	Score        int // Higher is a better match (SQLite session store only)
+}
+
+// SearchQuery is a parsed global search: words and "quoted phrases" that
+// must all appear in a message (a trailing * matches any word starting with
+// the prefix), and filters given as model:, provider:, after: and before:
+type SearchQuery struct {
+	Terms    []string // Lowercase, without quotes; a prefix ends in *
+	Model    string   // Part of the session's model name
+	Provider string   // The session's provider ID
+	After    time.Time
+	Before   time.Time
+}
+
+// searchDateFormat is the format of the after: and before: filters
+const searchDateFormat = "2006-01-02"
+
+// ParseSearchQuery splits a search into terms and filters. after:DATE
+// matches messages from that day on, before:DATE messages before that day.
+// A filter with an unreadable value is searched for as a word.
+func ParseSearchQuery(query string) SearchQuery {
+	var q SearchQuery
+	for _, token := range splitSearchQuery(query) {
+		if strings.HasPrefix(token, `"`) {
+			if phrase := strings.ToLower(strings.TrimSpace(strings.Trim(token, `"`))); phrase != "" {
+				q.Terms = append(q.Terms, phrase)
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(token, ":")
+		if found && value != "" {
+			switch strings.ToLower(key) {
+			case "model":
+				q.Model = value
+				continue
+			case "provider":
+				q.Provider = value
+				continue
+			case "after":
+				if t, err := time.ParseInLocation(searchDateFormat, value, time.Local); err == nil {
+					q.After = t
+					continue
+				}
+			case "before":
+				if t, err := time.ParseInLocation(searchDateFormat, value, time.Local); err == nil {
+					q.Before = t
+					continue
+				}
+			}
+		}
+		q.Terms = append(q.Terms, strings.ToLower(token))
+	}
+	return q
+}
+
+// splitSearchQuery splits a search at spaces outside of double quotes,
+// keeping the quotes around phrases
+func splitSearchQuery(query string) []string {
+	var tokens []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if quoted {
+				current.WriteRune(r)
+				tokens = append(tokens, current.String())
+				current.Reset()
+			} else {
+				if current.Len() > 0 {
+					tokens = append(tokens, current.String())
+					current.Reset()
+				}
+				current.WriteRune(r)
+			}
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// IsEmpty reports whether the search has neither terms nor filters
+func (q SearchQuery) IsEmpty() bool {
+	return len(q.Terms) == 0 && q.Model == "" && q.Provider == "" && q.After.IsZero() && q.Before.IsZero()
+}
+
+// ftsQuery builds the FTS5 MATCH expression for the terms. Each term is
+// quoted so punctuation in it can't be read as query syntax.
+func (q SearchQuery) ftsQuery() string {
+	parts := make([]string, 0, len(q.Terms))
+	for _, term := range q.Terms {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimRight(term, "*")
+		if term == "" {
+			continue
+		}
+		part := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			part += "*"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// matchesSession reports whether a session passes the model and provider filters
+func (q SearchQuery) matchesSession(session *Session) bool {
+	if q.Model != "" && !strings.Contains(strings.ToLower(session.Model), strings.ToLower(q.Model)) {
+		return false
+	}
+	return q.Provider == "" || strings.EqualFold(session.Provider, q.Provider)
+}
+
+// matchesMessage reports whether a message has every term and passes the
+// date filters. Without the index, a prefix matches anywhere in the text.
+func (q SearchQuery) matchesMessage(msg Message) bool {
+	if !q.After.IsZero() && msg.Timestamp.Before(q.After) {
+		return false
+	}
+	if !q.Before.IsZero() && !msg.Timestamp.Before(q.Before) {
+		return false
+	}
+	contentLower := strings.ToLower(msg.Content)
+	for _, term := range q.Terms {
+		if !strings.Contains(contentLower, strings.TrimRight(term, "*")) {
+			return false
+		}
+	}
+	return true
 }
 
 type SearchIndex struct {
@@ -24,49 +165,80 @@ func NewSearchIndex(storage *SessionStorage) *SearchIndex {
 	return &SearchIndex{storage: storage}
 }
 
+// SearchAllSessions searches the messages of every session (see
+// ParseSearchQuery for the syntax). With the SQLite session store results
+// are ranked, best first; otherwise they are in session order.
 func (si *SearchIndex) SearchAllSessions(query string) ([]SessionMessageMatch, error) {
-	if query == "" {
+	q := ParseSearchQuery(query)
+	if q.IsEmpty() {
 		return []SessionMessageMatch{}, nil
 	}
 
+	if si.storage.db != nil {
+		return si.storage.searchSQLite(q)
+	}
+
 	sessionList, err := si.storage.List()
 	if err != nil {
 		return nil, err
 	}
 
-	queryLower := strings.ToLower(query)
 	var matches []SessionMessageMatch
 
 	for _, sessionMeta := range sessionList {
 		session, err := si.storage.Load(sessionMeta.ID)
-		if err != nil {
+		if err != nil || !q.matchesSession(session) {
 			continue
 		}
 
-		for i, msg := range session.Messages {
-			if msg.Role == "system" {
-				continue
-			}
+		matches = append(matches, matchMessages(session, "", 0, session.Messages, q)...)
+		matches = append(matches, matchBranches(session, session.Branches, q)...)
+	}
 
-			if strings.Contains(strings.ToLower(msg.Content), queryLower) {
-				preview := msg.Content
-				if len(preview) > 100 {
-					preview = preview[:100] + "..."
-				}
+	return matches, nil
+}
 
-				matches = append(matches, SessionMessageMatch{
-					SessionID:    session.ID,
-					SessionName:  session.Name,
-					MessageIndex: i,
-					Role:         msg.Role,
-					Content:      msg.Content,
-					Preview:      preview,
-					Timestamp:    msg.Timestamp,
-					Score:        0,
-				})
-			}
+// matchBranches searches the stashed versions of a conversation, and the
+// versions stashed inside them
+func matchBranches(session *Session, branches []Branch, q SearchQuery) []SessionMessageMatch {
+	var matches []SessionMessageMatch
+	for _, b := range branches {
+		matches = append(matches, matchMessages(session, b.ID, b.At, b.Messages, q)...)
+		matches = append(matches, matchBranches(session, b.Branches, q)...)
+	}
+	return matches
+}
+
+// matchMessages searches messages that start at index offset in the
+// conversation
+func matchMessages(session *Session, branchID string, offset int, messages []Message, q SearchQuery) []SessionMessageMatch {
+	var matches []SessionMessageMatch
+	for i, msg := range messages {
+		if msg.Role == "system" {
+			continue
+		}
+
+		if q.matchesMessage(msg) {
+			matches = append(matches, SessionMessageMatch{
+				SessionID:    session.ID,
+				SessionName:  session.Name,
+				BranchID:     branchID,
+				MessageIndex: offset + i,
+				Role:         msg.Role,
+				Content:      msg.Content,
+				Preview:      previewContent(msg.Content),
+				Timestamp:    msg.Timestamp,
+				Score:        0,
+			})
 		}
 	}
+	return matches
+}
 
-	return matches, nil
+// previewContent shortens a message for a search result
+func previewContent(content string) string {
+	if len(content) > 100 {
+		return content[:100] + "..."
+	}
+	return content
 }
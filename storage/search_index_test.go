@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	q := ParseSearchQuery(`Docker "multi stage build" comp* model:llama provider:ollama after:2026-01-01 before:2026-02-01 when:later`)
+
+	wantTerms := []string{"docker", "multi stage build", "comp*", "when:later"}
+	if len(q.Terms) != len(wantTerms) {
+		t.Fatalf("Terms = %q, want %q", q.Terms, wantTerms)
+	}
+	for i := range wantTerms {
+		if q.Terms[i] != wantTerms[i] {
+			t.Errorf("Terms[%d] = %q, want %q", i, q.Terms[i], wantTerms[i])
+		}
+	}
+	if q.Model != "llama" || q.Provider != "ollama" {
+		t.Errorf("Model = %q, Provider = %q", q.Model, q.Provider)
+	}
+	if want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local); !q.After.Equal(want) {
+		t.Errorf("After = %v, want %v", q.After, want)
+	}
+	if want := time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local); !q.Before.Equal(want) {
+		t.Errorf("Before = %v, want %v", q.Before, want)
+	}
+	if got, want := q.ftsQuery(), `"docker" "multi stage build" "comp"* "when:later"`; got != want {
+		t.Errorf("ftsQuery = %s, want %s", got, want)
+	}
+
+	t.Run("unreadable date is a word", func(t *testing.T) {
+		q := ParseSearchQuery("after:soon")
+		if !q.After.IsZero() || len(q.Terms) != 1 || q.Terms[0] != "after:soon" {
+			t.Errorf("query = %+v", q)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		if !ParseSearchQuery(`  "" `).IsEmpty() {
+			t.Error("a blank query should be empty")
+		}
+		if ParseSearchQuery("provider:ollama").IsEmpty() {
+			t.Error("a filter alone is a search")
+		}
+	})
+}
+
+func TestSearchAllSessionsFilters(t *testing.T) {
+	s, err := NewSessionStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+
+	jan := time.Date(2026, 1, 10, 12, 0, 0, 0, time.Local)
+	mar := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	sessions := []*Session{
+		{Name: "local", Provider: "ollama", Model: "llama3.1:latest", Messages: conversation(jan, "how do I write a Dockerfile", "use a multi stage build")},
+		{Name: "cloud", Provider: "openrouter", Model: "qwen/qwen3-coder:free", Messages: conversation(mar, "docker compose networks", "compose creates a default network")},
+	}
+	for _, session := range sessions {
+		if err := s.Save(session); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	search := func(query string) []string {
+		t.Helper()
+		matches, err := NewSearchIndex(s).SearchAllSessions(query)
+		if err != nil {
+			t.Fatalf("SearchAllSessions(%q): %v", query, err)
+		}
+		var found []string
+		for _, m := range matches {
+			found = append(found, m.SessionName+": "+m.Content)
+		}
+		return found
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"docker", 2},
+		{"docker provider:ollama", 1},
+		{"docker model:QWEN", 1},
+		{"docker after:2026-02-01", 1},
+		{"docker before:2026-02-01", 1},
+		{`"multi stage"`, 1},
+		{`"stage multi"`, 0},
+		{"compose network", 2},
+		{"provider:openrouter", 2},
+	}
+	for _, tt := range tests {
+		if got := search(tt.query); len(got) != tt.want {
+			t.Errorf("%q found %q, want %d matches", tt.query, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -19,6 +20,56 @@ type Message struct {
 	Content   string    `json:"content"`
 	Rendered  string    `json:"rendered,omitempty"` // Cached markdown rendering
 	Timestamp time.Time `json:"timestamp"`
+
+	// Reasoning is the thinking a reasoning model reported before its answer
+	// (role "assistant" only). It is shown folded and never sent back.
+	Reasoning string `json:"reasoning,omitempty"`
+
+	// Interrupted is set when the user stopped the response before it finished;
+	// Content then holds the partial text
+	Interrupted bool `json:"interrupted,omitempty"`
+
+	// Files attached to a user message, stored inline so sessions stay
+	// self-contained when exported
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	// Token counts the provider reported for the response (role "assistant"
+	// only; zero if the provider reported nothing). PromptTokens covers the
+	// whole request, so it measures how much context was in use.
+	PromptTokens     int `json:"prompt_tokens,omitempty"`
+	CompletionTokens int `json:"completion_tokens,omitempty"`
+
+	// Provider ID and model that produced the response (role "assistant"
+	// only). They differ from the session's when a fallback model answered.
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+
+	// Tool execution (role "tool" only): the call that was made and how it went.
+	// Content holds the tool result that was sent back to the model.
+	ToolCall   *ToolCallRecord `json:"tool_call,omitempty"`
+	ToolError  string          `json:"tool_error,omitempty"`
+	DurationMs int64           `json:"duration_ms,omitempty"`
+}
+
+// Attachment is a persisted file attached to a message
+type Attachment struct {
+	Name     string `json:"name"`
+	MIMEType string `json:"mime_type"`
+	Data     []byte `json:"data"` // Base64 in JSON
+}
+
+// ToolCallRecord is a persisted tool call requested by the model
+type ToolCallRecord struct {
+	ID        string         `json:"id,omitempty"` // Provider-issued call ID (if any)
+	Name      string         `json:"name"`         // Namespaced tool name (pluginID.toolName)
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// ResourceRef is an MCP resource pinned to a session
+type ResourceRef struct {
+	PluginID string `json:"plugin_id"`
+	URI      string `json:"uri"`
+	Name     string `json:"name,omitempty"` // Shown in the chat; the URI when empty
 }
 
 // TokenUsage tracks token consumption for a session
@@ -27,7 +78,7 @@ type TokenUsage struct {
 	ActiveTokens     int       `json:"active_tokens"`
 	CompactedTokens  int       `json:"compacted_tokens"`
 	LastUpdated      time.Time `json:"last_updated"`
-	EstimationMethod string    `json:"estimation_method"` // "character_based" or provider-specific
+	EstimationMethod string    `json:"estimation_method"` // "character_based" or "provider_reported"
 }
 
 // Session represents a chat session
@@ -43,6 +94,27 @@ type Session struct {
 	EnabledPlugins []string  `json:"enabled_plugins,omitempty"`
 	AllowedTools   []string  `json:"allowed_tools,omitempty"` // Tools permanently approved for this session
 
+	// Sampling settings chosen for this session; unset fields fall back to
+	// the [generation] defaults in config.toml
+	Generation config.GenerationParams `json:"generation,omitzero"`
+
+	// Models to try when the session's model fails; empty uses the
+	// [[fallback]] list from config.toml
+	Fallback []config.FallbackTarget `json:"fallback,omitempty"`
+
+	// Plugin resources pinned to the session, whose current contents are
+	// sent along with every prompt
+	Resources []ResourceRef `json:"resources,omitempty"`
+
+	// Other versions of the conversation, left behind when a message was
+	// edited or a reply regenerated (see Session.Branch)
+	Branches []Branch `json:"branches,omitempty"`
+
+	// Set on sessions created with SessionStorage.Fork: the session they were
+	// copied from and the index of the last message copied
+	ParentID string `json:"parent_id,omitempty"`
+	ForkedAt int    `json:"forked_at,omitempty"`
+
 	// Context Management
 	CompactionMarker    int        `json:"compaction_marker,omitempty"`
 	CompactedSummary    string     `json:"compacted_summary,omitempty"`
@@ -63,11 +135,13 @@ type SessionMetadata struct {
 	SystemPrompt   string    `json:"system_prompt,omitempty"`
 	EnabledPlugins []string  `json:"enabled_plugins,omitempty"`
 	AllowedTools   []string  `json:"allowed_tools,omitempty"` // Tools permanently approved for this session
+	ParentID       string    `json:"parent_id,omitempty"`     // Session this one was forked from
 }
 
 // SessionStorage handles session persistence
 type SessionStorage struct {
 	sessionsDir string
+	db          *sql.DB // Set when sessions are kept in SQLite (see NewSQLiteSessionStorage)
 }
 
 // NewSessionStorage creates a new session storage
@@ -103,6 +177,10 @@ func (s *SessionStorage) Save(session *Session) error {
 		return fmt.Errorf("failed to marshal session: %w", err)
 	}
 
+	if s.db != nil {
+		return s.saveSQLite(session, data)
+	}
+
 	// Use 0600 permissions - session files contain sensitive conversation history
 	if err := os.WriteFile(filepath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write session file: %w", err)
@@ -113,12 +191,9 @@ func (s *SessionStorage) Save(session *Session) error {
 
 // Load loads a session from disk
 func (s *SessionStorage) Load(id string) (*Session, error) {
-	filename := fmt.Sprintf("%s.json", id)
-	filepath := filepath.Join(s.sessionsDir, filename)
-
-	data, err := os.ReadFile(filepath)
+	data, err := s.readSession(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read session file: %w", err)
+		return nil, err
 	}
 
 	var session Session
@@ -140,8 +215,28 @@ func (s *SessionStorage) Load(id string) (*Session, error) {
 	return &session, nil
 }
 
+// readSession returns the JSON of a session, from its file or the database
+func (s *SessionStorage) readSession(id string) ([]byte, error) {
+	if s.db != nil {
+		return s.loadSQLite(id)
+	}
+
+	filename := fmt.Sprintf("%s.json", id)
+	filepath := filepath.Join(s.sessionsDir, filename)
+
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	return data, nil
+}
+
 // List returns metadata for all sessions, sorted by update time (newest first)
 func (s *SessionStorage) List() ([]SessionMetadata, error) {
+	if s.db != nil {
+		return s.listSQLite()
+	}
+
 	entries, err := os.ReadDir(s.sessionsDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
@@ -176,6 +271,7 @@ func (s *SessionStorage) List() ([]SessionMetadata, error) {
 			SystemPrompt:   session.SystemPrompt,
 			EnabledPlugins: session.EnabledPlugins,
 			AllowedTools:   session.AllowedTools,
+			ParentID:       session.ParentID,
 		})
 	}
 
@@ -189,13 +285,22 @@ func (s *SessionStorage) List() ([]SessionMetadata, error) {
 
 // Delete deletes a session from disk
 func (s *SessionStorage) Delete(id string) error {
-	filename := fmt.Sprintf("%s.json", id)
-	filepath := filepath.Join(s.sessionsDir, filename)
+	if s.db != nil {
+		if err := s.deleteSQLite(id); err != nil {
+			return err
+		}
+	} else {
+		filename := fmt.Sprintf("%s.json", id)
+		filepath := filepath.Join(s.sessionsDir, filename)
 
-	if err := os.Remove(filepath); err != nil {
-		return fmt.Errorf("failed to delete session file: %w", err)
+		if err := os.Remove(filepath); err != nil {
+			return fmt.Errorf("failed to delete session file: %w", err)
+		}
 	}
 
+	// Semantic search embeddings go with the session
+	_ = s.Embeddings().Delete(id)
+
 	return nil
 }
 
@@ -231,6 +336,50 @@ func (s *SessionStorage) RenameSession(id string, newName string) error {
 	return nil
 }
 
+// Fork copies a session's conversation up to and including the message at
+// atMessageIndex into a new session, along with its settings (system prompt,
+// plugins, allowed tools, pinned resources, provider and model), so another
+// model or approach can be tried from there without changing the original.
+// The compaction summary comes along if it only covers copied messages.
+// Other versions of the conversation (see Session.Branch) stay with the
+// original.
+func (s *SessionStorage) Fork(id string, atMessageIndex int) (*Session, error) {
+	parent, err := s.Load(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	if atMessageIndex < 0 || atMessageIndex >= len(parent.Messages) {
+		return nil, fmt.Errorf("message %d out of range (%d messages)", atMessageIndex, len(parent.Messages))
+	}
+
+	messages := append([]Message(nil), parent.Messages[:atMessageIndex+1]...)
+	fork := &Session{
+		Name:           parent.Name + " (fork)",
+		Model:          parent.Model,
+		Provider:       parent.Provider,
+		Messages:       messages,
+		SystemPrompt:   parent.SystemPrompt,
+		EnabledPlugins: append([]string{}, parent.EnabledPlugins...),
+		AllowedTools:   append([]string{}, parent.AllowedTools...),
+		Resources:      append([]ResourceRef(nil), parent.Resources...),
+		Generation:     parent.Generation,
+		Fallback:       append([]config.FallbackTarget(nil), parent.Fallback...),
+		ParentID:       parent.ID,
+		ForkedAt:       atMessageIndex,
+	}
+	if parent.CompactionMarker > 0 && parent.CompactionMarker <= countConversation(messages) {
+		fork.CompactionMarker = parent.CompactionMarker
+		fork.CompactedSummary = parent.CompactedSummary
+		fork.LLMSummary = parent.LLMSummary
+		fork.CompactionTimestamp = parent.CompactionTimestamp
+	}
+
+	if err := s.Save(fork); err != nil {
+		return nil, fmt.Errorf("failed to save fork: %w", err)
+	}
+	return fork, nil
+}
+
 // SanitizeFilename removes or replaces characters that are invalid in filenames
 func SanitizeFilename(name string) string {
 	// Replace problematic characters with hyphens
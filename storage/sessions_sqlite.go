@@ -0,0 +1,376 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"otui/config"
+)
+
+// maxSearchResults caps the matches returned from the SQLite full-text index
+const maxSearchResults = 500
+
+// OpenSessionStorage opens the session store selected by session_store in
+// config.toml: JSON files (the default) or SQLite
+func OpenSessionStorage(dataDir, store string) (*SessionStorage, error) {
+	if store == config.SessionStoreSQLite {
+		return NewSQLiteSessionStorage(dataDir)
+	}
+	return NewSessionStorage(dataDir)
+}
+
+// NewSQLiteSessionStorage creates a session storage that keeps sessions in
+// sessions.db with a full-text index over their messages. The first time it
+// is opened, sessions are imported from the JSON files, which are left in
+// place.
+func NewSQLiteSessionStorage(dataDir string) (*SessionStorage, error) {
+	s, err := NewSessionStorage(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(dataDir, "sessions.db"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// Concurrent saves would otherwise fail with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	s.db = db
+	if err := s.initializeDB(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	if err := s.importJSONSessions(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to import JSON sessions: %w", err)
+	}
+
+	return s, nil
+}
+
+// Close closes the SQLite database, if sessions are kept in one
+func (s *SessionStorage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+func (s *SessionStorage) initializeDB() error {
+	// Times are Unix milliseconds so they compare and sort as numbers. data
+	// holds the whole session in the JSON format of the session files.
+	schema := `
+	CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		model TEXT NOT NULL,
+		provider TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		message_count INTEGER NOT NULL,
+		system_prompt TEXT NOT NULL,
+		enabled_plugins TEXT NOT NULL,
+		allowed_tools TEXT NOT NULL,
+		parent_id TEXT NOT NULL,
+		data TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_sessions_updated_at ON sessions(updated_at);
+	CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
+		content,
+		session_id UNINDEXED,
+		branch_id UNINDEXED,
+		message_index UNINDEXED,
+		role UNINDEXED,
+		timestamp UNINDEXED
+	);
+	CREATE TABLE IF NOT EXISTS migrations (
+		name TEXT PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// importJSONSessions copies the session files into the database, once
+func (s *SessionStorage) importJSONSessions() error {
+	var done int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM migrations WHERE name = 'json_import'`).Scan(&done); err != nil {
+		return err
+	}
+	if done > 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(s.sessionsDir)
+	if err != nil {
+		return fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	imported := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.sessionsDir, entry.Name()))
+		if err != nil {
+			continue // Skip unreadable files
+		}
+		var session Session
+		if err := json.Unmarshal(data, &session); err != nil || session.ID == "" {
+			continue // Skip corrupted files
+		}
+
+		if err := putSession(tx, &session, data); err != nil {
+			return fmt.Errorf("failed to import session %s: %w", session.ID, err)
+		}
+		imported++
+	}
+
+	if _, err := tx.Exec(`INSERT INTO migrations (name, applied_at) VALUES ('json_import', ?)`, time.Now().UnixMilli()); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if config.DebugLog != nil {
+		config.DebugLog.Printf("[storage] Imported %d JSON sessions into sessions.db", imported)
+	}
+	return nil
+}
+
+// saveSQLite stores a session and re-indexes its messages
+func (s *SessionStorage) saveSQLite(session *Session, data []byte) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := putSession(tx, session, data); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// putSession writes a session row and its messages (including the other
+// versions of the conversation) to the full-text index
+func putSession(tx *sql.Tx, session *Session, data []byte) error {
+	enabledPlugins, _ := json.Marshal(session.GetEnabledPlugins())
+	allowedTools, _ := json.Marshal(session.AllowedTools)
+
+	_, err := tx.Exec(`
+	INSERT OR REPLACE INTO sessions (id, name, model, provider, created_at, updated_at, message_count,
+		system_prompt, enabled_plugins, allowed_tools, parent_id, data)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		session.ID,
+		session.Name,
+		session.Model,
+		session.Provider,
+		session.CreatedAt.UnixMilli(),
+		session.UpdatedAt.UnixMilli(),
+		len(session.Messages),
+		session.SystemPrompt,
+		string(enabledPlugins),
+		string(allowedTools),
+		session.ParentID,
+		string(data),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM messages_fts WHERE session_id = ?`, session.ID); err != nil {
+		return err
+	}
+	insert, err := tx.Prepare(`
+	INSERT INTO messages_fts (content, session_id, branch_id, message_index, role, timestamp)
+	VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	var index func(branchID string, offset int, messages []Message, branches []Branch) error
+	index = func(branchID string, offset int, messages []Message, branches []Branch) error {
+		for i, msg := range messages {
+			if msg.Role == "system" {
+				continue
+			}
+			if _, err := insert.Exec(msg.Content, session.ID, branchID, offset+i, msg.Role, msg.Timestamp.UnixMilli()); err != nil {
+				return err
+			}
+		}
+		for _, b := range branches {
+			if err := index(b.ID, b.At, b.Messages, b.Branches); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return index("", 0, session.Messages, session.Branches)
+}
+
+// loadSQLite returns a session in the JSON format of the session files
+func (s *SessionStorage) loadSQLite(id string) ([]byte, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM sessions WHERE id = ?`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to read session: %s not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	return []byte(data), nil
+}
+
+func (s *SessionStorage) listSQLite() ([]SessionMetadata, error) {
+	rows, err := s.db.Query(`
+	SELECT id, name, model, provider, created_at, updated_at, message_count,
+		system_prompt, enabled_plugins, allowed_tools, parent_id
+	FROM sessions
+	ORDER BY updated_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []SessionMetadata
+	for rows.Next() {
+		var meta SessionMetadata
+		var createdAt, updatedAt int64
+		var enabledPlugins, allowedTools string
+		if err := rows.Scan(&meta.ID, &meta.Name, &meta.Model, &meta.Provider, &createdAt, &updatedAt,
+			&meta.MessageCount, &meta.SystemPrompt, &enabledPlugins, &allowedTools, &meta.ParentID); err != nil {
+			return nil, fmt.Errorf("failed to list sessions: %w", err)
+		}
+		meta.CreatedAt = time.UnixMilli(createdAt)
+		meta.UpdatedAt = time.UnixMilli(updatedAt)
+		_ = json.Unmarshal([]byte(enabledPlugins), &meta.EnabledPlugins)
+		_ = json.Unmarshal([]byte(allowedTools), &meta.AllowedTools)
+		sessions = append(sessions, meta)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *SessionStorage) deleteSQLite(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM sessions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("failed to delete session: %s not found", id)
+	}
+	if _, err := tx.Exec(`DELETE FROM messages_fts WHERE session_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return tx.Commit()
+}
+
+// searchSQLite runs a search against the full-text index, best matches first.
+// Without search terms it lists the messages passing the filters, newest
+// first.
+func (s *SessionStorage) searchSQLite(q SearchQuery) ([]SessionMessageMatch, error) {
+	var where []string
+	var args []any
+
+	match := q.ftsQuery()
+	preview, score := "''", "0"
+	order := "messages_fts.timestamp DESC"
+	if match != "" {
+		where = append(where, "messages_fts MATCH ?")
+		args = append(args, match)
+		preview = "snippet(messages_fts, 0, '', '', '...', 16)"
+		score, order = "rank", "rank"
+	}
+	if q.Model != "" {
+		where = append(where, `LOWER(sessions.model) LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(strings.ToLower(q.Model))+"%")
+	}
+	if q.Provider != "" {
+		where = append(where, "LOWER(sessions.provider) = ?")
+		args = append(args, strings.ToLower(q.Provider))
+	}
+	if !q.After.IsZero() {
+		where = append(where, "messages_fts.timestamp >= ?")
+		args = append(args, q.After.UnixMilli())
+	}
+	if !q.Before.IsZero() {
+		where = append(where, "messages_fts.timestamp < ?")
+		args = append(args, q.Before.UnixMilli())
+	}
+
+	query := fmt.Sprintf(`
+	SELECT messages_fts.session_id, sessions.name, messages_fts.branch_id, messages_fts.message_index,
+		messages_fts.role, messages_fts.content, messages_fts.timestamp, %s, %s
+	FROM messages_fts JOIN sessions ON sessions.id = messages_fts.session_id
+	`, preview, score)
+	if len(where) > 0 {
+		query += "WHERE " + strings.Join(where, " AND ") + "\n"
+	}
+	query += fmt.Sprintf("ORDER BY %s LIMIT %d", order, maxSearchResults)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []SessionMessageMatch
+	for rows.Next() {
+		var m SessionMessageMatch
+		var timestamp int64
+		var rank float64
+		if err := rows.Scan(&m.SessionID, &m.SessionName, &m.BranchID, &m.MessageIndex,
+			&m.Role, &m.Content, &timestamp, &m.Preview, &rank); err != nil {
+			return nil, fmt.Errorf("failed to search sessions: %w", err)
+		}
+		m.Timestamp = time.UnixMilli(timestamp)
+		if m.Preview == "" {
+			m.Preview = previewContent(m.Content)
+		}
+		// bm25 ranks are negative, lower is better
+		m.Score = int(-rank * 1000)
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// escapeLike escapes the LIKE wildcards in s (with \ as the escape character)
+func escapeLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	return strings.ReplaceAll(s, "_", `\_`)
+}
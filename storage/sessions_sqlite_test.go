@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteSessionStorage(t *testing.T) {
+	dataDir := t.TempDir()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Sessions saved as JSON files before switching stores
+	jsonStorage, err := NewSessionStorage(dataDir)
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+	old := &Session{Name: "from json", Provider: "ollama", Model: "llama3.1:latest", Messages: conversation(start, "q1", "a1")}
+	if err := jsonStorage.Save(old); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	updatedAt := old.UpdatedAt
+
+	s, err := OpenSessionStorage(dataDir, "sqlite")
+	if err != nil {
+		t.Fatalf("OpenSessionStorage: %v", err)
+	}
+	defer s.Close()
+
+	t.Run("imports JSON sessions", func(t *testing.T) {
+		loaded, err := s.Load(old.ID)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if loaded.Name != "from json" || !sameContents(loaded.Messages, "q1", "a1") {
+			t.Errorf("imported session = %+v", loaded)
+		}
+		if _, err := os.Stat(filepath.Join(dataDir, "sessions", old.ID+".json")); err != nil {
+			t.Errorf("the JSON file should be left in place: %v", err)
+		}
+
+		sessions, err := s.List()
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(sessions) != 1 || sessions[0].MessageCount != 2 || !sessions[0].UpdatedAt.Equal(updatedAt.Truncate(time.Millisecond)) {
+			t.Errorf("List = %+v, want the imported session unchanged", sessions)
+		}
+	})
+
+	t.Run("save, list and delete", func(t *testing.T) {
+		session := &Session{
+			Name:           "new",
+			Provider:       "openrouter",
+			Model:          "qwen/qwen3-coder:free",
+			EnabledPlugins: []string{"filesystem"},
+			ParentID:       old.ID,
+			Messages:       conversation(start, "hello"),
+		}
+		if err := s.Save(session); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dataDir, "sessions", session.ID+".json")); !os.IsNotExist(err) {
+			t.Error("a session saved to SQLite shouldn't get a JSON file")
+		}
+
+		sessions, err := s.List()
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(sessions) != 2 || sessions[0].ID != session.ID {
+			t.Fatalf("List = %+v, want the new session first", sessions)
+		}
+		if sessions[0].ParentID != old.ID || len(sessions[0].EnabledPlugins) != 1 {
+			t.Errorf("metadata = %+v", sessions[0])
+		}
+
+		if err := s.Delete(session.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := s.Load(session.ID); err == nil {
+			t.Error("Load found a deleted session")
+		}
+		if err := s.Delete(session.ID); err == nil {
+			t.Error("deleting a missing session should fail")
+		}
+		matches, err := NewSearchIndex(s).SearchAllSessions("hello")
+		if err != nil || len(matches) != 0 {
+			t.Errorf("search found %d messages of a deleted session (%v)", len(matches), err)
+		}
+	})
+
+	t.Run("imports only once", func(t *testing.T) {
+		if err := s.Delete(old.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		s.Close()
+
+		reopened, err := NewSQLiteSessionStorage(dataDir)
+		if err != nil {
+			t.Fatalf("NewSQLiteSessionStorage: %v", err)
+		}
+		defer reopened.Close()
+		if sessions, _ := reopened.List(); len(sessions) != 0 {
+			t.Errorf("reopening imported the JSON files again: %+v", sessions)
+		}
+	})
+}
+
+func TestSearchAllSessionsSQLite(t *testing.T) {
+	s, err := NewSQLiteSessionStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteSessionStorage: %v", err)
+	}
+	defer s.Close()
+
+	jan := time.Date(2026, 1, 10, 12, 0, 0, 0, time.Local)
+	mar := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	passing := &Session{Name: "passing", Provider: "ollama", Model: "llama3.1:latest",
+		Messages: conversation(jan, "what is docker", "docker docker docker, it is all docker")}
+	detailed := &Session{Name: "detailed", Provider: "openrouter", Model: "qwen/qwen3-coder:free",
+		Messages: conversation(mar, "explain containers", "a container image is built in a multi stage build, docker or podman can run it and many other tools exist for the job")}
+	_ = detailed.Branch(1)
+	detailed.Messages = append(detailed.Messages, Message{Role: "assistant", Content: "podman runs containers", Timestamp: mar.Add(time.Hour)})
+	for _, session := range []*Session{passing, detailed} {
+		if err := s.Save(session); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	search := func(query string) []SessionMessageMatch {
+		t.Helper()
+		matches, err := NewSearchIndex(s).SearchAllSessions(query)
+		if err != nil {
+			t.Fatalf("SearchAllSessions(%q): %v", query, err)
+		}
+		return matches
+	}
+
+	t.Run("ranked", func(t *testing.T) {
+		matches := search("docker")
+		if len(matches) != 3 {
+			t.Fatalf("found %d matches, want 3", len(matches))
+		}
+		if matches[0].Content != "docker docker docker, it is all docker" {
+			t.Errorf("best match = %q", matches[0].Content)
+		}
+		for i := 1; i < len(matches); i++ {
+			if matches[i].Score > matches[i-1].Score {
+				t.Errorf("match %d scores %d, more than the one before (%d)", i, matches[i].Score, matches[i-1].Score)
+			}
+		}
+	})
+
+	t.Run("phrase and prefix", func(t *testing.T) {
+		if got := search(`"multi stage build"`); len(got) != 1 || got[0].SessionID != detailed.ID {
+			t.Errorf("phrase found %+v", got)
+		}
+		if got := search(`"build stage multi"`); len(got) != 0 {
+			t.Errorf("phrase out of order found %d matches", len(got))
+		}
+		if got := search("contain*"); len(got) != 3 {
+			t.Errorf("prefix found %d matches, want 3", len(got))
+		}
+		if got := search(`c++ "it's"`); len(got) != 0 {
+			t.Errorf("punctuation found %d matches", len(got))
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		if got := search("docker provider:ollama"); len(got) != 2 {
+			t.Errorf("provider filter found %d matches, want 2", len(got))
+		}
+		if got := search("docker model:qwen"); len(got) != 1 || got[0].SessionName != "detailed" {
+			t.Errorf("model filter found %+v", got)
+		}
+		if got := search("docker after:2026-02-01"); len(got) != 1 {
+			t.Errorf("after filter found %d matches, want 1", len(got))
+		}
+		if got := search("before:2026-02-01"); len(got) != 2 || got[0].Timestamp.Before(got[1].Timestamp) {
+			t.Errorf("filter alone found %+v, want 2 matches newest first", got)
+		}
+	})
+
+	t.Run("other versions", func(t *testing.T) {
+		got := search("multi stage")
+		if len(got) != 1 || got[0].BranchID == "" || got[0].MessageIndex != 1 {
+			t.Fatalf("match in another version = %+v", got)
+		}
+		loaded, err := s.Load(detailed.ID)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if !loaded.SwitchToBranch(got[0].BranchID) || loaded.Messages[1].Content != got[0].Content {
+			t.Errorf("switching to the match shows %v", contents(loaded.Messages))
+		}
+	})
+}
@@ -1,8 +1,13 @@
 package storage
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"otui/config"
 )
 
 func TestSanitizeFilename(t *testing.T) {
@@ -167,3 +172,189 @@ func TestSessionPlugins(t *testing.T) {
 		}
 	})
 }
+
+func TestSessionToolMessagesRoundTrip(t *testing.T) {
+	s, err := NewSessionStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+
+	session := &Session{
+		Name: "tools",
+		Messages: []Message{
+			{Role: "user", Content: "list files"},
+			{
+				Role:       "tool",
+				Content:    `[{"type":"text","text":"a.txt"}]`,
+				ToolCall:   &ToolCallRecord{ID: "call_1", Name: "fs.list_dir", Arguments: map[string]any{"path": "/tmp"}},
+				DurationMs: 42,
+			},
+			{
+				Role:      "tool",
+				Content:   "Error executing fs.read_file: denied",
+				ToolCall:  &ToolCallRecord{Name: "fs.read_file"},
+				ToolError: "denied",
+			},
+		},
+	}
+	if err := s.Save(session); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := s.Load(session.ID)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(loaded.Messages))
+	}
+
+	listed := loaded.Messages[1]
+	if listed.ToolCall == nil || listed.ToolCall.ID != "call_1" || listed.ToolCall.Name != "fs.list_dir" {
+		t.Errorf("tool call not preserved: %+v", listed.ToolCall)
+	}
+	if listed.ToolCall != nil && listed.ToolCall.Arguments["path"] != "/tmp" {
+		t.Errorf("arguments not preserved: %v", listed.ToolCall.Arguments)
+	}
+	if listed.DurationMs != 42 {
+		t.Errorf("DurationMs = %d, want 42", listed.DurationMs)
+	}
+	if loaded.Messages[2].ToolError != "denied" {
+		t.Errorf("ToolError = %q, want %q", loaded.Messages[2].ToolError, "denied")
+	}
+	if loaded.Messages[0].ToolCall != nil {
+		t.Error("user message should not carry a tool call")
+	}
+}
+
+func TestExportIncludesGenerationParams(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSessionStorage(dir)
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+
+	temperature := 0.2
+	session := &Session{Name: "tuned", Generation: config.GenerationParams{Temperature: &temperature, NumCtx: 32768}}
+	if err := s.Save(session); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	exportPath := filepath.Join(dir, "export.json")
+	if err := s.ExportToJSON(session.ID, exportPath); err != nil {
+		t.Fatalf("ExportToJSON: %v", err)
+	}
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+
+	var exported Session
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("parse export: %v", err)
+	}
+	got := exported.Generation
+	if got.Temperature == nil || *got.Temperature != 0.2 || got.NumCtx != 32768 || got.TopP != nil || got.Seed != nil {
+		t.Errorf("exported generation = %s, want temperature=0.2 num_ctx=32768", got)
+	}
+
+	// Sessions without settings don't write an empty object
+	plain := &Session{Name: "plain"}
+	if err := s.Save(plain); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	raw, _ := json.Marshal(plain)
+	if strings.Contains(string(raw), `"generation"`) {
+		t.Errorf("unset generation params were written: %s", raw)
+	}
+}
+
+func TestForkSession(t *testing.T) {
+	s, err := NewSessionStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+
+	parent := &Session{
+		Name:             "original",
+		Provider:         "openrouter",
+		Model:            "qwen/qwen3-coder:free",
+		SystemPrompt:     "Be brief",
+		EnabledPlugins:   []string{"filesystem"},
+		AllowedTools:     []string{"filesystem.read_file"},
+		CompactionMarker: 2,
+		CompactedSummary: "Compacted 2 messages",
+		LLMSummary:       "summary",
+		Messages: []Message{
+			{Role: "user", Content: "q1"},
+			{Role: "assistant", Content: "a1"},
+			{Role: "user", Content: "q2"},
+			{Role: "tool", Content: "result"},
+			{Role: "assistant", Content: "a2"},
+		},
+	}
+	if err := s.Save(parent); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	t.Run("copies up to the message", func(t *testing.T) {
+		fork, err := s.Fork(parent.ID, 2)
+		if err != nil {
+			t.Fatalf("Fork: %v", err)
+		}
+		loaded, err := s.Load(fork.ID)
+		if err != nil {
+			t.Fatalf("Load fork: %v", err)
+		}
+		if loaded.ID == parent.ID || loaded.ParentID != parent.ID || loaded.ForkedAt != 2 {
+			t.Errorf("fork ID=%s parent=%s forkedAt=%d", loaded.ID, loaded.ParentID, loaded.ForkedAt)
+		}
+		if len(loaded.Messages) != 3 || loaded.Messages[2].Content != "q2" {
+			t.Errorf("fork messages = %+v, want q1, a1, q2", loaded.Messages)
+		}
+		if loaded.Provider != "openrouter" || loaded.Model != parent.Model || loaded.SystemPrompt != "Be brief" ||
+			!loaded.IsPluginEnabled("filesystem") || len(loaded.AllowedTools) != 1 {
+			t.Errorf("settings not copied: %+v", loaded)
+		}
+		if loaded.CompactionMarker != 2 || loaded.LLMSummary != "summary" {
+			t.Errorf("compaction = %d %q, want the parent's", loaded.CompactionMarker, loaded.LLMSummary)
+		}
+
+		original, err := s.Load(parent.ID)
+		if err != nil {
+			t.Fatalf("Load parent: %v", err)
+		}
+		if len(original.Messages) != 5 || original.ParentID != "" {
+			t.Errorf("parent changed: %d messages, parent %q", len(original.Messages), original.ParentID)
+		}
+
+		sessions, err := s.List()
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		for _, meta := range sessions {
+			if meta.ID == fork.ID && meta.ParentID != parent.ID {
+				t.Errorf("listed fork has parent %q", meta.ParentID)
+			}
+		}
+	})
+
+	t.Run("compaction past the fork point is dropped", func(t *testing.T) {
+		fork, err := s.Fork(parent.ID, 0)
+		if err != nil {
+			t.Fatalf("Fork: %v", err)
+		}
+		if fork.CompactionMarker != 0 || fork.LLMSummary != "" || fork.CompactedSummary != "" {
+			t.Errorf("compaction = %d %q, want none", fork.CompactionMarker, fork.LLMSummary)
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		if _, err := s.Fork(parent.ID, 5); err == nil {
+			t.Error("expected an error for a message past the end")
+		}
+		if _, err := s.Fork("missing", 0); err == nil {
+			t.Error("expected an error for an unknown session")
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// UsageRecord is the token usage of one provider request
+type UsageRecord struct {
+	Timestamp    time.Time `json:"timestamp"`
+	SessionID    string    `json:"session_id"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	Cost         float64   `json:"cost"`             // USD, priced when the request was made
+	Priced       bool      `json:"priced,omitempty"` // False if no price was known for the model
+}
+
+// UsageTotals sums usage records
+type UsageTotals struct {
+	Requests     int
+	InputTokens  int
+	OutputTokens int
+	Cost         float64 // USD, over priced requests only
+	Unpriced     int     // Requests recorded without a price
+}
+
+// Add counts a record in the totals
+func (t *UsageTotals) Add(rec UsageRecord) {
+	t.Requests++
+	t.InputTokens += rec.InputTokens
+	t.OutputTokens += rec.OutputTokens
+	t.Cost += rec.Cost
+	if !rec.Priced {
+		t.Unpriced++
+	}
+}
+
+// UsageSummary is the usage of one provider and model
+type UsageSummary struct {
+	Provider string
+	Model    string
+	UsageTotals
+}
+
+// UsageLedger is an append-only log of usage records (usage.jsonl in the
+// data directory, next to the sessions)
+type UsageLedger struct {
+	path string
+}
+
+// usageMu serializes appends; requests from parallel steps finish concurrently
+var usageMu sync.Mutex
+
+// Usage returns the usage ledger stored alongside the sessions
+func (s *SessionStorage) Usage() *UsageLedger {
+	return &UsageLedger{path: filepath.Join(filepath.Dir(s.sessionsDir), "usage.jsonl")}
+}
+
+// Record appends a usage record to the ledger
+func (l *UsageLedger) Record(rec UsageRecord) error {
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage record: %w", err)
+	}
+
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	// 0600 - the ledger reveals which models and sessions were used
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage record: %w", err)
+	}
+	return nil
+}
+
+// Records returns the records for a session in the order they were made,
+// or every record when sessionID is empty
+func (l *UsageLedger) Records(sessionID string) ([]UsageRecord, error) {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer f.Close()
+
+	var records []UsageRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec UsageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue // Skip a line cut short by a crash
+		}
+		if sessionID != "" && rec.SessionID != sessionID {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+	return records, nil
+}
+
+// SessionTotals sums the usage of a session (every session when sessionID is empty)
+func (l *UsageLedger) SessionTotals(sessionID string) (UsageTotals, error) {
+	records, err := l.Records(sessionID)
+	if err != nil {
+		return UsageTotals{}, err
+	}
+
+	var totals UsageTotals
+	for _, rec := range records {
+		totals.Add(rec)
+	}
+	return totals, nil
+}
+
+// Summarize groups a session's usage by provider and model, most expensive
+// first (every session when sessionID is empty)
+func (l *UsageLedger) Summarize(sessionID string) ([]UsageSummary, error) {
+	records, err := l.Records(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	byModel := make(map[[2]string]*UsageSummary)
+	var summaries []*UsageSummary
+	for _, rec := range records {
+		key := [2]string{rec.Provider, rec.Model}
+		summary, ok := byModel[key]
+		if !ok {
+			summary = &UsageSummary{Provider: rec.Provider, Model: rec.Model}
+			byModel[key] = summary
+			summaries = append(summaries, summary)
+		}
+		summary.Add(rec)
+	}
+
+	result := make([]UsageSummary, len(summaries))
+	for i, summary := range summaries {
+		result[i] = *summary
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		return result[i].InputTokens+result[i].OutputTokens > result[j].InputTokens+result[j].OutputTokens
+	})
+	return result, nil
+}
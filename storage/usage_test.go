@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUsageLedger(t *testing.T) {
+	dataDir := t.TempDir()
+	s, err := NewSessionStorage(dataDir)
+	if err != nil {
+		t.Fatalf("NewSessionStorage: %v", err)
+	}
+	ledger := s.Usage()
+
+	t.Run("empty", func(t *testing.T) {
+		totals, err := ledger.SessionTotals("")
+		if err != nil || totals.Requests != 0 {
+			t.Errorf("SessionTotals = %+v, %v; want zero totals", totals, err)
+		}
+	})
+
+	records := []UsageRecord{
+		{SessionID: "a", Provider: "openrouter", Model: "qwen", InputTokens: 1000, OutputTokens: 100, Cost: 0.002, Priced: true},
+		{SessionID: "a", Provider: "openrouter", Model: "qwen", InputTokens: 1200, OutputTokens: 50, Cost: 0.003, Priced: true},
+		{SessionID: "a", Provider: "ollama", Model: "llama3", InputTokens: 500, OutputTokens: 20},
+		{SessionID: "b", Provider: "openai", Model: "gpt-4o", InputTokens: 300, OutputTokens: 30, Cost: 0.01, Priced: true},
+	}
+	for _, rec := range records {
+		if err := ledger.Record(rec); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	t.Run("session_totals", func(t *testing.T) {
+		totals, err := ledger.SessionTotals("a")
+		if err != nil {
+			t.Fatalf("SessionTotals: %v", err)
+		}
+		if totals.Requests != 3 || totals.InputTokens != 2700 || totals.OutputTokens != 170 || totals.Unpriced != 1 {
+			t.Errorf("unexpected totals: %+v", totals)
+		}
+		if totals.Cost < 0.00499 || totals.Cost > 0.00501 {
+			t.Errorf("Cost = %f, want 0.005", totals.Cost)
+		}
+	})
+
+	t.Run("summarize", func(t *testing.T) {
+		summaries, err := ledger.Summarize("")
+		if err != nil {
+			t.Fatalf("Summarize: %v", err)
+		}
+		if len(summaries) != 3 {
+			t.Fatalf("got %d summaries, want 3", len(summaries))
+		}
+		if summaries[0].Provider != "openai" || summaries[1].Model != "qwen" || summaries[1].Requests != 2 {
+			t.Errorf("unexpected order or grouping: %+v", summaries)
+		}
+	})
+
+	t.Run("skips_truncated_line", func(t *testing.T) {
+		f, err := os.OpenFile(filepath.Join(dataDir, "usage.jsonl"), os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(`{"session_id":"a","input_tok`)
+		f.Close()
+
+		got, err := ledger.Records("a")
+		if err != nil || len(got) != 3 {
+			t.Errorf("Records = %d records, %v; want 3", len(got), err)
+		}
+	})
+}
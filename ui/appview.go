@@ -35,12 +35,9 @@ type AppView struct {
 	ready  bool
 
 	// Streaming UI state
-	currentResp *strings.Builder // Pointer to avoid copy panic
-	showHelp    bool
-
-	// Typewriter effect fields
-	chunks     []string // Chunks to display with typewriter effect
-	chunkIndex int      // Current chunk being displayed
+	currentResp      *strings.Builder // Pointer to avoid copy panic
+	currentReasoning *strings.Builder // Thinking streamed with the current response
+	showHelp         bool
 
 	// Scroll-during-streaming state
 	userScrolledUp bool // User scrolled up during streaming; suppress auto-scroll
@@ -73,8 +70,11 @@ type AppView struct {
 	filteredSessionList  []storage.SessionMetadata
 	sessionExportMode    bool
 	sessionExportInput   textinput.Model
+	sessionExportOptions storage.ExportOptions // Format and contents, kept between exports
 	sessionExporting     bool
-	sessionExportSuccess string // Contains export path if successful, empty otherwise
+	sessionExportSuccess string            // Contains export path if successful, empty otherwise
+	sessionUsage         *sessionUsageView // Usage breakdown, nil when not shown
+	sessionFork          *sessionForkView  // Fork point picker, nil when not shown
 
 	// Import session state
 	sessionImportPicker     FilePickerState
@@ -93,6 +93,16 @@ type AppView struct {
 	// About modal
 	showAbout bool
 
+	// Files attached to the next message
+	attachPicker       FilePickerState
+	pendingAttachments []Attachment
+
+	// Tool call blocks in chat (collapsed by default)
+	expandToolBlocks bool
+
+	// Model reasoning in chat (folded by default)
+	expandReasoning bool
+
 	// Settings modal
 	showSettings            bool
 	settingsFields          []SettingField
@@ -157,6 +167,10 @@ type AppView struct {
 	editSessionID             string
 	editSessionPluginIdx      int      // Selected plugin in the list
 	editSessionEnabledPlugins []string // Temporary storage for enabled plugins during edit
+	editSessionParamsInput    textinput.Model
+	editSessionParamsError    string // Why the generation parameters couldn't be saved
+	editSessionFallbackInput  textinput.Model
+	editSessionFallbackError  string // Why the fallback models couldn't be saved
 
 	showMessageSearch      bool
 	messageSearchInput     textinput.Model
@@ -169,10 +183,21 @@ type AppView struct {
 	globalSearchResults   []storage.SessionMessageMatch
 	selectedGlobalIdx     int
 	globalSearchScrollIdx int
+	globalSearchSemantic  bool   // Search by meaning (Tab) instead of keywords
+	semanticSearchQuery   string // Search the semantic results are for
+	semanticSearching     bool
+	semanticIndexing      bool // Embedding sessions saved before semantic search was on
+	semanticSearchErr     string
 
 	highlightedMessageIdx     int
 	highlightFlashCount       int
 	pendingScrollToMessageIdx int
+	pendingBranchID           string // Version of the conversation to show once the session loads
+
+	// Message actions modal (edit & resend, regenerate, switch versions)
+	showMessageActions bool
+	messageActionsIdx  int // Selected entry
+	editingMessageIdx  int // User message being edited in the input, -1 if none
 
 	showPluginManager  bool
 	pluginManagerState PluginManagerState
@@ -199,9 +224,13 @@ type AppView struct {
 	pendingCompaction       *appmodel.CompactionRequestMsg
 	temporarilyAllowedTools []string // Tools approved once - removed after execution
 
+	// Requests from running plugins, answered one at a time
+	pendingSampling *mcp.SamplingRequest  // Completion awaiting approval
+	elicitationForm *ElicitationFormState // Form a plugin asked the user to fill in
+
 	// Plugin operation modal (enable/disable feedback for individual plugins in Plugin Manager)
 	showPluginOperationModal bool
-	pluginOperationPhase     string // "enabling", "disabling", "complete", "error"
+	pluginOperationPhase     string // "enabling", "disabling", "upgrading", "complete", "error"
 	pluginOperationName      string // Plugin display name
 	pluginOperationError     string // Error message if failed
 	pluginOperationSpinner   spinner.Model
@@ -209,10 +238,10 @@ type AppView struct {
 	// Multi-step iteration UI state (Phase 2)
 	iterationCount     int  // Current step number
 	maxIterations      int  // Max from config
-	pendingNextStep    bool // Continue after typewriter?
+	pendingNextStep    bool // Continue after the streamed response?
 	pendingToolCalls   []ToolCall
 	pendingToolContext []Message
-	pendingSummary     *IterationSummaryMsg // Summary to add after typewriter completes
+	pendingSummary     *IterationSummaryMsg // Summary to add after the response completes
 
 	// Session generation counter — incremented on session switch to invalidate stale async renders
 	sessionGeneration uint64
@@ -301,6 +330,12 @@ func NewAppView(cfg *config.Config, sessionStorage *storage.SessionStorage, last
 		OperationType:  "Import",
 	})
 
+	attachPicker := NewFilePickerState(FilePickerConfig{
+		Title:         "Attach File",
+		Mode:          FilePickerModeOpen,
+		OperationType: "Attach",
+	})
+
 	sessionFilterInput := textinput.New()
 	sessionFilterInput.Prompt = "Filter: "
 	sessionFilterInput.CharLimit = 64
@@ -373,10 +408,12 @@ func NewAppView(cfg *config.Config, sessionStorage *storage.SessionStorage, last
 		textarea:                     ta,
 		viewport:                     vp,
 		currentResp:                  &strings.Builder{},
+		currentReasoning:             &strings.Builder{},
 		ready:                        false,
 		showHelp:                     false,
 		showAbout:                    false,
 		sessionImportPicker:          sessionImportPicker,
+		attachPicker:                 attachPicker,
 		sessionFilterMode:            false,
 		sessionFilterInput:           sessionFilterInput,
 		filteredSessionList:          []storage.SessionMetadata{},
@@ -392,6 +429,7 @@ func NewAppView(cfg *config.Config, sessionStorage *storage.SessionStorage, last
 		globalSearchInput:            globalSearchInput,
 		highlightedMessageIdx:        -1,
 		pendingScrollToMessageIdx:    -1,
+		editingMessageIdx:            -1,
 		passphraseForDataDir:         passphraseForDataDir,
 		showPluginManager:            false,
 		pluginManagerState: PluginManagerState{
@@ -410,11 +448,14 @@ func (a AppView) Init() tea.Cmd {
 	cmds := []tea.Cmd{
 		textarea.Blink,
 		a.dataModel.FetchAllModels(false), // Background fetch on startup, don't show selector
+		a.dataModel.LoadModelCapabilities(),
 	}
 
 	// Start all enabled plugins asynchronously
 	if a.dataModel.MCPManager != nil {
 		cmds = append(cmds, a.dataModel.StartAllPlugins())
+		cmds = append(cmds, a.dataModel.WatchResourceUpdatesCmd())
+		cmds = append(cmds, a.dataModel.WatchPluginRequestsCmd())
 	}
 
 	return tea.Batch(cmds...)
@@ -455,6 +496,14 @@ func (a AppView) View() string {
 		)
 	}
 
+	// Show plugin requests (a plugin is waiting for the answer)
+	if a.pendingSampling != nil {
+		return a.renderSamplingModal()
+	}
+	if a.elicitationForm != nil {
+		return a.renderElicitationForm()
+	}
+
 	// Show passphrase modal for data dir switch (uses shared modal helper)
 	if a.showPassphraseForDataDir {
 		return a.renderPassphraseForDataDirModal()
@@ -535,7 +584,7 @@ func (a AppView) View() string {
 			}
 		}
 
-		return renderSessionModal(a, "New session", a.newSessionNameInput, a.newSessionPromptInput, a.newSessionFocusedField, a.width, a.height, availablePlugins, a.newSessionEnabledPlugins, unavailablePluginIDs, a.newSessionPluginIdx)
+		return renderSessionModal(a, "New session", a.newSessionNameInput, a.newSessionPromptInput, a.newSessionFocusedField, a.width, a.height, availablePlugins, a.newSessionEnabledPlugins, unavailablePluginIDs, a.newSessionPluginIdx, textinput.Model{}, "", textinput.Model{}, "")
 	}
 
 	// Show edit session modal (must be before session manager)
@@ -569,7 +618,7 @@ func (a AppView) View() string {
 			}
 		}
 
-		return renderSessionModal(a, "Edit session", a.newSessionNameInput, a.newSessionPromptInput, a.newSessionFocusedField, a.width, a.height, availablePlugins, a.editSessionEnabledPlugins, unavailablePluginIDs, a.editSessionPluginIdx)
+		return renderSessionModal(a, "Edit session", a.newSessionNameInput, a.newSessionPromptInput, a.newSessionFocusedField, a.width, a.height, availablePlugins, a.editSessionEnabledPlugins, unavailablePluginIDs, a.editSessionPluginIdx, a.editSessionParamsInput, a.editSessionParamsError, a.editSessionFallbackInput, a.editSessionFallbackError)
 	}
 
 	// Show session manager if toggled
@@ -578,6 +627,12 @@ func (a AppView) View() string {
 		if a.dataModel.CurrentSession != nil {
 			currentSessionID = a.dataModel.CurrentSession.ID
 		}
+		if a.sessionUsage != nil {
+			return renderSessionUsage(*a.sessionUsage, a.width, a.height)
+		}
+		if a.sessionFork != nil {
+			return renderSessionFork(*a.sessionFork, a.width, a.height)
+		}
 		return renderSessionManager(a, a.sessionList, a.selectedSessionIdx, currentSessionID, a.sessionRenameMode, a.sessionRenameInput, a.sessionExportMode, a.sessionExportInput, a.exportingSession, a.exportCleaningUp, a.exportSpinner, a.sessionExportSuccess, a.sessionImportPicker, a.sessionImportSuccess, a.confirmDeleteSession, a.sessionFilterMode, a.sessionFilterInput, a.filteredSessionList, a.width, a.height)
 	}
 
@@ -590,6 +645,10 @@ func (a AppView) View() string {
 		return renderGlobalSearch(a, a.globalSearchInput, a.globalSearchResults, a.selectedGlobalIdx, a.globalSearchScrollIdx, a.width, a.height)
 	}
 
+	if a.showMessageActions {
+		return renderMessageActions(a, a.messageActions(), a.messageActionsIdx, a.width, a.height)
+	}
+
 	if a.showMessageSearch {
 		return renderMessageSearch(a, a.messageSearchInput, a.messageSearchResults, a.selectedSearchIdx, a.messageSearchScrollIdx, a.width, a.height)
 	}
@@ -599,6 +658,10 @@ func (a AppView) View() string {
 		return renderAboutModal(a, a.width, a.height, a.dataModel.Version, a.dataModel.License)
 	}
 
+	if a.attachPicker.Active {
+		return RenderFilePickerModal(a.attachPicker, a.width, a.height)
+	}
+
 	// Show tool warning modal if triggered
 	if a.showToolWarningModal {
 		return RenderToolWarningModal(a.pendingModelSwitch, a.toolWarningPluginList, a.width, a.height)
@@ -700,6 +763,12 @@ func (a AppView) View() string {
 			percentageStyle.Render(fmt.Sprintf(" %.0f%%", percentage*100)) +
 			AssistantStyle.Bold(true).Render(" | max:") +
 			DimStyle.Render(fmt.Sprintf(" %d", metadata.ContextWindow))
+
+		// Session cost, once a request to a priced model has been recorded
+		if usage := a.dataModel.SessionUsage(); usage.Requests > usage.Unpriced {
+			contextIndicator += AssistantStyle.Bold(true).Render(" | cost:") +
+				DimStyle.Render(" "+formatUsageCost(usage))
+		}
 	}
 
 	// Combine title (left) and context (right) with spacing
@@ -741,6 +810,29 @@ func (a AppView) View() string {
 		a.formatKeyDisplay("primary", "Y"),
 		descStyle.Render("Copy"),
 	)
+	if len(a.pendingAttachments) > 0 {
+		names := make([]string, len(a.pendingAttachments))
+		for i, att := range a.pendingAttachments {
+			names[i] = att.Name
+		}
+		statusBar = fmt.Sprintf("📎 %s  %s %s  %s %s  Enter %s",
+			strings.Join(names, ", "),
+			a.formatKeyDisplay("primary", "O"),
+			descStyle.Render("Attach more"),
+			a.formatKeyDisplay("secondary", "O"),
+			descStyle.Render("Remove all"),
+			descStyle.Render("Send"),
+		)
+	}
+	if hint := a.promptCommandHint(); hint != "" && a.editingMessageIdx < 0 {
+		statusBar = hint
+	}
+	if a.editingMessageIdx >= 0 {
+		statusBar = fmt.Sprintf("✏️  Editing an earlier message  Enter %s  Esc %s",
+			descStyle.Render("Send as new version"),
+			descStyle.Render("Cancel"),
+		)
+	}
 	statusBar = StatusStyle.Render(statusBar)
 
 	// Combine all parts
@@ -790,6 +882,8 @@ func (a *AppView) setCurrentSession(session *storage.Session) {
 	}
 	// Reset system prompt tool warning flag when switching sessions
 	a.systemPromptToolWarningShown = false
+	// An edit belongs to the session it was started in
+	a.editingMessageIdx = -1
 }
 
 func (a *AppView) closeAllModals() {
@@ -800,14 +894,18 @@ func (a *AppView) closeAllModals() {
 	a.showToolWarningModal = false
 	a.showMessageSearch = false
 	a.showGlobalSearch = false
+	a.showMessageActions = false
 	a.showSettings = false
 	a.showAbout = false
 	a.showPluginManager = false
+	a.attachPicker.Reset()
 
 	a.sessionRenameMode = false
 	a.sessionExportMode = false
 	a.sessionFilterMode = false
 	a.confirmDeleteSession = nil
+	a.sessionUsage = nil
+	a.sessionFork = nil
 	a.sessionImportPicker.Active = false
 	a.pluginManagerState.confirmations.deletePlugin = nil
 
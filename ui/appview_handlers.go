@@ -3,6 +3,7 @@ package ui
 import (
 	"context"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -11,6 +12,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"otui/config"
+	"otui/model"
+	"otui/storage"
 )
 
 func (a AppView) handleSessionRenameMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -182,10 +185,34 @@ func (a AppView) handleSessionExportMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		// Start export with context and spinner tick
 		return a, tea.Batch(
-			a.dataModel.ExportSessionCmd(ctx, sessionID, a.exportTargetPath),
+			a.dataModel.ExportSessionCmd(ctx, sessionID, a.exportTargetPath, a.sessionExportOptions),
 			a.exportSpinner.Tick,
 		)
 
+	case "tab", "shift+tab":
+		step := 1
+		if msg.String() == "shift+tab" {
+			step = len(storage.ExportFormats) - 1
+		}
+		current := slices.Index(storage.ExportFormats, a.sessionExportOptions.Format)
+		a.sessionExportOptions.Format = storage.ExportFormats[(current+step)%len(storage.ExportFormats)]
+		if path := strings.TrimSpace(a.sessionExportInput.Value()); path != "" {
+			a.sessionExportInput.SetValue(storage.ExportPathWithFormat(path, a.sessionExportOptions.Format))
+		}
+		return a, nil
+
+	case kb.PrimaryKey("t"):
+		a.sessionExportOptions.ToolCalls = !a.sessionExportOptions.ToolCalls
+		return a, nil
+
+	case kb.PrimaryKey("p"):
+		a.sessionExportOptions.SystemPrompt = !a.sessionExportOptions.SystemPrompt
+		return a, nil
+
+	case kb.PrimaryKey("c"):
+		a.sessionExportOptions.CompactionSummary = !a.sessionExportOptions.CompactionSummary
+		return a, nil
+
 	case kb.GetActionKey("clear_input"):
 		a.sessionExportInput.SetValue("")
 		return a, nil
@@ -261,3 +288,40 @@ func (a AppView) handleSessionImportMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	return a, cmd
 }
+
+// handleAttachPickerUpdate adds the chosen file to the attachments of the next message
+func (a AppView) handleAttachPickerUpdate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" {
+		a.attachPicker.Reset()
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	a.attachPicker.Picker, cmd = a.attachPicker.Picker.Update(msg)
+
+	path := a.attachPicker.Picker.Path
+	if path == "" {
+		return a, cmd
+	}
+	// A directory was entered, not chosen - clear Path so we don't trigger again
+	a.attachPicker.Picker.Path = ""
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return a, cmd
+	}
+	a.attachPicker.Reset()
+
+	attachment, err := model.LoadAttachment(path)
+	if err == nil {
+		err = a.dataModel.CheckAttachments([]Attachment{attachment})
+	}
+	if err != nil {
+		a.showAcknowledgeModal = true
+		a.acknowledgeModalTitle = "Cannot Attach File"
+		a.acknowledgeModalMsg = err.Error()
+		a.acknowledgeModalType = ModalTypeWarning
+		return a, nil
+	}
+
+	a.pendingAttachments = append(a.pendingAttachments, attachment)
+	return a, nil
+}
@@ -315,6 +315,8 @@ func (a *AppView) resetUIStateForDataDirSwitch() {
 	a.filteredSessionList = nil
 	a.sessionExportMode = false
 	a.sessionExportSuccess = ""
+	a.sessionUsage = nil
+	a.sessionFork = nil
 
 	// Reset plugin manager state
 	a.pluginManagerState.selection.selectedPluginIdx = 0
@@ -410,16 +412,6 @@ func (a *AppView) showAnalyzingResults() {
 	a.updateViewportContent(true)
 }
 
-// startTypewriter initializes typewriter state and returns tick command
-func (a *AppView) startTypewriter(chunks []string) tea.Cmd {
-	a.chunks = chunks
-	a.chunkIndex = 0
-	a.currentResp.Reset()
-	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
-		return displayChunkTickMsg{}
-	})
-}
-
 // getPluginShortName extracts plugin ID from tool name and gets short display name
 func (a *AppView) getPluginShortName(toolName string) string {
 	pluginID := toolName
@@ -486,3 +478,39 @@ func (a *AppView) refreshProvidersAndModels() tea.Cmd {
 	a.dataModel.ClearModelCache("")
 	return a.dataModel.FetchAllModels(false) // Background refresh, don't show selector
 }
+
+// resetSessionParamsInput fills the edit session modal's generation
+// parameters field with a session's own settings. The placeholder shows
+// the [generation] defaults that apply when the field is empty.
+func (a *AppView) resetSessionParamsInput(params config.GenerationParams) {
+	if a.editSessionParamsInput.Width == 0 {
+		a.editSessionParamsInput = textinput.New()
+		a.editSessionParamsInput.Width = 60
+		a.editSessionParamsInput.CharLimit = 200
+	}
+	a.editSessionParamsInput.Placeholder = "Provider defaults (e.g. temperature=0.7 num_ctx=32768)"
+	if defaults := a.dataModel.Config.Generation.String(); defaults != "" {
+		a.editSessionParamsInput.Placeholder = "Defaults: " + defaults
+	}
+	a.editSessionParamsInput.SetValue(params.String())
+	a.editSessionParamsInput.Blur()
+	a.editSessionParamsError = ""
+}
+
+// resetSessionFallbackInput fills the edit session modal's fallback models
+// field with a session's own list. The placeholder shows the [[fallback]]
+// list that applies when the field is empty.
+func (a *AppView) resetSessionFallbackInput(fallback []config.FallbackTarget) {
+	if a.editSessionFallbackInput.Width == 0 {
+		a.editSessionFallbackInput = textinput.New()
+		a.editSessionFallbackInput.Width = 60
+		a.editSessionFallbackInput.CharLimit = 500
+	}
+	a.editSessionFallbackInput.Placeholder = "None (e.g. openrouter:qwen/qwen3-coder:free, ollama:llama3.1:latest)"
+	if defaults := config.FormatFallbacks(a.dataModel.Config.Fallback); defaults != "" {
+		a.editSessionFallbackInput.Placeholder = "Defaults: " + defaults
+	}
+	a.editSessionFallbackInput.SetValue(config.FormatFallbacks(fallback))
+	a.editSessionFallbackInput.Blur()
+	a.editSessionFallbackError = ""
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -28,8 +29,12 @@ func (a *AppView) updateViewportContent(gotoBottom bool) {
 	}
 
 	var content strings.Builder
+	branchPoints := a.dataModel.BranchPoints()
 
 	for i, msg := range a.dataModel.Messages {
+		if v, ok := branchPoints[i]; ok {
+			content.WriteString(formatBranchMarker(v))
+		}
 
 		highlightPrefix := ""
 		if i == a.highlightedMessageIdx && a.highlightFlashCount%2 == 1 {
@@ -80,12 +85,21 @@ func (a *AppView) updateViewportContent(gotoBottom bool) {
 
 		// User messages with vertical bar formatting
 		if msg.Role == "user" {
-			formattedUser := formatUserMessage(highlightPrefix, timestamp, role, renderedContent)
+			formattedUser := formatUserMessage(highlightPrefix, timestamp, role, withAttachmentList(msg, renderedContent))
 			content.WriteString(formattedUser)
 			continue
 		}
 
+		// Tool calls as collapsible blocks
+		if msg.Role == "tool" {
+			content.WriteString(formatToolMessage(highlightPrefix, timestamp, msg, a.expandToolBlocks))
+			continue
+		}
+
 		// Default formatting for assistant and other system messages
+		renderedContent = withInterruptedNotice(msg, renderedContent)
+		renderedContent = a.withResponderNotice(msg, renderedContent)
+		renderedContent = a.withReasoning(msg, renderedContent)
 		content.WriteString(fmt.Sprintf("%s%s %s\n%s\n\n", highlightPrefix, timestamp, role, renderedContent))
 	}
 
@@ -101,9 +115,14 @@ func (a *AppView) updateStreamingMessage() {
 	}
 
 	var content strings.Builder
+	branchPoints := a.dataModel.BranchPoints()
 
 	// Render all previous messages
-	for _, msg := range a.dataModel.Messages {
+	for i, msg := range a.dataModel.Messages {
+		if v, ok := branchPoints[i]; ok {
+			content.WriteString(formatBranchMarker(v))
+		}
+
 		timestamp := DimStyle.Render(msg.Timestamp.Format("[15:04]"))
 
 		var roleStyle = DimStyle
@@ -123,10 +142,12 @@ func (a *AppView) updateStreamingMessage() {
 		role := roleStyle.Render(roleName)
 
 		if msg.Role == "user" {
-			formattedUser := formatUserMessage("", timestamp, role, msg.Rendered)
+			formattedUser := formatUserMessage("", timestamp, role, withAttachmentList(msg, msg.Rendered))
 			content.WriteString(formattedUser)
+		} else if msg.Role == "tool" {
+			content.WriteString(formatToolMessage("", timestamp, msg, a.expandToolBlocks))
 		} else {
-			content.WriteString(fmt.Sprintf("%s %s\n%s\n\n", timestamp, role, msg.Rendered))
+			content.WriteString(fmt.Sprintf("%s %s\n%s\n\n", timestamp, role, a.withReasoning(msg, a.withResponderNotice(msg, withInterruptedNotice(msg, msg.Rendered)))))
 		}
 	}
 
@@ -139,6 +160,14 @@ func (a *AppView) updateStreamingMessage() {
 	if a.currentResp.String() != "" {
 		streamContent = a.currentResp.String() + "▋"
 	}
+	if a.currentReasoning.Len() > 0 {
+		thinking := formatReasoning(a.currentReasoning.String(), a.expandReasoning, a.currentResp.Len() == 0, a.width)
+		if a.currentResp.Len() == 0 {
+			streamContent = thinking + " " + a.loadingSpinner.View()
+		} else {
+			streamContent = thinking + "\n" + streamContent
+		}
+	}
 
 	content.WriteString(fmt.Sprintf("%s %s\n%s\n\n", timestamp, role, streamContent))
 
@@ -148,6 +177,86 @@ func (a *AppView) updateStreamingMessage() {
 	}
 }
 
+// withInterruptedNotice marks responses the user stopped before they finished
+func withInterruptedNotice(msg Message, rendered string) string {
+	if !msg.Interrupted {
+		return rendered
+	}
+	return strings.TrimRight(rendered, "\n") + "\n\n" + DimStyle.Render("⚠️ Response cancelled")
+}
+
+// withResponderNotice names the model that answered when it isn't the
+// session's own, e.g. a fallback model or one used before switching models
+func (a *AppView) withResponderNotice(msg Message, rendered string) string {
+	by := msg.AnsweredBy
+	session := a.dataModel.CurrentSession
+	if msg.Role != "assistant" || by.Model == "" || session == nil {
+		return rendered
+	}
+	if by.Model == session.Model && (by.Provider == session.Provider || session.Provider == "") {
+		return rendered
+	}
+	return strings.TrimRight(rendered, "\n") + "\n" + DimStyle.Render(fmt.Sprintf("↪ Answered by %s (%s)", by.Model, by.Provider))
+}
+
+// withReasoning puts the model's reasoning above its answer, folded to one
+// line unless reasoning blocks are expanded
+func (a *AppView) withReasoning(msg Message, rendered string) string {
+	if msg.Reasoning == "" {
+		return rendered
+	}
+	thinking := formatReasoning(msg.Reasoning, a.expandReasoning, false, a.width)
+	if strings.TrimSpace(rendered) == "" {
+		return thinking
+	}
+	return thinking + "\n" + rendered
+}
+
+// formatReasoning renders reasoning as a summary line with the thinking
+// below it when expanded. thinking marks reasoning that is still streaming.
+func formatReasoning(reasoning string, expanded, thinking bool, width int) string {
+	marker := "▸"
+	if expanded {
+		marker = "▾"
+	}
+	label := "Thought"
+	if thinking {
+		label = "Thinking"
+	}
+	summary := DimStyle.Render(fmt.Sprintf("%s 💭 %s (%d words)", marker, label, len(strings.Fields(reasoning))))
+	if !expanded {
+		return summary
+	}
+
+	bar := DimStyle.Render("│")
+	body := DimStyle.Italic(true).Width(max(width-6, 20)).Render(strings.TrimSpace(reasoning))
+	var result strings.Builder
+	result.WriteString(summary)
+	for _, line := range strings.Split(body, "\n") {
+		result.WriteString("\n" + bar + " " + line)
+	}
+	return result.String()
+}
+
+// withAttachmentList lists the files attached to a user message below its text
+func withAttachmentList(msg Message, rendered string) string {
+	if len(msg.Attachments) == 0 {
+		return rendered
+	}
+	lines := make([]string, 0, len(msg.Attachments))
+	for _, att := range msg.Attachments {
+		label := "📎 " + att.Name
+		if size := formatSize(int64(len(att.Data))); size != "" {
+			label += " (" + size + ")"
+		}
+		lines = append(lines, DimStyle.Render(label))
+	}
+	if strings.TrimSpace(rendered) == "" {
+		return strings.Join(lines, "\n")
+	}
+	return strings.TrimRight(rendered, "\n") + "\n" + strings.Join(lines, "\n")
+}
+
 func formatUserMessage(highlightPrefix, timestamp, role, content string) string {
 	greenBold := "\x1b[32;1m"
 	reset := "\x1b[0m"
@@ -460,6 +569,68 @@ func formatPermissionMessage(timestamp, content string) string {
 	return result.String()
 }
 
+// maxToolResultLines caps how much of a tool result an expanded block shows
+const maxToolResultLines = 20
+
+// formatToolMessage renders a tool call and its result as a block with a dim
+// vertical bar. Collapsed blocks show a single header line; expanded blocks
+// add the arguments and (truncated) result.
+func formatToolMessage(highlightPrefix, timestamp string, msg Message, expanded bool) string {
+	dim := "\x1b[90m"
+	red := "\x1b[31m"
+	reset := "\x1b[0m"
+	bar := dim + "│" + reset
+
+	toolName := "tool"
+	var args map[string]any
+	if msg.ToolCall != nil {
+		toolName = msg.ToolCall.Name
+		args = msg.ToolCall.Arguments
+	}
+
+	marker := "▸"
+	if expanded {
+		marker = "▾"
+	}
+
+	status := AssistantStyle.Render("✓")
+	if msg.ToolError != "" {
+		status = red + "✗" + reset
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("%s%s %s %s 🔧 %s %s %s\n",
+		highlightPrefix, bar, timestamp, marker, toolName, status, DimStyle.Render("("+formatDuration(msg.Duration)+")")))
+
+	if !expanded {
+		result.WriteString("\n")
+		return result.String()
+	}
+
+	if len(args) > 0 {
+		if argsJSON, err := json.Marshal(args); err == nil {
+			result.WriteString(fmt.Sprintf("%s %s %s\n", bar, DimStyle.Render("Arguments:"), string(argsJSON)))
+		}
+	}
+
+	if msg.ToolError != "" {
+		result.WriteString(fmt.Sprintf("%s %s%s%s\n", bar, red, msg.ToolError, reset))
+	}
+
+	result.WriteString(fmt.Sprintf("%s %s\n", bar, DimStyle.Render("Result:")))
+	lines := strings.Split(strings.TrimSpace(msg.Content), "\n")
+	for i, line := range lines {
+		if i == maxToolResultLines {
+			result.WriteString(fmt.Sprintf("%s %s\n", bar, DimStyle.Render(fmt.Sprintf("… %d more lines", len(lines)-maxToolResultLines))))
+			break
+		}
+		result.WriteString(fmt.Sprintf("%s %s\n", bar, line))
+	}
+
+	result.WriteString("\n")
+	return result.String()
+}
+
 // buildIterationSummary formats multi-step summary (Phase 2)
 func buildIterationSummary(summary IterationSummaryMsg) string {
 	var b strings.Builder
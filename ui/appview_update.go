@@ -65,7 +65,7 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	// Update plugin operation spinner if modal is active
-	if a.showPluginOperationModal && (a.pluginOperationPhase == "enabling" || a.pluginOperationPhase == "disabling") {
+	if a.showPluginOperationModal && (a.pluginOperationPhase == "enabling" || a.pluginOperationPhase == "disabling" || a.pluginOperationPhase == "upgrading") {
 		a.pluginOperationSpinner, cmd = a.pluginOperationSpinner.Update(msg)
 		cmds = append(cmds, cmd)
 	}
@@ -106,6 +106,14 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Same for the attachment picker (KeyMsg is handled in handleAttachPickerUpdate)
+	if a.attachPicker.Active {
+		if _, isKey := msg.(tea.KeyMsg); !isKey {
+			a.attachPicker.Picker, cmd = a.attachPicker.Picker.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		a.width = msg.Width
@@ -188,6 +196,14 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, tea.Quit
 		}
 
+		// PRIORITY 0.4: Plugin requests (block all other keys while shown)
+		if a.pendingSampling != nil {
+			return a.handleSamplingKey(msg)
+		}
+		if a.elicitationForm != nil {
+			return a.handleElicitationKey(msg)
+		}
+
 		// PRIORITY 0.5: Permission system key handling (blocks all other keys when active)
 		if a.waitingForPermission && a.pendingPermission != nil {
 			switch msg.String() {
@@ -287,7 +303,7 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.dataModel.SessionDirty = false
 			a.textarea.Reset()
 			a.updateViewportContent(true)
-			return a, nil
+			return a, a.dataModel.LoadModelCapabilities()
 
 		case kb.GetActionKey("session_manager"):
 			wasOpen := a.showSessionManager
@@ -326,6 +342,8 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				copy(a.editSessionEnabledPlugins, a.dataModel.CurrentSession.EnabledPlugins)
 				a.newSessionNameInput.SetValue(a.dataModel.CurrentSession.Name)
 				a.newSessionPromptInput.SetValue(a.dataModel.CurrentSession.SystemPrompt)
+				a.resetSessionParamsInput(a.dataModel.CurrentSession.Generation)
+				a.resetSessionFallbackInput(a.dataModel.CurrentSession.Fallback)
 				a.newSessionNameInput.Focus()
 				a.newSessionPromptInput.Blur()
 				return a, textinput.Blink
@@ -368,6 +386,7 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.globalSearchInput.SetValue("")
 				a.globalSearchResults = []storage.SessionMessageMatch{}
 				a.selectedGlobalIdx = 0
+				a.setGlobalSearchSemantic(false)
 				return a, textinput.Blink
 			}
 			return a, nil
@@ -483,6 +502,7 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.showPluginManager = !wasOpen
 			if a.showPluginManager {
 				a.initPluginManager()
+				return a, a.dataModel.CheckPluginUpdates()
 			}
 			return a, nil
 
@@ -704,58 +724,51 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a.handleMessageSearchUpdate(msg)
 		}
 
+		if a.showMessageActions {
+			return a.handleMessageActionsUpdate(msg)
+		}
+
 		if a.showAbout {
 			return a.handleAboutUpdate(msg)
 		}
 
+		if a.attachPicker.Active {
+			return a.handleAttachPickerUpdate(msg)
+		}
+
 		// PRIORITY 3: Tab handling (chat input)
 		if msg.String() == "tab" && !a.dataModel.Streaming {
-			a.textarea.InsertString("   ")
+			if !a.completePromptCommand() {
+				a.textarea.InsertString("   ")
+			}
 			return a, nil
 		}
 
 		// PRIORITY 4: Streaming cancellation (only if no modal open)
-		if msg.String() == "esc" && a.dataModel.Streaming {
-			a.dataModel.Streaming = false
-			a.userScrolledUp = false
-
-			partialResp := a.currentResp.String()
-
-			// Remove loading message (but not persistent step messages)
-			if len(a.dataModel.Messages) > 0 &&
-				a.dataModel.Messages[len(a.dataModel.Messages)-1].Role == "system" &&
-				!a.dataModel.Messages[len(a.dataModel.Messages)-1].Persistent {
-				a.dataModel.Messages = a.dataModel.Messages[:len(a.dataModel.Messages)-1]
-			}
-
-			if partialResp != "" {
-				a.dataModel.Messages = append(a.dataModel.Messages, Message{
-					Role:      "assistant",
-					Content:   partialResp + "\n\n⚠️ Response cancelled",
-					Rendered:  partialResp + "\n\n⚠️ Response cancelled",
-					Timestamp: time.Now(),
-				})
-			} else {
-				a.dataModel.Messages = append(a.dataModel.Messages, Message{
-					Role:      "system",
-					Content:   "⚠️ Request cancelled",
-					Rendered:  "⚠️ Request cancelled",
-					Timestamp: time.Now(),
-				})
-			}
-
-			a.chunks = nil
-			a.chunkIndex = 0
-			a.currentResp.Reset()
-
-			a.updateViewportContent(true)
-			return a, nil
+		if (msg.String() == "esc" || msg.String() == kb.GetActionKey("stop_generation")) && a.dataModel.Streaming {
+			return a.stopGeneration()
 		}
 
 		// Handle Enter for sending messages - DON'T let textarea process it
 		// But allow Alt+Enter to pass through for newlines
 		if msg.Type == tea.KeyEnter && !msg.Alt && !a.dataModel.Streaming {
-			if a.textarea.Value() != "" {
+			// "/plugin:prompt ..." runs a plugin's prompt instead of being sent
+			if a.editingMessageIdx < 0 {
+				if view, cmd, ok := a.runPromptCommand(); ok {
+					return view, cmd
+				}
+			}
+
+			if a.textarea.Value() != "" || len(a.pendingAttachments) > 0 {
+				// The model may have changed since the files were attached
+				if err := a.dataModel.CheckAttachments(a.pendingAttachments); err != nil {
+					a.showAcknowledgeModal = true
+					a.acknowledgeModalTitle = "Cannot Send Images"
+					a.acknowledgeModalMsg = err.Error()
+					a.acknowledgeModalType = ModalTypeWarning
+					return a, nil
+				}
+
 				// Check if we need to show system prompt + tools warning
 				// Conditions: system prompt is set, tools are available, warning hasn't been shown yet
 				if a.dataModel.CurrentSession != nil &&
@@ -800,13 +813,20 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return a, nil
 				}
 
+				// An edited prompt replaces the original as a new version of the conversation
+				if a.editingMessageIdx >= 0 {
+					a.branchEditedMessage()
+				}
+
 				// Add user message
 				a.dataModel.Messages = append(a.dataModel.Messages, Message{
-					Role:      "user",
-					Content:   userMsg,
-					Rendered:  userMsg, // Start with plain text, will be rendered async
-					Timestamp: time.Now(),
+					Role:        "user",
+					Content:     userMsg,
+					Rendered:    userMsg, // Start with plain text, will be rendered async
+					Timestamp:   time.Now(),
+					Attachments: a.pendingAttachments,
 				})
+				a.pendingAttachments = nil
 
 				// Trigger markdown rendering for user message
 				userMessageIndex := len(a.dataModel.Messages) - 1
@@ -882,6 +902,31 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.showAbout = !a.showAbout
 			return a, nil
 
+		case kb.GetActionKey("attach_file"):
+			if !a.dataModel.Streaming {
+				a.attachPicker.Activate()
+				return a, a.attachPicker.Picker.Init()
+			}
+			return a, nil
+
+		case kb.GetActionKey("clear_attachments"):
+			a.pendingAttachments = nil
+			return a, nil
+
+		case kb.GetActionKey("regenerate"):
+			if !a.dataModel.Streaming {
+				return a.regenerateLast()
+			}
+			return a, nil
+
+		case kb.GetActionKey("message_actions"):
+			wasOpen := a.showMessageActions
+			a.closeAllModals()
+			if !wasOpen && !a.dataModel.Streaming {
+				a.openMessageActions()
+			}
+			return a, nil
+
 		case kb.GetActionKey("external_editor"):
 			// Open external editor (only if not streaming)
 			if !a.dataModel.Streaming {
@@ -894,8 +939,30 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.showAbout = false
 				return a, nil
 			}
+			if a.editingMessageIdx >= 0 {
+				a.cancelEdit()
+				return a, nil
+			}
 			// Fall through to other esc handlers
 
+		case kb.GetActionKey("toggle_tool_blocks"):
+			a.expandToolBlocks = !a.expandToolBlocks
+			if a.dataModel.Streaming {
+				a.updateStreamingMessage()
+				return a, nil
+			}
+			a.updateViewportContent(false)
+			return a, nil
+
+		case kb.GetActionKey("toggle_reasoning"):
+			a.expandReasoning = !a.expandReasoning
+			if a.dataModel.Streaming {
+				a.updateStreamingMessage()
+				return a, nil
+			}
+			a.updateViewportContent(false)
+			return a, nil
+
 		case kb.GetActionKey("yank_last_response"):
 			// Copy last assistant message
 			for i := len(a.dataModel.Messages) - 1; i >= 0; i-- {
@@ -916,6 +983,10 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					role = "You"
 				case "assistant":
 					role = "Assistant"
+				case "tool":
+					if msg.ToolCall != nil {
+						role = "Tool " + msg.ToolCall.Name
+					}
 				}
 				allText.WriteString(fmt.Sprintf("[%s] %s:\n%s\n\n",
 					msg.Timestamp.Format("15:04"),
@@ -981,12 +1052,12 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	// Streaming messages → appview_update_streaming.go
-	case streamChunksCollectedMsg, displayChunkTickMsg, streamChunkMsg, streamDoneMsg, streamErrorMsg, notifyCompleteMsg:
+	case streamChunkMsg, retryNoticeMsg, streamDoneMsg, streamErrorMsg, timeoutErrorMsg, notifyCompleteMsg:
 		return a.handleStreamingMessage(msg)
 
 	// Phase 6: Tool execution handlers
 	// Tool messages → appview_update_tools.go
-	case toolCallsDetectedMsg, toolExecutionCompleteMsg, toolExecutionErrorMsg,
+	case toolCallsDetectedMsg, toolResultsMsg, toolExecutionCompleteMsg, toolExecutionErrorMsg,
 		toolPermissionRequestMsg, toolPermissionResponseMsg:
 		return a.handleToolMessage(msg)
 
@@ -1170,6 +1241,14 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				_ = config.SavePluginsConfig(a.dataModel.Config.DataDir(), a.pluginManagerState.pluginState.Config)
 			}
 
+			if msg.Operation == "upgrade" {
+				delete(a.pluginManagerState.updates, msg.PluginID)
+				a.showAcknowledgeModal = true
+				a.acknowledgeModalTitle = "Plugin Upgraded"
+				a.acknowledgeModalMsg = fmt.Sprintf("%s was upgraded to %s.", a.pluginOperationName, msg.Version)
+				a.acknowledgeModalType = ModalTypeInfo
+			}
+
 			a.showPluginOperationModal = false
 			a.pluginOperationPhase = ""
 			a.pluginOperationName = ""
@@ -1225,9 +1304,16 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Reset selection to first plugin
 			a.pluginManagerState.selection.selectedPluginIdx = 0
+
+			// The registry may list newer versions
+			return a, a.dataModel.CheckPluginUpdates()
 		}
 		return a, nil
 
+	case pluginUpdatesMsg:
+		a.pluginManagerState.updates = msg.Updates
+		return a, nil
+
 	case editorContentMsg:
 		// Load edited content into textarea
 		a.textarea.SetValue(msg.Content)
@@ -1271,13 +1357,83 @@ func (a AppView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// Session messages → appview_update_sessions.go
 	case sessionLoadedMsg, sessionSavedMsg, sessionRenamedMsg, sessionExportedMsg,
-		sessionImportedMsg, exportCleanupDoneMsg:
+		sessionImportedMsg, sessionForkedMsg, exportCleanupDoneMsg,
+		semanticSearchResultMsg, sessionsIndexedMsg:
 		return a.handleSessionMessage(msg)
 
 	// Data export messages → appview_update_ui.go
 	case dataExportedMsg, dataExportCleanupDoneMsg:
 		return a.handleUIMessage(msg)
 
+	// Plugin resources
+	case resourceAttachedMsg:
+		a.pluginManagerState.resources.status = ""
+		err := msg.Err
+		if err == nil {
+			err = a.dataModel.CheckAttachments(msg.Attachments)
+		}
+		if err != nil {
+			a.showAcknowledgeModal = true
+			a.acknowledgeModalTitle = "Cannot Attach Resource"
+			a.acknowledgeModalMsg = err.Error()
+			a.acknowledgeModalType = ModalTypeWarning
+			return a, nil
+		}
+		a.pendingAttachments = append(a.pendingAttachments, msg.Attachments...)
+		a.pluginManagerState.resources = ResourceBrowserState{}
+		a.pluginManagerState.detailsModal.visible = false
+		a.pluginManagerState.detailsModal.plugin = nil
+		a.showPluginManager = false
+		return a, nil
+
+	case resourcePinnedMsg:
+		switch {
+		case msg.Err != nil:
+			a.pluginManagerState.resources.status = fmt.Sprintf("Pinned %s, but it couldn't be read: %v", msg.Name, msg.Err)
+		case msg.Pinned:
+			a.pluginManagerState.resources.status = "Pinned " + msg.Name + " - it's sent with every message"
+		default:
+			a.pluginManagerState.resources.status = "Unpinned " + msg.Name
+		}
+		return a, nil
+
+	case promptRenderedMsg:
+		if msg.Err != nil {
+			a.showAcknowledgeModal = true
+			a.acknowledgeModalTitle = "Prompt Failed"
+			a.acknowledgeModalMsg = msg.Err.Error()
+			a.acknowledgeModalType = ModalTypeError
+			return a, nil
+		}
+		if a.dataModel.Streaming {
+			// A reply started meanwhile; its messages can't go in the middle of it
+			a.showAcknowledgeModal = true
+			a.acknowledgeModalTitle = "Prompt Not Added"
+			a.acknowledgeModalMsg = fmt.Sprintf("/%s finished while a reply was being generated. Run it again once the reply is done.", msg.Name)
+			a.acknowledgeModalType = ModalTypeWarning
+			return a, nil
+		}
+		return a.addPromptMessages(msg.Messages)
+
+	case resourceUpdatedMsg:
+		// Keep watching for the next change
+		return a, a.dataModel.WatchResourceUpdatesCmd()
+
+	case samplingRequestMsg:
+		return a.handleSamplingRequest(msg)
+
+	case samplingCompleteMsg:
+		if msg.Err != nil {
+			a.showAcknowledgeModal = true
+			a.acknowledgeModalTitle = "Sampling Failed"
+			a.acknowledgeModalMsg = fmt.Sprintf("The completion %s asked for failed: %v", msg.PluginID, msg.Err)
+			a.acknowledgeModalType = ModalTypeError
+		}
+		return a, nil
+
+	case elicitationRequestMsg:
+		return a.handleElicitationRequest(msg)
+
 	// Plugin manager messages
 	case installProgressMsg:
 		if a.showPluginManager && a.pluginManagerState.installModal.visible {
@@ -50,10 +50,9 @@ func (a AppView) handleSessionMessage(msg tea.Msg) (AppView, tea.Cmd) {
 		// Load session into UI and sync with MCP manager
 		a.setCurrentSession(msg.Session)
 
-		// Reset streaming/typewriter state from previous session
+		// Reset streaming state from previous session
 		a.currentResp.Reset()
-		a.chunks = nil
-		a.chunkIndex = 0
+		a.currentReasoning.Reset()
 		a.userScrolledUp = false
 		a.highlightedMessageIdx = -1
 		a.highlightFlashCount = 0
@@ -67,21 +66,12 @@ func (a AppView) handleSessionMessage(msg tea.Msg) (AppView, tea.Cmd) {
 			a.dataModel.SessionStorage.SaveCurrentSessionID(msg.Session.ID)
 		}
 
+		// A search hit in another version of the conversation shows that version
+		switchedBranch := a.pendingBranchID != "" && msg.Session.SwitchToBranch(a.pendingBranchID)
+		a.pendingBranchID = ""
+
 		// Convert storage messages to UI messages
-		a.dataModel.Messages = []Message{}
-		for _, sMsg := range msg.Session.Messages {
-			// Use cached rendering if available, otherwise use content
-			rendered := sMsg.Rendered
-			if rendered == "" {
-				rendered = sMsg.Content
-			}
-			a.dataModel.Messages = append(a.dataModel.Messages, Message{
-				Role:      sMsg.Role,
-				Content:   sMsg.Content,
-				Rendered:  rendered,
-				Timestamp: sMsg.Timestamp,
-			})
-		}
+		a.dataModel.LoadSessionMessages()
 
 		// Set model and provider from session (Phase 1.6: multi-provider support)
 		if msg.Session.Model != "" {
@@ -173,6 +163,10 @@ func (a AppView) handleSessionMessage(msg tea.Msg) (AppView, tea.Cmd) {
 
 			// Trigger flash animation
 			var renderCmds []tea.Cmd
+			if switchedBranch {
+				renderCmds = append(renderCmds, a.dataModel.SaveCurrentSession())
+			}
+			renderCmds = append(renderCmds, a.dataModel.LoadModelCapabilities())
 			renderCmds = append(renderCmds, tea.Tick(300*time.Millisecond, func(time.Time) tea.Msg {
 				return flashTickMsg{}
 			}))
@@ -198,6 +192,10 @@ func (a AppView) handleSessionMessage(msg tea.Msg) (AppView, tea.Cmd) {
 		// Trigger markdown rendering for user and assistant messages that need it
 		// Render in REVERSE order (newest first) since viewport shows bottom
 		var renderCmds []tea.Cmd
+		if switchedBranch {
+			renderCmds = append(renderCmds, a.dataModel.SaveCurrentSession())
+		}
+		renderCmds = append(renderCmds, a.dataModel.LoadModelCapabilities())
 		for i := len(a.dataModel.Messages) - 1; i >= 0; i-- {
 			if a.dataModel.Messages[i].Role == "assistant" || a.dataModel.Messages[i].Role == "user" {
 				// Skip if already rendered (cached from disk)
@@ -278,6 +276,31 @@ func (a AppView) handleSessionMessage(msg tea.Msg) (AppView, tea.Cmd) {
 			config.DebugLog.Printf("Session saved successfully")
 		}
 
+		// Keep the semantic search index up to date (no-op unless enabled)
+		return a, a.dataModel.UpdateEmbeddingsCmd()
+
+	case semanticSearchResultMsg:
+		if !a.showGlobalSearch || !a.globalSearchSemantic || msg.Query != a.semanticSearchQuery {
+			return a, nil // The search changed since
+		}
+		a.semanticSearching = false
+		if msg.Err != nil {
+			a.semanticSearchErr = fmt.Sprintf("Semantic search failed: %v", msg.Err)
+			return a, nil
+		}
+		a.globalSearchResults = msg.Results
+		a.selectedGlobalIdx = 0
+		a.globalSearchScrollIdx = 0
+		return a, nil
+
+	case sessionsIndexedMsg:
+		a.semanticIndexing = false
+		if msg.Err != nil {
+			a.semanticSearchErr = fmt.Sprintf("Indexing sessions failed: %v", msg.Err)
+		}
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("[Embeddings] Indexed %d sessions (err: %v)", msg.Indexed, msg.Err)
+		}
 		return a, nil
 
 	case sessionRenamedMsg:
@@ -375,6 +398,21 @@ func (a AppView) handleSessionMessage(msg tea.Msg) (AppView, tea.Cmd) {
 			return sessionsListMsg{Sessions: sessions, Err: err}
 		}
 
+	case sessionForkedMsg:
+		if msg.Err != nil {
+			a.showAcknowledgeModal = true
+			a.acknowledgeModalTitle = "Fork Failed"
+			a.acknowledgeModalMsg = msg.Err.Error()
+			a.acknowledgeModalType = ModalTypeError
+			return a, nil
+		}
+
+		// Switch to the fork so it can be taken in another direction
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("Forked session %s into %s (%d messages)", msg.Session.ParentID, msg.Session.ID, len(msg.Session.Messages))
+		}
+		return a, a.dataModel.LoadSession(msg.Session.ID)
+
 	case exportCleanupDoneMsg:
 		// Cleanup finished - return to session manager
 		a.exportCleaningUp = false
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,157 +10,23 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"otui/config"
+	"otui/model"
 )
 
 // handleStreamingMessage handles all streaming-related messages
 func (a AppView) handleStreamingMessage(msg tea.Msg) (AppView, tea.Cmd) {
-	var cmds []tea.Cmd
-
 	switch msg := msg.(type) {
-	case streamChunksCollectedMsg:
-		if config.DebugLog != nil {
-			config.DebugLog.Printf("streamChunksCollectedMsg received - %d chunks collected", len(msg.Chunks))
-		}
-
-		// Ignore if user cancelled
-		if !a.dataModel.Streaming {
-			if config.DebugLog != nil {
-				config.DebugLog.Printf("Ignoring streamChunksCollectedMsg - user cancelled")
-			}
-			return a, nil
-		}
-
-		// Keep system message - spinner stays animated until first real content arrives
-
-		// Initialize typewriter effect
-		a.chunks = msg.Chunks
-		a.chunkIndex = 0
-		a.dataModel.Streaming = true
-		a.currentResp.Reset()
-
-		// Start displaying chunks with typewriter effect after a brief delay
-		// System message with animated spinner stays visible during this delay
-		return a, tea.Tick(300*time.Millisecond, func(time.Time) tea.Msg {
-			return displayChunkTickMsg{}
-		})
-
-	case displayChunkTickMsg:
-		// Stop typewriter if user cancelled
-		if !a.dataModel.Streaming {
+	case streamChunkMsg:
+		// Ignore chunks from a request the user already cancelled
+		if !a.dataModel.Streaming || msg.Stream != a.dataModel.ActiveStream {
 			return a, nil
 		}
 
-		if a.chunkIndex >= len(a.chunks) {
-			// All chunks displayed - finalize
-			fullResp := a.currentResp.String()
-			a.dataModel.Streaming = false
-			a.userScrolledUp = false
-			a.chunks = nil
-			a.chunkIndex = 0
-			a.currentResp.Reset()
-
-			if config.DebugLog != nil {
-				config.DebugLog.Printf("Typewriter complete - finalizing message (len=%d)", len(fullResp))
-			}
-
-			// Remove "Analyzing results..." message before adding response
-			a.removeLastNonPersistentSystemMessage()
-
-			// Check if iteration should continue (Phase 2)
-			// If continuing AND response is empty, skip adding empty message
-			if a.pendingNextStep {
-				toolMsg := toolCallsDetectedMsg{
-					ToolCalls:       a.pendingToolCalls,
-					InitialResponse: "",
-					ContextMessages: a.pendingToolContext,
-				}
-
-				// Clear pending state
-				a.pendingNextStep = false
-				a.pendingToolCalls = nil
-				a.pendingToolContext = nil
-
-				// Only add non-empty responses
-				if fullResp != "" {
-					a.dataModel.Messages = append(a.dataModel.Messages, Message{
-						Role:      "assistant",
-						Content:   fullResp,
-						Rendered:  fullResp,
-						Timestamp: time.Now(),
-					})
-					a.updateViewportContent(true)
-					a.dataModel.SessionDirty = true
-
-					messageIndex := len(a.dataModel.Messages) - 1
-					cmds = []tea.Cmd{
-						a.renderMarkdownAsync(messageIndex, fullResp),
-						a.dataModel.AutoSaveSession(),
-						a.dataModel.CheckAutoCompactionCmd(), // Check if auto-compaction should trigger
-					}
-				}
-
-				// Trigger next iteration
-				newA, newCmd := a.handleToolMessage(toolMsg)
-				if newCmd != nil {
-					cmds = append(cmds, newCmd)
-				}
-				return newA, tea.Batch(cmds...)
-			}
-
-			// Final response - add message and summary
-			if fullResp != "" {
-				a.dataModel.Messages = append(a.dataModel.Messages, Message{
-					Role:      "assistant",
-					Content:   fullResp,
-					Rendered:  fullResp,
-					Timestamp: time.Now(),
-				})
-			}
-
-			messageIndex := len(a.dataModel.Messages) - 1
-
-			// Add summary after assistant message (Phase 2)
-			if a.pendingSummary != nil {
-				summaryContent := buildIterationSummary(*a.pendingSummary)
-				a.dataModel.Messages = append(a.dataModel.Messages, Message{
-					Role:       "system",
-					Content:    summaryContent,
-					Rendered:   summaryContent,
-					Timestamp:  time.Now(),
-					Persistent: true,
-				})
-				a.pendingSummary = nil
-			}
-
-			a.updateViewportContent(true)
-			a.dataModel.SessionDirty = true
-
-			// No more steps - done
-			if fullResp != "" {
-				cmds = []tea.Cmd{
-					a.renderMarkdownAsync(messageIndex, fullResp),
-					a.dataModel.AutoSaveSession(),
-					a.dataModel.CheckAutoCompactionCmd(), // Check if auto-compaction should trigger
-				}
-			} else {
-				cmds = []tea.Cmd{
-					a.dataModel.AutoSaveSession(),
-					a.dataModel.CheckAutoCompactionCmd(), // Check if auto-compaction should trigger
-				}
-			}
-			if a.dataModel.Config.NotifyOnComplete {
-				cmds = append(cmds, notifyResponseComplete())
-			}
-			return a, tea.Batch(cmds...)
-		}
-
-		// Display next chunk
-		chunk := a.chunks[a.chunkIndex]
-		a.chunkIndex++
-		a.currentResp.WriteString(chunk)
+		a.currentResp.WriteString(msg.Chunk)
+		a.currentReasoning.WriteString(msg.Reasoning)
 
-		// Remove loading message AFTER first NON-EMPTY chunk is written
-		if a.currentResp.String() != "" {
+		// Remove loading message once real content (or thinking) arrives
+		if a.currentResp.Len() > 0 || a.currentReasoning.Len() > 0 {
 			a.removeLastNonPersistentSystemMessage()
 		}
 
@@ -172,75 +39,61 @@ func (a AppView) handleStreamingMessage(msg tea.Msg) (AppView, tea.Cmd) {
 			a.updateStreamingMessage()
 		}
 
-		// Schedule next chunk with delay (30ms, but first chunk is immediate)
-		delay := 30 * time.Millisecond
-		if a.chunkIndex == 1 {
-			delay = time.Millisecond // First chunk nearly immediate
+		return a, msg.Stream.Next()
+
+	case retryNoticeMsg:
+		if !a.dataModel.Streaming || msg.Stream != a.dataModel.ActiveStream {
+			return a, nil
 		}
 
-		return a, tea.Tick(delay, func(time.Time) tea.Msg {
-			return displayChunkTickMsg{}
+		// Nothing has streamed yet - the notice takes the loading message's place
+		a.removeLastNonPersistentSystemMessage()
+		a.dataModel.Messages = append(a.dataModel.Messages, Message{
+			Role:      "system",
+			Content:   msg.Notice,
+			Rendered:  msg.Notice,
+			Timestamp: time.Now(),
 		})
-
-	case streamChunkMsg:
-		a.currentResp.WriteString(msg.Chunk)
-		a.updateStreamingMessage()
-		return a, nil
+		a.updateViewportContent(true)
+		return a, msg.Stream.Next()
 
 	case streamDoneMsg:
 		if config.DebugLog != nil {
 			config.DebugLog.Printf("streamDoneMsg received - response length: %d", len(msg.FullResponse))
 		}
 
-		a.dataModel.Streaming = false
-		a.userScrolledUp = false
-
-		// Remove loading message (last system message, but not persistent step messages)
-		if len(a.dataModel.Messages) > 0 &&
-			a.dataModel.Messages[len(a.dataModel.Messages)-1].Role == "system" &&
-			!a.dataModel.Messages[len(a.dataModel.Messages)-1].Persistent {
-			a.dataModel.Messages = a.dataModel.Messages[:len(a.dataModel.Messages)-1]
+		// Ignore if user cancelled, or if the stream was replaced by a newer one
+		if !a.dataModel.Streaming || msg.Stream != a.dataModel.ActiveStream {
+			if config.DebugLog != nil {
+				config.DebugLog.Printf("Ignoring streamDoneMsg - stream cancelled or replaced")
+			}
+			return a, nil
 		}
 
-		// Add final assistant message with plain text initially
-		if msg.FullResponse != "" {
-			a.dataModel.Messages = append(a.dataModel.Messages, Message{
-				Role:      "assistant",
-				Content:   msg.FullResponse,
-				Rendered:  msg.FullResponse, // Start with plain text
-				Timestamp: time.Now(),
-			})
-
-			messageIndex := len(a.dataModel.Messages) - 1
-
+		// No response received (and no tool calls)
+		if msg.FullResponse == "" {
 			if config.DebugLog != nil {
-				config.DebugLog.Printf("Message added as plain text, triggering async markdown render")
+				config.DebugLog.Printf("ERROR: No response in streamDoneMsg")
 			}
 
-			// Update viewport immediately with plain text
-			a.updateViewportContent(true)
+			a.dataModel.Streaming = false
+			a.dataModel.ActiveStream = nil
+			a.userScrolledUp = false
+			a.currentResp.Reset()
+			a.currentReasoning.Reset()
+			a.removeLastNonPersistentSystemMessage()
 
-			// Trigger async markdown rendering (non-blocking)
-			cmds := []tea.Cmd{a.renderMarkdownAsync(messageIndex, msg.FullResponse)}
-			if a.dataModel.Config.NotifyOnComplete {
-				cmds = append(cmds, notifyResponseComplete())
-			}
-			return a, tea.Batch(cmds...)
-		}
-		// No response received
-		if config.DebugLog != nil {
-			config.DebugLog.Printf("ERROR: No response in streamDoneMsg")
+			a.dataModel.Messages = append(a.dataModel.Messages, Message{
+				Role:      "system",
+				Content:   "⚠️ No response received from Ollama",
+				Rendered:  "⚠️ No response received from Ollama",
+				Timestamp: time.Now(),
+			})
+			a.updateViewportContent(true)
+			return a, nil
 		}
-		a.dataModel.Messages = append(a.dataModel.Messages, Message{
-			Role:      "system",
-			Content:   "⚠️ No response received from Ollama",
-			Rendered:  "⚠️ No response received from Ollama",
-			Timestamp: time.Now(),
-		})
-		a.updateViewportContent(true)
-		a.currentResp.Reset()
 
-		return a, nil
+		return a.finishResponse(msg.FullResponse, msg.Reasoning, msg.Usage, msg.AnsweredBy)
 
 	case streamErrorMsg:
 		if config.DebugLog != nil {
@@ -248,8 +101,10 @@ func (a AppView) handleStreamingMessage(msg tea.Msg) (AppView, tea.Cmd) {
 		}
 
 		a.dataModel.Streaming = false
+		a.dataModel.ActiveStream = nil
 		a.userScrolledUp = false
 		a.currentResp.Reset()
+		a.currentReasoning.Reset()
 
 		// Remove loading message (but not persistent step messages)
 		if len(a.dataModel.Messages) > 0 &&
@@ -273,6 +128,11 @@ func (a AppView) handleStreamingMessage(msg tea.Msg) (AppView, tea.Cmd) {
 				"Press %s to change model.", currentModel, a.formatKeyDisplay("primary", "M"))
 		}
 
+		// Images sent to a model without vision support
+		if errors.Is(msg.Err, model.ErrVisionUnsupported) {
+			displayMsg = fmt.Sprintf("❌ Error: %v\n\nPress %s to change model.", msg.Err, a.formatKeyDisplay("primary", "M"))
+		}
+
 		// Wrap error message to fit viewport width
 		maxWidth := a.width - 10 // Leave padding for margins
 		if maxWidth > 0 {
@@ -289,6 +149,43 @@ func (a AppView) handleStreamingMessage(msg tea.Msg) (AppView, tea.Cmd) {
 		a.updateViewportContent(true)
 		return a, nil
 
+	case timeoutErrorMsg:
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("timeoutErrorMsg received: %v", msg.Err)
+		}
+
+		// A timeout can end any step of a multi-step run
+		a.dataModel.Streaming = false
+		a.dataModel.ActiveStream = nil
+		a.userScrolledUp = false
+		a.currentResp.Reset()
+		a.currentReasoning.Reset()
+		a.executingTool = ""
+		a.pendingNextStep = false
+		a.pendingSummary = nil
+		a.iterationCount = 0
+		a.dataModel.CurrentIteration = 0
+		a = a.cleanupTemporaryTools()
+
+		// Remove loading message (but not persistent step messages)
+		a.removeLastNonPersistentSystemMessage()
+
+		displayMsg := fmt.Sprintf("⏱️ %s did not respond within %s.\n\n"+
+			"Raise [timeouts] generation in config.toml, or set timeout in the provider's [[providers]] entry.",
+			a.dataModel.Provider.GetModel(), msg.Err.Timeout)
+		if maxWidth := a.width - 10; maxWidth > 0 {
+			displayMsg = lipgloss.NewStyle().Width(maxWidth).Render(displayMsg)
+		}
+
+		a.dataModel.Messages = append(a.dataModel.Messages, Message{
+			Role:      "system",
+			Content:   displayMsg,
+			Rendered:  displayMsg,
+			Timestamp: time.Now(),
+		})
+		a.updateViewportContent(true)
+		return a, nil
+
 	case notifyCompleteMsg:
 		// No-op — notification already emitted
 		return a, nil
@@ -296,3 +193,180 @@ func (a AppView) handleStreamingMessage(msg tea.Msg) (AppView, tea.Cmd) {
 
 	return a, nil
 }
+
+// finishResponse adds a completed response to the conversation. fullResp is
+// the provider's final text (cleaned of leaked tool calls), which may differ
+// from what was streamed into the viewport, reasoning the thinking reported
+// with it, usage its reported token counts and answeredBy the model that
+// produced it. If another step is pending, it is started once the response is added.
+func (a AppView) finishResponse(fullResp, reasoning string, usage Usage, answeredBy model.Responder) (AppView, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	a.dataModel.Streaming = false
+	a.dataModel.ActiveStream = nil
+	a.userScrolledUp = false
+	a.currentResp.Reset()
+	a.currentReasoning.Reset()
+
+	if config.DebugLog != nil {
+		config.DebugLog.Printf("Response complete - finalizing message (len=%d)", len(fullResp))
+	}
+
+	// Remove "Analyzing results..." message before adding response
+	a.removeLastNonPersistentSystemMessage()
+
+	// Check if iteration should continue (Phase 2)
+	// If continuing AND response is empty, skip adding empty message
+	if a.pendingNextStep {
+		toolMsg := toolCallsDetectedMsg{
+			ToolCalls:       a.pendingToolCalls,
+			InitialResponse: "",
+			ContextMessages: a.pendingToolContext,
+		}
+
+		// Clear pending state
+		a.pendingNextStep = false
+		a.pendingToolCalls = nil
+		a.pendingToolContext = nil
+
+		// Only add non-empty responses (thinking alone is kept too)
+		if fullResp != "" || reasoning != "" {
+			a.dataModel.Messages = append(a.dataModel.Messages, Message{
+				Role:       "assistant",
+				Content:    fullResp,
+				Reasoning:  reasoning,
+				Rendered:   fullResp,
+				Timestamp:  time.Now(),
+				Usage:      usage,
+				AnsweredBy: answeredBy,
+			})
+			a.updateViewportContent(true)
+			a.dataModel.SessionDirty = true
+
+			messageIndex := len(a.dataModel.Messages) - 1
+			cmds = []tea.Cmd{
+				a.dataModel.AutoSaveSession(),
+				a.dataModel.CheckAutoCompactionCmd(), // Check if auto-compaction should trigger
+			}
+			if fullResp != "" {
+				cmds = append(cmds, a.renderMarkdownAsync(messageIndex, fullResp))
+			}
+		}
+
+		// Trigger next iteration
+		newA, newCmd := a.handleToolMessage(toolMsg)
+		if newCmd != nil {
+			cmds = append(cmds, newCmd)
+		}
+		return newA, tea.Batch(cmds...)
+	}
+
+	// Final response - add message and summary
+	if fullResp != "" || reasoning != "" {
+		a.dataModel.Messages = append(a.dataModel.Messages, Message{
+			Role:       "assistant",
+			Content:    fullResp,
+			Reasoning:  reasoning,
+			Rendered:   fullResp,
+			Timestamp:  time.Now(),
+			Usage:      usage,
+			AnsweredBy: answeredBy,
+		})
+	}
+
+	messageIndex := len(a.dataModel.Messages) - 1
+
+	// Add summary after assistant message (Phase 2)
+	if a.pendingSummary != nil {
+		summaryContent := buildIterationSummary(*a.pendingSummary)
+		a.dataModel.Messages = append(a.dataModel.Messages, Message{
+			Role:       "system",
+			Content:    summaryContent,
+			Rendered:   summaryContent,
+			Timestamp:  time.Now(),
+			Persistent: true,
+		})
+		a.pendingSummary = nil
+	}
+
+	a.updateViewportContent(true)
+	a.dataModel.SessionDirty = true
+
+	// No more steps - done
+	if fullResp != "" {
+		cmds = []tea.Cmd{
+			a.renderMarkdownAsync(messageIndex, fullResp),
+			a.dataModel.AutoSaveSession(),
+			a.dataModel.CheckAutoCompactionCmd(), // Check if auto-compaction should trigger
+		}
+	} else {
+		cmds = []tea.Cmd{
+			a.dataModel.AutoSaveSession(),
+			a.dataModel.CheckAutoCompactionCmd(), // Check if auto-compaction should trigger
+		}
+	}
+	if a.dataModel.Config.NotifyOnComplete {
+		cmds = append(cmds, notifyResponseComplete())
+	}
+	return a, tea.Batch(cmds...)
+}
+
+// stopGeneration aborts the current turn at the user's request. The
+// partial response is kept and marked as interrupted in the session, and
+// tool results that already came back stay in the conversation.
+func (a AppView) stopGeneration() (AppView, tea.Cmd) {
+	if config.DebugLog != nil {
+		config.DebugLog.Printf("User stopped generation (partial response: %d chars, executing tool: %q)",
+			a.currentResp.Len(), a.executingTool)
+	}
+
+	// Cancel the provider request and any running tool calls
+	a.dataModel.CancelStream()
+	a.dataModel.Streaming = false
+	a.userScrolledUp = false
+
+	// Drop the rest of a multi-step run
+	a.executingTool = ""
+	a.pendingNextStep = false
+	a.pendingToolCalls = nil
+	a.pendingToolContext = nil
+	a.pendingSummary = nil
+	a.iterationCount = 0
+	a.dataModel.CurrentIteration = 0
+	a = a.cleanupTemporaryTools()
+
+	partialResp := a.currentResp.String()
+	partialReasoning := a.currentReasoning.String()
+	a.currentResp.Reset()
+	a.currentReasoning.Reset()
+
+	// Remove loading or running step message (but not completed steps)
+	a.removeLastNonPersistentSystemMessage()
+
+	if partialResp == "" && partialReasoning == "" {
+		a.dataModel.Messages = append(a.dataModel.Messages, Message{
+			Role:      "system",
+			Content:   "⚠️ Request cancelled",
+			Rendered:  "⚠️ Request cancelled",
+			Timestamp: time.Now(),
+		})
+		a.updateViewportContent(true)
+		return a, nil
+	}
+
+	a.dataModel.Messages = append(a.dataModel.Messages, Message{
+		Role:        "assistant",
+		Content:     partialResp,
+		Reasoning:   partialReasoning,
+		Rendered:    partialResp,
+		Timestamp:   time.Now(),
+		Interrupted: true,
+	})
+	a.updateViewportContent(true)
+	a.dataModel.SessionDirty = true
+
+	return a, tea.Batch(
+		a.renderMarkdownAsync(len(a.dataModel.Messages)-1, partialResp),
+		a.dataModel.AutoSaveSession(),
+	)
+}
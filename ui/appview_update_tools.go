@@ -2,7 +2,6 @@ package ui
 
 import (
 	"fmt"
-	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,6 +13,8 @@ import (
 func (a AppView) handleToolMessage(msg tea.Msg) (AppView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case toolCallsDetectedMsg:
+		var renderCmd tea.Cmd
+
 		if config.DebugLog != nil {
 			config.DebugLog.Printf("Tool calls detected: %d calls", len(msg.ToolCalls))
 		}
@@ -29,6 +30,26 @@ func (a AppView) handleToolMessage(msg tea.Msg) (AppView, tea.Cmd) {
 		// Remove "Waiting for response..." message
 		a.removeLastNonPersistentSystemMessage()
 
+		// Keep text the model streamed before requesting tools (already cleaned
+		// of leaked tool calls); replay attaches the calls to it
+		a.currentResp.Reset()
+		a.currentReasoning.Reset()
+		if msg.InitialResponse != "" || msg.Reasoning != "" {
+			a.dataModel.Messages = append(a.dataModel.Messages, Message{
+				Role:       "assistant",
+				Content:    msg.InitialResponse,
+				Reasoning:  msg.Reasoning,
+				Rendered:   msg.InitialResponse,
+				Timestamp:  time.Now(),
+				Usage:      msg.Usage,
+				AnsweredBy: msg.AnsweredBy,
+			})
+			a.dataModel.SessionDirty = true
+			if msg.InitialResponse != "" {
+				renderCmd = a.renderMarkdownAsync(len(a.dataModel.Messages)-1, msg.InitialResponse)
+			}
+		}
+
 		// Extract purpose and create step message
 		var firstToolCall *ToolCall
 		if len(msg.ToolCalls) > 0 {
@@ -41,14 +62,40 @@ func (a AppView) handleToolMessage(msg tea.Msg) (AppView, tea.Cmd) {
 		a.startToolExecution(msg.ToolCalls[0].Name)
 
 		return a, tea.Batch(
+			renderCmd,
 			a.toolExecutionSpinner.Tick,
 			a.loadingSpinner.Tick,
 			a.dataModel.ExecuteToolsAndContinue(msg),
 		)
 
+	case toolResultsMsg:
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("Tool results received - %d results", len(msg.ToolMessages))
+		}
+
+		// Clear tool execution state
+		a.executingTool = ""
+
+		// Ignore results from a request the user already cancelled
+		if !a.dataModel.Streaming || msg.Stream != a.dataModel.ActiveStream {
+			return a, nil
+		}
+
+		// Complete the step (checkmark + persistent)
+		a.completeStepMessage()
+
+		// Keep executed tool calls and their results in the conversation
+		a.dataModel.Messages = append(a.dataModel.Messages, msg.ToolMessages...)
+		a.dataModel.SessionDirty = true
+
+		// Show analyzing state until the follow-up response streams in
+		a.showAnalyzingResults()
+
+		return a, msg.Stream.Next()
+
 	case toolExecutionCompleteMsg:
 		if config.DebugLog != nil {
-			config.DebugLog.Printf("Tool execution complete - %d chunks", len(msg.Chunks))
+			config.DebugLog.Printf("Tool execution complete - %d chars", len(msg.FullResponse))
 		}
 
 		// Clear tool execution state
@@ -65,13 +112,7 @@ func (a AppView) handleToolMessage(msg tea.Msg) (AppView, tea.Cmd) {
 			return a, nil
 		}
 
-		// Complete the step (checkmark + persistent)
-		a.completeStepMessage()
-
-		// Show analyzing state
-		a.showAnalyzingResults()
-
-		// Store summary to add after typewriter completes
+		// Store summary to add after the response
 		if msg.IterationSummary.TotalSteps > 0 {
 			a.pendingSummary = &msg.IterationSummary
 		}
@@ -88,8 +129,7 @@ func (a AppView) handleToolMessage(msg tea.Msg) (AppView, tea.Cmd) {
 			a.dataModel.CurrentIteration = 0
 		}
 
-		// Start typewriter
-		return a, a.startTypewriter(msg.Chunks)
+		return a.finishResponse(msg.FullResponse, msg.Reasoning, msg.Usage, msg.AnsweredBy)
 
 	case toolExecutionErrorMsg:
 		if config.DebugLog != nil {
@@ -99,7 +139,9 @@ func (a AppView) handleToolMessage(msg tea.Msg) (AppView, tea.Cmd) {
 		// Clear state
 		a.executingTool = ""
 		a.dataModel.Streaming = false
+		a.dataModel.ActiveStream = nil
 		a.currentResp.Reset()
+		a.currentReasoning.Reset()
 		a.iterationCount = 0
 		a.dataModel.CurrentIteration = 0
 		a.pendingNextStep = false
@@ -107,12 +149,9 @@ func (a AppView) handleToolMessage(msg tea.Msg) (AppView, tea.Cmd) {
 		// CLEANUP: Remove temporarily allowed tools
 		a = a.cleanupTemporaryTools()
 
-		// Remove step message
-		if len(a.dataModel.Messages) > 0 &&
-			a.dataModel.Messages[len(a.dataModel.Messages)-1].Role == "system" &&
-			strings.HasPrefix(a.dataModel.Messages[len(a.dataModel.Messages)-1].Content, "🔧 ") {
-			a.dataModel.Messages = a.dataModel.Messages[:len(a.dataModel.Messages)-1]
-		}
+		// Remove the running step message, or "Analyzing results..." if the
+		// tools finished and the follow-up response failed
+		a.removeLastNonPersistentSystemMessage()
 
 		// Show error
 		a.dataModel.Messages = append(a.dataModel.Messages, Message{
@@ -167,6 +206,7 @@ func (a AppView) handleToolMessage(msg tea.Msg) (AppView, tea.Cmd) {
 		// EARLY RETURN: Handle denial
 		if !msg.Approved {
 			a.dataModel.Streaming = false
+			a.dataModel.ActiveStream = nil
 
 			// Remove "Waiting for response..." loading message (but not persistent step messages)
 			if len(a.dataModel.Messages) > 0 &&
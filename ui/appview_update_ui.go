@@ -79,6 +79,13 @@ func (a AppView) handleUIMessage(msg tea.Msg) (AppView, tea.Cmd) {
 
 		return a, nil
 
+	case modelCapabilitiesLoadedMsg:
+		if msg.Err != nil && config.DebugLog != nil {
+			config.DebugLog.Printf("Failed to load capabilities of %s: %v", msg.Model, msg.Err)
+		}
+		a.dataModel.ForgetModelMetadata(msg.Model)
+		return a, nil
+
 	case sessionsListMsg:
 		if msg.Err != nil {
 			if config.DebugLog != nil {
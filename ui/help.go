@@ -50,8 +50,15 @@ func (a AppView) renderHelpModal(width, height int) string {
 		lipgloss.Left,
 		blue.Render("## Chat Actions"),
 		"• Enter         Send message",
+		"• /plugin:name  Run a plugin prompt (Tab completes)",
 		fmt.Sprintf("• %-13s Copy last response", kb.DisplayActionKey("yank_last_response")),
 		fmt.Sprintf("• %-13s Copy conversation", kb.DisplayActionKey("yank_conversation")),
+		fmt.Sprintf("• %-13s Expand/collapse tools", kb.DisplayActionKey("toggle_tool_blocks")),
+		fmt.Sprintf("• %-13s Expand/collapse thinking", kb.DisplayActionKey("toggle_reasoning")),
+		fmt.Sprintf("• %-13s Stop response (or Esc)", kb.DisplayActionKey("stop_generation")),
+		fmt.Sprintf("• %-13s Attach image/file", kb.DisplayActionKey("attach_file")),
+		fmt.Sprintf("• %-13s Regenerate last reply", kb.DisplayActionKey("regenerate")),
+		fmt.Sprintf("• %-13s Edit/regenerate/versions", kb.DisplayActionKey("message_actions")),
 	)
 
 	tips := lipgloss.JoinVertical(
@@ -233,3 +233,37 @@ func TestStripANSI(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatToolMessage(t *testing.T) {
+	msg := Message{
+		Role:     "tool",
+		Content:  "line1\nline2",
+		ToolCall: &ToolCall{Name: "fs.read_file", Arguments: map[string]any{"path": "a.txt"}},
+	}
+
+	collapsed := stripANSI(formatToolMessage("", "[10:00]", msg, false))
+	if !strings.Contains(collapsed, "▸") || !strings.Contains(collapsed, "fs.read_file") {
+		t.Errorf("collapsed block missing header: %q", collapsed)
+	}
+	if strings.Contains(collapsed, "line1") {
+		t.Errorf("collapsed block should not show the result: %q", collapsed)
+	}
+
+	expanded := stripANSI(formatToolMessage("", "[10:00]", msg, true))
+	for _, want := range []string{"▾", `"path":"a.txt"`, "line1", "line2"} {
+		if !strings.Contains(expanded, want) {
+			t.Errorf("expanded block missing %q: %q", want, expanded)
+		}
+	}
+
+	var long strings.Builder
+	for i := 0; i < maxToolResultLines+5; i++ {
+		long.WriteString("x\n")
+	}
+	msg.Content = long.String()
+	msg.ToolError = "failed"
+	truncated := stripANSI(formatToolMessage("", "[10:00]", msg, true))
+	if !strings.Contains(truncated, "5 more lines") || !strings.Contains(truncated, "✗") {
+		t.Errorf("expected truncation note and error marker: %q", truncated)
+	}
+}
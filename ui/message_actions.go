@@ -0,0 +1,295 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"otui/config"
+	appmodel "otui/model"
+)
+
+// messageAction is an entry in the message actions modal: a prompt, or the
+// reply it got
+type messageAction struct {
+	role     string // "user" or "assistant"
+	index    int    // Message in dataModel.Messages, -1 for a reply that failed
+	branchAt int    // Where a new version starts: at the prompt, or right after it for the reply
+}
+
+// messageActions lists the prompts in the conversation, each followed by the
+// final reply to it
+func (a AppView) messageActions() []messageAction {
+	messages := a.dataModel.Messages
+	var actions []messageAction
+	for i, msg := range messages {
+		if msg.Role != "user" {
+			continue
+		}
+		actions = append(actions, messageAction{role: "user", index: i, branchAt: i})
+
+		reply := -1
+		for j := i + 1; j < len(messages) && messages[j].Role != "user"; j++ {
+			if messages[j].Role == "assistant" {
+				reply = j
+			}
+		}
+		// A failed regeneration leaves no reply, but the others can still be switched to
+		if reply >= 0 || a.dataModel.BranchVersionAt(i+1).Total > 1 {
+			actions = append(actions, messageAction{role: "assistant", index: reply, branchAt: i + 1})
+		}
+	}
+	return actions
+}
+
+// openMessageActions shows the message actions modal with the last reply selected
+func (a *AppView) openMessageActions() {
+	a.showMessageActions = true
+	a.messageActionsIdx = max(len(a.messageActions())-1, 0)
+}
+
+func (a AppView) handleMessageActionsUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
+	kb := a.dataModel.Config.Keybindings
+	actions := a.messageActions()
+
+	switch msg.String() {
+	case "esc", kb.GetActionKey("message_actions"):
+		a.showMessageActions = false
+		return a, nil
+	case "up", kb.GetActionKey("model_selector_up"):
+		if a.messageActionsIdx > 0 {
+			a.messageActionsIdx--
+		}
+		return a, nil
+	case "down", kb.GetActionKey("model_selector_down"):
+		if a.messageActionsIdx < len(actions)-1 {
+			a.messageActionsIdx++
+		}
+		return a, nil
+	}
+
+	if a.messageActionsIdx >= len(actions) || a.dataModel.Streaming {
+		return a, nil
+	}
+	selected := actions[a.messageActionsIdx]
+
+	switch msg.String() {
+	case "e":
+		if selected.role != "user" {
+			return a, nil
+		}
+		a.showMessageActions = false
+		a.editMessage(selected.index)
+		return a, nil
+	case "r":
+		a.showMessageActions = false
+		if selected.role == "user" {
+			return a.regenerate(selected.index + 1)
+		}
+		return a.regenerate(selected.branchAt)
+	case "left", "h":
+		return a.switchBranch(selected.branchAt, -1)
+	case "right", "l":
+		return a.switchBranch(selected.branchAt, 1)
+	}
+	return a, nil
+}
+
+// editMessage puts an earlier prompt in the input. Sending it starts a new
+// version of the conversation from there (see branchEditedMessage).
+func (a *AppView) editMessage(idx int) {
+	msg := a.dataModel.Messages[idx]
+	a.editingMessageIdx = idx
+	a.textarea.SetValue(msg.Content)
+	a.pendingAttachments = append([]Attachment(nil), msg.Attachments...)
+}
+
+// cancelEdit leaves the prompt being edited as it was
+func (a *AppView) cancelEdit() {
+	a.editingMessageIdx = -1
+	a.textarea.Reset()
+	a.pendingAttachments = nil
+}
+
+// branchEditedMessage moves the prompt being edited and everything after it
+// into another version of the conversation, so the edited prompt is sent in
+// its place
+func (a *AppView) branchEditedMessage() {
+	idx := a.editingMessageIdx
+	a.editingMessageIdx = -1
+	if err := a.dataModel.BranchFrom(idx); err != nil {
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("[UI] Failed to branch at message %d: %v", idx, err)
+		}
+		return
+	}
+	a.sessionGeneration++ // Message indices changed
+}
+
+// regenerateLast asks for a new reply to the last prompt
+func (a AppView) regenerateLast() (AppView, tea.Cmd) {
+	for i := len(a.dataModel.Messages) - 1; i >= 0; i-- {
+		if a.dataModel.Messages[i].Role == "user" {
+			return a.regenerate(i + 1)
+		}
+	}
+	return a, nil
+}
+
+// regenerate keeps the reply starting at message at as another version of
+// the conversation and asks the model for a new one
+func (a AppView) regenerate(at int) (AppView, tea.Cmd) {
+	canSend, errMsg := a.dataModel.CanSendMessage()
+	if !canSend {
+		a.dataModel.Messages = append(a.dataModel.Messages, Message{
+			Role:      "system",
+			Content:   errMsg,
+			Rendered:  errMsg,
+			Timestamp: time.Now(),
+		})
+		a.updateViewportContent(true)
+		return a, nil
+	}
+
+	if err := a.dataModel.BranchFrom(at); err != nil {
+		if config.DebugLog != nil {
+			config.DebugLog.Printf("[UI] Failed to branch at message %d: %v", at, err)
+		}
+		return a, nil
+	}
+	a.sessionGeneration++
+	a.editingMessageIdx = -1
+
+	// Initialize and start spinner
+	a.loadingSpinner = spinner.New()
+	a.loadingSpinner.Spinner = spinner.Dot
+	a.loadingSpinner.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("15")) // Bright white
+
+	loadingMsg := "Waiting for response..."
+	a.dataModel.Messages = append(a.dataModel.Messages, Message{
+		Role:      "system",
+		Content:   loadingMsg,
+		Rendered:  loadingMsg,
+		Timestamp: time.Now(),
+	})
+
+	a.dataModel.Streaming = true
+	a.updateViewportContent(true)
+
+	return a, tea.Batch(
+		a.dataModel.SendToOllama(),
+		a.loadingSpinner.Tick,
+	)
+}
+
+// switchBranch shows another version of the conversation from message at on
+func (a AppView) switchBranch(at, delta int) (AppView, tea.Cmd) {
+	if !a.dataModel.SwitchBranch(at, delta) {
+		return a, nil
+	}
+	a.sessionGeneration++
+	a.editingMessageIdx = -1
+	a.updateViewportContent(true)
+
+	cmds := []tea.Cmd{a.dataModel.SaveCurrentSession()}
+	for i, msg := range a.dataModel.Messages {
+		if (msg.Role == "assistant" || msg.Role == "user") && msg.Rendered == msg.Content {
+			cmds = append(cmds, a.renderMarkdownAsync(i, msg.Content))
+		}
+	}
+	return a, tea.Batch(cmds...)
+}
+
+// formatBranchMarker marks where versions of the conversation diverge
+func formatBranchMarker(v appmodel.BranchVersion) string {
+	return DimStyle.Render(fmt.Sprintf("⎇ Version %d of %d", v.Current, v.Total)) + "\n"
+}
+
+func renderMessageActions(a AppView, actions []messageAction, selectedIdx, width, height int) string {
+	modalWidth := width - 4
+	if modalWidth > 100 {
+		modalWidth = 100
+	}
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(dimColor).
+		Padding(1, 2)
+
+	title := TitleStyle.Render("⎇ Messages")
+
+	listView := ""
+	if len(actions) == 0 {
+		listView = DimStyle.Render("No messages yet")
+	} else {
+		// Border(2) + Padding(2) + Title(1) + Blank(1) + Blank(1) + Footer(1) +
+		// scroll indicators (2) = 10 lines
+		maxVisible := max(height-10, 1)
+		startIdx := max(selectedIdx-maxVisible+1, 0)
+		endIdx := min(startIdx+maxVisible, len(actions))
+
+		if startIdx > 0 {
+			listView += DimStyle.Render(fmt.Sprintf("↑ %d more above", startIdx)) + "\n"
+		}
+
+		previewWidth := modalWidth - 30
+		for i := startIdx; i < endIdx; i++ {
+			action := actions[i]
+
+			label := UserStyle.Render("You      ")
+			if action.role == "assistant" {
+				label = AssistantStyle.Render("  Reply  ")
+			}
+
+			preview := DimStyle.Render("(no reply)")
+			if action.index >= 0 {
+				preview = previewLine(a.dataModel.Messages[action.index].Content, previewWidth)
+			}
+
+			versions := ""
+			if v := a.dataModel.BranchVersionAt(action.branchAt); v.Total > 1 {
+				versions = DimStyle.Render(fmt.Sprintf("  ‹%d/%d›", v.Current, v.Total))
+			}
+
+			line := fmt.Sprintf("%s %s%s", label, preview, versions)
+			if i == selectedIdx {
+				line = SelectedStyle.Render("> ") + line
+			} else {
+				line = "  " + line
+			}
+			listView += line + "\n"
+		}
+
+		if endIdx < len(actions) {
+			listView += DimStyle.Render(fmt.Sprintf("↓ %d more below", len(actions)-endIdx))
+		}
+	}
+
+	footer := FormatFooter("J/K", "Navigate", "E", "Edit & resend", "R", "Regenerate", "H/L", "Switch version", "Esc", "Close")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		listView,
+		"",
+		footer,
+	)
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center,
+		modalStyle.Width(modalWidth).Render(content))
+}
+
+// previewLine shortens a message to its first line, at most width characters
+func previewLine(content string, width int) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	runes := []rune(line)
+	if width > 3 && len(runes) > width {
+		return string(runes[:width-3]) + "..."
+	}
+	return line
+}
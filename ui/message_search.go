@@ -108,6 +108,20 @@ func renderMessageSearch(a AppView, searchInput textinput.Model, results []stora
 		modalStyle.Width(modalWidth).Render(content))
 }
 
+// setGlobalSearchSemantic switches the global search between keywords and
+// meaning (semantic search)
+func (a *AppView) setGlobalSearchSemantic(semantic bool) {
+	a.globalSearchSemantic = semantic
+	a.semanticSearchQuery = ""
+	a.semanticSearching = false
+	a.semanticSearchErr = ""
+	if semantic {
+		a.globalSearchInput.Prompt = "Search meaning: "
+	} else {
+		a.globalSearchInput.Prompt = "Search all: "
+	}
+}
+
 func renderGlobalSearch(a AppView, searchInput textinput.Model, results []storage.SessionMessageMatch, selectedIdx, scrollIdx, width, height int) string {
 	modalWidth := width - 4
 	if modalWidth > 100 {
@@ -120,12 +134,30 @@ func renderGlobalSearch(a AppView, searchInput textinput.Model, results []storag
 		Padding(1, 2)
 
 	title := TitleStyle.Render("🔍 Search All Sessions")
+	if a.globalSearchSemantic {
+		title = TitleStyle.Render("🔍 Search All Sessions · Semantic")
+	}
 	searchView := searchInput.View()
+	if a.semanticSearchErr != "" {
+		searchView += "\n" + lipgloss.NewStyle().Foreground(dangerColor).Render(a.semanticSearchErr)
+	} else if a.semanticIndexing && a.globalSearchSemantic {
+		searchView += "\n" + DimStyle.Render("Indexing sessions for semantic search...")
+	}
 
 	resultsView := ""
 	if len(results) == 0 {
-		if searchInput.Value() == "" {
-			resultsView = DimStyle.Render("Type to search across all sessions...")
+		if a.globalSearchSemantic {
+			switch {
+			case a.semanticSearching:
+				resultsView = DimStyle.Render("Searching...")
+			case a.semanticSearchQuery != "":
+				resultsView = DimStyle.Render("No matches found")
+			default:
+				resultsView = DimStyle.Render("Describe what you're looking for and press Enter...")
+			}
+		} else if searchInput.Value() == "" {
+			resultsView = DimStyle.Render("Type to search across all sessions...\n\n" +
+				"\"exact phrase\"  prefix*  model:NAME  provider:ID  after:YYYY-MM-DD  before:YYYY-MM-DD")
 		} else {
 			resultsView = DimStyle.Render("No matches found")
 		}
@@ -170,10 +202,18 @@ func renderGlobalSearch(a AppView, searchInput textinput.Model, results []storag
 				roleStyle = AssistantStyle
 			}
 
+			role := match.Role
+			if match.BranchID != "" {
+				role += " · other version"
+			}
+			if a.globalSearchSemantic {
+				role += fmt.Sprintf(" · %d%% similar", match.Score/10)
+			}
+
 			matchText := fmt.Sprintf("%s [%s] %s\n  %s",
 				roleStyle.Render(match.SessionName),
 				match.Timestamp.Format("Jan 2, 3:04 PM"),
-				DimStyle.Render(match.Role),
+				DimStyle.Render(role),
 				match.Preview,
 			)
 
@@ -191,7 +231,10 @@ func renderGlobalSearch(a AppView, searchInput textinput.Model, results []storag
 		}
 	}
 
-	footer := FormatFooter("Type", "to search", a.formatKeyDisplay("primary", "J/K"), "Navigate", "Enter", "View Session", "Esc", "Close")
+	footer := FormatFooter("Type", "to search", a.formatKeyDisplay("primary", "J/K"), "Navigate", "Enter", "View Session", "Tab", "Semantic", "Esc", "Close")
+	if a.globalSearchSemantic {
+		footer = FormatFooter(a.formatKeyDisplay("primary", "J/K"), "Navigate", "Enter", "Search / View Session", "Tab", "Keywords", "Esc", "Close")
+	}
 
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
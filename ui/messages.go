@@ -7,26 +7,33 @@ import (
 // Message type aliases for backward compatibility
 type Message = model.Message
 type ToolCall = model.ToolCall
+type Usage = model.Usage
+type Attachment = model.Attachment
 
 // Message type aliases - these are now defined in model package
 type streamChunkMsg = model.StreamChunkMsg
 type streamDoneMsg = model.StreamDoneMsg
 type streamErrorMsg = model.StreamErrorMsg
-type streamChunksCollectedMsg = model.StreamChunksCollectedMsg
-type displayChunkTickMsg = model.DisplayChunkTickMsg
+type retryNoticeMsg = model.RetryNoticeMsg
+type timeoutErrorMsg = model.TimeoutErrorMsg
 type toolCallsDetectedMsg = model.ToolCallsDetectedMsg
+type toolResultsMsg = model.ToolResultsMsg
 type toolExecutionCompleteMsg = model.ToolExecutionCompleteMsg
 type toolExecutionErrorMsg = model.ToolExecutionErrorMsg
 type toolPermissionRequestMsg = model.ToolPermissionRequestMsg
 type toolPermissionResponseMsg = model.ToolPermissionResponseMsg
 type markdownRenderedMsg = model.MarkdownRenderedMsg
 type modelsListMsg = model.ModelsListMsg
+type modelCapabilitiesLoadedMsg = model.ModelCapabilitiesLoadedMsg
 type sessionsListMsg = model.SessionsListMsg
 type sessionLoadedMsg = model.SessionLoadedMsg
 type sessionSavedMsg = model.SessionSavedMsg
 type sessionRenamedMsg = model.SessionRenamedMsg
 type sessionExportedMsg = model.SessionExportedMsg
 type sessionImportedMsg = model.SessionImportedMsg
+type sessionForkedMsg = model.SessionForkedMsg
+type semanticSearchResultMsg = model.SemanticSearchResultMsg
+type sessionsIndexedMsg = model.SessionsIndexedMsg
 type exportCleanupDoneMsg = model.ExportCleanupDoneMsg
 type dataExportedMsg = model.DataExportedMsg
 type dataExportCleanupDoneMsg = model.DataExportCleanupDoneMsg
@@ -34,6 +41,14 @@ type flashTickMsg = model.FlashTickMsg
 type pluginOperationCompleteMsg = model.PluginOperationCompleteMsg
 type pluginStartupCompleteMsg = model.PluginStartupCompleteMsg
 type registryRefreshCompleteMsg = model.RegistryRefreshCompleteMsg
+type pluginUpdatesMsg = model.PluginUpdatesMsg
+type resourceAttachedMsg = model.ResourceAttachedMsg
+type resourcePinnedMsg = model.ResourcePinnedMsg
+type resourceUpdatedMsg = model.ResourceUpdatedMsg
+type promptRenderedMsg = model.PromptRenderedMsg
+type samplingRequestMsg = model.SamplingRequestMsg
+type samplingCompleteMsg = model.SamplingCompleteMsg
+type elicitationRequestMsg = model.ElicitationRequestMsg
 type editorContentMsg = model.EditorContentMsg
 type editorErrorMsg = model.EditorErrorMsg
 
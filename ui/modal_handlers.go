@@ -70,6 +70,38 @@ func (a AppView) handleSessionManagerUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 		return a, nil
 	}
 
+	if a.sessionUsage != nil {
+		switch msg.String() {
+		case "esc", "enter", "u":
+			a.sessionUsage = nil
+		}
+		return a, nil
+	}
+
+	if a.sessionFork != nil {
+		switch msg.String() {
+		case "esc":
+			a.sessionFork = nil
+		case "j", "down":
+			if a.sessionFork.selected < len(a.sessionFork.points)-1 {
+				a.sessionFork.selected++
+			}
+		case "k", "up":
+			if a.sessionFork.selected > 0 {
+				a.sessionFork.selected--
+			}
+		case "enter":
+			if len(a.sessionFork.points) == 0 {
+				return a, nil
+			}
+			sessionID := a.sessionFork.sessionID
+			point := a.sessionFork.points[a.sessionFork.selected]
+			a.sessionFork = nil
+			return a, a.dataModel.ForkSessionCmd(sessionID, point.index)
+		}
+		return a, nil
+	}
+
 	if a.sessionRenameMode {
 		model, cmd := a.handleSessionRenameMode(msg)
 		return model.(AppView), cmd
@@ -171,6 +203,20 @@ func (a AppView) handleSessionManagerUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 	case "esc":
 		a.showSessionManager = false
 		return a, nil
+	case "u":
+		list := a.getSessionList()
+		if a.dataModel.SessionStorage != nil && a.selectedSessionIdx >= 0 && a.selectedSessionIdx < len(list) {
+			view, err := loadSessionUsage(a.dataModel.SessionStorage, list[a.selectedSessionIdx])
+			if err != nil {
+				a.showAcknowledgeModal = true
+				a.acknowledgeModalTitle = "Usage Unavailable"
+				a.acknowledgeModalMsg = err.Error()
+				a.acknowledgeModalType = ModalTypeError
+				return a, nil
+			}
+			a.sessionUsage = view
+		}
+		return a, nil
 	case "j", "down":
 		list := a.getSessionList()
 		if a.selectedSessionIdx < len(list)-1 {
@@ -255,13 +301,17 @@ func (a AppView) handleSessionManagerUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 			a.newSessionFocusedField = 0
 			a.editSessionPluginIdx = 0
 
-			// Load full session to get enabled plugins
+			// Load full session to get enabled plugins and generation parameters
 			fullSession, err := a.dataModel.SessionStorage.Load(sessionMeta.ID)
 			if err == nil && fullSession != nil {
 				a.editSessionEnabledPlugins = make([]string, len(fullSession.EnabledPlugins))
 				copy(a.editSessionEnabledPlugins, fullSession.EnabledPlugins)
+				a.resetSessionParamsInput(fullSession.Generation)
+				a.resetSessionFallbackInput(fullSession.Fallback)
 			} else {
 				a.editSessionEnabledPlugins = []string{}
+				a.resetSessionParamsInput(config.GenerationParams{})
+				a.resetSessionFallbackInput(nil)
 			}
 
 			a.newSessionNameInput.SetValue(sessionMeta.Name)
@@ -279,8 +329,11 @@ func (a AppView) handleSessionManagerUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 				a.sessionExportInput.Width = 70
 				a.sessionExportInput.CharLimit = 500
 			}
+			if a.sessionExportOptions.Format == "" {
+				a.sessionExportOptions = storage.DefaultExportOptions()
+			}
 			sessionName := list[a.selectedSessionIdx].Name
-			defaultPath := storage.GenerateExportPath(sessionName)
+			defaultPath := storage.ExportPathWithFormat(storage.GenerateExportPath(sessionName), a.sessionExportOptions.Format)
 			a.sessionExportMode = true
 			a.sessionExportInput.SetValue(defaultPath)
 			a.sessionExportInput.Focus()
@@ -294,6 +347,30 @@ func (a AppView) handleSessionManagerUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 			a.confirmDeleteSession = &sessionMeta
 		}
 		return a, nil
+	case "f":
+		list := a.getSessionList()
+		if a.selectedSessionIdx >= 0 && a.selectedSessionIdx < len(list) {
+			sessionID := list[a.selectedSessionIdx].ID
+			isCurrentSession := a.dataModel.CurrentSession != nil && a.dataModel.CurrentSession.ID == sessionID
+			if isCurrentSession && a.dataModel.Streaming {
+				a.showAcknowledgeModal = true
+				a.acknowledgeModalTitle = "Cannot Fork Session"
+				a.acknowledgeModalMsg = "Session has an active response.\nWait for it to finish before forking."
+				a.acknowledgeModalType = ModalTypeWarning
+				return a, nil
+			}
+
+			session, err := a.dataModel.LatestSession(sessionID)
+			if err != nil {
+				a.showAcknowledgeModal = true
+				a.acknowledgeModalTitle = "Fork Failed"
+				a.acknowledgeModalMsg = err.Error()
+				a.acknowledgeModalType = ModalTypeError
+				return a, nil
+			}
+			a.sessionFork = newSessionForkView(session)
+		}
+		return a, nil
 	}
 	return a, nil
 }
@@ -488,7 +565,7 @@ func (a AppView) handleNewSessionModalUpdate(msg tea.KeyMsg) (AppView, tea.Cmd)
 			a.newSessionEnabledPlugins = []string{}
 			a.textarea.Reset()
 			a.updateViewportContent(true)
-			return a, nil
+			return a, a.dataModel.LoadModelCapabilities()
 		}
 
 	case "alt+enter":
@@ -524,7 +601,7 @@ func (a AppView) handleNewSessionModalUpdate(msg tea.KeyMsg) (AppView, tea.Cmd)
 		a.newSessionEnabledPlugins = []string{}
 		a.textarea.Reset()
 		a.updateViewportContent(true)
-		return a, nil
+		return a, a.dataModel.LoadModelCapabilities()
 	}
 
 	// Update focused input field with the key (for fields 0 and 1)
@@ -781,12 +858,7 @@ func (a AppView) handleMessageSearchUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 		// Convert []Message to []storage.Message
 		storageMessages := make([]storage.Message, len(a.dataModel.Messages))
 		for i, msg := range a.dataModel.Messages {
-			storageMessages[i] = storage.Message{
-				Role:      msg.Role,
-				Content:   msg.Content,
-				Rendered:  msg.Rendered,
-				Timestamp: msg.Timestamp,
-			}
+			storageMessages[i] = msg.ToStorage()
 		}
 		a.messageSearchResults = storage.SearchMessages(storageMessages, query)
 		a.selectedSearchIdx = 0
@@ -812,11 +884,39 @@ func (a AppView) handleGlobalSearchUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 			a.selectedGlobalIdx++
 		}
 		return a, nil
+	case "tab":
+		if !a.dataModel.SemanticSearchEnabled() {
+			a.semanticSearchErr = "Semantic search is off: set enabled = true under [semantic_search] in config.toml"
+			return a, nil
+		}
+		a.setGlobalSearchSemantic(!a.globalSearchSemantic)
+		a.globalSearchResults = []storage.SessionMessageMatch{}
+		a.selectedGlobalIdx = 0
+		a.globalSearchScrollIdx = 0
+		if a.globalSearchSemantic {
+			if a.semanticIndexing {
+				return a, nil
+			}
+			a.semanticIndexing = true
+			return a, a.dataModel.IndexSessionsCmd()
+		}
+		// Back to keywords: search what was typed right away
+		return a.runGlobalSearch(), nil
 	case "enter":
+		// In semantic mode Enter runs the search; once the results are in, it opens one
+		query := strings.TrimSpace(a.globalSearchInput.Value())
+		if a.globalSearchSemantic && query != "" && query != a.semanticSearchQuery {
+			a.semanticSearchQuery = query
+			a.semanticSearching = true
+			a.semanticSearchErr = ""
+			a.globalSearchResults = []storage.SessionMessageMatch{}
+			return a, a.dataModel.SemanticSearchCmd(query)
+		}
 		if a.selectedGlobalIdx >= 0 && a.selectedGlobalIdx < len(a.globalSearchResults) {
 			selectedMatch := a.globalSearchResults[a.selectedGlobalIdx]
 			a.showGlobalSearch = false
 			a.pendingScrollToMessageIdx = selectedMatch.MessageIndex
+			a.pendingBranchID = selectedMatch.BranchID
 			return a, a.dataModel.LoadSession(selectedMatch.SessionID)
 		}
 		return a, nil
@@ -824,6 +924,20 @@ func (a AppView) handleGlobalSearchUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 
 	var cmd tea.Cmd
 	a.globalSearchInput, cmd = a.globalSearchInput.Update(msg)
+	if a.globalSearchSemantic {
+		// Embedding a search takes a round trip to Ollama, so it waits for Enter
+		if strings.TrimSpace(a.globalSearchInput.Value()) != a.semanticSearchQuery {
+			a.semanticSearchQuery = ""
+			a.semanticSearching = false
+			a.globalSearchResults = []storage.SessionMessageMatch{}
+		}
+		return a, cmd
+	}
+	return a.runGlobalSearch(), cmd
+}
+
+// runGlobalSearch searches all sessions for the keywords typed
+func (a AppView) runGlobalSearch() AppView {
 	query := a.globalSearchInput.Value()
 	if query != "" && a.dataModel.SearchIndex != nil {
 		results, err := a.dataModel.SearchIndex.SearchAllSessions(query)
@@ -834,7 +948,7 @@ func (a AppView) handleGlobalSearchUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 	} else {
 		a.globalSearchResults = []storage.SessionMessageMatch{}
 	}
-	return a, cmd
+	return a
 }
 
 func (a AppView) handleEditSessionModalUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
@@ -851,11 +965,13 @@ func (a AppView) handleEditSessionModalUpdate(msg tea.KeyMsg) (AppView, tea.Cmd)
 		a.showEditSessionModal = false
 		a.newSessionNameInput.Blur()
 		a.newSessionPromptInput.Blur()
+		a.editSessionParamsInput.Blur()
+		a.editSessionFallbackInput.Blur()
 		a.editSessionID = ""
 		return a, nil
 
 	case "tab":
-		// Cycle through fields: 0=name, 1=prompt, 2=plugins (edit mode only)
+		// Cycle through fields: 0=name, 1=prompt, 2=plugins, 3=generation parameters, 4=fallback models
 		switch a.newSessionFocusedField {
 		case 0:
 			a.newSessionFocusedField = 1
@@ -864,27 +980,43 @@ func (a AppView) handleEditSessionModalUpdate(msg tea.KeyMsg) (AppView, tea.Cmd)
 		case 1:
 			a.newSessionFocusedField = 2
 			a.newSessionPromptInput.Blur()
+		case 2:
+			a.newSessionFocusedField = 3
+			a.editSessionParamsInput.Focus()
+		case 3:
+			a.newSessionFocusedField = 4
+			a.editSessionParamsInput.Blur()
+			a.editSessionFallbackInput.Focus()
 		default:
-			// From plugins back to name
+			// From fallback models back to name
 			a.newSessionFocusedField = 0
+			a.editSessionFallbackInput.Blur()
 			a.newSessionNameInput.Focus()
 		}
 		return a, textarea.Blink
 
 	case "shift+tab":
-		// Cycle backward through fields: 0=name, 2=plugins, 1=prompt
+		// Cycle backward through fields: 0=name, 4=fallback models, 3=generation parameters, 2=plugins, 1=prompt
 		switch a.newSessionFocusedField {
 		case 0:
-			a.newSessionFocusedField = 2
+			a.newSessionFocusedField = 4
 			a.newSessionNameInput.Blur()
+			a.editSessionFallbackInput.Focus()
 		case 1:
 			a.newSessionFocusedField = 0
 			a.newSessionPromptInput.Blur()
 			a.newSessionNameInput.Focus()
-		default:
-			// From plugins back to prompt
+		case 2:
 			a.newSessionFocusedField = 1
 			a.newSessionPromptInput.Focus()
+		case 3:
+			a.newSessionFocusedField = 2
+			a.editSessionParamsInput.Blur()
+		default:
+			// From fallback models back to parameters
+			a.newSessionFocusedField = 3
+			a.editSessionFallbackInput.Blur()
+			a.editSessionParamsInput.Focus()
 		}
 		return a, textarea.Blink
 
@@ -990,6 +1122,12 @@ func (a AppView) handleEditSessionModalUpdate(msg tea.KeyMsg) (AppView, tea.Cmd)
 			a.newSessionNameInput.SetValue("")
 		case 1: // Prompt field
 			a.newSessionPromptInput.SetValue("")
+		case 3: // Generation parameters field
+			a.editSessionParamsInput.SetValue("")
+			a.editSessionParamsError = ""
+		case 4: // Fallback models field
+			a.editSessionFallbackInput.SetValue("")
+			a.editSessionFallbackError = ""
 		}
 		return a, nil
 
@@ -999,23 +1137,53 @@ func (a AppView) handleEditSessionModalUpdate(msg tea.KeyMsg) (AppView, tea.Cmd)
 		newSystemPrompt := strings.TrimSpace(a.newSessionPromptInput.Value())
 		enabledPlugins := a.editSessionEnabledPlugins
 
+		// Keep the modal open until the parameters parse
+		generation, err := config.ParseGenerationParams(a.editSessionParamsInput.Value())
+		if err != nil {
+			a.editSessionParamsError = err.Error()
+			a.newSessionFocusedField = 3
+			a.newSessionNameInput.Blur()
+			a.newSessionPromptInput.Blur()
+			a.editSessionParamsInput.Focus()
+			a.editSessionFallbackInput.Blur()
+			return a, textinput.Blink
+		}
+		fallback, err := config.ParseFallbacks(a.editSessionFallbackInput.Value())
+		if err != nil {
+			a.editSessionFallbackError = err.Error()
+			a.newSessionFocusedField = 4
+			a.newSessionNameInput.Blur()
+			a.newSessionPromptInput.Blur()
+			a.editSessionParamsInput.Blur()
+			a.editSessionFallbackInput.Focus()
+			return a, textinput.Blink
+		}
+
 		sessionID := a.editSessionID
 		a.showEditSessionModal = false
 		a.newSessionNameInput.Blur()
 		a.newSessionPromptInput.Blur()
+		a.editSessionParamsInput.Blur()
+		a.editSessionFallbackInput.Blur()
 		a.editSessionID = ""
 
-		return a, a.dataModel.UpdateSessionPropertiesCmd(sessionID, newName, newSystemPrompt, enabledPlugins)
+		return a, a.dataModel.UpdateSessionPropertiesCmd(sessionID, newName, newSystemPrompt, enabledPlugins, generation, fallback)
 	}
 
-	// Update focused input field with the key (for fields 0 and 1)
-	// This allows normal typing in name and system prompt fields
+	// Update focused input field with the key (for fields 0, 1, 3 and 4)
+	// This allows normal typing in name, system prompt, parameter and fallback fields
 	var cmd tea.Cmd
 	switch a.newSessionFocusedField {
 	case 0:
 		a.newSessionNameInput, cmd = a.newSessionNameInput.Update(msg)
 	case 1:
 		a.newSessionPromptInput, cmd = a.newSessionPromptInput.Update(msg)
+	case 3:
+		a.editSessionParamsInput, cmd = a.editSessionParamsInput.Update(msg)
+		a.editSessionParamsError = ""
+	case 4:
+		a.editSessionFallbackInput, cmd = a.editSessionFallbackInput.Update(msg)
+		a.editSessionFallbackError = ""
 	}
 	// Field 2 (plugins) doesn't have an input component to update
 
@@ -1062,7 +1230,7 @@ func (a AppView) handleSystemPromptToolWarningUpdate(msg tea.KeyMsg) (AppView, t
 		a.showSystemPromptToolWarning = false
 
 		// Now send the message (copy the logic from the Enter handler)
-		if a.textarea.Value() != "" {
+		if a.textarea.Value() != "" || len(a.pendingAttachments) > 0 {
 			userMsg := a.textarea.Value()
 			a.textarea.Reset()
 
@@ -1077,13 +1245,20 @@ func (a AppView) handleSystemPromptToolWarningUpdate(msg tea.KeyMsg) (AppView, t
 				config.DebugLog.Printf("System prompt warning acknowledged - sending message: %s", userMsg)
 			}
 
+			// An edited prompt replaces the original as a new version of the conversation
+			if a.editingMessageIdx >= 0 {
+				a.branchEditedMessage()
+			}
+
 			// Add user message
 			a.dataModel.Messages = append(a.dataModel.Messages, Message{
-				Role:      "user",
-				Content:   userMsg,
-				Rendered:  userMsg, // Start with plain text, will be rendered async
-				Timestamp: time.Now(),
+				Role:        "user",
+				Content:     userMsg,
+				Rendered:    userMsg, // Start with plain text, will be rendered async
+				Timestamp:   time.Now(),
+				Attachments: a.pendingAttachments,
 			})
+			a.pendingAttachments = nil
 
 			// Trigger markdown rendering for user message
 			userMessageIndex := len(a.dataModel.Messages) - 1
@@ -320,6 +320,8 @@ func RenderPluginOperationModal(phase string, spinnerView string, pluginName str
 	case "disabling":
 		// Show spinner during disable - default color
 		content = spinnerView + " Disabling " + pluginName + "..."
+	case "upgrading":
+		content = spinnerView + " Upgrading " + pluginName + "..."
 	case "error":
 		// Show error message (no borders)
 		titleStyle := lipgloss.NewStyle().
@@ -350,7 +352,7 @@ func RenderPluginOperationModal(phase string, spinnerView string, pluginName str
 		return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box.String())
 	}
 
-	// For enabling/disabling phase - simple one-line with spinner (no borders)
+	// For enabling/disabling/upgrading phase - simple one-line with spinner (no borders)
 	paddedContent := lipgloss.NewStyle().
 		Width(modalWidth).
 		Align(lipgloss.Center).
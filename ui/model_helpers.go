@@ -47,6 +47,7 @@ func FindModelByName(models []ollama.ModelInfo, modelName string) (int, *ollama.
 //   - OpenRouter: Most models, with some exceptions
 //   - Anthropic: ALL Claude models support tools
 //   - OpenAI: Most models (gpt-4, gpt-3.5-turbo, etc.)
+//   - Gemini: Gemini models (not Gemma)
 //
 // This replaces the Ollama-only ollama.ModelSupportsToolCalling() check
 // to provide consistent tool indicators across all providers.
@@ -77,6 +78,10 @@ func ModelSupportsTools(model ollama.ModelInfo) bool {
 		}
 		return false
 
+	case "gemini":
+		// Gemini models support function calling; Gemma models on the same API don't
+		return strings.HasPrefix(strings.ToLower(model.InternalName), "gemini")
+
 	case "openrouter":
 		// OpenRouter: Most models support tools
 		// Exception: Some very small models may not
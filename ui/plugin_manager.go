@@ -27,10 +27,13 @@ type PluginManagerState struct {
 	uninstallModal  UninstallModalState
 	configModal     ConfigModalState
 	detailsModal    DetailsModalState
+	resources       ResourceBrowserState
 	warnings        WarningModalStates
 	confirmations   ConfirmationModalStates
 	addCustomModal  AddCustomModalState
 	registryRefresh RegistryRefreshState
+
+	updates map[string]mcp.PluginUpdate // Newer versions of installed plugins
 }
 
 // initPluginManager initializes the plugin manager state
@@ -250,6 +253,10 @@ func (a *AppView) renderPluginManager() string {
 		)
 	}
 
+	if a.pluginManagerState.resources.visible {
+		return a.renderResourceBrowser()
+	}
+
 	if a.pluginManagerState.detailsModal.visible {
 		return a.renderPluginDetails()
 	}
@@ -1126,11 +1126,22 @@ func (a AppView) handlePluginManagerUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 		return a, nil
 	}
 
+	if a.pluginManagerState.resources.visible {
+		return a.handleResourceBrowser(msg)
+	}
+
 	if a.pluginManagerState.detailsModal.visible {
 		switch msg.String() {
 		case "esc":
 			a.pluginManagerState.detailsModal.visible = false
 			a.pluginManagerState.detailsModal.plugin = nil
+		case "r":
+			if err := a.openResourceBrowser(a.pluginManagerState.detailsModal.plugin); err != nil {
+				a.showAcknowledgeModal = true
+				a.acknowledgeModalTitle = "Resources Unavailable"
+				a.acknowledgeModalMsg = err.Error()
+				a.acknowledgeModalType = ModalTypeWarning
+			}
 		case "i":
 			if !a.pluginManagerState.pluginState.Installer.IsInstalled(a.pluginManagerState.detailsModal.plugin.ID) {
 				a.pluginManagerState.warnings.showSecurity = true
@@ -1141,6 +1152,10 @@ func (a AppView) handlePluginManagerUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 			if a.pluginManagerState.pluginState.Installer.IsInstalled(a.pluginManagerState.detailsModal.plugin.ID) {
 				a.pluginManagerState.confirmations.deletePlugin = a.pluginManagerState.detailsModal.plugin
 			}
+		case "U":
+			if _, ok := a.pluginManagerState.updates[a.pluginManagerState.detailsModal.plugin.ID]; ok {
+				return a.startPluginUpgrade(a.pluginManagerState.detailsModal.plugin)
+			}
 		case "c":
 			if a.pluginManagerState.pluginState.Installer.IsInstalled(a.pluginManagerState.detailsModal.plugin.ID) {
 				plugin := a.pluginManagerState.detailsModal.plugin
@@ -1528,11 +1543,35 @@ func (a AppView) handlePluginManagerUpdate(msg tea.KeyMsg) (AppView, tea.Cmd) {
 				)
 			}
 		}
+	case "U":
+		if a.pluginManagerState.selection.viewMode == "installed" && len(plugins) > 0 && a.pluginManagerState.selection.selectedPluginIdx < len(plugins) {
+			plugin := &plugins[a.pluginManagerState.selection.selectedPluginIdx]
+			if _, ok := a.pluginManagerState.updates[plugin.ID]; ok {
+				return a.startPluginUpgrade(plugin)
+			}
+		}
 	}
 
 	return a, nil
 }
 
+// startPluginUpgrade upgrades an installed plugin to its newest version,
+// showing a spinner until the new version has started or been rolled back
+func (a AppView) startPluginUpgrade(plugin *mcp.Plugin) (AppView, tea.Cmd) {
+	a.showPluginOperationModal = true
+	a.pluginOperationPhase = "upgrading"
+	a.pluginOperationName = plugin.Name
+	a.pluginOperationError = ""
+	a.pluginOperationSpinner = spinner.New()
+	a.pluginOperationSpinner.Spinner = spinner.Dot
+	a.pluginOperationSpinner.Style = lipgloss.NewStyle().Foreground(successColor)
+
+	return a, tea.Batch(
+		a.pluginOperationSpinner.Tick,
+		a.dataModel.UpgradePlugin(plugin.ID),
+	)
+}
+
 // startPluginInstall initiates plugin installation
 func (a AppView) startPluginInstall(plugin *mcp.Plugin) (AppView, tea.Cmd) {
 	ctx, cancel := context.WithCancel(context.Background())
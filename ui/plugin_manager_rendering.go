@@ -161,6 +161,9 @@ func (a *AppView) renderPluginItem(plugin mcp.Plugin, selected bool, width int)
 	}
 
 	line := fmt.Sprintf("%s%s  %s  %s  %s", indicator, statusStyled, descPadded, categoryPadded, stars)
+	if update, ok := a.pluginManagerState.updates[plugin.ID]; ok && installed {
+		line += "  " + lipgloss.NewStyle().Foreground(warningColor).Render("↑ "+update.Latest)
+	}
 
 	styledLine := line
 
@@ -210,6 +213,9 @@ func (a *AppView) renderPluginFooter() string {
 			} else {
 				footerParts = append(footerParts, "e", "Enable")
 			}
+			if _, ok := a.pluginManagerState.updates[plugin.ID]; ok {
+				footerParts = append(footerParts, "U", "Upgrade")
+			}
 		}
 	default:
 		plugins := a.getVisiblePlugins()
@@ -266,6 +272,11 @@ func (a *AppView) renderPluginDetails() string {
 		details = append(details, struct{ label, value string }{"Stars", fmt.Sprintf("%d", plugin.Stars)})
 	}
 
+	update, hasUpdate := a.pluginManagerState.updates[plugin.ID]
+	if hasUpdate {
+		details = append(details, struct{ label, value string }{"Update", fmt.Sprintf("%s → %s", update.Installed, update.Latest)})
+	}
+
 	var messageLines []string
 	for _, d := range details {
 		if d.value == "" {
@@ -288,13 +299,17 @@ func (a *AppView) renderPluginDetails() string {
 		return RenderThreeSectionModal(plugin.Name, messageLines, footer, ModalTypeInfo, 80, a.width, a.height)
 	}
 
-	// Plugin is installed - show uninstall and configure options
-	footer = FormatFooter("u", "Uninstall", "c", "Configure", "Esc", "Close")
+	// Plugin is installed - show uninstall, configure and resources options
+	footerParts := []string{"u", "Uninstall", "c", "Configure", "r", "Resources"}
+	if hasUpdate {
+		footerParts = append(footerParts, "U", "Upgrade")
+	}
 
 	// Add edit option for custom plugins
 	if plugin.Custom {
-		footer = FormatFooter("u", "Uninstall", "c", "Configure", "e", "Edit", "Esc", "Close")
+		footerParts = append(footerParts, "e", "Edit")
 	}
+	footer = FormatFooter(append(footerParts, "Esc", "Close")...)
 
 	return RenderThreeSectionModal(plugin.Name, messageLines, footer, ModalTypeInfo, 80, a.width, a.height)
 }
@@ -65,6 +65,17 @@ type DetailsModalState struct {
 	plugin  *mcp.Plugin
 }
 
+// ResourceBrowserState manages the browser of a running plugin's resources
+type ResourceBrowserState struct {
+	visible    bool
+	plugin     *mcp.Plugin
+	items      []resourceItem
+	selected   int
+	editingURI bool            // Filling in a template before attaching it
+	uriInput   textinput.Model // The template's URI being filled in
+	status     string
+}
+
 // WarningModalStates groups all warning/info modals
 type WarningModalStates struct {
 	// Security warning
@@ -0,0 +1,288 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+
+	"otui/mcp"
+	appmodel "otui/model"
+)
+
+// ElicitationFormState is a form a plugin asked the user to fill in
+type ElicitationFormState struct {
+	request *mcp.ElicitationRequest
+	fields  []appmodel.ElicitationField
+	inputs  []textinput.Model // Text entry for string and number fields
+	values  []string          // Current values of boolean and enum fields
+	focused int
+	err     string
+}
+
+// handleSamplingRequest asks the user to approve a plugin's completion
+// request. While one waits, the next isn't watched for.
+func (a AppView) handleSamplingRequest(msg samplingRequestMsg) (AppView, tea.Cmd) {
+	if a.dataModel.Provider == nil {
+		msg.Request.Respond(nil, fmt.Errorf("no model is available"))
+		return a, a.dataModel.WatchPluginRequestsCmd()
+	}
+	a.pendingSampling = msg.Request
+	return a, nil
+}
+
+func (a AppView) handleSamplingKey(msg tea.KeyMsg) (AppView, tea.Cmd) {
+	request := a.pendingSampling
+	switch msg.String() {
+	case "y":
+		a.pendingSampling = nil
+		return a, tea.Batch(a.dataModel.RunSamplingCmd(request), a.dataModel.WatchPluginRequestsCmd())
+	case "n", "esc":
+		a.pendingSampling = nil
+		request.Respond(nil, fmt.Errorf("the user declined the request"))
+		return a, a.dataModel.WatchPluginRequestsCmd()
+	}
+	// Block all other keys while waiting for approval
+	return a, nil
+}
+
+func (a AppView) renderSamplingModal() string {
+	request := a.pendingSampling
+	params := request.Params
+
+	modalWidth := min(a.width-10, 80)
+	lineStyle := lipgloss.NewStyle().Width(modalWidth)
+	dimLine := lineStyle.Foreground(dimColor)
+	previewWidth := modalWidth - 14
+
+	var messageLines []string
+	intro := fmt.Sprintf("%s asks %s for a completion:", request.PluginID, a.dataModel.Provider.GetModel())
+	for _, wl := range wrapText(intro, modalWidth-4) {
+		messageLines = append(messageLines, lineStyle.Render("  "+wl))
+	}
+	messageLines = append(messageLines, strings.Repeat(" ", modalWidth))
+
+	if params.SystemPrompt != "" {
+		messageLines = append(messageLines, lineStyle.Render("  System:    "+DimStyle.Render(previewLine(params.SystemPrompt, previewWidth))))
+	}
+
+	// The latest messages are the ones being answered
+	const maxShown = 6
+	messages := appmodel.SamplingMessages(mcptypes.CreateMessageParams{Messages: params.Messages})
+	if len(messages) > maxShown {
+		messageLines = append(messageLines, dimLine.Render(fmt.Sprintf("  … %d earlier messages", len(messages)-maxShown)))
+		messages = messages[len(messages)-maxShown:]
+	}
+	for _, msg := range messages {
+		content := msg.Content
+		if len(msg.Attachments) > 0 {
+			content = "[image]"
+		}
+		label := "User:"
+		if msg.Role == "assistant" {
+			label = "Assistant:"
+		}
+		messageLines = append(messageLines, lineStyle.Render(fmt.Sprintf("  %-10s %s", label, DimStyle.Render(previewLine(content, previewWidth)))))
+	}
+
+	if params.MaxTokens > 0 {
+		messageLines = append(messageLines, strings.Repeat(" ", modalWidth))
+		messageLines = append(messageLines, dimLine.Render(fmt.Sprintf("  Up to %d tokens", params.MaxTokens)))
+	}
+
+	footer := FormatFooter("y", "Approve", "n", "Decline")
+	return RenderThreeSectionModal("Sampling Request", messageLines, footer, ModalTypeInfo, modalWidth, a.width, a.height)
+}
+
+// handleElicitationRequest opens the form a plugin asked for. Schemas the
+// form can't show are declined.
+func (a AppView) handleElicitationRequest(msg elicitationRequestMsg) (AppView, tea.Cmd) {
+	fields, err := appmodel.ElicitationFields(msg.Request.Params.RequestedSchema)
+	if err != nil {
+		msg.Request.Respond(mcptypes.ElicitationResponseActionDecline, nil)
+		a.showAcknowledgeModal = true
+		a.acknowledgeModalTitle = "Plugin Request Declined"
+		a.acknowledgeModalMsg = fmt.Sprintf("%s asked for input OTUI can't show: %v", msg.Request.PluginID, err)
+		a.acknowledgeModalType = ModalTypeWarning
+		return a, a.dataModel.WatchPluginRequestsCmd()
+	}
+
+	form := ElicitationFormState{
+		request: msg.Request,
+		fields:  fields,
+		inputs:  make([]textinput.Model, len(fields)),
+		values:  make([]string, len(fields)),
+	}
+	for i, field := range fields {
+		input := textinput.New()
+		input.CharLimit = 1000
+		input.Placeholder = field.Format
+		input.SetValue(field.Default)
+		form.inputs[i] = input
+		form.values[i] = field.Default
+	}
+	a.elicitationForm = &form
+	return a, form.focus(0)
+}
+
+// focus moves the cursor to the i-th field
+func (f *ElicitationFormState) focus(i int) tea.Cmd {
+	if len(f.fields) == 0 {
+		return nil
+	}
+	f.inputs[f.focused].Blur()
+	f.focused = (i + len(f.fields)) % len(f.fields)
+	if f.choice() {
+		return nil
+	}
+	return f.inputs[f.focused].Focus()
+}
+
+// choice reports whether the focused field is picked from a list of values
+// rather than typed
+func (f *ElicitationFormState) choice() bool {
+	field := f.fields[f.focused]
+	return field.Type == "boolean" || len(field.Enum) > 0
+}
+
+// cycle moves the focused boolean or enum field to its next or previous
+// value. Optional enums can also be left unset.
+func (f *ElicitationFormState) cycle(step int) {
+	field := f.fields[f.focused]
+	options := field.Enum
+	if field.Type == "boolean" {
+		options = []string{"false", "true"}
+	} else if !field.Required {
+		options = append([]string{""}, options...)
+	}
+
+	current := 0
+	for i, option := range options {
+		if option == f.values[f.focused] {
+			current = i
+		}
+	}
+	f.values[f.focused] = options[(current+step+len(options))%len(options)]
+}
+
+func (a AppView) handleElicitationKey(msg tea.KeyMsg) (AppView, tea.Cmd) {
+	form := a.elicitationForm
+
+	switch msg.String() {
+	case "esc":
+		a.elicitationForm = nil
+		form.request.Respond(mcptypes.ElicitationResponseActionCancel, nil)
+		return a, a.dataModel.WatchPluginRequestsCmd()
+	case "ctrl+d":
+		a.elicitationForm = nil
+		form.request.Respond(mcptypes.ElicitationResponseActionDecline, nil)
+		return a, a.dataModel.WatchPluginRequestsCmd()
+	case "enter":
+		values := make(map[string]string)
+		for i, field := range form.fields {
+			if field.Type == "boolean" || len(field.Enum) > 0 {
+				values[field.Name] = form.values[i]
+			} else {
+				values[field.Name] = form.inputs[i].Value()
+			}
+		}
+		content, err := appmodel.ElicitationContent(form.fields, values)
+		if err != nil {
+			form.err = err.Error()
+			return a, nil
+		}
+		a.elicitationForm = nil
+		form.request.Respond(mcptypes.ElicitationResponseActionAccept, content)
+		return a, a.dataModel.WatchPluginRequestsCmd()
+	case "tab", "down":
+		return a, form.focus(form.focused + 1)
+	case "shift+tab", "up":
+		return a, form.focus(form.focused - 1)
+	}
+
+	if len(form.fields) == 0 {
+		return a, nil
+	}
+	if form.choice() {
+		switch msg.String() {
+		case "left":
+			form.cycle(-1)
+		case "right", " ":
+			form.cycle(1)
+		}
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	form.inputs[form.focused], cmd = form.inputs[form.focused].Update(msg)
+	return a, cmd
+}
+
+func (a AppView) renderElicitationForm() string {
+	form := a.elicitationForm
+
+	modalWidth := min(a.width-10, 80)
+	lineStyle := lipgloss.NewStyle().Width(modalWidth)
+	dimLine := lineStyle.Foreground(dimColor)
+	blank := strings.Repeat(" ", modalWidth)
+
+	var messageLines []string
+	for _, wl := range wrapText(form.request.Params.Message, modalWidth-4) {
+		messageLines = append(messageLines, lineStyle.Render("  "+wl))
+	}
+
+	for i, field := range form.fields {
+		messageLines = append(messageLines, blank)
+
+		label := field.Label()
+		if field.Required {
+			label += " *"
+		}
+		if i == form.focused {
+			label = SelectedStyle.Render("▶ " + label)
+		} else {
+			label = "  " + label
+		}
+		messageLines = append(messageLines, lineStyle.Render(label))
+
+		var value string
+		switch {
+		case field.Type == "boolean":
+			value = "[ ] No"
+			if form.values[i] == "true" {
+				value = "[x] Yes"
+			}
+		case len(field.Enum) > 0:
+			value = DimStyle.Render("(none)")
+			for j, option := range field.Enum {
+				if option == form.values[i] {
+					value = field.EnumLabel(j)
+				}
+			}
+			value = "◀ " + value + " ▶"
+		default:
+			value = form.inputs[i].View()
+		}
+		messageLines = append(messageLines, lineStyle.Render("    "+value))
+
+		if i == form.focused && field.Description != "" {
+			for _, wl := range wrapText(field.Description, modalWidth-6) {
+				messageLines = append(messageLines, dimLine.Render("    "+wl))
+			}
+		}
+	}
+
+	if form.err != "" {
+		messageLines = append(messageLines, blank)
+		messageLines = append(messageLines, lineStyle.Foreground(dangerColor).Render("  "+form.err))
+	}
+
+	footer := FormatFooter("Tab", "Next", "Enter", "Submit", "Ctrl+D", "Decline", "Esc", "Cancel")
+	if len(form.fields) > 0 && form.choice() {
+		footer = FormatFooter("←/→", "Change", "Tab", "Next", "Enter", "Submit", "Ctrl+D", "Decline", "Esc", "Cancel")
+	}
+	return RenderThreeSectionModal("Input Requested: "+form.request.PluginID, messageLines, footer, ModalTypeInfo, modalWidth, a.width, a.height)
+}
@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"otui/mcp"
+	"otui/storage"
+)
+
+// resourceItem is a resource, or a template for one, offered by a plugin
+type resourceItem struct {
+	name        string
+	uri         string // The URI template for templates
+	description string
+	template    bool
+}
+
+// openResourceBrowser lists the resources of a running plugin, opened with
+// "r" in the plugin details
+func (a *AppView) openResourceBrowser(plugin *mcp.Plugin) error {
+	if a.dataModel.MCPManager == nil {
+		return fmt.Errorf("plugins are not available")
+	}
+	resources, templates, err := a.dataModel.MCPManager.GetResources(plugin.ID)
+	if err != nil {
+		return fmt.Errorf("%s isn't running. Enable it for this session to browse its resources.", plugin.Name)
+	}
+
+	var items []resourceItem
+	for _, r := range resources {
+		items = append(items, resourceItem{name: r.Name, uri: r.URI, description: r.Description})
+	}
+	for _, t := range templates {
+		if t.URITemplate == nil || t.URITemplate.Template == nil {
+			continue
+		}
+		items = append(items, resourceItem{name: t.Name, uri: t.URITemplate.Raw(), description: t.Description, template: true})
+	}
+
+	uriInput := textinput.New()
+	uriInput.Placeholder = "Resource URI"
+	uriInput.CharLimit = 500
+
+	a.pluginManagerState.resources = ResourceBrowserState{
+		visible:  true,
+		plugin:   plugin,
+		items:    items,
+		uriInput: uriInput,
+	}
+	return nil
+}
+
+func (a AppView) handleResourceBrowser(msg tea.KeyMsg) (AppView, tea.Cmd) {
+	kb := a.dataModel.Config.Keybindings
+	browser := &a.pluginManagerState.resources
+
+	if browser.editingURI {
+		switch msg.String() {
+		case "esc":
+			browser.editingURI = false
+			browser.uriInput.Blur()
+			return a, nil
+		case "enter":
+			uri := strings.TrimSpace(browser.uriInput.Value())
+			if uri == "" || strings.ContainsAny(uri, "{}") {
+				browser.status = "Fill in the template's {placeholders} first"
+				return a, nil
+			}
+			browser.editingURI = false
+			browser.uriInput.Blur()
+			browser.status = "Reading " + uri + "..."
+			return a, a.dataModel.AttachResourceCmd(browser.plugin.ID, uri, "")
+		}
+		var cmd tea.Cmd
+		browser.uriInput, cmd = browser.uriInput.Update(msg)
+		return a, cmd
+	}
+
+	switch msg.String() {
+	case "esc":
+		a.pluginManagerState.resources = ResourceBrowserState{}
+	case kb.GetActionKey("plugin_up"), kb.GetActionKey("plugin_up_arrow"):
+		if browser.selected > 0 {
+			browser.selected--
+		}
+	case kb.GetActionKey("plugin_down"), kb.GetActionKey("plugin_down_arrow"):
+		if browser.selected < len(browser.items)-1 {
+			browser.selected++
+		}
+	case "enter":
+		if len(browser.items) == 0 {
+			return a, nil
+		}
+		item := browser.items[browser.selected]
+		if item.template {
+			browser.editingURI = true
+			browser.uriInput.SetValue(item.uri)
+			browser.uriInput.CursorEnd()
+			browser.uriInput.Focus()
+			return a, textinput.Blink
+		}
+		browser.status = "Reading " + item.name + "..."
+		return a, a.dataModel.AttachResourceCmd(browser.plugin.ID, item.uri, item.name)
+	case "p":
+		if len(browser.items) == 0 {
+			return a, nil
+		}
+		item := browser.items[browser.selected]
+		if item.template {
+			browser.status = "Templates can't be pinned - attach the resource instead"
+			return a, nil
+		}
+		_, cmd := a.dataModel.TogglePinnedResource(storage.ResourceRef{PluginID: browser.plugin.ID, URI: item.uri, Name: item.name})
+		if cmd == nil {
+			return a, nil
+		}
+		return a, tea.Batch(cmd, a.dataModel.AutoSaveSession())
+	}
+	return a, nil
+}
+
+// renderResourceBrowser renders the resources of a plugin, with those pinned
+// to the session marked
+func (a *AppView) renderResourceBrowser() string {
+	browser := a.pluginManagerState.resources
+
+	modalWidth := a.width - 10
+	if modalWidth > 90 {
+		modalWidth = 90
+	}
+
+	lineStyle := lipgloss.NewStyle().Width(modalWidth)
+	dimLine := lineStyle.Foreground(dimColor)
+
+	var messageLines []string
+	messageLines = append(messageLines, dimLine.Render("  Attach a resource to your next message, or pin it to send it with every one"))
+	messageLines = append(messageLines, strings.Repeat(" ", modalWidth))
+
+	if len(browser.items) == 0 {
+		messageLines = append(messageLines, dimLine.Italic(true).Render("  This plugin offers no resources"))
+	} else {
+		// Title, blank lines, footer, hint, status and scroll indicators
+		maxVisible := max(a.height-16, 3)
+		start := max(browser.selected-maxVisible+1, 0)
+		end := min(start+maxVisible, len(browser.items))
+
+		if start > 0 {
+			messageLines = append(messageLines, dimLine.Render(fmt.Sprintf("  ↑ %d more above", start)))
+		}
+		for i := start; i < end; i++ {
+			item := browser.items[i]
+			marker := "  "
+			if item.template {
+				marker = "⧉ "
+			} else if a.dataModel.IsResourcePinned(browser.plugin.ID, item.uri) {
+				marker = "📌"
+			}
+
+			name := item.name
+			if name == "" {
+				name = item.uri
+			}
+			line := fmt.Sprintf("%s %s  %s", marker, name, DimStyle.Render(previewLine(item.uri, 50)))
+			if i == browser.selected {
+				line = SelectedStyle.Render("▶ ") + line
+			} else {
+				line = "  " + line
+			}
+			messageLines = append(messageLines, lineStyle.Render(line))
+		}
+		if end < len(browser.items) {
+			messageLines = append(messageLines, dimLine.Render(fmt.Sprintf("  ↓ %d more below", len(browser.items)-end)))
+		}
+
+		if description := browser.items[browser.selected].description; description != "" {
+			messageLines = append(messageLines, strings.Repeat(" ", modalWidth))
+			for _, wl := range wrapText(description, modalWidth-4) {
+				messageLines = append(messageLines, dimLine.Render("  "+wl))
+			}
+		}
+	}
+
+	if browser.editingURI {
+		messageLines = append(messageLines, strings.Repeat(" ", modalWidth))
+		messageLines = append(messageLines, lineStyle.Render("  URI: "+browser.uriInput.View()))
+	}
+	if browser.status != "" {
+		messageLines = append(messageLines, strings.Repeat(" ", modalWidth))
+		messageLines = append(messageLines, dimLine.Render("  "+browser.status))
+	}
+
+	title := "Resources: " + browser.plugin.Name
+	footer := FormatFooter("j/k", "Navigate", "Enter", "Attach", "p", "Pin", "Esc", "Back")
+	if browser.editingURI {
+		footer = FormatFooter("Enter", "Attach", "Esc", "Cancel")
+	}
+
+	return RenderThreeSectionModal(title, messageLines, footer, ModalTypeInfo, modalWidth, a.width, a.height)
+}
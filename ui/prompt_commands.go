@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	appmodel "otui/model"
+)
+
+// completePromptCommand completes a "/plugin:prompt" command in the input box
+// on Tab. It reports false when the input isn't a command it can complete.
+func (a *AppView) completePromptCommand() bool {
+	value := a.textarea.Value()
+	if !strings.HasPrefix(value, "/") {
+		return false
+	}
+	completed := appmodel.CompletePromptInput(value, a.dataModel.PromptCommands())
+	if completed == value {
+		return false
+	}
+	a.textarea.SetValue(completed)
+	a.textarea.CursorEnd()
+	return true
+}
+
+// runPromptCommand runs the input as a plugin prompt if it names one. It
+// reports false for anything else, which is sent as a message.
+func (a AppView) runPromptCommand() (AppView, tea.Cmd, bool) {
+	name, args, err := appmodel.ParsePromptCommand(a.textarea.Value())
+	if name == "" {
+		return a, nil, false
+	}
+	prompt, ok := appmodel.FindPrompt(a.dataModel.PromptCommands(), name)
+	if !ok {
+		return a, nil, false
+	}
+
+	if err == nil {
+		err = appmodel.CheckPromptArguments(prompt, args)
+	}
+	if err != nil {
+		a.showAcknowledgeModal = true
+		a.acknowledgeModalTitle = "Cannot Run Prompt"
+		a.acknowledgeModalMsg = fmt.Sprintf("%v\n\nUsage: /%s %s", err, prompt.Name, appmodel.PromptUsage(prompt))
+		a.acknowledgeModalType = ModalTypeWarning
+		return a, nil, true
+	}
+
+	a.textarea.Reset()
+	return a, a.dataModel.RunPromptCmd(name, args), true
+}
+
+// addPromptMessages adds the messages of a rendered prompt to the
+// conversation and, when the last is from the user, asks the model to reply
+func (a AppView) addPromptMessages(messages []Message) (AppView, tea.Cmd) {
+	for _, msg := range messages {
+		if err := a.dataModel.CheckAttachments(msg.Attachments); err != nil {
+			a.showAcknowledgeModal = true
+			a.acknowledgeModalTitle = "Cannot Send Images"
+			a.acknowledgeModalMsg = err.Error()
+			a.acknowledgeModalType = ModalTypeWarning
+			return a, nil
+		}
+	}
+
+	var cmds []tea.Cmd
+	for _, msg := range messages {
+		msg.Rendered = msg.Content
+		a.dataModel.Messages = append(a.dataModel.Messages, msg)
+		cmds = append(cmds, a.renderMarkdownAsync(len(a.dataModel.Messages)-1, msg.Content))
+	}
+	a.dataModel.SessionDirty = true
+
+	if messages[len(messages)-1].Role != "user" {
+		a.updateViewportContent(true)
+		return a, tea.Batch(append(cmds, a.dataModel.AutoSaveSession())...)
+	}
+
+	canSend, errMsg := a.dataModel.CanSendMessage()
+	if !canSend {
+		a.dataModel.Messages = append(a.dataModel.Messages, Message{
+			Role:      "system",
+			Content:   errMsg,
+			Rendered:  errMsg,
+			Timestamp: time.Now(),
+		})
+		a.updateViewportContent(true)
+		return a, tea.Batch(append(cmds, a.dataModel.AutoSaveSession())...)
+	}
+
+	// Initialize and start spinner
+	a.loadingSpinner = spinner.New()
+	a.loadingSpinner.Spinner = spinner.Dot
+	a.loadingSpinner.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("15")) // Bright white
+
+	loadingMsg := "Waiting for response..."
+	a.dataModel.Messages = append(a.dataModel.Messages, Message{
+		Role:      "system",
+		Content:   loadingMsg,
+		Rendered:  loadingMsg,
+		Timestamp: time.Now(),
+	})
+
+	a.dataModel.Streaming = true
+	a.updateViewportContent(true)
+
+	return a, tea.Batch(append(cmds, a.dataModel.SendToOllama(), a.loadingSpinner.Tick)...)
+}
+
+// promptCommandHint describes the commands matching a "/" typed in the input
+// box, for the status bar. It's empty when the input isn't a command.
+func (a AppView) promptCommandHint() string {
+	value := a.textarea.Value()
+	if !strings.HasPrefix(value, "/") || strings.Contains(value, "\n") {
+		return ""
+	}
+	prompts := a.dataModel.PromptCommands()
+	if len(prompts) == 0 {
+		return ""
+	}
+	descStyle := lipgloss.NewStyle().Foreground(successColor).Bold(true)
+
+	command, _, hasArgs := strings.Cut(value[1:], " ")
+	if hasArgs {
+		prompt, ok := appmodel.FindPrompt(prompts, command)
+		if !ok {
+			return ""
+		}
+		hint := "/" + prompt.Name
+		if usage := appmodel.PromptUsage(prompt); usage != "" {
+			hint += " " + usage
+		}
+		if prompt.Description != "" {
+			hint += " - " + prompt.Description
+		}
+		return fmt.Sprintf("%s  Tab %s  Enter %s",
+			previewLine(hint, max(a.width-30, 20)), descStyle.Render("Argument"), descStyle.Render("Run"))
+	}
+
+	var names []string
+	for _, p := range prompts {
+		if strings.HasPrefix(p.Name, command) {
+			names = append(names, "/"+p.Name)
+		}
+	}
+	if len(names) == 0 {
+		return "No matching plugin prompt"
+	}
+	return fmt.Sprintf("%s  Tab %s",
+		previewLine(strings.Join(names, "  "), max(a.width-20, 20)), descStyle.Render("Complete"))
+}
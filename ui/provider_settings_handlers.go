@@ -57,9 +57,10 @@ func (a AppView) handleProviderSettingsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd
 	switch msg.String() {
 	case "h", "left", "shift+tab":
 		// Previous tab (with wrap) - DON'T reload cache, preserves unsaved changes
-		for i, id := range providerTabs {
+		tabs := providerTabIDs(a.dataModel.Config)
+		for i, id := range tabs {
 			if id == a.providerSettingsState.selectedProviderID {
-				a.providerSettingsState.selectedProviderID = providerTabs[(i-1+len(providerTabs))%len(providerTabs)]
+				a.providerSettingsState.selectedProviderID = tabs[(i-1+len(tabs))%len(tabs)]
 				a.providerSettingsState.selectedFieldIdx = 0
 				break
 			}
@@ -68,9 +69,10 @@ func (a AppView) handleProviderSettingsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd
 
 	case "l", "right", "tab":
 		// Next tab (with wrap) - DON'T reload cache, preserves unsaved changes
-		for i, id := range providerTabs {
+		tabs := providerTabIDs(a.dataModel.Config)
+		for i, id := range tabs {
 			if id == a.providerSettingsState.selectedProviderID {
-				a.providerSettingsState.selectedProviderID = providerTabs[(i+1)%len(providerTabs)]
+				a.providerSettingsState.selectedProviderID = tabs[(i+1)%len(tabs)]
 				a.providerSettingsState.selectedFieldIdx = 0
 				break
 			}
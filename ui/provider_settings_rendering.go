@@ -70,8 +70,8 @@ func (a *AppView) renderProviderSettings(width, height int) string {
 func (a *AppView) renderProviderTabs(width int) string {
 	var tabStrs []string
 
-	for _, providerID := range providerTabs {
-		displayName := providerNames[providerID]
+	for _, providerID := range providerTabIDs(a.dataModel.Config) {
+		displayName := providerTabName(a.dataModel.Config, providerID)
 		style := lipgloss.NewStyle().Padding(0, 2)
 
 		if providerID == a.providerSettingsState.selectedProviderID {
@@ -15,7 +15,7 @@ type ProviderSettingsState struct {
 	visible bool
 
 	// Tab selection
-	selectedProviderID string // "ollama", "openrouter", "anthropic", "openai"
+	selectedProviderID string // "ollama", "openrouter", "anthropic", "openai", "gemini" or a custom provider ID
 
 	// Field selection
 	selectedFieldIdx int // 0=host/apikey, 1=enabled
@@ -31,8 +31,8 @@ type ProviderSettingsState struct {
 	saveError  string
 }
 
-// Provider tab order (for navigation)
-var providerTabs = []string{"ollama", "openrouter", "openai", "anthropic"}
+// Built-in provider tab order (for navigation); custom providers follow
+var providerTabs = []string{"ollama", "openrouter", "openai", "anthropic", "gemini"}
 
 // Provider display names
 var providerNames = map[string]string{
@@ -40,6 +40,24 @@ var providerNames = map[string]string{
 	"openrouter": "OpenRouter",
 	"openai":     "OpenAI",
 	"anthropic":  "Anthropic",
+	"gemini":     "Gemini",
+}
+
+// providerTabIDs returns the built-in tabs followed by the custom providers from config
+func providerTabIDs(cfg *config.Config) []string {
+	tabs := append([]string(nil), providerTabs...)
+	for _, prov := range cfg.CustomProviders() {
+		tabs = append(tabs, prov.ID)
+	}
+	return tabs
+}
+
+// providerTabName returns the tab label for a provider
+func providerTabName(cfg *config.Config, providerID string) string {
+	if name, ok := providerNames[providerID]; ok {
+		return name
+	}
+	return cfg.ProviderDisplayName(providerID)
 }
 
 // ProviderFieldType identifies the type of provider field
@@ -71,7 +89,7 @@ func (p *ProviderSettingsState) getProviderFields(providerID string, cfg *config
 			{Label: "API Key", Value: p.maskAPIKey(apiKey), Type: ProviderFieldTypeAPIKey},
 			{Label: "Enabled", Value: p.getProviderEnabled(cfg, "ollama"), Type: ProviderFieldTypeEnabled},
 		}
-	case "openrouter", "anthropic", "openai":
+	case "openrouter", "anthropic", "openai", "gemini":
 		apiKey := ""
 		if cfg.CredentialStore != nil {
 			apiKey = cfg.CredentialStore.Get(providerID)
@@ -81,6 +99,21 @@ func (p *ProviderSettingsState) getProviderFields(providerID string, cfg *config
 			{Label: "Enabled", Value: p.getProviderEnabled(cfg, providerID), Type: ProviderFieldTypeEnabled},
 		}
 	default:
+		// Custom OpenAI-compatible provider
+		for _, prov := range cfg.CustomProviders() {
+			if prov.ID != providerID {
+				continue
+			}
+			apiKey := ""
+			if cfg.CredentialStore != nil {
+				apiKey = cfg.CredentialStore.Get(providerID)
+			}
+			return []ProviderField{
+				{Label: "Base URL", Value: prov.BaseURL, Type: ProviderFieldTypeHost},
+				{Label: "API Key", Value: p.maskAPIKey(apiKey), Type: ProviderFieldTypeAPIKey},
+				{Label: "Enabled", Value: p.getProviderEnabled(cfg, providerID), Type: ProviderFieldTypeEnabled},
+			}
+		}
 		return []ProviderField{}
 	}
 }
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"otui/storage"
+)
+
+// sessionForkView picks the message to fork a session at, opened with "f"
+// in the session manager
+type sessionForkView struct {
+	sessionID   string
+	sessionName string
+	points      []forkPoint // Prompts and replies, oldest first
+	selected    int
+}
+
+// forkPoint is a message a session can be forked at
+type forkPoint struct {
+	index     int // Into the session's messages
+	role      string
+	preview   string
+	timestamp string
+}
+
+// newSessionForkView lists the prompts and replies of a session, with the
+// last one selected
+func newSessionForkView(session *storage.Session) *sessionForkView {
+	view := &sessionForkView{sessionID: session.ID, sessionName: session.Name}
+	for i, msg := range session.Messages {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			continue
+		}
+		view.points = append(view.points, forkPoint{
+			index:     i,
+			role:      msg.Role,
+			preview:   previewLine(msg.Content, 60),
+			timestamp: msg.Timestamp.Format("Jan 2, 15:04"),
+		})
+	}
+	view.selected = max(len(view.points)-1, 0)
+	return view
+}
+
+func renderSessionFork(view sessionForkView, width, height int) string {
+	modalWidth := width - 10
+	if modalWidth > 90 {
+		modalWidth = 90
+	}
+
+	lineStyle := lipgloss.NewStyle().Width(modalWidth)
+	dimLine := lineStyle.Foreground(dimColor)
+
+	var messageLines []string
+	messageLines = append(messageLines, dimLine.Render("  The new session gets the messages up to and including the selected one"))
+	messageLines = append(messageLines, strings.Repeat(" ", modalWidth))
+
+	if len(view.points) == 0 {
+		messageLines = append(messageLines, dimLine.Italic(true).Render("  No messages to fork at"))
+	} else {
+		// Title, blank lines, footer, hint and scroll indicators
+		maxVisible := max(height-14, 3)
+		start := max(view.selected-maxVisible+1, 0)
+		end := min(start+maxVisible, len(view.points))
+
+		if start > 0 {
+			messageLines = append(messageLines, dimLine.Render(fmt.Sprintf("  ↑ %d more above", start)))
+		}
+		for i := start; i < end; i++ {
+			point := view.points[i]
+			role := UserStyle.Render("You      ")
+			if point.role == "assistant" {
+				role = AssistantStyle.Render("Assistant")
+			}
+
+			line := fmt.Sprintf("%s %s  %s", DimStyle.Render(point.timestamp), role, point.preview)
+			if i == view.selected {
+				line = SelectedStyle.Render("▶ ") + line
+			} else {
+				line = "  " + line
+			}
+			messageLines = append(messageLines, lineStyle.Render(line))
+		}
+		if end < len(view.points) {
+			messageLines = append(messageLines, dimLine.Render(fmt.Sprintf("  ↓ %d more below", len(view.points)-end)))
+		}
+	}
+
+	title := "Fork: " + view.sessionName
+	footer := FormatFooter("j/k", "Navigate", "Enter", "Fork here", "Esc", "Back")
+
+	return RenderThreeSectionModal(title, messageLines, footer, ModalTypeInfo, modalWidth, width, height)
+}
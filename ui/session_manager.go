@@ -150,6 +150,9 @@ func renderSessionManager(a AppView,sessions []storage.SessionMetadata, selected
 				}
 			}
 
+			// Forked sessions are marked after the name too
+			isFork := session.ParentID != "" && !renameMode
+
 			// Check if this is the current session (will add marker after spacing calculation)
 			hasCurrentMarker := false
 			if session.ID == currentSessionID && !renameMode {
@@ -200,6 +203,9 @@ func renderSessionManager(a AppView,sessions []storage.SessionMetadata, selected
 			if hasBullet {
 				spacing -= 2 // " •" = 2 visible characters
 			}
+			if isFork {
+				spacing -= 2 // " ⑂" = 2 visible characters
+			}
 
 			if spacing < 2 {
 				spacing = 2
@@ -219,6 +225,10 @@ func renderSessionManager(a AppView,sessions []storage.SessionMetadata, selected
 				bulletStyled := lipgloss.NewStyle().Foreground(accentColor).Render("•")
 				leftSide = leftSide + " " + bulletStyled
 			}
+			if isFork {
+				forkStyled := lipgloss.NewStyle().Foreground(dimColor).Render("⑂")
+				leftSide = leftSide + " " + forkStyled
+			}
 
 			// Style the right side individually BEFORE building line
 			rightSideStyled := rightSide
@@ -251,7 +261,7 @@ func renderSessionManager(a AppView,sessions []storage.SessionMetadata, selected
 	} else if filterMode {
 		footerText = FormatFooter("Type", "to filter", a.formatKeyDisplay("primary", "J/K"), "Navigate", "Enter", "Load", "Esc", "Cancel")
 	} else {
-		footerText = FormatFooter("/", "Filter", "j/k", "Navigate", "Enter", "Load", "e", "Edit", "i", "Import", "n", "New", "r", "Rename", "x", "Export", "d", "Delete", "Esc", "Exit")
+		footerText = FormatFooter("/", "Filter", "j/k", "Navigate", "Enter", "Load", "e", "Edit", "i", "Import", "n", "New", "r", "Rename", "u", "Usage", "f", "Fork", "x", "Export", "d", "Delete", "Esc", "Exit")
 	}
 	// Footer section (with top border only)
 	footerSection := lipgloss.NewStyle().
@@ -376,7 +386,8 @@ func renderExportModal(a AppView, exportInput textinput.Model, exporting bool, c
 	}
 
 	// State 1: Input mode (borderless 3-section)
-	title := "Export Session to JSON"
+	opts := a.sessionExportOptions
+	title := "Export Session to " + opts.Format.Label()
 
 	var messageLines []string
 	messageLines = append(messageLines, strings.Repeat(" ", modalWidth)) // Top padding
@@ -395,9 +406,36 @@ func renderExportModal(a AppView, exportInput textinput.Model, exporting bool, c
 		Render("  " + exportInput.View())
 
 	messageLines = append(messageLines, inputLine)
+	messageLines = append(messageLines, strings.Repeat(" ", modalWidth))
+
+	var formats []string
+	for _, f := range storage.ExportFormats {
+		if f == opts.Format {
+			formats = append(formats, SelectedStyle.Render("["+f.Label()+"]"))
+		} else {
+			formats = append(formats, DimStyle.Render(f.Label()))
+		}
+	}
+	messageLines = append(messageLines, promptStyle.Render("  Format:  "+strings.Join(formats, "  ")))
+
+	if opts.Format == storage.ExportJSON {
+		messageLines = append(messageLines, promptStyle.Foreground(dimColor).Render("  Includes everything, and can be imported again"))
+	} else {
+		checkbox := func(on bool, key, label string) string {
+			box := "[ ]"
+			if on {
+				box = "[x]"
+			}
+			return fmt.Sprintf("%s %s %s", box, label, DimStyle.Render("("+a.formatKeyDisplay("primary", key)+")"))
+		}
+		messageLines = append(messageLines, promptStyle.Render("  Include:"))
+		messageLines = append(messageLines, promptStyle.Render("    "+checkbox(opts.ToolCalls, "T", "Tool calls and results")))
+		messageLines = append(messageLines, promptStyle.Render("    "+checkbox(opts.SystemPrompt, "P", "System prompt")))
+		messageLines = append(messageLines, promptStyle.Render("    "+checkbox(opts.CompactionSummary, "C", "Compaction summary")))
+	}
 	messageLines = append(messageLines, strings.Repeat(" ", modalWidth)) // Bottom padding
 
-	footer := fmt.Sprintf("Esc Cancel  Enter Export  %s Clear", a.formatKeyDisplay("primary", "U"))
+	footer := fmt.Sprintf("Esc Cancel  Enter Export  Tab Format  %s Clear", a.formatKeyDisplay("primary", "U"))
 
 	return RenderThreeSectionModal(
 		title,
@@ -550,7 +588,7 @@ func renderImportSuccess(session *storage.Session, width, height int) string {
 	)
 }
 
-func renderSessionModal(a AppView, title string, nameInput textinput.Model, promptInput textarea.Model, focusedField int, width, height int, availablePlugins []mcp.Plugin, enabledPluginIDs []string, unavailablePluginIDs []string, selectedPluginIdx int) string {
+func renderSessionModal(a AppView, title string, nameInput textinput.Model, promptInput textarea.Model, focusedField int, width, height int, availablePlugins []mcp.Plugin, enabledPluginIDs []string, unavailablePluginIDs []string, selectedPluginIdx int, paramsInput textinput.Model, paramsError string, fallbackInput textinput.Model, fallbackError string) string {
 	modalWidth := width - 10
 	if modalWidth > 80 {
 		modalWidth = 80
@@ -713,6 +751,41 @@ func renderSessionModal(a AppView, title string, nameInput textinput.Model, prom
 		}
 	}
 
+	// Generation parameters field (edit mode only)
+	if title == "Edit session" {
+		messageLines = append(messageLines, strings.Repeat(" ", modalWidth)) // Spacing
+
+		paramsLabelStyle := lipgloss.NewStyle().Width(modalWidth)
+		paramsStyle := lipgloss.NewStyle().Width(modalWidth)
+		if focusedField == 3 {
+			paramsLabelStyle = paramsLabelStyle.Foreground(successColor).Bold(true)
+			paramsStyle = paramsStyle.Foreground(accentColor).Bold(true)
+		}
+		messageLines = append(messageLines, paramsLabelStyle.Render("  Generation Parameters:"))
+		messageLines = append(messageLines, paramsStyle.Render("  "+paramsInput.View()))
+		if paramsError != "" {
+			messageLines = append(messageLines, lipgloss.NewStyle().Foreground(dangerColor).Width(modalWidth).Render("  "+paramsError))
+		} else {
+			messageLines = append(messageLines, lipgloss.NewStyle().Foreground(dimColor).Width(modalWidth).Render("  temperature, top_p, max_tokens, seed, num_ctx (Ollama)"))
+		}
+
+		messageLines = append(messageLines, strings.Repeat(" ", modalWidth)) // Spacing
+
+		fallbackLabelStyle := lipgloss.NewStyle().Width(modalWidth)
+		fallbackStyle := lipgloss.NewStyle().Width(modalWidth)
+		if focusedField == 4 {
+			fallbackLabelStyle = fallbackLabelStyle.Foreground(successColor).Bold(true)
+			fallbackStyle = fallbackStyle.Foreground(accentColor).Bold(true)
+		}
+		messageLines = append(messageLines, fallbackLabelStyle.Render("  Fallback Models:"))
+		messageLines = append(messageLines, fallbackStyle.Render("  "+fallbackInput.View()))
+		if fallbackError != "" {
+			messageLines = append(messageLines, lipgloss.NewStyle().Foreground(dangerColor).Width(modalWidth).Render("  "+fallbackError))
+		} else {
+			messageLines = append(messageLines, lipgloss.NewStyle().Foreground(dimColor).Width(modalWidth).Render("  provider:model, tried in order when the session's model fails"))
+		}
+	}
+
 	messageLines = append(messageLines, strings.Repeat(" ", modalWidth)) // Bottom padding
 
 	messageSection := lipgloss.NewStyle().
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"otui/storage"
+)
+
+// sessionUsageView is the usage breakdown opened with "u" in the session manager
+type sessionUsageView struct {
+	sessionName string
+	summaries   []storage.UsageSummary // Per provider and model, most expensive first
+	totals      storage.UsageTotals    // This session
+	allTotals   storage.UsageTotals    // Every session in the ledger
+}
+
+// loadSessionUsage reads a session's usage from the ledger
+func loadSessionUsage(sessionStorage *storage.SessionStorage, session storage.SessionMetadata) (*sessionUsageView, error) {
+	ledger := sessionStorage.Usage()
+
+	summaries, err := ledger.Summarize(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	allTotals, err := ledger.SessionTotals("")
+	if err != nil {
+		return nil, err
+	}
+
+	view := &sessionUsageView{
+		sessionName: session.Name,
+		summaries:   summaries,
+		allTotals:   allTotals,
+	}
+	for _, summary := range summaries {
+		view.totals.Requests += summary.Requests
+		view.totals.InputTokens += summary.InputTokens
+		view.totals.OutputTokens += summary.OutputTokens
+		view.totals.Cost += summary.Cost
+		view.totals.Unpriced += summary.Unpriced
+	}
+	return view, nil
+}
+
+func renderSessionUsage(view sessionUsageView, width, height int) string {
+	modalWidth := width - 10
+	if modalWidth > 90 {
+		modalWidth = 90
+	}
+
+	lineStyle := lipgloss.NewStyle().Width(modalWidth)
+	dimLine := lineStyle.Foreground(dimColor)
+
+	var messageLines []string
+	if len(view.summaries) == 0 {
+		messageLines = append(messageLines, dimLine.Italic(true).Render("  No usage recorded for this session yet"))
+	} else {
+		nameWidth := modalWidth - 48
+		if nameWidth < 12 {
+			nameWidth = 12
+		}
+		row := func(name, requests, input, output, cost string) string {
+			if len(name) > nameWidth {
+				name = name[:nameWidth-3] + "..."
+			}
+			return fmt.Sprintf("  %-*s %5s %11s %11s %11s", nameWidth, name, requests, input, output, cost)
+		}
+
+		messageLines = append(messageLines, lineStyle.Bold(true).Foreground(accentColor).Render(row("Provider / model", "Reqs", "Input", "Output", "Cost")))
+		for _, summary := range view.summaries {
+			messageLines = append(messageLines, lineStyle.Render(row(
+				summary.Provider+" / "+summary.Model,
+				fmt.Sprint(summary.Requests),
+				fmt.Sprint(summary.InputTokens),
+				fmt.Sprint(summary.OutputTokens),
+				formatUsageCost(summary.UsageTotals),
+			)))
+		}
+		messageLines = append(messageLines, dimLine.Render("  "+strings.Repeat("─", modalWidth-4)))
+		messageLines = append(messageLines, lineStyle.Bold(true).Render(row(
+			"Session total",
+			fmt.Sprint(view.totals.Requests),
+			fmt.Sprint(view.totals.InputTokens),
+			fmt.Sprint(view.totals.OutputTokens),
+			formatUsageCost(view.totals),
+		)))
+	}
+
+	messageLines = append(messageLines, strings.Repeat(" ", modalWidth))
+	messageLines = append(messageLines, dimLine.Render(fmt.Sprintf("  All sessions: %d requests, %s",
+		view.allTotals.Requests, formatUsageCost(view.allTotals))))
+	if view.totals.Unpriced > 0 || view.allTotals.Unpriced > 0 {
+		messageLines = append(messageLines, dimLine.Render("  * excludes requests to models without a price; add them under [prices] in config.toml"))
+	}
+
+	title := "Usage: " + view.sessionName
+	footer := FormatFooter("Esc", "Back")
+
+	return RenderThreeSectionModal(title, messageLines, footer, ModalTypeInfo, modalWidth, width, height)
+}
+
+// formatCost formats a USD amount, keeping sub-cent precision for small totals
+func formatCost(cost float64) string {
+	if cost < 1 {
+		return fmt.Sprintf("$%.4f", cost)
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
+
+// formatUsageCost formats the cost of some usage, "—" when nothing was priced
+// and marked with "*" when some requests were unpriced
+func formatUsageCost(totals storage.UsageTotals) string {
+	if totals.Requests > 0 && totals.Unpriced == totals.Requests {
+		return "—"
+	}
+	cost := formatCost(totals.Cost)
+	if totals.Unpriced > 0 {
+		cost += "*"
+	}
+	return cost
+}
@@ -93,7 +93,7 @@ func (a AppView) handleSettingsInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Refresh provider settings cache (only if map exists - screen may be closed)
 			if a.providerSettingsState.currentFieldsMap != nil {
-				for _, providerID := range providerTabs {
+				for _, providerID := range providerTabIDs(msg.cfg) {
 					a.providerSettingsState.currentFieldsMap[providerID] = a.providerSettingsState.getProviderFields(providerID, msg.cfg)
 				}
 			}
@@ -288,8 +288,11 @@ func (a AppView) handleSettingsInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Data dir didn't change - update session storage
-		newStorage, err := storage.NewSessionStorage(newDataDir)
+		newStorage, err := storage.OpenSessionStorage(newDataDir, a.dataModel.Config.SessionStore)
 		if err == nil {
+			if a.dataModel.SessionStorage != nil {
+				_ = a.dataModel.SessionStorage.Close()
+			}
 			a.dataModel.SessionStorage = newStorage
 			a.dataModel.SearchIndex = storage.NewSearchIndex(newStorage)
 		}
@@ -538,7 +541,7 @@ func (a AppView) handleSettingsNavigationMode(msg tea.KeyMsg) (tea.Model, tea.Cm
 				config.DebugLog.Printf("[CacheInit] ========== INITIALIZING PROVIDER CACHE ==========")
 			}
 
-			for _, providerID := range providerTabs {
+			for _, providerID := range providerTabIDs(a.dataModel.Config) {
 				a.providerSettingsState.currentFieldsMap[providerID] = a.providerSettingsState.getProviderFields(
 					providerID,
 					a.dataModel.Config,
@@ -188,6 +188,7 @@ func NewWelcomeModel(kb *config.KeyBindingsConfig) WelcomeModel {
 		{ID: "openrouter", Name: "OpenRouter", Enabled: false, BaseURL: "https://openrouter.ai/api/v1"},
 		{ID: "openai", Name: "OpenAI", Enabled: false, BaseURL: "https://api.openai.com/v1"},
 		{ID: "anthropic", Name: "Anthropic", Enabled: false, BaseURL: "https://api.anthropic.com"},
+		{ID: "gemini", Name: "Gemini", Enabled: false, BaseURL: "https://generativelanguage.googleapis.com/v1beta"},
 	}
 
 	// Detect restart scenario (system config exists but user config doesn't)